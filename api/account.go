@@ -24,15 +24,29 @@ func (server *Server) createAccount(ctx *gin.Context) {
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
+	idempotency, hasIdempotencyKey, err := server.idempotencyParams(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
 	arg := db.CreateAccountParams{
 		Owner:    authPayload.Username,
 		Currency: req.Currency,
-		Balance:  0,
 	}
 
-	account, err := server.store.CreateAccount(ctx, arg)
+	var account db.Account
+	if hasIdempotencyKey {
+		account, err = server.store.IdempotentCreateAccountTx(ctx, idempotency, arg)
+	} else {
+		account, err = server.store.CreateAccount(ctx, arg)
+	}
 
 	if err != nil {
+		if errors.Is(err, db.ErrIdempotencyKeyReused) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
 		if pgErr, ok := err.(*pgconn.PgError); ok {
 			switch pgErr.ConstraintName {
 			case "owner_username_fk", "owner_currency_key":
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/token"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type listAccountEntriesURI struct {
+	ID int64 `uri:"id" binding:"min=1"`
+}
+
+type listAccountEntriesQuery struct {
+	FromTime  time.Time `form:"from_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	ToTime    time.Time `form:"to_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	Direction string    `form:"direction" binding:"omitempty,oneof=credit debit"`
+	Cursor    int64     `form:"cursor" binding:"min=0"`
+	PageSize  int32     `form:"page_size" binding:"required,min=1,max=100"`
+}
+
+type accountEntriesResponse struct {
+	AccountID      int64                      `json:"account_id"`
+	Currency       string                     `json:"currency"`
+	OpeningBalance int64                      `json:"opening_balance"`
+	ClosingBalance int64                      `json:"closing_balance"`
+	Entries        []db.ListAccountEntriesRow `json:"entries"`
+	NextCursor     int64                      `json:"next_cursor"`
+}
+
+func (server *Server) listAccountEntries(ctx *gin.Context) {
+	var uri listAccountEntriesURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listAccountEntriesQuery
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !req.FromTime.IsZero() && !req.ToTime.IsZero() && !req.FromTime.Before(req.ToTime) {
+		err := errors.New("from_time must be before to_time")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != account.Owner {
+		err = errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	arg := db.AccountStatementTxParams{
+		AccountID: account.ID,
+		FromTime:  pgtype.Timestamptz{Time: req.FromTime, Valid: !req.FromTime.IsZero()},
+		ToTime:    pgtype.Timestamptz{Time: req.ToTime, Valid: !req.ToTime.IsZero()},
+		AfterID:   req.Cursor,
+		PageSize:  req.PageSize,
+	}
+	if req.Direction != "" {
+		arg.Direction = &req.Direction
+	}
+
+	result, err := server.store.AccountStatementTx(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := accountEntriesResponse{
+		AccountID:      account.ID,
+		Currency:       account.Currency,
+		OpeningBalance: result.OpeningBalance,
+		ClosingBalance: result.ClosingBalance,
+		Entries:        result.Entries,
+		NextCursor:     result.NextAfterID,
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
@@ -1,27 +1,42 @@
 package api
 
 import (
+	"context"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/token"
 	"github.com/hykura1501/simple_bank/util"
 	"github.com/stretchr/testify/require"
 )
 
 func newTestServer(t *testing.T, store db.Store) *Server {
 	config := util.Config{
-		TokenSymmetricKey:   util.RandomString(32),
-		AccessTokenDuration: time.Minute,
+		TokenSymmetricKey:      util.RandomString(32),
+		AccessTokenDuration:    time.Minute,
+		IdempotencyKeyDuration: time.Hour,
 	}
 
-	server, err := NewServer(config, store)
+	server, err := NewServer(config, store, nil)
 	require.NoError(t, err)
 
+	// the revocation cache would query the mock store on every authenticated request
+	server.revocations = revocationCheckerFunc(func(ctx context.Context, payload *token.Payload) error {
+		return nil
+	})
+	require.NoError(t, server.setupRouter())
+
 	return server
+}
+
+// revocationCheckerFunc lets tests decide which tokens are revoked
+type revocationCheckerFunc func(ctx context.Context, payload *token.Payload) error
 
+func (f revocationCheckerFunc) Check(ctx context.Context, payload *token.Payload) error {
+	return f(ctx, payload)
 }
 
 func TestMain(m *testing.M) {
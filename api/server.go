@@ -7,60 +7,140 @@ import (
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/fx"
+	"github.com/hykura1501/simple_bank/lockout"
+	"github.com/hykura1501/simple_bank/mfa"
+	"github.com/hykura1501/simple_bank/revocation"
 	"github.com/hykura1501/simple_bank/token"
 	"github.com/hykura1501/simple_bank/util"
+	"github.com/hykura1501/simple_bank/validation"
 )
 
+// idempotencyKeyHeader lets clients retry a request without executing it twice
+const idempotencyKeyHeader = "Idempotency-Key"
+
 type Server struct {
-	store      db.Store
-	tokenMaker token.Maker
-	config     util.Config
-	router     *gin.Engine
+	store         db.Store
+	tokenMaker    token.Maker
+	rateProvider  fx.RateProvider
+	roundingMode  fx.RoundingMode
+	revocations   revocation.Checker
+	loginLimiter  *lockout.Limiter
+	mfaThresholds mfa.TransferThresholds
+	passwords     *validation.PasswordPolicy
+	hasher        *util.PasswordHasher
+	config        util.Config
+	router        *gin.Engine
 }
 
-func NewServer(config util.Config, store db.Store) (*Server, error) {
-	tokenMaker, err := token.NewJWTMaker(config.TokenSymmetricKey)
+func NewServer(config util.Config, store db.Store, keyring *token.Keyring) (*Server, error) {
+	tokenMaker, err := token.NewMaker(config, keyring)
 
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
+	rateProvider, err := fx.NewRateProvider(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create exchange rate provider: %w", err)
+	}
+
+	roundingMode, err := fx.ParseRoundingMode(config.FXRoundingMode)
+	if err != nil {
+		return nil, err
+	}
+
+	mfaThresholds, err := mfa.ParseTransferThresholds(config.MFATransferThresholds)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse MFA_TRANSFER_THRESHOLDS: %w", err)
+	}
+
+	loginAttempts, err := lockout.NewStore(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create login attempt store: %w", err)
+	}
+
+	passwords, err := validation.NewPasswordPolicy(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password policy: %w", err)
+	}
+
+	hasher, err := util.NewPasswordHasher(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password hasher: %w", err)
+	}
+
 	server := &Server{
-		store:      store,
-		config:     config,
-		tokenMaker: tokenMaker,
+		store:         store,
+		config:        config,
+		tokenMaker:    tokenMaker,
+		rateProvider:  rateProvider,
+		roundingMode:  roundingMode,
+		revocations:   revocation.NewCache(revocation.NewDBStore(store), config.RevocationCacheTTL),
+		loginLimiter:  lockout.NewLimiter(loginAttempts, config),
+		mfaThresholds: mfaThresholds,
+		passwords:     passwords,
+		hasher:        hasher,
 	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("currency", validCurrency)
 	}
 
-	server.setupRouter()
+	if err := server.setupRouter(); err != nil {
+		return nil, err
+	}
 	return server, nil
 }
 
-func (server *Server) setupRouter() {
+func (server *Server) setupRouter() error {
 	router := gin.Default()
 
+	// gin trusts the X-Forwarded-For header of every proxy by default, which lets a client pick its IP address
+	if err := router.SetTrustedProxies(server.config.TrustedProxies); err != nil {
+		return fmt.Errorf("cannot set TRUSTED_PROXIES: %w", err)
+	}
+
 	router.POST("/users", server.createUser)
 	router.POST("/users/login", server.loginUser)
 	router.POST("/tokens/renew_access", server.renewAccessToken)
 
-	authGroups := router.Group("/").Use(authMiddleware(server.tokenMaker))
+	authGroups := router.Group("/").Use(authMiddleware(server.tokenMaker, server.revocations))
 
 	authGroups.POST("/accounts", server.createAccount)
 	authGroups.GET("/accounts/:id", server.getAccount)
 	authGroups.GET("/accounts", server.listAccount)
+	authGroups.GET("/accounts/:id/entries", server.listAccountEntries)
 
 	authGroups.POST("/transfers", server.createTransfer)
 
 	server.router = router
+	return nil
 }
 
 func (server *Server) StartServer(address string) error {
 	return server.router.Run(address)
 }
 
+// idempotencyParams reads the optional Idempotency-Key header of the request.
+// It returns false if the header is missing
+func (server *Server) idempotencyParams(ctx *gin.Context, username string) (db.IdempotencyParams, bool, error) {
+	key := ctx.GetHeader(idempotencyKeyHeader)
+	if key == "" {
+		return db.IdempotencyParams{}, false, nil
+	}
+
+	if err := validation.ValidateIdempotencyKey(key); err != nil {
+		return db.IdempotencyParams{}, false, fmt.Errorf("invalid %s header: %w", idempotencyKeyHeader, err)
+	}
+
+	return db.IdempotencyParams{
+		Key:      key,
+		Username: username,
+		Duration: server.config.IdempotencyKeyDuration,
+	}, true, nil
+}
+
 func errorResponse(err error) gin.H {
 	return gin.H{
 		"error": err.Error(),
@@ -6,7 +6,10 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/token"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type renewAccessTokenRequest struct {
@@ -14,8 +17,10 @@ type renewAccessTokenRequest struct {
 }
 
 type renewAccessTokenResponse struct {
-	AccessToken          string    `json:"access_token"`
-	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
 }
 
 func (server *Server) renewAccessToken(ctx *gin.Context) {
@@ -31,6 +36,16 @@ func (server *Server) renewAccessToken(ctx *gin.Context) {
 		return
 	}
 
+	if err := payload.CheckType(token.TokenTypeRefresh); err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if err := server.revocations.Check(ctx, payload); err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
 	session, err := server.store.GetSession(ctx, payload.ID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -42,6 +57,16 @@ func (server *Server) renewAccessToken(ctx *gin.Context) {
 		return
 	}
 
+	if session.IsUsed {
+		// a rotated refresh token is presented again, so it may have been stolen
+		if _, err := server.store.BlockSessionFamily(ctx, session.FamilyID); err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusUnauthorized, errorResponse(db.ErrRefreshTokenReused))
+		return
+	}
+
 	if session.IsBlocked {
 		err = errors.New("blocked session")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
@@ -66,15 +91,50 @@ func (server *Server) renewAccessToken(ctx *gin.Context) {
 		return
 	}
 
-	accessToken, accessTokenPayload, err := server.tokenMaker.CreateToken(payload.Username, server.config.AccessTokenDuration)
+	accessToken, accessTokenPayload, err := server.tokenMaker.CreateToken(payload.Username, payload.Role, session.FamilyID, token.TokenTypeAccess, server.config.AccessTokenDuration)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
+	// the rotated refresh token keeps the expiry of the login session
+	refreshToken, refreshTokenPayload, err := server.tokenMaker.CreateToken(payload.Username, payload.Role, session.FamilyID, token.TokenTypeRefresh, time.Until(session.ExpiredAt.Time))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	arg := db.RotateSessionTxParams{
+		OldSession: session,
+		NewSession: db.CreateSessionParams{
+			ID:           refreshTokenPayload.ID,
+			Username:     session.Username,
+			RefreshToken: refreshToken,
+			UserAgent:    ctx.Request.UserAgent(),
+			ClientIp:     ctx.ClientIP(),
+			IsBlocked:    false,
+			ExpiredAt: pgtype.Timestamptz{
+				Time:  refreshTokenPayload.ExpiredAt,
+				Valid: true,
+			},
+		},
+	}
+
+	_, err = server.store.RotateSessionTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrRefreshTokenReused) {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
 	renewAccessTokenResponse := renewAccessTokenResponse{
-		AccessToken:          accessToken,
-		AccessTokenExpiresAt: accessTokenPayload.ExpiredAt,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessTokenPayload.ExpiredAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshTokenPayload.ExpiredAt,
 	}
 
 	ctx.JSON(http.StatusOK, renewAccessTokenResponse)
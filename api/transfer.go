@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/fx"
 	"github.com/hykura1501/simple_bank/token"
 	"github.com/jackc/pgx/v5"
 )
@@ -38,28 +39,70 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 	if !valid {
 		return
 	}
-	_, valid = server.validAccount(ctx, req.ToAccountID, req.Currency)
+
+	if !server.verifiedUser(ctx, authPayload.Username) {
+		return
+	}
+
+	if !server.allowedWithoutMFA(ctx, authPayload.Username, fromAcc.Currency, req.Amount) {
+		return
+	}
+
+	toAcc, valid := server.existingAccount(ctx, req.ToAccountID)
 
 	if !valid {
 		return
 	}
 
-	arg := db.CreateTransferParams{
+	idempotency, hasIdempotencyKey, err := server.idempotencyParams(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.TransferTxParams{
 		FromAccountID: req.FromAccountID,
 		ToAccountID:   req.ToAccountID,
 		Amount:        req.Amount,
 	}
 
-	result, err := server.store.TransferTx(ctx, arg)
+	if fromAcc.Currency != toAcc.Currency {
+		rate, err := server.rateProvider.GetRate(ctx, fromAcc.Currency, toAcc.Currency)
+		if err != nil {
+			if errors.Is(err, fx.ErrRateNotFound) {
+				ctx.JSON(http.StatusBadRequest, errorResponse(err))
+				return
+			}
+			ctx.JSON(http.StatusBadGateway, errorResponse(err))
+			return
+		}
+		arg.ExchangeRate = &rate
+		arg.RoundingMode = server.roundingMode
+	}
+
+	var result db.TransferTxResult
+	if hasIdempotencyKey {
+		result, err = server.store.IdempotentTransferTx(ctx, idempotency, arg)
+	} else {
+		result, err = server.store.TransferTx(ctx, arg)
+	}
 
 	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrAccountFrozen) || errors.Is(err, db.ErrIdempotencyKeyReused) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrExchangeRateMismatch) || errors.Is(err, fx.ErrAmountTooSmall) || errors.Is(err, fx.ErrAmountTooLarge) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 	ctx.JSON(http.StatusOK, result)
 }
 
-func (server *Server) validAccount(ctx *gin.Context, accID int64, currency string) (db.Account, bool) {
+func (server *Server) existingAccount(ctx *gin.Context, accID int64) (db.Account, bool) {
 	acc, err := server.store.GetAccount(ctx, accID)
 
 	if err != nil {
@@ -71,8 +114,61 @@ func (server *Server) validAccount(ctx *gin.Context, accID int64, currency strin
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return acc, false
 	}
+	return acc, true
+}
+
+func (server *Server) verifiedUser(ctx *gin.Context, username string) bool {
+	user, err := server.store.GetUser(ctx, username)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if !user.IsEmailVerified {
+		err := errors.New("email address must be verified before creating transfers")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return false
+	}
+	return true
+}
+
+// allowedWithoutMFA checks that the transfer doesn't need a TOTP code.
+// Transfers that need one are only implemented by the gRPC gateway
+func (server *Server) allowedWithoutMFA(ctx *gin.Context, username string, currency string, amount int64) bool {
+	threshold, exceeds := server.mfaThresholds.Exceeds(currency, amount)
+	if !exceeds {
+		return true
+	}
+
+	userMFA, err := server.store.GetUserMFA(ctx, username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return true
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if userMFA.IsEnabled {
+		err := fmt.Errorf("transfers above %d %s require two-factor authentication", threshold, currency)
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return false
+	}
+	return true
+}
+
+func (server *Server) validAccount(ctx *gin.Context, accID int64, currency string) (db.Account, bool) {
+	acc, valid := server.existingAccount(ctx, accID)
+	if !valid {
+		return acc, false
+	}
+
 	if acc.Currency != currency {
-		err = fmt.Errorf("account [%d] currency mismatch: %s vs %s", accID, acc.Currency, currency)
+		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", accID, acc.Currency, currency)
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return acc, false
 	}
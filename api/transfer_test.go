@@ -13,6 +13,7 @@ import (
 	"github.com/golang/mock/gomock"
 	mockdb "github.com/hykura1501/simple_bank/db/mock"
 	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/fx"
 	"github.com/hykura1501/simple_bank/token"
 	"github.com/hykura1501/simple_bank/util"
 	"github.com/jackc/pgx/v5"
@@ -22,6 +23,7 @@ import (
 func TestCreateTransfer(t *testing.T) {
 	user1, _ := randomUser(t)
 	user2, _ := randomUser(t)
+	user1.IsEmailVerified = true
 	acc1 := randomAccount(user1.Username)
 	acc2 := randomAccount(user2.Username)
 
@@ -80,7 +82,7 @@ func TestCreateTransfer(t *testing.T) {
 		Currency:      util.VND,
 	}
 
-	argTransfer := db.CreateTransferParams{
+	argTransfer := db.TransferTxParams{
 		FromAccountID: req.FromAccountID,
 		ToAccountID:   req.ToAccountID,
 		Amount:        req.Amount,
@@ -105,10 +107,11 @@ func TestCreateTransfer(t *testing.T) {
 			name:            "OK",
 			transferRequest: req,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAcc.Owner, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAcc.Owner, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), fromAcc.ID).Times(1).Return(fromAcc, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
 				store.EXPECT().GetAccount(gomock.Any(), toAcc.ID).Times(1).Return(toAcc, nil)
 
 				store.EXPECT().
@@ -125,7 +128,7 @@ func TestCreateTransfer(t *testing.T) {
 			name:            "InvalidID",
 			transferRequest: invalidIDReq,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAcc.Owner, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAcc.Owner, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
@@ -140,7 +143,7 @@ func TestCreateTransfer(t *testing.T) {
 			name:            "InvalidAmount",
 			transferRequest: invalidAmountReq,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAcc.Owner, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAcc.Owner, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
@@ -155,7 +158,7 @@ func TestCreateTransfer(t *testing.T) {
 			name:            "NotFoundFromAcc",
 			transferRequest: req,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAcc.Owner, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAcc.Owner, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), fromAcc.ID).Times(1).Return(db.Account{}, pgx.ErrNoRows)
@@ -169,10 +172,11 @@ func TestCreateTransfer(t *testing.T) {
 			name:            "NotFoundToAcc",
 			transferRequest: req,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAcc.Owner, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAcc.Owner, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), fromAcc.ID).Times(1).Return(fromAcc, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
 				store.EXPECT().GetAccount(gomock.Any(), toAcc.ID).Times(1).Return(db.Account{}, pgx.ErrNoRows)
 				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
 			},
@@ -184,7 +188,7 @@ func TestCreateTransfer(t *testing.T) {
 			name:            "InternalError_1",
 			transferRequest: req,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAcc.Owner, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAcc.Owner, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), fromAcc.ID).Times(1).Return(db.Account{}, sql.ErrConnDone)
@@ -198,10 +202,11 @@ func TestCreateTransfer(t *testing.T) {
 			name:            "InternalError_2",
 			transferRequest: req,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAcc.Owner, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAcc.Owner, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), fromAcc.ID).Times(1).Return(fromAcc, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
 				store.EXPECT().GetAccount(gomock.Any(), toAcc.ID).Times(1).Return(toAcc, nil)
 				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(argTransfer)).Times(1).Return(db.TransferTxResult{}, sql.ErrConnDone)
 			},
@@ -209,11 +214,46 @@ func TestCreateTransfer(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name:            "InsufficientFunds",
+			transferRequest: req,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAcc.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAcc.ID).Times(1).Return(fromAcc, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAcc.ID).Times(1).Return(toAcc, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(argTransfer)).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:            "EmailNotVerified",
+			transferRequest: req,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAcc.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				unverifiedUser := user1
+				unverifiedUser.IsEmailVerified = false
+
+				store.EXPECT().GetAccount(gomock.Any(), fromAcc.ID).Times(1).Return(fromAcc, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(unverifiedUser, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAcc.ID).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name:            "InvalidCurrency",
 			transferRequest: invalidCurrencyReq,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAcc.Owner, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAcc.Owner, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), fromAcc.ID).Times(1).Return(fromAcc, nil)
@@ -252,6 +292,231 @@ func TestCreateTransfer(t *testing.T) {
 	}
 }
 
+func TestCreateTransferIdempotencyKey(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	user1.IsEmailVerified = true
+	fromAcc := randomAccount(user1.Username)
+	toAcc := randomAccount(user2.Username)
+	fromAcc.Currency = util.USD
+	toAcc.Currency = util.USD
+
+	req := transferRequest{
+		FromAccountID: fromAcc.ID,
+		ToAccountID:   toAcc.ID,
+		Amount:        10,
+		Currency:      util.USD,
+	}
+
+	argTransfer := db.TransferTxParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+	}
+
+	key := util.RandomString(32)
+	idempotency := db.IdempotencyParams{
+		Key:      key,
+		Username: fromAcc.Owner,
+		Duration: time.Hour,
+	}
+
+	result := db.TransferTxResult{
+		Transfer: db.Transfer{
+			FromAccountID: fromAcc.ID,
+			ToAccountID:   toAcc.ID,
+			Amount:        req.Amount,
+		},
+		FromAccount: fromAcc,
+		ToAccount:   toAcc,
+	}
+
+	testCases := []struct {
+		name           string
+		idempotencyKey string
+		buildStubs     func(store *mockdb.MockStore)
+		checkResponse  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:           "OK",
+			idempotencyKey: key,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAcc.ID).Times(1).Return(fromAcc, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAcc.ID).Times(1).Return(toAcc, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					IdempotentTransferTx(gomock.Any(), gomock.Eq(idempotency), gomock.Eq(argTransfer)).
+					Times(1).
+					Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchTransferTxResult(t, recorder.Body, result)
+			},
+		},
+		{
+			name:           "KeyReused",
+			idempotencyKey: key,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAcc.ID).Times(1).Return(fromAcc, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAcc.ID).Times(1).Return(toAcc, nil)
+				store.EXPECT().
+					IdempotentTransferTx(gomock.Any(), gomock.Eq(idempotency), gomock.Eq(argTransfer)).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrIdempotencyKeyReused)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:           "InvalidKey",
+			idempotencyKey: util.RandomString(256),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAcc.ID).Times(1).Return(fromAcc, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAcc.ID).Times(1).Return(toAcc, nil)
+				store.EXPECT().IdempotentTransferTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			bodyData, err := json.Marshal(req)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(bodyData))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, fromAcc.Owner, util.DepositorRole, time.Minute)
+			request.Header.Set(idempotencyKeyHeader, tc.idempotencyKey)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateTransferCrossCurrency(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	user1.IsEmailVerified = true
+	fromAcc := randomAccount(user1.Username)
+	toAcc := randomAccount(user2.Username)
+	fromAcc.Currency = util.USD
+
+	rateProvider, err := fx.NewStaticProvider(util.USD, map[string]string{util.EUR: "0.92"})
+	require.NoError(t, err)
+
+	req := transferRequest{
+		FromAccountID: fromAcc.ID,
+		ToAccountID:   toAcc.ID,
+		Amount:        100,
+		Currency:      util.USD,
+	}
+
+	testCases := []struct {
+		name          string
+		toCurrency    string
+		buildStubs    func(store *mockdb.MockStore, toAcc db.Account)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "OK",
+			toCurrency: util.EUR,
+			buildStubs: func(store *mockdb.MockStore, toAcc db.Account) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAcc.ID).Times(1).Return(fromAcc, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAcc.ID).Times(1).Return(toAcc, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.TransferTxParams) (db.TransferTxResult, error) {
+						require.Equal(t, req.Amount, arg.Amount)
+						require.NotNil(t, arg.ExchangeRate)
+						require.Equal(t, util.USD, arg.ExchangeRate.From)
+						require.Equal(t, util.EUR, arg.ExchangeRate.To)
+						require.Equal(t, "0.9200000000", arg.ExchangeRate.String())
+						require.Equal(t, fx.DefaultRoundingMode, arg.RoundingMode)
+						return db.TransferTxResult{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:       "RateNotFound",
+			toCurrency: util.VND,
+			buildStubs: func(store *mockdb.MockStore, toAcc db.Account) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAcc.ID).Times(1).Return(fromAcc, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAcc.ID).Times(1).Return(toAcc, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "ExchangeRateMismatch",
+			toCurrency: util.EUR,
+			buildStubs: func(store *mockdb.MockStore, toAcc db.Account) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAcc.ID).Times(1).Return(fromAcc, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAcc.ID).Times(1).Return(toAcc, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrExchangeRateMismatch)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			toAcc := toAcc
+			toAcc.Currency = tc.toCurrency
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store, toAcc)
+			server := newTestServer(t, store)
+			server.rateProvider = rateProvider
+
+			recorder := httptest.NewRecorder()
+
+			bodyData, err := json.Marshal(req)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(bodyData))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, fromAcc.Owner, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 func requireBodyMatchTransferTxResult(t *testing.T, body *bytes.Buffer, result db.TransferTxResult) {
 	data, err := io.ReadAll(body)
 	require.NoError(t, err)
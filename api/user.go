@@ -1,15 +1,22 @@
 package api
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	db "github.com/hykura1501/simple_bank/db/sqlc"
-	"github.com/hykura1501/simple_bank/util"
+	"github.com/hykura1501/simple_bank/lockout"
+	"github.com/hykura1501/simple_bank/token"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
 )
 
 type createUserRequest struct {
@@ -25,6 +32,8 @@ type userResponse struct {
 	Email             string             `json:"email"`
 	PasswordChangedAt pgtype.Timestamptz `json:"password_changed_at"`
 	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	IsEmailVerified   bool               `json:"is_email_verified"`
+	Role              string             `json:"role"`
 }
 
 func newUserResponse(user db.User) userResponse {
@@ -34,6 +43,8 @@ func newUserResponse(user db.User) userResponse {
 		Email:             user.Email,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
+		IsEmailVerified:   user.IsEmailVerified,
+		Role:              user.Role,
 	}
 }
 
@@ -44,7 +55,12 @@ func (server *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
-	hashedPassword, err := util.HashPassword(req.Password)
+	if errs := server.passwords.Validate(req.Password, req.Username, req.Email); errs != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("password %w", errors.Join(errs...))))
+		return
+	}
+
+	hashedPassword, err := server.hasher.Hash(req.Password)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 	}
@@ -94,29 +110,59 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
+	if !server.reserveLoginAttempt(ctx, req.Username) {
+		return
+	}
+
 	user, err := server.store.GetUser(ctx, req.Username)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			server.hasher.VerifyUnknownUser(req.Password)
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidCredentials))
 			return
-
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		if server.releaseLoginAttempt(ctx, req.Username) {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	needsRehash, err := server.hasher.Verify(req.Password, user.HashedPassword)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidCredentials))
+		return
+	}
+	if !server.releaseLoginAttempt(ctx, req.Username) {
 		return
 	}
+	if needsRehash {
+		server.rehashPassword(ctx, user, req.Password)
+	}
 
-	if err := util.CheckPassword(req.Password, user.HashedPassword); err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	// the two-factor authentication step is only implemented by the gRPC gateway
+	userMFA, err := server.store.GetUserMFA(ctx, user.Username)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
+	if err == nil && userMFA.IsEnabled {
+		err := errors.New("two-factor authentication is enabled for this user")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	sessionID := pgtype.UUID{
+		Bytes: uuid.New(),
+		Valid: true,
+	}
 
-	accessToken, accessTokenPayload, err := server.tokenMaker.CreateToken(req.Username, server.config.AccessTokenDuration)
+	accessToken, accessTokenPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, sessionID, token.TokenTypeAccess, server.config.AccessTokenDuration)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	refreshToken, refreshTokenPayload, err := server.tokenMaker.CreateToken(req.Username, server.config.RefreshTokenDuration)
+	refreshToken, refreshTokenPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, sessionID, token.TokenTypeRefresh, server.config.RefreshTokenDuration)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
@@ -124,6 +170,7 @@ func (server *Server) loginUser(ctx *gin.Context) {
 
 	arg := db.CreateSessionParams{
 		ID:           refreshTokenPayload.ID,
+		FamilyID:     sessionID,
 		Username:     req.Username,
 		RefreshToken: refreshToken,
 		UserAgent:    ctx.Request.UserAgent(),
@@ -141,8 +188,13 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
+	if err := server.loginLimiter.RecordSuccess(ctx, user.Username); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
 	loginUserResponse := loginUserResponse{
-		SessionID:             session.ID,
+		SessionID:             session.FamilyID,
 		AccessToken:           accessToken,
 		AccessTokenExpiresAt:  accessTokenPayload.ExpiredAt,
 		RefreshToken:          refreshToken,
@@ -152,3 +204,51 @@ func (server *Server) loginUser(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, loginUserResponse)
 }
+
+// errInvalidCredentials is returned for an unknown username as well as for a wrong password,
+// so that the response doesn't reveal which usernames exist
+var errInvalidCredentials = errors.New("invalid username or password")
+
+// reserveLoginAttempt counts the login as a failure until releaseLoginAttempt is called,
+// and responds with 429 if the username or the client IP failed to log in too many times
+func (server *Server) reserveLoginAttempt(ctx *gin.Context, username string) bool {
+	err := server.loginLimiter.Reserve(ctx, username, ctx.ClientIP())
+	if err == nil {
+		return true
+	}
+
+	var lockedErr *lockout.LockedError
+	if errors.As(err, &lockedErr) {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+		ctx.JSON(http.StatusTooManyRequests, errorResponse(err))
+		return false
+	}
+	ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	return false
+}
+
+// releaseLoginAttempt takes back the attempt reserved for a login whose password was correct,
+// or that failed for another reason. It responds with 500 if the attempt cannot be released
+func (server *Server) releaseLoginAttempt(ctx *gin.Context, username string) bool {
+	if err := server.loginLimiter.Release(ctx, username, ctx.ClientIP()); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+	return true
+}
+
+// rehashPassword replaces the hash of the user's password with one of the configured algorithm and parameters.
+// A failure doesn't fail the login, the hash is replaced on a later login instead
+func (server *Server) rehashPassword(ctx *gin.Context, user db.User, password string) {
+	hashedPassword, err := server.hasher.Hash(password)
+	if err == nil {
+		_, err = server.store.RehashUserPassword(ctx, db.RehashUserPasswordParams{
+			NewHashedPassword: hashedPassword,
+			Username:          user.Username,
+			OldHashedPassword: user.HashedPassword,
+		})
+	}
+	if err != nil {
+		log.Error().Err(err).Str("username", user.Username).Msg("cannot rehash password")
+	}
+}
@@ -0,0 +1,13 @@
+CREATE TABLE "idempotency_keys" (
+  "key" varchar NOT NULL,
+  "username" varchar NOT NULL,
+  "request_hash" varchar NOT NULL,
+  "response" jsonb,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expired_at" timestamptz NOT NULL,
+  PRIMARY KEY ("username", "key")
+);
+
+COMMENT ON COLUMN "idempotency_keys"."response" IS 'null until the request has been executed';
+
+ALTER TABLE "idempotency_keys" ADD CONSTRAINT "idempotency_username_fk" FOREIGN KEY ("username") REFERENCES "users" ("username");
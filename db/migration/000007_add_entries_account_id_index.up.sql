@@ -0,0 +1 @@
+CREATE INDEX ON "entries" ("account_id", "id");
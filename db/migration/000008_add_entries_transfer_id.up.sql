@@ -0,0 +1,16 @@
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "entries" ("transfer_id");
+
+-- both entries of a transfer are created in its transaction, so they share its created_at
+UPDATE "entries" SET "transfer_id" = "transfers"."id"
+FROM "transfers"
+WHERE "entries"."created_at" = "transfers"."created_at"
+  AND (
+    ("entries"."account_id" = "transfers"."from_account_id" AND "entries"."amount" = -"transfers"."amount")
+    OR ("entries"."account_id" = "transfers"."to_account_id" AND "entries"."amount" = "transfers"."to_amount")
+  );
+
+COMMENT ON COLUMN "entries"."transfer_id" IS 'the transfer that created the entry';
@@ -0,0 +1,26 @@
+CREATE TABLE "user_mfa" (
+  "username" varchar PRIMARY KEY,
+  "encrypted_secret" bytea NOT NULL,
+  "is_enabled" bool NOT NULL DEFAULT false,
+  "last_used_step" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "enabled_at" timestamptz
+);
+
+CREATE TABLE "mfa_recovery_codes" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "hashed_code" varchar NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "user_mfa" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "mfa_recovery_codes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+CREATE UNIQUE INDEX ON "mfa_recovery_codes" ("username", "hashed_code");
+
+COMMENT ON COLUMN "user_mfa"."encrypted_secret" IS 'TOTP secret encrypted with MFA_ENCRYPTION_KEY';
+
+COMMENT ON COLUMN "user_mfa"."last_used_step" IS 'time step of the last accepted TOTP code, so that a code cannot be used twice';
@@ -0,0 +1,12 @@
+CREATE TABLE "password_history" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "hashed_password" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "password_history" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+CREATE INDEX ON "password_history" ("username", "created_at");
+
+COMMENT ON COLUMN "password_history"."created_at" IS 'when the password was replaced';
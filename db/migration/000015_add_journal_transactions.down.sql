@@ -0,0 +1,32 @@
+DROP TRIGGER IF EXISTS "journal_transactions_immutable" ON "journal_transactions";
+
+DROP TRIGGER IF EXISTS "entries_immutable" ON "entries";
+
+DROP FUNCTION IF EXISTS reject_ledger_change();
+
+DROP TRIGGER IF EXISTS "entries_balanced" ON "entries";
+
+DROP FUNCTION IF EXISTS check_journal_transaction_balanced();
+
+-- the balances of the accounts already include their opening balances
+DELETE FROM "entries" WHERE "journal_transaction_id" IN (
+  SELECT "id" FROM "journal_transactions" WHERE "kind" = 'opening_balance'
+);
+
+DELETE FROM "journal_transactions" WHERE "kind" = 'opening_balance';
+
+DELETE FROM "accounts" WHERE "owner" = 'opening_balances';
+
+DELETE FROM "users" WHERE "username" = 'opening_balances';
+
+DROP FUNCTION IF EXISTS post_opening_balance(bigint);
+
+-- the fx_clearing user is kept, its accounts hold the balances of past cross-currency transfers
+
+ALTER TABLE "entries" DROP CONSTRAINT IF EXISTS "entries_amount_non_zero";
+
+ALTER TABLE "entries" DROP CONSTRAINT IF EXISTS "entries_journal_transaction_required";
+
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "journal_transaction_id";
+
+DROP TABLE IF EXISTS "journal_transactions";
@@ -0,0 +1,127 @@
+CREATE TABLE "journal_transactions" (
+  "id" bigserial PRIMARY KEY,
+  "kind" varchar NOT NULL,
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "journal_transactions" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "journal_transactions" ("transfer_id");
+
+COMMENT ON COLUMN "journal_transactions"."transfer_id" IS 'the transfer that the journal transaction records, if any';
+
+ALTER TABLE "entries" ADD COLUMN "journal_transaction_id" bigint;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("journal_transaction_id") REFERENCES "journal_transactions" ("id");
+
+CREATE INDEX ON "entries" ("journal_transaction_id");
+
+COMMENT ON COLUMN "entries"."journal_transaction_id" IS 'the balanced journal transaction that the entry is posted in';
+
+-- entries written before the journal existed are left as they are,
+-- every new entry must be posted in a journal transaction
+ALTER TABLE "entries" ADD CONSTRAINT "entries_journal_transaction_required" CHECK ("journal_transaction_id" IS NOT NULL) NOT VALID;
+
+ALTER TABLE "entries" ADD CONSTRAINT "entries_amount_non_zero" CHECK ("amount" <> 0) NOT VALID;
+
+-- cross-currency transfers are balanced in each currency through the accounts of this user
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email", "is_email_verified")
+VALUES ('fx_clearing', '!', 'FX Clearing', 'fx_clearing@simplebank.local', true);
+
+CREATE FUNCTION check_journal_transaction_balanced() RETURNS trigger AS $$
+DECLARE
+  unbalanced record;
+BEGIN
+  SELECT accounts.currency, SUM(entries.amount) AS total
+  INTO unbalanced
+  FROM entries
+  JOIN accounts ON accounts.id = entries.account_id
+  WHERE entries.journal_transaction_id = NEW.journal_transaction_id
+  GROUP BY accounts.currency
+  HAVING SUM(entries.amount) <> 0
+  LIMIT 1;
+
+  IF FOUND THEN
+    RAISE EXCEPTION 'journal transaction % is not balanced: postings in % sum to %',
+      NEW.journal_transaction_id, unbalanced.currency, unbalanced.total
+      USING ERRCODE = 'check_violation';
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- checked at commit, once every posting of the journal transaction has been written
+CREATE CONSTRAINT TRIGGER "entries_balanced"
+AFTER INSERT ON "entries"
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW
+WHEN (NEW.journal_transaction_id IS NOT NULL)
+EXECUTE FUNCTION check_journal_transaction_balanced();
+
+CREATE FUNCTION reject_ledger_change() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION '% are immutable, post a compensating journal transaction instead', TG_TABLE_NAME
+    USING ERRCODE = 'restrict_violation';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "entries_immutable"
+BEFORE UPDATE OR DELETE OR TRUNCATE ON "entries"
+FOR EACH STATEMENT
+EXECUTE FUNCTION reject_ledger_change();
+
+CREATE TRIGGER "journal_transactions_immutable"
+BEFORE UPDATE OR DELETE OR TRUNCATE ON "journal_transactions"
+FOR EACH STATEMENT
+EXECUTE FUNCTION reject_ledger_change();
+
+-- balances written before the journal existed are posted as opening balances against the accounts of this user,
+-- so the balance of every account is the sum of its entries
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email", "is_email_verified")
+VALUES ('opening_balances', '!', 'Opening Balances', 'opening_balances@simplebank.local', true);
+
+CREATE FUNCTION post_opening_balance(opening_account_id bigint) RETURNS void AS $$
+DECLARE
+  opening record;
+  equity_account_id bigint;
+  opening_transaction_id bigint;
+BEGIN
+  PERFORM 1 FROM accounts WHERE id = opening_account_id FOR NO KEY UPDATE;
+
+  SELECT
+    accounts.id,
+    accounts.currency,
+    accounts.created_at,
+    accounts.balance - COALESCE((SELECT SUM(entries.amount) FROM entries WHERE entries.account_id = accounts.id), 0) AS amount
+  INTO opening
+  FROM accounts
+  WHERE accounts.id = opening_account_id;
+
+  IF NOT FOUND OR opening.amount = 0 THEN
+    RETURN;
+  END IF;
+
+  INSERT INTO accounts (owner, balance, currency)
+  VALUES ('opening_balances', 0, opening.currency)
+  ON CONFLICT (owner, currency) DO NOTHING;
+
+  SELECT id INTO equity_account_id
+  FROM accounts
+  WHERE owner = 'opening_balances' AND currency = opening.currency
+  FOR NO KEY UPDATE;
+
+  -- dated at the creation of the account, so statements list it before the entries that followed it
+  INSERT INTO journal_transactions (kind, created_at)
+  VALUES ('opening_balance', opening.created_at)
+  RETURNING id INTO opening_transaction_id;
+
+  INSERT INTO entries (account_id, amount, journal_transaction_id, created_at) VALUES
+    (opening.id, opening.amount, opening_transaction_id, opening.created_at),
+    (equity_account_id, -opening.amount, opening_transaction_id, opening.created_at);
+
+  UPDATE accounts SET balance = balance - opening.amount WHERE id = equity_account_id;
+END;
+$$ LANGUAGE plpgsql;
+
+SELECT post_opening_balance(id) FROM accounts WHERE owner <> 'opening_balances' ORDER BY id;
@@ -0,0 +1,3 @@
+ALTER TABLE "accounts" ADD COLUMN "is_frozen" bool NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN "accounts"."is_frozen" IS 'no transfer can be made from or to the account';
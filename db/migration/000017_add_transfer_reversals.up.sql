@@ -0,0 +1,25 @@
+CREATE TABLE "transfer_reversals" (
+  "id" bigserial PRIMARY KEY,
+  "transfer_id" bigint NOT NULL,
+  "reversal_transfer_id" bigint UNIQUE NOT NULL,
+  "reason_code" varchar NOT NULL,
+  "reversed_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "transfer_reversals" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfer_reversals" ADD FOREIGN KEY ("reversal_transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfer_reversals" ADD FOREIGN KEY ("reversed_by") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_reversals" ADD CONSTRAINT "transfer_reversals_reason_code_check"
+  CHECK ("reason_code" IN ('duplicate', 'fraud', 'customer_request', 'processing_error'));
+
+CREATE INDEX ON "transfer_reversals" ("transfer_id");
+
+COMMENT ON COLUMN "transfer_reversals"."transfer_id" IS 'the transfer being reversed';
+
+COMMENT ON COLUMN "transfer_reversals"."reversal_transfer_id" IS 'the compensating transfer, from the destination back to the source account';
+
+COMMENT ON COLUMN "transfer_reversals"."reversed_by" IS 'the banker or admin who reversed the transfer';
@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS "balance_adjustments_immutable" ON "balance_adjustments";
+
+DROP TRIGGER IF EXISTS "transfer_reversals_immutable" ON "transfer_reversals";
+
+DROP TRIGGER IF EXISTS "transfers_immutable" ON "transfers";
+
+DROP TABLE IF EXISTS "balance_adjustments";
+
+-- the adjustments user is kept, its accounts hold the balances of past adjustments
@@ -0,0 +1,46 @@
+-- balance adjustments are posted against the accounts of this user, one per currency
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email", "is_email_verified")
+VALUES ('adjustments', '!', 'Balance Adjustments', 'adjustments@simplebank.local', true);
+
+CREATE TABLE "balance_adjustments" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "journal_transaction_id" bigint UNIQUE NOT NULL,
+  "amount" bigint NOT NULL,
+  "reason" varchar NOT NULL,
+  "adjusted_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "balance_adjustments" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "balance_adjustments" ADD FOREIGN KEY ("journal_transaction_id") REFERENCES "journal_transactions" ("id");
+
+ALTER TABLE "balance_adjustments" ADD FOREIGN KEY ("adjusted_by") REFERENCES "users" ("username");
+
+ALTER TABLE "balance_adjustments" ADD CONSTRAINT "balance_adjustments_amount_non_zero" CHECK ("amount" <> 0);
+
+CREATE INDEX ON "balance_adjustments" ("account_id");
+
+COMMENT ON COLUMN "balance_adjustments"."amount" IS 'added to the account balance, negative for a debit';
+
+COMMENT ON COLUMN "balance_adjustments"."journal_transaction_id" IS 'the compensating journal transaction that posted the adjustment';
+
+COMMENT ON COLUMN "balance_adjustments"."adjusted_by" IS 'the banker or admin who adjusted the balance';
+
+-- like entries, the records of money movements are append-only:
+-- a mistake is corrected by a reversal or an adjustment, never by rewriting history
+CREATE TRIGGER "transfers_immutable"
+BEFORE UPDATE OR DELETE OR TRUNCATE ON "transfers"
+FOR EACH STATEMENT
+EXECUTE FUNCTION reject_ledger_change();
+
+CREATE TRIGGER "transfer_reversals_immutable"
+BEFORE UPDATE OR DELETE OR TRUNCATE ON "transfer_reversals"
+FOR EACH STATEMENT
+EXECUTE FUNCTION reject_ledger_change();
+
+CREATE TRIGGER "balance_adjustments_immutable"
+BEFORE UPDATE OR DELETE OR TRUNCATE ON "balance_adjustments"
+FOR EACH STATEMENT
+EXECUTE FUNCTION reject_ledger_change();
@@ -0,0 +1,78 @@
+CREATE TABLE "scheduled_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "schedule" varchar NOT NULL,
+  "start_at" timestamptz NOT NULL,
+  "end_at" timestamptz,
+  "max_occurrences" integer,
+  "occurrences" integer NOT NULL DEFAULT 0,
+  "next_run_at" timestamptz,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD CONSTRAINT "scheduled_transfers_amount_positive" CHECK ("amount" > 0);
+
+ALTER TABLE "scheduled_transfers" ADD CONSTRAINT "scheduled_transfers_max_occurrences_positive" CHECK ("max_occurrences" > 0);
+
+ALTER TABLE "scheduled_transfers" ADD CONSTRAINT "scheduled_transfers_status_check"
+  CHECK ("status" IN ('active', 'paused', 'completed', 'cancelled'));
+
+CREATE INDEX ON "scheduled_transfers" ("owner");
+
+CREATE INDEX ON "scheduled_transfers" ("next_run_at") WHERE "status" = 'active';
+
+COMMENT ON COLUMN "scheduled_transfers"."amount" IS 'must be positive, in the currency of both accounts';
+
+COMMENT ON COLUMN "scheduled_transfers"."schedule" IS 'cron expression, in UTC unless it starts with CRON_TZ=';
+
+COMMENT ON COLUMN "scheduled_transfers"."end_at" IS 'no run is due after this time';
+
+COMMENT ON COLUMN "scheduled_transfers"."max_occurrences" IS 'the scheduled transfer completes after this many runs';
+
+COMMENT ON COLUMN "scheduled_transfers"."occurrences" IS 'number of runs so far, failed or not';
+
+COMMENT ON COLUMN "scheduled_transfers"."next_run_at" IS 'null unless the scheduled transfer is active';
+
+COMMENT ON COLUMN "scheduled_transfers"."status" IS 'active, paused, completed or cancelled';
+
+CREATE TABLE "scheduled_transfer_runs" (
+  "id" bigserial PRIMARY KEY,
+  "scheduled_transfer_id" bigint NOT NULL,
+  "scheduled_at" timestamptz NOT NULL,
+  "status" varchar NOT NULL,
+  "transfer_id" bigint,
+  "failure_reason" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD CONSTRAINT "scheduled_transfer_runs_status_check"
+  CHECK ("status" IN ('succeeded', 'failed'));
+
+-- an occurrence is run at most once, however many times its task is retried
+CREATE UNIQUE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id", "scheduled_at");
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."scheduled_at" IS 'the occurrence that the run executed';
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."transfer_id" IS 'the transfer made by a successful run';
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."failure_reason" IS 'why a failed run made no transfer';
+
+CREATE TRIGGER "scheduled_transfer_runs_immutable"
+BEFORE UPDATE OR DELETE OR TRUNCATE ON "scheduled_transfer_runs"
+FOR EACH STATEMENT
+EXECUTE FUNCTION reject_ledger_change();
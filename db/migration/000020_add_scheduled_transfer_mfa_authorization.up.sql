@@ -0,0 +1,3 @@
+ALTER TABLE "scheduled_transfers" ADD COLUMN "mfa_authorized_amount" bigint;
+
+COMMENT ON COLUMN "scheduled_transfers"."mfa_authorized_amount" IS 'the largest amount that the owner authorized with a TOTP code, null if none';
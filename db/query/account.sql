@@ -1,16 +1,29 @@
 -- name: CreateAccount :one
 INSERT INTO accounts
 (
-  owner, 
-  balance, 
+  owner,
+  balance,
   currency
-) VALUES ($1, $2, $3)
+) VALUES ($1, 0, $2)
 RETURNING *;
 
+-- name: CreateSystemAccount :exec
+INSERT INTO accounts
+(
+  owner,
+  balance,
+  currency
+) VALUES ($1, 0, $2)
+ON CONFLICT (owner, currency) DO NOTHING;
+
 -- name: GetAccount :one
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1;
 
+-- name: GetAccountByCurrency :one
+SELECT * FROM accounts
+WHERE owner = $1 AND currency = $2 LIMIT 1;
+
 -- name: GetAccountForUpdate :one
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1
@@ -22,19 +35,33 @@ WHERE owner = $1
 ORDER BY id
 LIMIT $2 OFFSET $3;
 
--- name: UpdateAccount :one
+-- name: UpdateAccountOverdraftLimit :one
 UPDATE accounts
-SET balance = $2
-WHERE id = $1
+SET overdraft_limit = sqlc.arg(overdraft_limit)
+WHERE id = sqlc.arg(id)
 RETURNING *;
 
--- name: AddAccountBalance :one
+-- name: FreezeAccount :one
 UPDATE accounts
-SET balance = balance + sqlc.arg(amount)
-WHERE id = sqlc.arg(id)
+SET is_frozen = true
+WHERE id = $1
 RETURNING *;
 
-
--- name: DeleteAccount :exec
-DELETE FROM accounts
-WHERE id = $1;
\ No newline at end of file
+-- name: ReconcileAccountBalances :many
+SELECT
+  accounts.id,
+  accounts.owner,
+  accounts.currency,
+  accounts.balance,
+  accounts.is_frozen,
+  ledger.total::bigint AS entries_balance,
+  ledger.entry_count
+FROM accounts
+CROSS JOIN LATERAL (
+  SELECT COALESCE(SUM(entries.amount), 0) AS total, COUNT(*) AS entry_count
+  FROM entries
+  WHERE entries.account_id = accounts.id
+) AS ledger
+WHERE accounts.id > sqlc.arg(after_id)
+ORDER BY accounts.id
+LIMIT sqlc.arg(batch_size);
\ No newline at end of file
@@ -0,0 +1,16 @@
+-- name: CreateBalanceAdjustment :one
+INSERT INTO balance_adjustments
+(
+  account_id,
+  journal_transaction_id,
+  amount,
+  reason,
+  adjusted_by
+) VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListBalanceAdjustments :many
+SELECT * FROM balance_adjustments
+WHERE account_id = $1
+ORDER BY id
+LIMIT $2 OFFSET $3;
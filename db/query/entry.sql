@@ -2,25 +2,72 @@
 INSERT INTO entries
 (
   account_id, 
-  amount
-) VALUES ($1, $2)
+  amount,
+  transfer_id,
+  journal_transaction_id
+) VALUES ($1, $2, $3, $4)
 RETURNING *;
 
 -- name: GetEntry :one
 SELECT * FROM entries
 WHERE id = $1 LIMIT 1;
 
+-- name: ListJournalTransactionEntries :many
+SELECT * FROM entries
+WHERE journal_transaction_id = $1
+ORDER BY id;
+
 -- name: ListEntries :many
 SELECT * FROM entries
 ORDER BY id
 LIMIT $1 OFFSET $2;
 
--- name: UpdateEntry :one
-UPDATE entries
-SET amount = $2
-WHERE id = $1
-RETURNING *;
+-- name: ListAccountEntries :many
+-- entries are in the order they were posted in, opening balances are dated at the creation of the account
+WITH ledger AS (
+  SELECT
+    id,
+    account_id,
+    amount,
+    created_at,
+    transfer_id,
+    ROW_NUMBER() OVER (ORDER BY created_at, id) AS ordinal,
+    SUM(amount) OVER (ORDER BY created_at, id) - SUM(amount) OVER () AS balance_offset
+  FROM entries
+  WHERE entries.account_id = sqlc.arg(account_id)
+)
+SELECT
+  ledger.id,
+  ledger.account_id,
+  ledger.amount,
+  ledger.created_at,
+  ledger.transfer_id,
+  transfers.from_account_id,
+  transfers.to_account_id,
+  (accounts.balance + ledger.balance_offset)::bigint AS running_balance
+FROM ledger
+JOIN accounts ON accounts.id = ledger.account_id
+LEFT JOIN transfers ON transfers.id = ledger.transfer_id
+WHERE ledger.ordinal > COALESCE((SELECT after_entry.ordinal FROM ledger AS after_entry WHERE after_entry.id = sqlc.arg(after_id)), 0)
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR ledger.created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR ledger.created_at < sqlc.narg(to_time))
+  AND (
+    sqlc.narg(direction)::text IS NULL
+    OR (sqlc.narg(direction) = 'credit' AND ledger.amount > 0)
+    OR (sqlc.narg(direction) = 'debit' AND ledger.amount < 0)
+  )
+ORDER BY ledger.ordinal
+LIMIT sqlc.arg(page_size);
 
--- name: DeleteEntry :exec
-DELETE FROM entries
-WHERE id = $1;
\ No newline at end of file
+-- name: GetAccountStatementSummary :one
+SELECT
+  (accounts.balance - COALESCE(SUM(entries.amount) FILTER (
+    WHERE sqlc.narg(from_time)::timestamptz IS NULL OR entries.created_at >= sqlc.narg(from_time)
+  ), 0))::bigint AS opening_balance,
+  (accounts.balance - COALESCE(SUM(entries.amount) FILTER (
+    WHERE entries.created_at >= sqlc.narg(to_time)::timestamptz
+  ), 0))::bigint AS closing_balance
+FROM accounts
+LEFT JOIN entries ON entries.account_id = accounts.id
+WHERE accounts.id = sqlc.arg(account_id)
+GROUP BY accounts.id;
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- name: CreateJournalTransaction :one
+INSERT INTO journal_transactions
+(
+  kind,
+  transfer_id
+) VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetJournalTransaction :one
+SELECT * FROM journal_transactions
+WHERE id = $1 LIMIT 1;
@@ -0,0 +1,18 @@
+-- name: CreateMFARecoveryCodes :execrows
+INSERT INTO mfa_recovery_codes
+(
+  username,
+  hashed_code
+)
+SELECT @username::varchar, unnest(@hashed_codes::varchar[]);
+
+-- name: UseMFARecoveryCode :execrows
+UPDATE mfa_recovery_codes
+SET used_at = now()
+WHERE username = @username
+  AND hashed_code = @hashed_code
+  AND used_at IS NULL;
+
+-- name: DeleteMFARecoveryCodes :exec
+DELETE FROM mfa_recovery_codes
+WHERE username = $1;
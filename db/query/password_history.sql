@@ -0,0 +1,14 @@
+-- name: ArchiveUserPassword :exec
+INSERT INTO password_history
+(
+  username,
+  hashed_password
+)
+SELECT username, hashed_password FROM users
+WHERE username = $1;
+
+-- name: ListPasswordHistory :many
+SELECT hashed_password FROM password_history
+WHERE username = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2;
@@ -0,0 +1,28 @@
+-- name: CreatePasswordReset :one
+INSERT INTO password_resets
+(
+  username,
+  hashed_token,
+  expired_at
+) VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetActivePasswordReset :one
+SELECT * FROM password_resets
+WHERE hashed_token = $1
+  AND is_used = FALSE
+  AND expired_at > now()
+LIMIT 1;
+
+-- name: UsePasswordReset :one
+UPDATE password_resets
+SET is_used = TRUE
+WHERE hashed_token = $1
+  AND is_used = FALSE
+  AND expired_at > now()
+RETURNING *;
+
+-- name: ExpireUserPasswordResets :execrows
+UPDATE password_resets
+SET is_used = TRUE
+WHERE username = $1 AND is_used = FALSE;
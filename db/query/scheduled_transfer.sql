@@ -0,0 +1,60 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers
+(
+  owner,
+  from_account_id,
+  to_account_id,
+  amount,
+  schedule,
+  start_at,
+  end_at,
+  max_occurrences,
+  next_run_at,
+  mfa_authorized_amount
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetScheduledTransferForUpdate :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY id
+LIMIT $2 OFFSET $3;
+
+-- name: ListDueScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE status = 'active' AND next_run_at <= sqlc.arg(now)
+ORDER BY next_run_at, id
+LIMIT sqlc.arg(batch_size);
+
+-- name: UpdateScheduledTransfer :one
+UPDATE scheduled_transfers
+SET
+  amount = $2,
+  schedule = $3,
+  end_at = $4,
+  max_occurrences = $5,
+  next_run_at = $6,
+  status = $7,
+  mfa_authorized_amount = $8,
+  updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: AdvanceScheduledTransfer :one
+UPDATE scheduled_transfers
+SET
+  occurrences = occurrences + 1,
+  next_run_at = sqlc.narg(next_run_at),
+  status = sqlc.arg(status),
+  updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
@@ -0,0 +1,16 @@
+-- name: CreateScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs
+(
+  scheduled_transfer_id,
+  scheduled_at,
+  status,
+  transfer_id,
+  failure_reason
+) VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListScheduledTransferRuns :many
+SELECT * FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = $1
+ORDER BY scheduled_at DESC
+LIMIT $2 OFFSET $3;
@@ -2,15 +2,73 @@
 INSERT INTO sessions
 (
   id,
+  family_id,
   username,
   refresh_token,
   user_agent,
   client_ip,
   is_blocked,
   expired_at
-) VALUES ($1, $2, $3, $4, $5, $6, $7)
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *;
 
 -- name: GetSession :one
 SELECT * FROM sessions
-WHERE id = $1 LIMIT 1;
\ No newline at end of file
+WHERE id = $1 LIMIT 1;
+
+-- name: IsSessionFamilyBlocked :one
+SELECT EXISTS (
+  SELECT 1 FROM sessions
+  WHERE family_id = $1 AND is_blocked = TRUE
+);
+
+-- name: BlockSession :one
+UPDATE sessions
+SET is_blocked = TRUE
+WHERE id = $1
+RETURNING *;
+
+-- name: BlockUserSessions :execrows
+UPDATE sessions
+SET is_blocked = TRUE
+WHERE username = $1 AND is_blocked = FALSE;
+
+-- name: BlockSessionFamily :execrows
+UPDATE sessions
+SET is_blocked = TRUE
+WHERE family_id = $1 AND is_blocked = FALSE;
+
+-- name: MarkSessionUsed :one
+UPDATE sessions
+SET is_used = TRUE
+WHERE id = $1 AND is_used = FALSE
+RETURNING *;
+
+-- name: ListActiveSessions :many
+SELECT
+  s.family_id,
+  s.user_agent,
+  s.client_ip,
+  (
+    SELECT min(f.created_at) FROM sessions f
+    WHERE f.family_id = s.family_id
+  )::timestamptz AS logged_in_at,
+  s.created_at AS renewed_at,
+  s.expired_at
+FROM sessions s
+WHERE s.username = $1
+  AND s.is_used = FALSE
+  AND s.is_blocked = FALSE
+  AND s.expired_at > now()
+ORDER BY logged_in_at DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: BlockUserSessionFamily :execrows
+UPDATE sessions
+SET is_blocked = TRUE
+WHERE family_id = $1 AND username = $2 AND is_blocked = FALSE;
+
+-- name: DeleteExpiredSessions :execrows
+DELETE FROM sessions
+WHERE expired_at < now();
@@ -3,8 +3,11 @@ INSERT INTO transfers
 (
   from_account_id, 
   to_account_id,
-  amount
-) VALUES ($1, $2, $3)
+  amount,
+  to_amount,
+  exchange_rate,
+  rounding_mode
+) VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
 -- name: GetTransfer :one
@@ -16,12 +19,28 @@ SELECT * FROM transfers
 ORDER BY id
 LIMIT $1 OFFSET $2;
 
--- name: UpdateTransfer :one
-UPDATE transfers
-SET amount = $2
-WHERE id = $1
-RETURNING *;
-
--- name: DeleteTransfer :exec
-DELETE FROM transfers
-WHERE id = $1;
\ No newline at end of file
+-- name: ReconcileTransferEntries :many
+SELECT
+  transfers.id,
+  transfers.from_account_id,
+  transfers.to_account_id,
+  transfers.amount,
+  transfers.to_amount,
+  COUNT(entries.id) FILTER (
+    WHERE entries.account_id = transfers.from_account_id AND entries.amount = -transfers.amount
+  ) AS from_entry_count,
+  COUNT(entries.id) FILTER (
+    WHERE entries.account_id = transfers.to_account_id AND entries.amount = transfers.to_amount
+  ) AS to_entry_count
+FROM transfers
+LEFT JOIN entries ON entries.transfer_id = transfers.id
+WHERE transfers.from_account_id > sqlc.arg(after_account_id)
+  AND transfers.from_account_id <= sqlc.arg(last_account_id)
+GROUP BY transfers.id
+HAVING COUNT(entries.id) FILTER (
+    WHERE entries.account_id = transfers.from_account_id AND entries.amount = -transfers.amount
+  ) <> 1
+  OR COUNT(entries.id) FILTER (
+    WHERE entries.account_id = transfers.to_account_id AND entries.amount = transfers.to_amount
+  ) <> 1
+ORDER BY transfers.id;
\ No newline at end of file
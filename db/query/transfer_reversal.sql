@@ -0,0 +1,28 @@
+-- name: CreateTransferReversal :one
+INSERT INTO transfer_reversals
+(
+  transfer_id,
+  reversal_transfer_id,
+  reason_code,
+  reversed_by
+) VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: IsReversalTransfer :one
+SELECT EXISTS (
+  SELECT 1 FROM transfer_reversals
+  WHERE reversal_transfer_id = $1
+);
+
+-- name: GetTransferReversedAmounts :one
+SELECT
+  COALESCE(SUM(transfers.amount), 0)::bigint AS debited_amount,
+  COALESCE(SUM(transfers.to_amount), 0)::bigint AS refunded_amount
+FROM transfer_reversals
+JOIN transfers ON transfers.id = transfer_reversals.reversal_transfer_id
+WHERE transfer_reversals.transfer_id = $1;
+
+-- name: ListTransferReversals :many
+SELECT * FROM transfer_reversals
+WHERE transfer_id = $1
+ORDER BY id;
@@ -17,6 +17,17 @@ UPDATE users SET
   full_name = COALESCE(sqlc.narg(full_name), full_name),
   hashed_password = COALESCE(sqlc.narg(hashed_password), hashed_password),
   email = COALESCE(sqlc.narg(email), email),
-  password_changed_at = COALESCE(sqlc.narg(password_changed_at), password_changed_at)
+  password_changed_at = COALESCE(sqlc.narg(password_changed_at), password_changed_at),
+  is_email_verified = COALESCE(sqlc.narg(is_email_verified), is_email_verified)
 WHERE username = sqlc.arg(username)
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: GetUserByEmail :one
+SELECT * FROM users
+WHERE email = $1 LIMIT 1;
+
+-- name: RehashUserPassword :execrows
+UPDATE users
+SET hashed_password = @new_hashed_password
+WHERE username = @username
+  AND hashed_password = @old_hashed_password;
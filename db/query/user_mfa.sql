@@ -0,0 +1,36 @@
+-- name: SetupUserMFA :one
+INSERT INTO user_mfa
+(
+  username,
+  encrypted_secret
+) VALUES ($1, $2)
+ON CONFLICT (username) DO UPDATE
+SET encrypted_secret = EXCLUDED.encrypted_secret,
+  last_used_step = 0,
+  created_at = now()
+WHERE user_mfa.is_enabled = FALSE
+RETURNING *;
+
+-- name: GetUserMFA :one
+SELECT * FROM user_mfa
+WHERE username = $1 LIMIT 1;
+
+-- name: EnableUserMFA :one
+UPDATE user_mfa
+SET is_enabled = TRUE,
+  enabled_at = now(),
+  last_used_step = @last_used_step
+WHERE username = @username
+  AND is_enabled = FALSE
+RETURNING *;
+
+-- name: UseUserMFAStep :execrows
+UPDATE user_mfa
+SET last_used_step = @last_used_step
+WHERE username = @username
+  AND is_enabled = TRUE
+  AND last_used_step < @last_used_step;
+
+-- name: DeleteUserMFA :exec
+DELETE FROM user_mfa
+WHERE username = $1;
@@ -9,20 +9,23 @@ import (
 	"context"
 )
 
-const addAccountBalance = `-- name: AddAccountBalance :one
-UPDATE accounts
-SET balance = balance + $1
-WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+const createAccount = `-- name: CreateAccount :one
+INSERT INTO accounts
+(
+  owner,
+  balance,
+  currency
+) VALUES ($1, 0, $2)
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, is_frozen
 `
 
-type AddAccountBalanceParams struct {
-	Amount int64 `json:"amount"`
-	ID     int64 `json:"id"`
+type CreateAccountParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
 }
 
-func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error) {
-	row := q.db.QueryRow(ctx, addAccountBalance, arg.Amount, arg.ID)
+func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	row := q.db.QueryRow(ctx, createAccount, arg.Owner, arg.Currency)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -30,28 +33,41 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.IsFrozen,
 	)
 	return i, err
 }
 
-const createAccount = `-- name: CreateAccount :one
+const createSystemAccount = `-- name: CreateSystemAccount :exec
 INSERT INTO accounts
 (
-  owner, 
-  balance, 
+  owner,
+  balance,
   currency
-) VALUES ($1, $2, $3)
-RETURNING id, owner, balance, currency, created_at
+) VALUES ($1, 0, $2)
+ON CONFLICT (owner, currency) DO NOTHING
 `
 
-type CreateAccountParams struct {
+type CreateSystemAccountParams struct {
 	Owner    string `json:"owner"`
-	Balance  int64  `json:"balance"`
 	Currency string `json:"currency"`
 }
 
-func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRow(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+func (q *Queries) CreateSystemAccount(ctx context.Context, arg CreateSystemAccountParams) error {
+	_, err := q.db.Exec(ctx, createSystemAccount, arg.Owner, arg.Currency)
+	return err
+}
+
+const freezeAccount = `-- name: FreezeAccount :one
+UPDATE accounts
+SET is_frozen = true
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, is_frozen
+`
+
+func (q *Queries) FreezeAccount(ctx context.Context, id int64) (Account, error) {
+	row := q.db.QueryRow(ctx, freezeAccount, id)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -59,22 +75,14 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.IsFrozen,
 	)
 	return i, err
 }
 
-const deleteAccount = `-- name: DeleteAccount :exec
-DELETE FROM accounts
-WHERE id = $1
-`
-
-func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, deleteAccount, id)
-	return err
-}
-
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, overdraft_limit, is_frozen FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -87,12 +95,39 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.IsFrozen,
+	)
+	return i, err
+}
+
+const getAccountByCurrency = `-- name: GetAccountByCurrency :one
+SELECT id, owner, balance, currency, created_at, overdraft_limit, is_frozen FROM accounts
+WHERE owner = $1 AND currency = $2 LIMIT 1
+`
+
+type GetAccountByCurrencyParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) GetAccountByCurrency(ctx context.Context, arg GetAccountByCurrencyParams) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccountByCurrency, arg.Owner, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.IsFrozen,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, overdraft_limit, is_frozen FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -106,12 +141,14 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.IsFrozen,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, overdraft_limit, is_frozen FROM accounts
 WHERE owner = $1
 ORDER BY id
 LIMIT $2 OFFSET $3
@@ -138,6 +175,71 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.OverdraftLimit,
+			&i.IsFrozen,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const reconcileAccountBalances = `-- name: ReconcileAccountBalances :many
+SELECT
+  accounts.id,
+  accounts.owner,
+  accounts.currency,
+  accounts.balance,
+  accounts.is_frozen,
+  ledger.total::bigint AS entries_balance,
+  ledger.entry_count
+FROM accounts
+CROSS JOIN LATERAL (
+  SELECT COALESCE(SUM(entries.amount), 0) AS total, COUNT(*) AS entry_count
+  FROM entries
+  WHERE entries.account_id = accounts.id
+) AS ledger
+WHERE accounts.id > $1
+ORDER BY accounts.id
+LIMIT $2
+`
+
+type ReconcileAccountBalancesParams struct {
+	AfterID   int64 `json:"after_id"`
+	BatchSize int32 `json:"batch_size"`
+}
+
+type ReconcileAccountBalancesRow struct {
+	ID             int64  `json:"id"`
+	Owner          string `json:"owner"`
+	Currency       string `json:"currency"`
+	Balance        int64  `json:"balance"`
+	IsFrozen       bool   `json:"is_frozen"`
+	EntriesBalance int64  `json:"entries_balance"`
+	EntryCount     int64  `json:"entry_count"`
+}
+
+func (q *Queries) ReconcileAccountBalances(ctx context.Context, arg ReconcileAccountBalancesParams) ([]ReconcileAccountBalancesRow, error) {
+	rows, err := q.db.Query(ctx, reconcileAccountBalances, arg.AfterID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ReconcileAccountBalancesRow{}
+	for rows.Next() {
+		var i ReconcileAccountBalancesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Currency,
+			&i.Balance,
+			&i.IsFrozen,
+			&i.EntriesBalance,
+			&i.EntryCount,
 		); err != nil {
 			return nil, err
 		}
@@ -149,20 +251,20 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 	return items, nil
 }
 
-const updateAccount = `-- name: UpdateAccount :one
+const updateAccountOverdraftLimit = `-- name: UpdateAccountOverdraftLimit :one
 UPDATE accounts
-SET balance = $2
-WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+SET overdraft_limit = $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, is_frozen
 `
 
-type UpdateAccountParams struct {
-	ID      int64 `json:"id"`
-	Balance int64 `json:"balance"`
+type UpdateAccountOverdraftLimitParams struct {
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	ID             int64 `json:"id"`
 }
 
-func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
-	row := q.db.QueryRow(ctx, updateAccount, arg.ID, arg.Balance)
+func (q *Queries) UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error) {
+	row := q.db.QueryRow(ctx, updateAccountOverdraftLimit, arg.OverdraftLimit, arg.ID)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -170,6 +272,8 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.IsFrozen,
 	)
 	return i, err
 }
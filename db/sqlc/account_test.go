@@ -5,28 +5,45 @@ import (
 	"testing"
 
 	"github.com/hykura1501/simple_bank/util"
-	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/require"
 )
 
 func createRandomAccount(t *testing.T) Account {
+	return createRandomAccountWithBalance(t, util.RandomMoney(), util.RandomCurrency())
+}
+
+func createRandomAccountWithBalance(t *testing.T, balance int64, currency string) Account {
 	user := createRandomUser(t)
 	arg := CreateAccountParams{
 		Owner:    user.Username,
-		Balance:  util.RandomMoney(),
-		Currency: util.RandomCurrency(),
+		Currency: currency,
 	}
 	account, err := testQueries.CreateAccount(context.Background(), arg)
 	require.NoError(t, err)
 	require.NotEmpty(t, account)
 
 	require.Equal(t, arg.Owner, account.Owner)
-	require.Equal(t, arg.Balance, account.Balance)
+	require.Zero(t, account.Balance)
 	require.Equal(t, arg.Currency, account.Currency)
+	require.Zero(t, account.OverdraftLimit)
+	require.False(t, account.IsFrozen)
 
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
 
+	if balance == 0 {
+		return account
+	}
+	return setTestAccountBalance(t, account, balance)
+}
+
+// setTestAccountBalance overwrites the balance without posting any entry.
+// The store cannot do this, so tests use SQL to start from a balance, or to make one drift from the ledger
+func setTestAccountBalance(t *testing.T, account Account, balance int64) Account {
+	_, err := testDB.Exec(context.Background(), "UPDATE accounts SET balance = $1 WHERE id = $2", balance, account.ID)
+	require.NoError(t, err)
+
+	account.Balance = balance
 	return account
 }
 
@@ -48,35 +65,43 @@ func TestGetAccount(t *testing.T) {
 	require.Equal(t, acc1.CreatedAt, acc2.CreatedAt)
 }
 
-func TestUpdateAccont(t *testing.T) {
+func TestUpdateAccountOverdraftLimit(t *testing.T) {
 	acc1 := createRandomAccount(t)
 
-	arg := UpdateAccountParams{
-		ID:      acc1.ID,
-		Balance: util.RandomMoney(),
+	arg := UpdateAccountOverdraftLimitParams{
+		ID:             acc1.ID,
+		OverdraftLimit: util.RandomMoney(),
 	}
 
-	acc2, err := testQueries.UpdateAccount(context.Background(), arg)
+	acc2, err := testQueries.UpdateAccountOverdraftLimit(context.Background(), arg)
 
 	require.NoError(t, err)
 	require.NotEmpty(t, acc2)
 
 	require.Equal(t, acc1.ID, acc2.ID)
-	require.Equal(t, acc1.Owner, acc2.Owner)
-	require.Equal(t, arg.Balance, acc2.Balance)
-	require.Equal(t, acc1.Currency, acc2.Currency)
-	require.Equal(t, acc1.CreatedAt, acc2.CreatedAt)
+	require.Equal(t, acc1.Balance, acc2.Balance)
+	require.Equal(t, arg.OverdraftLimit, acc2.OverdraftLimit)
+
+	_, err = testQueries.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             acc1.ID,
+		OverdraftLimit: -1,
+	})
+	require.Error(t, err)
 }
 
-func TestDeleteAccount(t *testing.T) {
+func TestFreezeAccount(t *testing.T) {
 	acc1 := createRandomAccount(t)
-	err := testQueries.DeleteAccount(context.Background(), acc1.ID)
+
+	acc2, err := testQueries.FreezeAccount(context.Background(), acc1.ID)
 	require.NoError(t, err)
+	require.Equal(t, acc1.ID, acc2.ID)
+	require.Equal(t, acc1.Balance, acc2.Balance)
+	require.True(t, acc2.IsFrozen)
 
-	acc2, err := testQueries.GetAccount(context.Background(), acc1.ID)
-	require.Error(t, err)
-	require.EqualError(t, err, pgx.ErrNoRows.Error())
-	require.Empty(t, acc2)
+	// freezing is idempotent
+	acc2, err = testQueries.FreezeAccount(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	require.True(t, acc2.IsFrozen)
 }
 
 func TestListAccount(t *testing.T) {
@@ -7,46 +7,81 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries
 (
   account_id, 
-  amount
-) VALUES ($1, $2)
-RETURNING id, account_id, amount, created_at
+  amount,
+  transfer_id,
+  journal_transaction_id
+) VALUES ($1, $2, $3, $4)
+RETURNING id, account_id, amount, created_at, transfer_id, journal_transaction_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID            int64  `json:"account_id"`
+	Amount               int64  `json:"amount"`
+	TransferID           *int64 `json:"transfer_id"`
+	JournalTransactionID *int64 `json:"journal_transaction_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRow(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRow(ctx, createEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.TransferID,
+		arg.JournalTransactionID,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.JournalTransactionID,
 	)
 	return i, err
 }
 
-const deleteEntry = `-- name: DeleteEntry :exec
-DELETE FROM entries
-WHERE id = $1
+const getAccountStatementSummary = `-- name: GetAccountStatementSummary :one
+SELECT
+  (accounts.balance - COALESCE(SUM(entries.amount) FILTER (
+    WHERE $1::timestamptz IS NULL OR entries.created_at >= $1
+  ), 0))::bigint AS opening_balance,
+  (accounts.balance - COALESCE(SUM(entries.amount) FILTER (
+    WHERE entries.created_at >= $2::timestamptz
+  ), 0))::bigint AS closing_balance
+FROM accounts
+LEFT JOIN entries ON entries.account_id = accounts.id
+WHERE accounts.id = $3
+GROUP BY accounts.id
 `
 
-func (q *Queries) DeleteEntry(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, deleteEntry, id)
-	return err
+type GetAccountStatementSummaryParams struct {
+	FromTime  pgtype.Timestamptz `json:"from_time"`
+	ToTime    pgtype.Timestamptz `json:"to_time"`
+	AccountID int64              `json:"account_id"`
+}
+
+type GetAccountStatementSummaryRow struct {
+	OpeningBalance int64 `json:"opening_balance"`
+	ClosingBalance int64 `json:"closing_balance"`
+}
+
+func (q *Queries) GetAccountStatementSummary(ctx context.Context, arg GetAccountStatementSummaryParams) (GetAccountStatementSummaryRow, error) {
+	row := q.db.QueryRow(ctx, getAccountStatementSummary, arg.FromTime, arg.ToTime, arg.AccountID)
+	var i GetAccountStatementSummaryRow
+	err := row.Scan(&i.OpeningBalance, &i.ClosingBalance)
+	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, journal_transaction_id FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -58,12 +93,107 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.JournalTransactionID,
 	)
 	return i, err
 }
 
+const listAccountEntries = `-- name: ListAccountEntries :many
+WITH ledger AS (
+  SELECT
+    id,
+    account_id,
+    amount,
+    created_at,
+    transfer_id,
+    ROW_NUMBER() OVER (ORDER BY created_at, id) AS ordinal,
+    SUM(amount) OVER (ORDER BY created_at, id) - SUM(amount) OVER () AS balance_offset
+  FROM entries
+  WHERE entries.account_id = $1
+)
+SELECT
+  ledger.id,
+  ledger.account_id,
+  ledger.amount,
+  ledger.created_at,
+  ledger.transfer_id,
+  transfers.from_account_id,
+  transfers.to_account_id,
+  (accounts.balance + ledger.balance_offset)::bigint AS running_balance
+FROM ledger
+JOIN accounts ON accounts.id = ledger.account_id
+LEFT JOIN transfers ON transfers.id = ledger.transfer_id
+WHERE ledger.ordinal > COALESCE((SELECT after_entry.ordinal FROM ledger AS after_entry WHERE after_entry.id = $2), 0)
+  AND ($3::timestamptz IS NULL OR ledger.created_at >= $3)
+  AND ($4::timestamptz IS NULL OR ledger.created_at < $4)
+  AND (
+    $5::text IS NULL
+    OR ($5 = 'credit' AND ledger.amount > 0)
+    OR ($5 = 'debit' AND ledger.amount < 0)
+  )
+ORDER BY ledger.ordinal
+LIMIT $6
+`
+
+type ListAccountEntriesParams struct {
+	AccountID int64              `json:"account_id"`
+	AfterID   int64              `json:"after_id"`
+	FromTime  pgtype.Timestamptz `json:"from_time"`
+	ToTime    pgtype.Timestamptz `json:"to_time"`
+	Direction *string            `json:"direction"`
+	PageSize  int32              `json:"page_size"`
+}
+
+type ListAccountEntriesRow struct {
+	ID             int64              `json:"id"`
+	AccountID      int64              `json:"account_id"`
+	Amount         int64              `json:"amount"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	TransferID     *int64             `json:"transfer_id"`
+	FromAccountID  *int64             `json:"from_account_id"`
+	ToAccountID    *int64             `json:"to_account_id"`
+	RunningBalance int64              `json:"running_balance"`
+}
+
+func (q *Queries) ListAccountEntries(ctx context.Context, arg ListAccountEntriesParams) ([]ListAccountEntriesRow, error) {
+	rows, err := q.db.Query(ctx, listAccountEntries,
+		arg.AccountID,
+		arg.AfterID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Direction,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountEntriesRow{}
+	for rows.Next() {
+		var i ListAccountEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.RunningBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, journal_transaction_id FROM entries
 ORDER BY id
 LIMIT $1 OFFSET $2
 `
@@ -87,6 +217,8 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.JournalTransactionID,
 		); err != nil {
 			return nil, err
 		}
@@ -98,26 +230,35 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 	return items, nil
 }
 
-const updateEntry = `-- name: UpdateEntry :one
-UPDATE entries
-SET amount = $2
-WHERE id = $1
-RETURNING id, account_id, amount, created_at
+const listJournalTransactionEntries = `-- name: ListJournalTransactionEntries :many
+SELECT id, account_id, amount, created_at, transfer_id, journal_transaction_id FROM entries
+WHERE journal_transaction_id = $1
+ORDER BY id
 `
 
-type UpdateEntryParams struct {
-	ID     int64 `json:"id"`
-	Amount int64 `json:"amount"`
-}
-
-func (q *Queries) UpdateEntry(ctx context.Context, arg UpdateEntryParams) (Entry, error) {
-	row := q.db.QueryRow(ctx, updateEntry, arg.ID, arg.Amount)
-	var i Entry
-	err := row.Scan(
-		&i.ID,
-		&i.AccountID,
-		&i.Amount,
-		&i.CreatedAt,
-	)
-	return i, err
+func (q *Queries) ListJournalTransactionEntries(ctx context.Context, journalTransactionID *int64) ([]Entry, error) {
+	rows, err := q.db.Query(ctx, listJournalTransactionEntries, journalTransactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.JournalTransactionID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
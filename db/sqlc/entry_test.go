@@ -3,26 +3,39 @@ package db
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/hykura1501/simple_bank/ledger"
 	"github.com/hykura1501/simple_bank/util"
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 )
 
 func createRandomEntry(t *testing.T) Entry {
 	acc := createRandomAccount(t)
+	return postTestEntry(t, acc, util.RandomMoney())
+}
 
-	arg := CreateEntryParams{
-		AccountID: acc.ID,
-		Amount:    util.RandomMoney(),
-	}
+// postTestEntry posts amount to the account in a balanced journal transaction
+// against a new account of the same currency, and returns the account's entry
+func postTestEntry(t *testing.T, account Account, amount int64) Entry {
+	counterparty := createRandomAccountWithBalance(t, 0, account.Currency)
 
-	en, err := testQueries.CreateEntry(context.Background(), arg)
-	require.NoError(t, err)
+	posted := postTestTransaction(t, ledger.Transaction{
+		Kind: "test",
+		Postings: []ledger.Posting{
+			{AccountID: account.ID, Currency: account.Currency, Amount: amount},
+			{AccountID: counterparty.ID, Currency: account.Currency, Amount: -amount},
+		},
+	})
+
+	en := posted.Entries[0]
 	require.NotEmpty(t, en)
 
-	require.Equal(t, arg.AccountID, en.AccountID)
-	require.Equal(t, arg.Amount, en.Amount)
+	require.Equal(t, account.ID, en.AccountID)
+	require.Equal(t, amount, en.Amount)
+	require.Nil(t, en.TransferID)
+	require.Equal(t, posted.JournalTransaction.ID, *en.JournalTransactionID)
 
 	require.NotZero(t, en.ID)
 	require.NotZero(t, en.CreatedAt)
@@ -45,36 +58,40 @@ func TestGetEntry(t *testing.T) {
 	require.Equal(t, en1.AccountID, en2.AccountID)
 	require.Equal(t, en1.Amount, en2.Amount)
 	require.Equal(t, en1.CreatedAt, en2.CreatedAt)
+	require.Equal(t, en1.JournalTransactionID, en2.JournalTransactionID)
+}
+
+func TestCreateEntryOutsideJournal(t *testing.T) {
+	acc := createRandomAccount(t)
+
+	_, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{
+		AccountID: acc.ID,
+		Amount:    util.RandomMoney(),
+	})
+	require.ErrorContains(t, err, "entries_journal_transaction_required")
 }
 
 func TestUpdateEntry(t *testing.T) {
 	en1 := createRandomEntry(t)
 
-	arg := UpdateEntryParams{
-		ID:     en1.ID,
-		Amount: util.RandomMoney(),
-	}
-
-	en2, err := testQueries.UpdateEntry(context.Background(), arg)
+	// there is no query to update an entry, the database refuses it anyway
+	_, err := testDB.Exec(context.Background(), "UPDATE entries SET amount = $2 WHERE id = $1", en1.ID, util.RandomMoney())
+	require.ErrorContains(t, err, "entries are immutable")
 
+	en2, err := testQueries.GetEntry(context.Background(), en1.ID)
 	require.NoError(t, err)
-	require.NotEmpty(t, en2)
-
-	require.Equal(t, en1.ID, en2.ID)
-	require.Equal(t, en1.AccountID, en2.AccountID)
-	require.Equal(t, arg.Amount, en2.Amount)
-	require.Equal(t, en1.CreatedAt, en2.CreatedAt)
+	require.Equal(t, en1.Amount, en2.Amount)
 }
 
 func TestDeleteEntry(t *testing.T) {
 	en1 := createRandomEntry(t)
-	err := testQueries.DeleteEntry(context.Background(), en1.ID)
-	require.NoError(t, err)
+
+	_, err := testDB.Exec(context.Background(), "DELETE FROM entries WHERE id = $1", en1.ID)
+	require.ErrorContains(t, err, "entries are immutable")
 
 	en2, err := testQueries.GetEntry(context.Background(), en1.ID)
-	require.Error(t, err)
-	require.EqualError(t, err, pgx.ErrNoRows.Error())
-	require.Empty(t, en2)
+	require.NoError(t, err)
+	require.Equal(t, en1.ID, en2.ID)
 }
 
 func TestListEntry(t *testing.T) {
@@ -96,3 +113,76 @@ func TestListEntry(t *testing.T) {
 		require.NotEmpty(t, en)
 	}
 }
+
+func TestListAccountEntries(t *testing.T) {
+	account := createRandomAccountWithBalance(t, 100, util.USD)
+
+	amounts := []int64{50, -30, 20}
+	entries := make([]Entry, len(amounts))
+	for i, amount := range amounts {
+		entries[i] = postTestEntry(t, account, amount)
+	}
+
+	arg := ListAccountEntriesParams{
+		AccountID: account.ID,
+		PageSize:  2,
+	}
+
+	page1, err := testQueries.ListAccountEntries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, page1, 2)
+	require.Equal(t, entries[0].ID, page1[0].ID)
+	require.Equal(t, int64(150), page1[0].RunningBalance)
+	require.Equal(t, entries[1].ID, page1[1].ID)
+	require.Equal(t, int64(120), page1[1].RunningBalance)
+
+	arg.AfterID = page1[1].ID
+	page2, err := testQueries.ListAccountEntries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, page2, 1)
+	require.Equal(t, entries[2].ID, page2[0].ID)
+	require.Equal(t, int64(140), page2[0].RunningBalance)
+
+	// running balances still include the entries filtered out
+	direction := EntryDirectionCredit
+	credits, err := testQueries.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID: account.ID,
+		Direction: &direction,
+		PageSize:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, credits, 2)
+	require.Equal(t, int64(150), credits[0].RunningBalance)
+	require.Equal(t, int64(140), credits[1].RunningBalance)
+
+	future := pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true}
+	none, err := testQueries.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID: account.ID,
+		FromTime:  future,
+		PageSize:  10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, none)
+}
+
+func TestGetAccountStatementSummary(t *testing.T) {
+	account := createRandomAccountWithBalance(t, 100, util.USD)
+
+	postTestEntry(t, account, -40)
+
+	summary, err := testQueries.GetAccountStatementSummary(context.Background(), GetAccountStatementSummaryParams{
+		AccountID: account.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), summary.OpeningBalance)
+	require.Equal(t, int64(60), summary.ClosingBalance)
+
+	past := pgtype.Timestamptz{Time: time.Now().Add(-time.Hour), Valid: true}
+	summary, err = testQueries.GetAccountStatementSummary(context.Background(), GetAccountStatementSummaryParams{
+		ToTime:    past,
+		AccountID: account.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), summary.OpeningBalance)
+	require.Equal(t, int64(100), summary.ClosingBalance)
+}
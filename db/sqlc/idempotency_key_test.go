@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomIdempotencyKey(t *testing.T, username string, expiredAt time.Time) IdempotencyKey {
+	arg := CreateIdempotencyKeyParams{
+		Key:         util.RandomString(32),
+		Username:    username,
+		RequestHash: util.RandomString(64),
+		ExpiredAt:   pgtype.Timestamptz{Time: expiredAt, Valid: true},
+	}
+
+	key, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, key)
+
+	require.Equal(t, arg.Key, key.Key)
+	require.Equal(t, arg.Username, key.Username)
+	require.Equal(t, arg.RequestHash, key.RequestHash)
+	require.Nil(t, key.Response)
+	require.WithinDuration(t, expiredAt, key.ExpiredAt.Time, time.Second)
+	require.NotZero(t, key.CreatedAt)
+
+	return key
+}
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomIdempotencyKey(t, user.Username, time.Now().Add(time.Hour))
+}
+
+func TestCreateIdempotencyKeyConflict(t *testing.T) {
+	user := createRandomUser(t)
+	key1 := createRandomIdempotencyKey(t, user.Username, time.Now().Add(time.Hour))
+
+	key2, err := testQueries.CreateIdempotencyKey(context.Background(), CreateIdempotencyKeyParams{
+		Key:         key1.Key,
+		Username:    key1.Username,
+		RequestHash: util.RandomString(64),
+		ExpiredAt:   pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+	require.Empty(t, key2)
+}
+
+func TestCreateIdempotencyKeyExpired(t *testing.T) {
+	user := createRandomUser(t)
+	key1 := createRandomIdempotencyKey(t, user.Username, time.Now().Add(-time.Minute))
+
+	arg := CreateIdempotencyKeyParams{
+		Key:         key1.Key,
+		Username:    key1.Username,
+		RequestHash: util.RandomString(64),
+		ExpiredAt:   pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+	key2, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.RequestHash, key2.RequestHash)
+	require.WithinDuration(t, arg.ExpiredAt.Time, key2.ExpiredAt.Time, time.Second)
+}
+
+func TestUpdateIdempotencyKeyResponse(t *testing.T) {
+	user := createRandomUser(t)
+	key1 := createRandomIdempotencyKey(t, user.Username, time.Now().Add(time.Hour))
+
+	response := []byte(`{"id":1}`)
+	err := testQueries.UpdateIdempotencyKeyResponse(context.Background(), UpdateIdempotencyKeyResponseParams{
+		Username: key1.Username,
+		Key:      key1.Key,
+		Response: response,
+	})
+	require.NoError(t, err)
+
+	key2, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: key1.Username,
+		Key:      key1.Key,
+	})
+	require.NoError(t, err)
+	require.Equal(t, key1.RequestHash, key2.RequestHash)
+	require.JSONEq(t, string(response), string(key2.Response))
+}
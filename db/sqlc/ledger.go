@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hykura1501/simple_bank/ledger"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostedTransaction is a journal transaction written to the database with its entries
+// and the accounts it moved money between, after their balances were updated
+type PostedTransaction struct {
+	JournalTransaction JournalTransaction
+	Entries            []Entry
+	Accounts           map[int64]Account
+}
+
+// postTransaction writes a balanced journal transaction and applies its postings to the accounts' balances.
+// It is the only way money moves between accounts: every entry is posted through it,
+// and an account balance only ever changes by the net of its postings.
+// The accounts are locked in ascending ID order, so it can run after the caller has locked some of them.
+// The database checks again at commit that the postings are balanced
+func postTransaction(ctx context.Context, q *Queries, txn ledger.Transaction, transferID *int64) (PostedTransaction, error) {
+	var posted PostedTransaction
+
+	err := txn.Validate()
+	if err != nil {
+		return posted, err
+	}
+
+	posted.Accounts = make(map[int64]Account)
+	for _, accountID := range txn.AccountIDs() {
+		account, err := q.GetAccountForUpdate(ctx, accountID)
+		if err != nil {
+			return posted, err
+		}
+		posted.Accounts[accountID] = account
+	}
+
+	for _, posting := range txn.Postings {
+		if currency := posted.Accounts[posting.AccountID].Currency; currency != posting.Currency {
+			return posted, fmt.Errorf("%w: account [%d] is in %s, posting is in %s",
+				ledger.ErrCurrencyMismatch, posting.AccountID, currency, posting.Currency)
+		}
+	}
+
+	posted.JournalTransaction, err = q.CreateJournalTransaction(ctx, CreateJournalTransactionParams{
+		Kind:       txn.Kind,
+		TransferID: transferID,
+	})
+	if err != nil {
+		return posted, err
+	}
+
+	posted.Entries = make([]Entry, 0, len(txn.Postings))
+	for _, posting := range txn.Postings {
+		entry, err := q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:            posting.AccountID,
+			Amount:               posting.Amount,
+			TransferID:           transferID,
+			JournalTransactionID: &posted.JournalTransaction.ID,
+		})
+		if err != nil {
+			return posted, err
+		}
+		posted.Entries = append(posted.Entries, entry)
+	}
+
+	net := txn.Net()
+	for _, accountID := range txn.AccountIDs() {
+		if net[accountID] == 0 {
+			continue
+		}
+
+		account, err := addAccountBalance(ctx, q, accountID, net[accountID])
+		if err != nil {
+			return posted, err
+		}
+		posted.Accounts[accountID] = account
+	}
+
+	return posted, nil
+}
+
+// addAccountBalanceSQL is kept out of db/query, so that sqlc doesn't add it to the Querier interface:
+// a balance must only change through postTransaction, together with the entries that explain it
+const addAccountBalanceSQL = `
+UPDATE accounts
+SET balance = balance + $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, is_frozen
+`
+
+func addAccountBalance(ctx context.Context, q *Queries, accountID int64, amount int64) (Account, error) {
+	row := q.db.QueryRow(ctx, addAccountBalanceSQL, amount, accountID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.IsFrozen,
+	)
+	return i, err
+}
+
+// systemAccountID returns the ID of the account of a system user, such as ledger.FXClearingOwner, in the currency.
+// The account is created the first time the currency is needed
+func systemAccountID(ctx context.Context, q *Queries, owner string, currency string) (int64, error) {
+	arg := GetAccountByCurrencyParams{
+		Owner:    owner,
+		Currency: currency,
+	}
+
+	account, err := q.GetAccountByCurrency(ctx, arg)
+	if err == nil {
+		return account.ID, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, err
+	}
+
+	err = q.CreateSystemAccount(ctx, CreateSystemAccountParams{
+		Owner:    owner,
+		Currency: currency,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	account, err = q.GetAccountByCurrency(ctx, arg)
+	return account.ID, err
+}
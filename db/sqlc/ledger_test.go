@@ -0,0 +1,183 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hykura1501/simple_bank/ledger"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// postTestTransaction posts the journal transaction in its own database transaction
+func postTestTransaction(t *testing.T, txn ledger.Transaction) PostedTransaction {
+	store := NewStore(testDB).(*SQLStore)
+
+	var posted PostedTransaction
+	err := store.execTx(context.Background(), func(q *Queries) error {
+		var err error
+		posted, err = postTransaction(context.Background(), q, txn, nil)
+		return err
+	})
+	require.NoError(t, err)
+
+	return posted
+}
+
+func TestPostTransaction(t *testing.T) {
+	acc1 := createRandomAccountWithBalance(t, 100, util.USD)
+	acc2 := createRandomAccountWithBalance(t, 0, util.USD)
+	acc3 := createRandomAccountWithBalance(t, 0, util.USD)
+
+	posted := postTestTransaction(t, ledger.Transaction{
+		Kind: "test",
+		Postings: []ledger.Posting{
+			{AccountID: acc1.ID, Currency: util.USD, Amount: -70},
+			{AccountID: acc2.ID, Currency: util.USD, Amount: 50},
+			{AccountID: acc3.ID, Currency: util.USD, Amount: 20},
+		},
+	})
+
+	require.NotZero(t, posted.JournalTransaction.ID)
+	require.Equal(t, "test", posted.JournalTransaction.Kind)
+	require.Nil(t, posted.JournalTransaction.TransferID)
+	require.Len(t, posted.Entries, 3)
+
+	require.Equal(t, int64(30), posted.Accounts[acc1.ID].Balance)
+	require.Equal(t, int64(50), posted.Accounts[acc2.ID].Balance)
+	require.Equal(t, int64(20), posted.Accounts[acc3.ID].Balance)
+
+	entries, err := testQueries.ListJournalTransactionEntries(context.Background(), &posted.JournalTransaction.ID)
+	require.NoError(t, err)
+	require.Equal(t, posted.Entries, entries)
+
+	journalTransaction, err := testQueries.GetJournalTransaction(context.Background(), posted.JournalTransaction.ID)
+	require.NoError(t, err)
+	require.Equal(t, posted.JournalTransaction, journalTransaction)
+}
+
+func TestPostTransactionInvalid(t *testing.T) {
+	usd := createRandomAccountWithBalance(t, 100, util.USD)
+	eur := createRandomAccountWithBalance(t, 100, util.EUR)
+
+	testCases := []struct {
+		name     string
+		postings []ledger.Posting
+		err      error
+	}{
+		{
+			name: "Unbalanced",
+			postings: []ledger.Posting{
+				{AccountID: usd.ID, Currency: util.USD, Amount: -10},
+				{AccountID: eur.ID, Currency: util.USD, Amount: 9},
+			},
+			err: ledger.ErrUnbalanced,
+		},
+		{
+			name: "CurrencyMismatch",
+			postings: []ledger.Posting{
+				{AccountID: usd.ID, Currency: util.USD, Amount: -10},
+				{AccountID: eur.ID, Currency: util.USD, Amount: 10},
+			},
+			err: ledger.ErrCurrencyMismatch,
+		},
+	}
+
+	store := NewStore(testDB).(*SQLStore)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := store.execTx(context.Background(), func(q *Queries) error {
+				_, err := postTransaction(context.Background(), q, ledger.Transaction{Kind: "test", Postings: tc.postings}, nil)
+				return err
+			})
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	// nothing was posted
+	account, err := testQueries.GetAccount(context.Background(), usd.ID)
+	require.NoError(t, err)
+	require.Equal(t, usd.Balance, account.Balance)
+}
+
+func TestUnbalancedJournalRejectedAtCommit(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+	account := createRandomAccount(t)
+
+	// writing the entries directly skips the checks of postTransaction, the database still refuses the commit
+	err := store.execTx(context.Background(), func(q *Queries) error {
+		journalTransaction, err := q.CreateJournalTransaction(context.Background(), CreateJournalTransactionParams{
+			Kind: "test",
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateEntry(context.Background(), CreateEntryParams{
+			AccountID:            account.ID,
+			Amount:               10,
+			JournalTransactionID: &journalTransaction.ID,
+		})
+		return err
+	})
+	require.ErrorContains(t, err, "is not balanced")
+}
+
+func TestPostOpeningBalance(t *testing.T) {
+	account := createRandomAccountWithBalance(t, 0, util.USD)
+	entry := postTestEntry(t, account, 30)
+
+	// the balance was written before the journal existed, without any entry
+	account = setTestAccountBalance(t, account, 100)
+
+	// the journal migration posts the opening balance of every existing account this way
+	_, err := testDB.Exec(context.Background(), "SELECT post_opening_balance($1)", account.ID)
+	require.NoError(t, err)
+
+	entries, err := testQueries.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID: account.ID,
+		PageSize:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// the opening balance is dated at the creation of the account, before the entries that followed it
+	opening := entries[0]
+	require.Equal(t, int64(70), opening.Amount)
+	require.Equal(t, int64(70), opening.RunningBalance)
+	require.Equal(t, account.CreatedAt, opening.CreatedAt)
+	require.Nil(t, opening.TransferID)
+	require.Equal(t, entry.ID, entries[1].ID)
+	require.Equal(t, int64(100), entries[1].RunningBalance)
+
+	openingEntry, err := testQueries.GetEntry(context.Background(), opening.ID)
+	require.NoError(t, err)
+	journalTransaction, err := testQueries.GetJournalTransaction(context.Background(), *openingEntry.JournalTransactionID)
+	require.NoError(t, err)
+	require.Equal(t, ledger.KindOpeningBalance, journalTransaction.Kind)
+
+	postings, err := testQueries.ListJournalTransactionEntries(context.Background(), &journalTransaction.ID)
+	require.NoError(t, err)
+	require.Len(t, postings, 2)
+
+	equityAccount, err := testQueries.GetAccount(context.Background(), postings[1].AccountID)
+	require.NoError(t, err)
+	require.Equal(t, ledger.OpeningBalanceOwner, equityAccount.Owner)
+	require.Equal(t, util.USD, equityAccount.Currency)
+	require.Equal(t, int64(-70), postings[1].Amount)
+
+	// the balance now matches the entries, so posting again does nothing
+	_, err = testDB.Exec(context.Background(), "SELECT post_opening_balance($1)", account.ID)
+	require.NoError(t, err)
+
+	entries, err = testQueries.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID: account.ID,
+		PageSize:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	account, err = testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), account.Balance)
+}
@@ -14,6 +14,23 @@ type Account struct {
 	Balance   int64              `json:"balance"`
 	Currency  string             `json:"currency"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	// how far below zero the balance is allowed to go
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	// no transfer can be made from or to the account
+	IsFrozen bool `json:"is_frozen"`
+}
+
+type BalanceAdjustment struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// the compensating journal transaction that posted the adjustment
+	JournalTransactionID int64 `json:"journal_transaction_id"`
+	// added to the account balance, negative for a debit
+	Amount int64  `json:"amount"`
+	Reason string `json:"reason"`
+	// the banker or admin who adjusted the balance
+	AdjustedBy string             `json:"adjusted_by"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
 }
 
 type Entry struct {
@@ -22,6 +39,93 @@ type Entry struct {
 	// can be negative or positive
 	Amount    int64              `json:"amount"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	// the transfer that created the entry
+	TransferID *int64 `json:"transfer_id"`
+	// the balanced journal transaction that the entry is posted in
+	JournalTransactionID *int64 `json:"journal_transaction_id"`
+}
+
+type IdempotencyKey struct {
+	Key         string `json:"key"`
+	Username    string `json:"username"`
+	RequestHash string `json:"request_hash"`
+	// null until the request has been executed
+	Response  []byte             `json:"response"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	ExpiredAt pgtype.Timestamptz `json:"expired_at"`
+}
+
+type MfaRecoveryCode struct {
+	ID         int64              `json:"id"`
+	Username   string             `json:"username"`
+	HashedCode string             `json:"hashed_code"`
+	UsedAt     pgtype.Timestamptz `json:"used_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type JournalTransaction struct {
+	ID   int64  `json:"id"`
+	Kind string `json:"kind"`
+	// the transfer that the journal transaction records, if any
+	TransferID *int64             `json:"transfer_id"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type PasswordHistory struct {
+	ID             int64  `json:"id"`
+	Username       string `json:"username"`
+	HashedPassword string `json:"hashed_password"`
+	// when the password was replaced
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type PasswordReset struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// sha256 of the token sent in the reset email, the token itself is never stored
+	HashedToken string             `json:"hashed_token"`
+	IsUsed      bool               `json:"is_used"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	ExpiredAt   pgtype.Timestamptz `json:"expired_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	// must be positive, in the currency of both accounts
+	Amount int64 `json:"amount"`
+	// cron expression, in UTC unless it starts with CRON_TZ=
+	Schedule string             `json:"schedule"`
+	StartAt  pgtype.Timestamptz `json:"start_at"`
+	// no run is due after this time
+	EndAt pgtype.Timestamptz `json:"end_at"`
+	// the scheduled transfer completes after this many runs
+	MaxOccurrences *int32 `json:"max_occurrences"`
+	// number of runs so far, failed or not
+	Occurrences int32 `json:"occurrences"`
+	// null unless the scheduled transfer is active
+	NextRunAt pgtype.Timestamptz `json:"next_run_at"`
+	// active, paused, completed or cancelled
+	Status    string             `json:"status"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	// the largest amount that the owner authorized with a TOTP code, null if none
+	MfaAuthorizedAmount *int64 `json:"mfa_authorized_amount"`
+}
+
+type ScheduledTransferRun struct {
+	ID                  int64 `json:"id"`
+	ScheduledTransferID int64 `json:"scheduled_transfer_id"`
+	// the occurrence that the run executed
+	ScheduledAt pgtype.Timestamptz `json:"scheduled_at"`
+	Status      string             `json:"status"`
+	// the transfer made by a successful run
+	TransferID *int64 `json:"transfer_id"`
+	// why a failed run made no transfer
+	FailureReason *string            `json:"failure_reason"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
 }
 
 type Session struct {
@@ -33,6 +137,10 @@ type Session struct {
 	IsBlocked    bool               `json:"is_blocked"`
 	ExpiredAt    pgtype.Timestamptz `json:"expired_at"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	// id of the login session that the refresh token was rotated from
+	FamilyID pgtype.UUID `json:"family_id"`
+	// the refresh token was exchanged for a new one
+	IsUsed bool `json:"is_used"`
 }
 
 type Transfer struct {
@@ -42,6 +150,23 @@ type Transfer struct {
 	// must be positive
 	Amount    int64              `json:"amount"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	// credited to the destination account, in its currency
+	ToAmount int64 `json:"to_amount"`
+	// source to destination currency, per major unit
+	ExchangeRate pgtype.Numeric `json:"exchange_rate"`
+	RoundingMode string         `json:"rounding_mode"`
+}
+
+type TransferReversal struct {
+	ID int64 `json:"id"`
+	// the transfer being reversed
+	TransferID int64 `json:"transfer_id"`
+	// the compensating transfer, from the destination back to the source account
+	ReversalTransferID int64  `json:"reversal_transfer_id"`
+	ReasonCode         string `json:"reason_code"`
+	// the banker or admin who reversed the transfer
+	ReversedBy string             `json:"reversed_by"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
 }
 
 type User struct {
@@ -51,4 +176,27 @@ type User struct {
 	Email             string             `json:"email"`
 	PasswordChangedAt pgtype.Timestamptz `json:"password_changed_at"`
 	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	IsEmailVerified   bool               `json:"is_email_verified"`
+	Role              string             `json:"role"`
+}
+
+type UserMfa struct {
+	Username string `json:"username"`
+	// TOTP secret encrypted with MFA_ENCRYPTION_KEY
+	EncryptedSecret []byte `json:"encrypted_secret"`
+	IsEnabled       bool   `json:"is_enabled"`
+	// time step of the last accepted TOTP code, so that a code cannot be used twice
+	LastUsedStep int64              `json:"last_used_step"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	EnabledAt    pgtype.Timestamptz `json:"enabled_at"`
+}
+
+type VerifyEmail struct {
+	ID         int64              `json:"id"`
+	Username   string             `json:"username"`
+	Email      string             `json:"email"`
+	SecretCode string             `json:"secret_code"`
+	IsUsed     bool               `json:"is_used"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	ExpiredAt  pgtype.Timestamptz `json:"expired_at"`
 }
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListPasswordHistory(t *testing.T) {
+	user := createRandomUser(t)
+
+	hashedPasswords, err := testQueries.ListPasswordHistory(context.Background(), ListPasswordHistoryParams{
+		Username: user.Username,
+		Limit:    5,
+	})
+	require.NoError(t, err)
+	require.Empty(t, hashedPasswords)
+
+	archived := []string{user.HashedPassword}
+	for range 2 {
+		err = testQueries.ArchiveUserPassword(context.Background(), user.Username)
+		require.NoError(t, err)
+
+		hashedPassword := util.RandomString(60)
+		_, err = testQueries.UpdateUser(context.Background(), UpdateUserParams{
+			Username:       user.Username,
+			HashedPassword: &hashedPassword,
+		})
+		require.NoError(t, err)
+		archived = append(archived, hashedPassword)
+	}
+
+	// the newest replaced password comes first
+	hashedPasswords, err = testQueries.ListPasswordHistory(context.Background(), ListPasswordHistoryParams{
+		Username: user.Username,
+		Limit:    5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{archived[1], archived[0]}, hashedPasswords)
+
+	hashedPasswords, err = testQueries.ListPasswordHistory(context.Background(), ListPasswordHistoryParams{
+		Username: user.Username,
+		Limit:    1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{archived[1]}, hashedPasswords)
+}
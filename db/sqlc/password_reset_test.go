@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPasswordReset(t *testing.T, user User, duration time.Duration) PasswordReset {
+	arg := CreatePasswordResetParams{
+		Username:    user.Username,
+		HashedToken: util.RandomString(64),
+		ExpiredAt: pgtype.Timestamptz{
+			Time:  time.Now().Add(duration),
+			Valid: true,
+		},
+	}
+
+	passwordReset, err := testQueries.CreatePasswordReset(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, passwordReset)
+
+	require.Equal(t, arg.Username, passwordReset.Username)
+	require.Equal(t, arg.HashedToken, passwordReset.HashedToken)
+	require.False(t, passwordReset.IsUsed)
+	require.NotZero(t, passwordReset.CreatedAt)
+	require.WithinDuration(t, arg.ExpiredAt.Time, passwordReset.ExpiredAt.Time, time.Second)
+
+	return passwordReset
+}
+
+func TestCreatePasswordReset(t *testing.T) {
+	createRandomPasswordReset(t, createRandomUser(t), time.Minute)
+}
+
+func TestGetActivePasswordReset(t *testing.T) {
+	passwordReset := createRandomPasswordReset(t, createRandomUser(t), time.Minute)
+
+	active, err := testQueries.GetActivePasswordReset(context.Background(), passwordReset.HashedToken)
+	require.NoError(t, err)
+	require.Equal(t, passwordReset.ID, active.ID)
+	require.Equal(t, passwordReset.Username, active.Username)
+
+	_, err = testQueries.UsePasswordReset(context.Background(), passwordReset.HashedToken)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetActivePasswordReset(context.Background(), passwordReset.HashedToken)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	expired := createRandomPasswordReset(t, createRandomUser(t), -time.Minute)
+	_, err = testQueries.GetActivePasswordReset(context.Background(), expired.HashedToken)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestUsePasswordReset(t *testing.T) {
+	passwordReset := createRandomPasswordReset(t, createRandomUser(t), time.Minute)
+
+	used, err := testQueries.UsePasswordReset(context.Background(), passwordReset.HashedToken)
+	require.NoError(t, err)
+	require.True(t, used.IsUsed)
+
+	// a token can only be used once
+	_, err = testQueries.UsePasswordReset(context.Background(), passwordReset.HashedToken)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestUseExpiredPasswordReset(t *testing.T) {
+	passwordReset := createRandomPasswordReset(t, createRandomUser(t), -time.Minute)
+
+	_, err := testQueries.UsePasswordReset(context.Background(), passwordReset.HashedToken)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestExpireUserPasswordResets(t *testing.T) {
+	user := createRandomUser(t)
+	passwordReset1 := createRandomPasswordReset(t, user, time.Minute)
+	passwordReset2 := createRandomPasswordReset(t, user, time.Minute)
+	other := createRandomPasswordReset(t, createRandomUser(t), time.Minute)
+
+	rows, err := testQueries.ExpireUserPasswordResets(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), rows)
+
+	for _, passwordReset := range []PasswordReset{passwordReset1, passwordReset2} {
+		_, err = testQueries.UsePasswordReset(context.Background(), passwordReset.HashedToken)
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+	}
+
+	_, err = testQueries.UsePasswordReset(context.Background(), other.HashedToken)
+	require.NoError(t, err)
+}
@@ -11,28 +11,76 @@ import (
 )
 
 type Querier interface {
-	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AdvanceScheduledTransfer(ctx context.Context, arg AdvanceScheduledTransferParams) (ScheduledTransfer, error)
+	ArchiveUserPassword(ctx context.Context, username string) error
+	BlockSession(ctx context.Context, id pgtype.UUID) (Session, error)
+	BlockSessionFamily(ctx context.Context, familyID pgtype.UUID) (int64, error)
+	BlockUserSessionFamily(ctx context.Context, arg BlockUserSessionFamilyParams) (int64, error)
+	BlockUserSessions(ctx context.Context, username string) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateBalanceAdjustment(ctx context.Context, arg CreateBalanceAdjustmentParams) (BalanceAdjustment, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateJournalTransaction(ctx context.Context, arg CreateJournalTransactionParams) (JournalTransaction, error)
+	CreateMFARecoveryCodes(ctx context.Context, arg CreateMFARecoveryCodesParams) (int64, error)
+	CreatePasswordReset(ctx context.Context, arg CreatePasswordResetParams) (PasswordReset, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateSystemAccount(ctx context.Context, arg CreateSystemAccountParams) error
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferReversal(ctx context.Context, arg CreateTransferReversalParams) (TransferReversal, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
-	DeleteAccount(ctx context.Context, id int64) error
-	DeleteEntry(ctx context.Context, id int64) error
-	DeleteTransfer(ctx context.Context, id int64) error
+	CreateVerifyEmail(ctx context.Context, arg CreateVerifyEmailParams) (VerifyEmail, error)
+	DeleteExpiredSessions(ctx context.Context) (int64, error)
+	DeleteMFARecoveryCodes(ctx context.Context, username string) error
+	DeleteUserMFA(ctx context.Context, username string) error
+	EnableUserMFA(ctx context.Context, arg EnableUserMFAParams) (UserMfa, error)
+	ExpireUserPasswordResets(ctx context.Context, username string) (int64, error)
+	FreezeAccount(ctx context.Context, id int64) (Account, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountByCurrency(ctx context.Context, arg GetAccountByCurrencyParams) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountStatementSummary(ctx context.Context, arg GetAccountStatementSummaryParams) (GetAccountStatementSummaryRow, error)
+	GetActivePasswordReset(ctx context.Context, hashedToken string) (PasswordReset, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetJournalTransaction(ctx context.Context, id int64) (JournalTransaction, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetScheduledTransferForUpdate(ctx context.Context, id int64) (ScheduledTransfer, error)
 	GetSession(ctx context.Context, id pgtype.UUID) (Session, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferReversedAmounts(ctx context.Context, transferID int64) (GetTransferReversedAmountsRow, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserMFA(ctx context.Context, username string) (UserMfa, error)
+	IsReversalTransfer(ctx context.Context, reversalTransferID int64) (bool, error)
+	IsSessionFamilyBlocked(ctx context.Context, familyID pgtype.UUID) (bool, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAccountEntries(ctx context.Context, arg ListAccountEntriesParams) ([]ListAccountEntriesRow, error)
+	ListActiveSessions(ctx context.Context, arg ListActiveSessionsParams) ([]ListActiveSessionsRow, error)
+	ListBalanceAdjustments(ctx context.Context, arg ListBalanceAdjustmentsParams) ([]BalanceAdjustment, error)
+	ListDueScheduledTransfers(ctx context.Context, arg ListDueScheduledTransfersParams) ([]ScheduledTransfer, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListJournalTransactionEntries(ctx context.Context, journalTransactionID *int64) ([]Entry, error)
+	ListPasswordHistory(ctx context.Context, arg ListPasswordHistoryParams) ([]string, error)
+	ListScheduledTransferRuns(ctx context.Context, arg ListScheduledTransferRunsParams) ([]ScheduledTransferRun, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListTransferReversals(ctx context.Context, transferID int64) ([]TransferReversal, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
-	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
-	UpdateEntry(ctx context.Context, arg UpdateEntryParams) (Entry, error)
-	UpdateTransfer(ctx context.Context, arg UpdateTransferParams) (Transfer, error)
+	MarkSessionUsed(ctx context.Context, id pgtype.UUID) (Session, error)
+	ReconcileAccountBalances(ctx context.Context, arg ReconcileAccountBalancesParams) ([]ReconcileAccountBalancesRow, error)
+	ReconcileTransferEntries(ctx context.Context, arg ReconcileTransferEntriesParams) ([]ReconcileTransferEntriesRow, error)
+	RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error)
+	SetupUserMFA(ctx context.Context, arg SetupUserMFAParams) (UserMfa, error)
+	UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error)
+	UpdateIdempotencyKeyResponse(ctx context.Context, arg UpdateIdempotencyKeyResponseParams) error
+	UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateVerifyEmail(ctx context.Context, arg UpdateVerifyEmailParams) (VerifyEmail, error)
+	UseMFARecoveryCode(ctx context.Context, arg UseMFARecoveryCodeParams) (int64, error)
+	UsePasswordReset(ctx context.Context, hashedToken string) (PasswordReset, error)
+	UseUserMFAStep(ctx context.Context, arg UseUserMFAStepParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
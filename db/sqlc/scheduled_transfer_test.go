@@ -0,0 +1,172 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomScheduledTransfer(t *testing.T, fromAccount Account, toAccount Account, nextRunAt time.Time) ScheduledTransfer {
+	maxOccurrences := int32(12)
+	arg := CreateScheduledTransferParams{
+		Owner:          fromAccount.Owner,
+		FromAccountID:  fromAccount.ID,
+		ToAccountID:    toAccount.ID,
+		Amount:         util.RandomMoney(),
+		Schedule:       "0 9 1 * *",
+		StartAt:        pgtype.Timestamptz{Time: nextRunAt, Valid: true},
+		MaxOccurrences: &maxOccurrences,
+		NextRunAt:      pgtype.Timestamptz{Time: nextRunAt, Valid: true},
+	}
+
+	scheduled, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, scheduled.ID)
+
+	require.Equal(t, arg.Owner, scheduled.Owner)
+	require.Equal(t, arg.FromAccountID, scheduled.FromAccountID)
+	require.Equal(t, arg.ToAccountID, scheduled.ToAccountID)
+	require.Equal(t, arg.Amount, scheduled.Amount)
+	require.Equal(t, arg.Schedule, scheduled.Schedule)
+	require.False(t, scheduled.EndAt.Valid)
+	require.Equal(t, &maxOccurrences, scheduled.MaxOccurrences)
+	require.Zero(t, scheduled.Occurrences)
+	require.WithinDuration(t, nextRunAt, scheduled.NextRunAt.Time, time.Millisecond)
+	require.Equal(t, ScheduledTransferActive, scheduled.Status)
+
+	return scheduled
+}
+
+func TestCreateScheduledTransfer(t *testing.T) {
+	fromAccount := createRandomAccountWithBalance(t, 100, util.USD)
+	toAccount := createRandomAccountWithBalance(t, 0, util.USD)
+	createRandomScheduledTransfer(t, fromAccount, toAccount, time.Now())
+}
+
+func TestGetScheduledTransfer(t *testing.T) {
+	fromAccount := createRandomAccountWithBalance(t, 100, util.USD)
+	toAccount := createRandomAccountWithBalance(t, 0, util.USD)
+	scheduled1 := createRandomScheduledTransfer(t, fromAccount, toAccount, time.Now())
+
+	scheduled2, err := testQueries.GetScheduledTransfer(context.Background(), scheduled1.ID)
+	require.NoError(t, err)
+	require.Equal(t, scheduled1, scheduled2)
+}
+
+func TestListScheduledTransfers(t *testing.T) {
+	fromAccount := createRandomAccountWithBalance(t, 100, util.USD)
+	toAccount := createRandomAccountWithBalance(t, 0, util.USD)
+
+	var lastScheduled ScheduledTransfer
+	for range 3 {
+		lastScheduled = createRandomScheduledTransfer(t, fromAccount, toAccount, time.Now())
+	}
+
+	scheduledTransfers, err := testQueries.ListScheduledTransfers(context.Background(), ListScheduledTransfersParams{
+		Owner:  fromAccount.Owner,
+		Limit:  2,
+		Offset: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, scheduledTransfers, 2)
+	require.Equal(t, lastScheduled, scheduledTransfers[1])
+}
+
+func TestListDueScheduledTransfers(t *testing.T) {
+	fromAccount := createRandomAccountWithBalance(t, 100, util.USD)
+	toAccount := createRandomAccountWithBalance(t, 0, util.USD)
+
+	now := time.Now().Add(-time.Duration(util.RandomInt(1, 1000)) * time.Hour)
+	due := createRandomScheduledTransfer(t, fromAccount, toAccount, now.Add(-time.Minute))
+	notDue := createRandomScheduledTransfer(t, fromAccount, toAccount, now.Add(time.Minute))
+	paused := createRandomScheduledTransfer(t, fromAccount, toAccount, now.Add(-time.Minute))
+
+	paused, err := testQueries.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:             paused.ID,
+		Amount:         paused.Amount,
+		Schedule:       paused.Schedule,
+		MaxOccurrences: paused.MaxOccurrences,
+		NextRunAt:      paused.NextRunAt,
+		Status:         ScheduledTransferPaused,
+	})
+	require.NoError(t, err)
+
+	scheduledTransfers, err := testQueries.ListDueScheduledTransfers(context.Background(), ListDueScheduledTransfersParams{
+		Now:       pgtype.Timestamptz{Time: now, Valid: true},
+		BatchSize: 1000,
+	})
+	require.NoError(t, err)
+
+	ids := make([]int64, 0, len(scheduledTransfers))
+	for _, scheduled := range scheduledTransfers {
+		require.Equal(t, ScheduledTransferActive, scheduled.Status)
+		require.False(t, scheduled.NextRunAt.Time.After(now))
+		ids = append(ids, scheduled.ID)
+	}
+	require.Contains(t, ids, due.ID)
+	require.NotContains(t, ids, notDue.ID)
+	require.NotContains(t, ids, paused.ID)
+}
+
+func TestUpdateScheduledTransfer(t *testing.T) {
+	fromAccount := createRandomAccountWithBalance(t, 100, util.USD)
+	toAccount := createRandomAccountWithBalance(t, 0, util.USD)
+	scheduled1 := createRandomScheduledTransfer(t, fromAccount, toAccount, time.Now())
+
+	endAt := pgtype.Timestamptz{Time: time.Now().Add(24 * time.Hour), Valid: true}
+	arg := UpdateScheduledTransferParams{
+		ID:        scheduled1.ID,
+		Amount:    scheduled1.Amount + 1,
+		Schedule:  "@weekly",
+		EndAt:     endAt,
+		NextRunAt: scheduled1.NextRunAt,
+		Status:    ScheduledTransferActive,
+	}
+
+	scheduled2, err := testQueries.UpdateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Amount, scheduled2.Amount)
+	require.Equal(t, arg.Schedule, scheduled2.Schedule)
+	require.WithinDuration(t, endAt.Time, scheduled2.EndAt.Time, time.Millisecond)
+	require.Nil(t, scheduled2.MaxOccurrences)
+	require.True(t, scheduled2.UpdatedAt.Time.After(scheduled1.UpdatedAt.Time))
+}
+
+func TestScheduledTransferRunImmutable(t *testing.T) {
+	fromAccount := createRandomAccountWithBalance(t, 100, util.USD)
+	toAccount := createRandomAccountWithBalance(t, 0, util.USD)
+	scheduled := createRandomScheduledTransfer(t, fromAccount, toAccount, time.Now())
+
+	failureReason := "insufficient funds"
+	run, err := testQueries.CreateScheduledTransferRun(context.Background(), CreateScheduledTransferRunParams{
+		ScheduledTransferID: scheduled.ID,
+		ScheduledAt:         scheduled.NextRunAt,
+		Status:              ScheduledTransferRunFailed,
+		FailureReason:       &failureReason,
+	})
+	require.NoError(t, err)
+	require.Equal(t, &failureReason, run.FailureReason)
+	require.Nil(t, run.TransferID)
+
+	// an occurrence is only run once
+	_, err = testQueries.CreateScheduledTransferRun(context.Background(), CreateScheduledTransferRunParams{
+		ScheduledTransferID: scheduled.ID,
+		ScheduledAt:         scheduled.NextRunAt,
+		Status:              ScheduledTransferRunSucceeded,
+	})
+	require.ErrorContains(t, err, "duplicate key")
+
+	_, err = testDB.Exec(context.Background(), "DELETE FROM scheduled_transfer_runs WHERE id = $1", run.ID)
+	require.ErrorContains(t, err, "scheduled_transfer_runs are immutable")
+
+	runs, err := testQueries.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduled.ID,
+		Limit:               5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []ScheduledTransferRun{run}, runs)
+}
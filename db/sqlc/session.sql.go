@@ -11,22 +11,96 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const blockSession = `-- name: BlockSession :one
+UPDATE sessions
+SET is_blocked = TRUE
+WHERE id = $1
+RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expired_at, created_at, family_id, is_used
+`
+
+func (q *Queries) BlockSession(ctx context.Context, id pgtype.UUID) (Session, error) {
+	row := q.db.QueryRow(ctx, blockSession, id)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.RefreshToken,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.IsBlocked,
+		&i.ExpiredAt,
+		&i.CreatedAt,
+		&i.FamilyID,
+		&i.IsUsed,
+	)
+	return i, err
+}
+
+const blockSessionFamily = `-- name: BlockSessionFamily :execrows
+UPDATE sessions
+SET is_blocked = TRUE
+WHERE family_id = $1 AND is_blocked = FALSE
+`
+
+func (q *Queries) BlockSessionFamily(ctx context.Context, familyID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, blockSessionFamily, familyID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const blockUserSessionFamily = `-- name: BlockUserSessionFamily :execrows
+UPDATE sessions
+SET is_blocked = TRUE
+WHERE family_id = $1 AND username = $2 AND is_blocked = FALSE
+`
+
+type BlockUserSessionFamilyParams struct {
+	FamilyID pgtype.UUID `json:"family_id"`
+	Username string      `json:"username"`
+}
+
+func (q *Queries) BlockUserSessionFamily(ctx context.Context, arg BlockUserSessionFamilyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, blockUserSessionFamily, arg.FamilyID, arg.Username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const blockUserSessions = `-- name: BlockUserSessions :execrows
+UPDATE sessions
+SET is_blocked = TRUE
+WHERE username = $1 AND is_blocked = FALSE
+`
+
+func (q *Queries) BlockUserSessions(ctx context.Context, username string) (int64, error) {
+	result, err := q.db.Exec(ctx, blockUserSessions, username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const createSession = `-- name: CreateSession :one
 INSERT INTO sessions
 (
   id,
+  family_id,
   username,
   refresh_token,
   user_agent,
   client_ip,
   is_blocked,
   expired_at
-) VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expired_at, created_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expired_at, created_at, family_id, is_used
 `
 
 type CreateSessionParams struct {
 	ID           pgtype.UUID        `json:"id"`
+	FamilyID     pgtype.UUID        `json:"family_id"`
 	Username     string             `json:"username"`
 	RefreshToken string             `json:"refresh_token"`
 	UserAgent    string             `json:"user_agent"`
@@ -38,6 +112,7 @@ type CreateSessionParams struct {
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
 	row := q.db.QueryRow(ctx, createSession,
 		arg.ID,
+		arg.FamilyID,
 		arg.Username,
 		arg.RefreshToken,
 		arg.UserAgent,
@@ -55,12 +130,27 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		&i.IsBlocked,
 		&i.ExpiredAt,
 		&i.CreatedAt,
+		&i.FamilyID,
+		&i.IsUsed,
 	)
 	return i, err
 }
 
+const deleteExpiredSessions = `-- name: DeleteExpiredSessions :execrows
+DELETE FROM sessions
+WHERE expired_at < now()
+`
+
+func (q *Queries) DeleteExpiredSessions(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredSessions)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getSession = `-- name: GetSession :one
-SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expired_at, created_at FROM sessions
+SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expired_at, created_at, family_id, is_used FROM sessions
 WHERE id = $1 LIMIT 1
 `
 
@@ -76,6 +166,110 @@ func (q *Queries) GetSession(ctx context.Context, id pgtype.UUID) (Session, erro
 		&i.IsBlocked,
 		&i.ExpiredAt,
 		&i.CreatedAt,
+		&i.FamilyID,
+		&i.IsUsed,
+	)
+	return i, err
+}
+
+const isSessionFamilyBlocked = `-- name: IsSessionFamilyBlocked :one
+SELECT EXISTS (
+  SELECT 1 FROM sessions
+  WHERE family_id = $1 AND is_blocked = TRUE
+)
+`
+
+func (q *Queries) IsSessionFamilyBlocked(ctx context.Context, familyID pgtype.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, isSessionFamilyBlocked, familyID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listActiveSessions = `-- name: ListActiveSessions :many
+SELECT
+  s.family_id,
+  s.user_agent,
+  s.client_ip,
+  (
+    SELECT min(f.created_at) FROM sessions f
+    WHERE f.family_id = s.family_id
+  )::timestamptz AS logged_in_at,
+  s.created_at AS renewed_at,
+  s.expired_at
+FROM sessions s
+WHERE s.username = $1
+  AND s.is_used = FALSE
+  AND s.is_blocked = FALSE
+  AND s.expired_at > now()
+ORDER BY logged_in_at DESC
+LIMIT $2
+OFFSET $3
+`
+
+type ListActiveSessionsParams struct {
+	Username string `json:"username"`
+	Limit    int32  `json:"limit"`
+	Offset   int32  `json:"offset"`
+}
+
+type ListActiveSessionsRow struct {
+	FamilyID   pgtype.UUID        `json:"family_id"`
+	UserAgent  string             `json:"user_agent"`
+	ClientIp   string             `json:"client_ip"`
+	LoggedInAt pgtype.Timestamptz `json:"logged_in_at"`
+	RenewedAt  pgtype.Timestamptz `json:"renewed_at"`
+	ExpiredAt  pgtype.Timestamptz `json:"expired_at"`
+}
+
+func (q *Queries) ListActiveSessions(ctx context.Context, arg ListActiveSessionsParams) ([]ListActiveSessionsRow, error) {
+	rows, err := q.db.Query(ctx, listActiveSessions, arg.Username, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListActiveSessionsRow{}
+	for rows.Next() {
+		var i ListActiveSessionsRow
+		if err := rows.Scan(
+			&i.FamilyID,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.LoggedInAt,
+			&i.RenewedAt,
+			&i.ExpiredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markSessionUsed = `-- name: MarkSessionUsed :one
+UPDATE sessions
+SET is_used = TRUE
+WHERE id = $1 AND is_used = FALSE
+RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expired_at, created_at, family_id, is_used
+`
+
+func (q *Queries) MarkSessionUsed(ctx context.Context, id pgtype.UUID) (Session, error) {
+	row := q.db.QueryRow(ctx, markSessionUsed, id)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.RefreshToken,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.IsBlocked,
+		&i.ExpiredAt,
+		&i.CreatedAt,
+		&i.FamilyID,
+		&i.IsUsed,
 	)
 	return i, err
 }
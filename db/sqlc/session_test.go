@@ -0,0 +1,168 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSession(t *testing.T, user User) Session {
+	id := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	arg := CreateSessionParams{
+		ID:           id,
+		FamilyID:     id,
+		Username:     user.Username,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    util.RandomString(10),
+		ClientIp:     "127.0.0.1",
+		ExpiredAt:    pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+
+	session, err := testQueries.CreateSession(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, session)
+
+	require.Equal(t, arg.ID, session.ID)
+	require.Equal(t, arg.FamilyID, session.FamilyID)
+	require.Equal(t, arg.Username, session.Username)
+	require.Equal(t, arg.RefreshToken, session.RefreshToken)
+	require.False(t, session.IsBlocked)
+	require.False(t, session.IsUsed)
+	require.WithinDuration(t, arg.ExpiredAt.Time, session.ExpiredAt.Time, time.Second)
+	require.NotZero(t, session.CreatedAt)
+
+	return session
+}
+
+func TestCreateSession(t *testing.T) {
+	createRandomSession(t, createRandomUser(t))
+}
+
+func TestBlockSession(t *testing.T) {
+	session := createRandomSession(t, createRandomUser(t))
+
+	blocked, err := testQueries.BlockSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+	require.Equal(t, session.ID, blocked.ID)
+}
+
+func TestBlockUserSessions(t *testing.T) {
+	user := createRandomUser(t)
+	for i := 0; i < 3; i++ {
+		createRandomSession(t, user)
+	}
+	other := createRandomSession(t, createRandomUser(t))
+
+	n, err := testQueries.BlockUserSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), n)
+
+	// sessions that are already blocked aren't counted again
+	n, err = testQueries.BlockUserSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	session, err := testQueries.GetSession(context.Background(), other.ID)
+	require.NoError(t, err)
+	require.False(t, session.IsBlocked)
+}
+
+func TestMarkSessionUsed(t *testing.T) {
+	session := createRandomSession(t, createRandomUser(t))
+
+	used, err := testQueries.MarkSessionUsed(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, used.IsUsed)
+
+	_, err = testQueries.MarkSessionUsed(context.Background(), session.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestListActiveSessions(t *testing.T) {
+	user := createRandomUser(t)
+	active := createRandomSession(t, user)
+	blocked := createRandomSession(t, user)
+	used := createRandomSession(t, user)
+
+	_, err := testQueries.BlockSession(context.Background(), blocked.ID)
+	require.NoError(t, err)
+	_, err = testQueries.MarkSessionUsed(context.Background(), used.ID)
+	require.NoError(t, err)
+
+	sessions, err := testQueries.ListActiveSessions(context.Background(), ListActiveSessionsParams{
+		Username: user.Username,
+		Limit:    10,
+		Offset:   0,
+	})
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, active.FamilyID, sessions[0].FamilyID)
+	require.Equal(t, active.UserAgent, sessions[0].UserAgent)
+	require.WithinDuration(t, active.CreatedAt.Time, sessions[0].LoggedInAt.Time, time.Second)
+}
+
+func TestBlockUserSessionFamily(t *testing.T) {
+	user := createRandomUser(t)
+	session := createRandomSession(t, user)
+
+	arg := BlockUserSessionFamilyParams{
+		FamilyID: session.FamilyID,
+		Username: createRandomUser(t).Username,
+	}
+
+	// sessions of other users can't be blocked
+	n, err := testQueries.BlockUserSessionFamily(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	arg.Username = user.Username
+	n, err = testQueries.BlockUserSessionFamily(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+}
+
+func TestDeleteExpiredSessions(t *testing.T) {
+	user := createRandomUser(t)
+	id := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	expired, err := testQueries.CreateSession(context.Background(), CreateSessionParams{
+		ID:           id,
+		FamilyID:     id,
+		Username:     user.Username,
+		RefreshToken: util.RandomString(32),
+		ExpiredAt:    pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true},
+	})
+	require.NoError(t, err)
+	active := createRandomSession(t, user)
+
+	n, err := testQueries.DeleteExpiredSessions(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, n, int64(1))
+
+	_, err = testQueries.GetSession(context.Background(), expired.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	_, err = testQueries.GetSession(context.Background(), active.ID)
+	require.NoError(t, err)
+}
+
+func TestIsSessionFamilyBlocked(t *testing.T) {
+	session := createRandomSession(t, createRandomUser(t))
+
+	blocked, err := testQueries.IsSessionFamilyBlocked(context.Background(), session.FamilyID)
+	require.NoError(t, err)
+	require.False(t, blocked)
+
+	_, err = testQueries.BlockSessionFamily(context.Background(), session.FamilyID)
+	require.NoError(t, err)
+
+	blocked, err = testQueries.IsSessionFamilyBlocked(context.Background(), session.FamilyID)
+	require.NoError(t, err)
+	require.True(t, blocked)
+}
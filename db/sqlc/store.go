@@ -2,14 +2,49 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
 
+	"github.com/hykura1501/simple_bank/fx"
+	"github.com/hykura1501/simple_bank/ledger"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrInsufficientFunds is returned by TransferTx when the source account
+// doesn't have enough balance (including its overdraft limit) to cover the transfer
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// ErrExchangeRateMismatch is returned by TransferTx when the accounts have different currencies
+// and the given exchange rate doesn't convert between them
+var ErrExchangeRateMismatch = errors.New("exchange rate doesn't match the accounts' currencies")
+
+// ErrAccountFrozen is returned by TransferTx when either account has been frozen, e.g. by reconciliation
+var ErrAccountFrozen = errors.New("account is frozen")
+
+// ErrTOTPCodeUsed is returned by TransferTx when the TOTP code that authorized the transfer was already used
+var ErrTOTPCodeUsed = errors.New("code was already used")
+
 type Store interface {
-	TransferTx(ctx context.Context, arg CreateTransferParams) (TransferTxResult, error)
+	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error)
+	AdjustAccountBalanceTx(ctx context.Context, arg AdjustAccountBalanceTxParams) (AdjustAccountBalanceTxResult, error)
+	IdempotentTransferTx(ctx context.Context, idempotency IdempotencyParams, arg TransferTxParams) (TransferTxResult, error)
+	ReplayIdempotentTransfer(ctx context.Context, idempotency IdempotencyParams, arg TransferTxParams) (TransferTxResult, bool, error)
+	IdempotentCreateAccountTx(ctx context.Context, idempotency IdempotencyParams, arg CreateAccountParams) (Account, error)
+	AccountStatementTx(ctx context.Context, arg AccountStatementTxParams) (AccountStatementTxResult, error)
+	ReconcileBatchTx(ctx context.Context, arg ReconcileBatchTxParams) (ReconcileBatchTxResult, error)
+	ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ExecuteScheduledTransferTxResult, error)
+	UpdateScheduledTransferTx(ctx context.Context, arg UpdateScheduledTransferTxParams) (ScheduledTransfer, error)
+	CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error)
+	UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (User, error)
+	VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (VerifyEmailTxResult, error)
+	RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (RotateSessionTxResult, error)
+	EnableMFATx(ctx context.Context, arg EnableMFATxParams) (EnableMFATxResult, error)
+	DisableMFATx(ctx context.Context, username string) error
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (ResetPasswordTxResult, error)
 	Querier
 }
 
@@ -27,7 +62,20 @@ func NewStore(db *pgxpool.Pool) Store {
 
 // execTx executes a function with a database transaction
 func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, pgx.TxOptions{})
+	return store.execTxWithOptions(ctx, pgx.TxOptions{}, fn)
+}
+
+// execReadOnlyTx executes a function with a read-only database transaction,
+// so all of its queries see the same snapshot of the data
+func (store *SQLStore) execReadOnlyTx(ctx context.Context, fn func(*Queries) error) error {
+	return store.execTxWithOptions(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.RepeatableRead,
+		AccessMode: pgx.ReadOnly,
+	}, fn)
+}
+
+func (store *SQLStore) execTxWithOptions(ctx context.Context, txOptions pgx.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, txOptions)
 	if err != nil {
 		return err
 	}
@@ -44,6 +92,21 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 	return tx.Commit(ctx)
 }
 
+// TransferTxParams contains the input parameters of the transfer transaction
+type TransferTxParams struct {
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	// Amount is debited from the source account, in its currency
+	Amount int64 `json:"amount"`
+	// ExchangeRate converts Amount into the currency of the destination account.
+	// It is only required when the two accounts have different currencies
+	ExchangeRate *fx.Rate        `json:"exchange_rate"`
+	RoundingMode fx.RoundingMode `json:"rounding_mode"`
+	// MFAStep is the time step of the TOTP code that authorized the transfer, if it needed one.
+	// It is recorded in the same database transaction, so only a transfer that is made uses up the code
+	MFAStep *UseUserMFAStepParams `json:"-"`
+}
+
 // TransferTxResult is the result of the transfer transaction
 type TransferTxResult struct {
 	Transfer    Transfer `json:"transfer"`
@@ -51,76 +114,164 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// JournalTransaction groups the entries of the transfer,
+	// including the FX clearing entries of a cross-currency transfer
+	JournalTransaction JournalTransaction `json:"journal_transaction"`
 }
 
 // TransferTx performs a money transfer from one account to other
-// It creates a transfer record, add account entries, and update accounts' balance with a single database transaction
-func (store *SQLStore) TransferTx(ctx context.Context, arg CreateTransferParams) (TransferTxResult, error) {
+// It creates a transfer record and posts its balanced journal transaction with a single database transaction
+// The source account is debited in its currency and the destination account is credited in its own currency
+// It returns ErrInsufficientFunds if the transfer would take the source account below its overdraft limit,
+// and ErrAccountFrozen if either account is frozen
+func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
-		result.Transfer, err = q.CreateTransfer(ctx, arg)
-		if err != nil {
-			return err
-		}
+		result, err = transferTx(ctx, q, arg)
+		return err
+	})
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
+	return result, err
+}
 
-		if err != nil {
-			return err
+func transferTx(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	fromAccount, toAccount, err := lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
+	if err != nil {
+		return result, err
+	}
+
+	for _, account := range []Account{fromAccount, toAccount} {
+		if account.IsFrozen {
+			return result, fmt.Errorf("%w: account [%d]", ErrAccountFrozen, account.ID)
 		}
+	}
+
+	if fromAccount.Balance-arg.Amount < -fromAccount.OverdraftLimit {
+		return result, fmt.Errorf("%w: account [%d] has balance %d and overdraft limit %d, cannot send %d",
+			ErrInsufficientFunds, fromAccount.ID, fromAccount.Balance, fromAccount.OverdraftLimit, arg.Amount)
+	}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
-		})
+	transferArg, err := convertTransfer(arg, fromAccount, toAccount)
+	if err != nil {
+		return result, err
+	}
 
+	if arg.MFAStep != nil {
+		rows, err := q.UseUserMFAStep(ctx, *arg.MFAStep)
 		if err != nil {
-			return err
+			return result, err
 		}
+		if rows == 0 {
+			return result, ErrTOTPCodeUsed
+		}
+	}
 
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, arg.ToAccountID, arg.Amount)
-			if err != nil {
-				return err
-			}
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.FromAccountID, -arg.Amount)
+	transfer, err := q.CreateTransfer(ctx, transferArg)
+	if err != nil {
+		return result, err
+	}
+
+	return postTransfer(ctx, q, ledger.KindTransfer, transfer, fromAccount, toAccount)
+}
+
+// postTransfer posts the journal transaction of a transfer that has just been created,
+// balancing a cross-currency transfer through the FX clearing accounts
+func postTransfer(ctx context.Context, q *Queries, kind string, transfer Transfer, fromAccount Account, toAccount Account) (TransferTxResult, error) {
+	result := TransferTxResult{
+		Transfer: transfer,
+	}
+
+	var err error
+	fxClearing := make(map[string]int64)
+	if fromAccount.Currency != toAccount.Currency {
+		for _, currency := range []string{fromAccount.Currency, toAccount.Currency} {
+			fxClearing[currency], err = systemAccountID(ctx, q, ledger.FXClearingOwner, currency)
 			if err != nil {
-				return err
+				return result, err
 			}
 		}
+	}
 
-		return nil
-	})
+	txn, err := ledger.NewTransfer(
+		ledger.Leg{AccountID: fromAccount.ID, Currency: fromAccount.Currency, Amount: transfer.Amount},
+		ledger.Leg{AccountID: toAccount.ID, Currency: toAccount.Currency, Amount: transfer.ToAmount},
+		fxClearing,
+	)
+	if err != nil {
+		return result, err
+	}
+	txn.Kind = kind
 
-	return result, err
+	posted, err := postTransaction(ctx, q, txn, &transfer.ID)
+	if err != nil {
+		return result, err
+	}
+
+	// the first two postings of a transfer are always the debit of the source and the credit of the destination
+	result.FromEntry = posted.Entries[0]
+	result.ToEntry = posted.Entries[1]
+	result.FromAccount = posted.Accounts[fromAccount.ID]
+	result.ToAccount = posted.Accounts[toAccount.ID]
+	result.JournalTransaction = posted.JournalTransaction
+
+	return result, nil
 }
 
-func addMoney(
-	ctx context.Context,
-	q *Queries,
-	acc1ID int64,
-	acc2ID int64,
-	amount int64,
-) (acc1 Account, acc2 Account, err error) {
-	acc1, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     acc1ID,
-		Amount: -amount,
-	})
+// convertTransfer works out how much the destination account is credited in its own currency
+func convertTransfer(arg TransferTxParams, fromAccount Account, toAccount Account) (CreateTransferParams, error) {
+	roundingMode := arg.RoundingMode
+	if roundingMode == "" {
+		roundingMode = fx.DefaultRoundingMode
+	}
 
+	transferArg := CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		ToAmount:      arg.Amount,
+		ExchangeRate:  pgtype.Numeric{Int: big.NewInt(1), Valid: true},
+		RoundingMode:  string(roundingMode),
+	}
+
+	if fromAccount.Currency == toAccount.Currency {
+		return transferArg, nil
+	}
+
+	rate := arg.ExchangeRate
+	if rate == nil || rate.From != fromAccount.Currency || rate.To != toAccount.Currency {
+		return transferArg, fmt.Errorf("%w: cannot transfer from %s to %s", ErrExchangeRateMismatch, fromAccount.Currency, toAccount.Currency)
+	}
+
+	toAmount, err := fx.Convert(arg.Amount, *rate, roundingMode)
 	if err != nil {
-		return
+		return transferArg, err
 	}
+	transferArg.ToAmount = toAmount
 
-	acc2, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     acc2ID,
-		Amount: amount,
-	})
+	err = transferArg.ExchangeRate.Scan(rate.String())
+	return transferArg, err
+}
 
+// lockAccounts locks both accounts of a transfer in ascending ID order to avoid deadlocks
+// and returns them, so the source balance can be checked safely
+func lockAccounts(ctx context.Context, q *Queries, fromAccountID int64, toAccountID int64) (fromAccount Account, toAccount Account, err error) {
+	if fromAccountID < toAccountID {
+		fromAccount, err = q.GetAccountForUpdate(ctx, fromAccountID)
+		if err != nil {
+			return
+		}
+		toAccount, err = q.GetAccountForUpdate(ctx, toAccountID)
+		return
+	}
+
+	toAccount, err = q.GetAccountForUpdate(ctx, toAccountID)
+	if err != nil {
+		return
+	}
+	fromAccount, err = q.GetAccountForUpdate(ctx, fromAccountID)
 	return
 }
@@ -2,17 +2,27 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/hykura1501/simple_bank/fx"
+	"github.com/hykura1501/simple_bank/ledger"
+	"github.com/hykura1501/simple_bank/mfa"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 )
 
 func TestTransferTx(t *testing.T) {
 	store := NewStore(testDB)
 
-	acc1 := createRandomAccount(t)
-	acc2 := createRandomAccount(t)
+	acc1 := createRandomAccountWithBalance(t, 1000, util.USD)
+	acc2 := createRandomAccountWithBalance(t, util.RandomMoney(), util.USD)
 
 	fmt.Println(">> before:", acc1.Balance, acc2.Balance)
 	n := 5
@@ -23,7 +33,7 @@ func TestTransferTx(t *testing.T) {
 
 	for range n {
 		go func() {
-			result, err := store.TransferTx(context.Background(), CreateTransferParams{
+			result, err := store.TransferTx(context.Background(), TransferTxParams{
 				FromAccountID: acc1.ID,
 				ToAccountID:   acc2.ID,
 				Amount:        amount,
@@ -56,6 +66,7 @@ func TestTransferTx(t *testing.T) {
 		require.NotEmpty(t, fromEntry)
 		require.Equal(t, acc1.ID, fromEntry.AccountID)
 		require.Equal(t, -amount, fromEntry.Amount)
+		require.Equal(t, &transfer.ID, fromEntry.TransferID)
 		require.NotZero(t, fromEntry.ID)
 		require.NotZero(t, fromEntry.CreatedAt)
 
@@ -66,12 +77,19 @@ func TestTransferTx(t *testing.T) {
 		require.NotEmpty(t, toEntry)
 		require.Equal(t, acc2.ID, toEntry.AccountID)
 		require.Equal(t, amount, toEntry.Amount)
+		require.Equal(t, &transfer.ID, toEntry.TransferID)
 		require.NotZero(t, toEntry.ID)
 		require.NotZero(t, toEntry.CreatedAt)
 
 		_, err = store.GetEntry(context.Background(), toEntry.ID)
 		require.NoError(t, err)
 
+		journalTransaction := result.JournalTransaction
+		require.Equal(t, ledger.KindTransfer, journalTransaction.Kind)
+		require.Equal(t, &transfer.ID, journalTransaction.TransferID)
+		require.Equal(t, &journalTransaction.ID, fromEntry.JournalTransactionID)
+		require.Equal(t, &journalTransaction.ID, toEntry.JournalTransactionID)
+
 		// Check account
 		fromAccount := result.FromAccount
 		require.NotEmpty(t, fromAccount)
@@ -110,8 +128,8 @@ func TestTransferTx(t *testing.T) {
 func TestTransferTxDeadlook(t *testing.T) {
 	store := NewStore(testDB)
 
-	acc1 := createRandomAccount(t)
-	acc2 := createRandomAccount(t)
+	acc1 := createRandomAccountWithBalance(t, 1000, util.USD)
+	acc2 := createRandomAccountWithBalance(t, 1000, util.USD)
 
 	fmt.Println(">> before:", acc1.Balance, acc2.Balance)
 	n := 10
@@ -127,7 +145,7 @@ func TestTransferTxDeadlook(t *testing.T) {
 			toAcc = acc1
 		}
 		go func() {
-			_, err := store.TransferTx(context.Background(), CreateTransferParams{
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
 				FromAccountID: fromAcc.ID,
 				ToAccountID:   toAcc.ID,
 				Amount:        amount,
@@ -151,3 +169,1262 @@ func TestTransferTxDeadlook(t *testing.T) {
 	require.Equal(t, acc1.Balance, updatedAcc1.Balance)
 	require.Equal(t, acc2.Balance, updatedAcc2.Balance)
 }
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	amount := int64(10)
+	succeeded := 5
+	acc1 := createRandomAccountWithBalance(t, int64(succeeded)*amount, util.USD)
+	acc2 := createRandomAccountWithBalance(t, util.RandomMoney(), util.USD)
+
+	n := 10
+	errs := make(chan error)
+
+	for range n {
+		go func() {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: acc1.ID,
+				ToAccountID:   acc2.ID,
+				Amount:        amount,
+			})
+			errs <- err
+		}()
+	}
+
+	failed := 0
+	for range n {
+		err := <-errs
+		if err != nil {
+			require.ErrorIs(t, err, ErrInsufficientFunds)
+			failed++
+		}
+	}
+	require.Equal(t, n-succeeded, failed)
+
+	updatedAcc1, err := store.GetAccount(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	require.Zero(t, updatedAcc1.Balance)
+
+	updatedAcc2, err := store.GetAccount(context.Background(), acc2.ID)
+	require.NoError(t, err)
+	require.Equal(t, acc2.Balance+int64(succeeded)*amount, updatedAcc2.Balance)
+}
+
+func TestTransferTxOverdraftLimit(t *testing.T) {
+	store := NewStore(testDB)
+
+	amount := int64(10)
+	acc1 := createRandomAccountWithBalance(t, 0, util.USD)
+	acc2 := createRandomAccountWithBalance(t, util.RandomMoney(), util.USD)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        amount,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             acc1.ID,
+		OverdraftLimit: amount,
+	})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+	require.Equal(t, -amount, result.FromAccount.Balance)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestIdempotentTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	acc1 := createRandomAccountWithBalance(t, 1000, util.USD)
+	acc2 := createRandomAccountWithBalance(t, util.RandomMoney(), util.USD)
+
+	idempotency := IdempotencyParams{
+		Key:      util.RandomString(32),
+		Username: acc1.Owner,
+		Duration: time.Hour,
+	}
+	arg := TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        10,
+	}
+
+	n := 5
+	errs := make(chan error)
+	results := make(chan TransferTxResult)
+
+	for range n {
+		go func() {
+			result, err := store.IdempotentTransferTx(context.Background(), idempotency, arg)
+			errs <- err
+			results <- result
+		}()
+	}
+
+	var transferID int64
+	for range n {
+		err := <-errs
+		require.NoError(t, err)
+
+		result := <-results
+		require.NotZero(t, result.Transfer.ID)
+		if transferID == 0 {
+			transferID = result.Transfer.ID
+		}
+		require.Equal(t, transferID, result.Transfer.ID)
+		require.Equal(t, acc1.Balance-arg.Amount, result.FromAccount.Balance)
+	}
+
+	updatedAcc1, err := store.GetAccount(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	require.Equal(t, acc1.Balance-arg.Amount, updatedAcc1.Balance)
+
+	// same key with a different payload
+	arg.Amount++
+	_, err = store.IdempotentTransferTx(context.Background(), idempotency, arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+}
+
+func TestReplayIdempotentTransfer(t *testing.T) {
+	store := NewStore(testDB)
+
+	acc1 := createRandomAccountWithBalance(t, 1000, util.USD)
+	acc2 := createRandomAccountWithBalance(t, util.RandomMoney(), util.USD)
+
+	idempotency := IdempotencyParams{
+		Key:      util.RandomString(32),
+		Username: acc1.Owner,
+		Duration: time.Hour,
+	}
+	arg := TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        10,
+	}
+
+	_, replayed, err := store.ReplayIdempotentTransfer(context.Background(), idempotency, arg)
+	require.NoError(t, err)
+	require.False(t, replayed)
+
+	result, err := store.IdempotentTransferTx(context.Background(), idempotency, arg)
+	require.NoError(t, err)
+
+	replay, replayed, err := store.ReplayIdempotentTransfer(context.Background(), idempotency, arg)
+	require.NoError(t, err)
+	require.True(t, replayed)
+	require.Equal(t, result.Transfer.ID, replay.Transfer.ID)
+	require.Equal(t, result.FromAccount.Balance, replay.FromAccount.Balance)
+
+	// same key with a different payload
+	arg.Amount++
+	_, replayed, err = store.ReplayIdempotentTransfer(context.Background(), idempotency, arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+	require.False(t, replayed)
+
+	// an expired key is not replayed
+	key := createRandomIdempotencyKey(t, acc1.Owner, time.Now().Add(-time.Minute))
+	_, replayed, err = store.ReplayIdempotentTransfer(context.Background(), IdempotencyParams{
+		Key:      key.Key,
+		Username: key.Username,
+		Duration: time.Hour,
+	}, arg)
+	require.NoError(t, err)
+	require.False(t, replayed)
+}
+
+func TestTransferTxMFAStep(t *testing.T) {
+	store := NewStore(testDB)
+
+	acc1 := createRandomAccountWithBalance(t, 10, util.USD)
+	acc2 := createRandomAccountWithBalance(t, util.RandomMoney(), util.USD)
+
+	user, err := store.GetUser(context.Background(), acc1.Owner)
+	require.NoError(t, err)
+	createRandomUserMFA(t, user)
+	_, err = store.EnableUserMFA(context.Background(), EnableUserMFAParams{
+		Username:     user.Username,
+		LastUsedStep: 100,
+	})
+	require.NoError(t, err)
+
+	arg := TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        20,
+		MFAStep: &UseUserMFAStepParams{
+			Username:     user.Username,
+			LastUsedStep: 101,
+		},
+	}
+
+	// a transfer that fails doesn't use up the code
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	userMFA, err := store.GetUserMFA(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), userMFA.LastUsedStep)
+
+	arg.Amount = 10
+	_, err = store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	userMFA, err = store.GetUserMFA(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(101), userMFA.LastUsedStep)
+
+	// the same code cannot authorize another transfer
+	arg.Amount = 1
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrTOTPCodeUsed)
+}
+
+func TestIdempotentCreateAccountTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	idempotency := IdempotencyParams{
+		Key:      util.RandomString(32),
+		Username: user.Username,
+		Duration: time.Hour,
+	}
+	arg := CreateAccountParams{
+		Owner:    user.Username,
+		Currency: util.USD,
+	}
+
+	acc1, err := store.IdempotentCreateAccountTx(context.Background(), idempotency, arg)
+	require.NoError(t, err)
+	require.NotZero(t, acc1.ID)
+
+	acc2, err := store.IdempotentCreateAccountTx(context.Background(), idempotency, arg)
+	require.NoError(t, err)
+	require.Equal(t, acc1.ID, acc2.ID)
+	require.WithinDuration(t, acc1.CreatedAt.Time, acc2.CreatedAt.Time, time.Second)
+
+	arg.Currency = util.EUR
+	_, err = store.IdempotentCreateAccountTx(context.Background(), idempotency, arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+}
+
+func TestTransferTxCrossCurrency(t *testing.T) {
+	store := NewStore(testDB)
+
+	acc1 := createRandomAccountWithBalance(t, 1000, util.USD)
+	acc2 := createRandomAccountWithBalance(t, 1000, util.VND)
+
+	rate, err := fx.NewRate(util.USD, util.VND, big.NewRat(25400, 1))
+	require.NoError(t, err)
+
+	arg := TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        150,
+	}
+
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrExchangeRateMismatch)
+
+	arg.ExchangeRate = &rate
+	arg.RoundingMode = fx.RoundDown
+	result, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// 150 cents at 25400 VND per dollar
+	toAmount := int64(38100)
+	require.Equal(t, arg.Amount, result.Transfer.Amount)
+	require.Equal(t, toAmount, result.Transfer.ToAmount)
+	require.Equal(t, string(fx.RoundDown), result.Transfer.RoundingMode)
+
+	storedRate, err := result.Transfer.ExchangeRate.Float64Value()
+	require.NoError(t, err)
+	require.Equal(t, float64(25400), storedRate.Float64)
+
+	require.Equal(t, -arg.Amount, result.FromEntry.Amount)
+	require.Equal(t, toAmount, result.ToEntry.Amount)
+	require.Equal(t, acc1.Balance-arg.Amount, result.FromAccount.Balance)
+	require.Equal(t, acc2.Balance+toAmount, result.ToAccount.Balance)
+
+	// each currency is balanced through its FX clearing account
+	entries, err := store.ListJournalTransactionEntries(context.Background(), &result.JournalTransaction.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	require.Equal(t, []Entry{result.FromEntry, result.ToEntry}, entries[:2])
+
+	usdClearing, err := store.GetAccountByCurrency(context.Background(), GetAccountByCurrencyParams{
+		Owner:    ledger.FXClearingOwner,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+	require.Equal(t, usdClearing.ID, entries[2].AccountID)
+	require.Equal(t, arg.Amount, entries[2].Amount)
+
+	vndClearing, err := store.GetAccountByCurrency(context.Background(), GetAccountByCurrencyParams{
+		Owner:    ledger.FXClearingOwner,
+		Currency: util.VND,
+	})
+	require.NoError(t, err)
+	require.Equal(t, vndClearing.ID, entries[3].AccountID)
+	require.Equal(t, -toAmount, entries[3].Amount)
+
+	reversedRate, err := fx.NewRate(util.VND, util.USD, big.NewRat(1, 25400))
+	require.NoError(t, err)
+
+	arg.ExchangeRate = &reversedRate
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrExchangeRateMismatch)
+}
+
+func TestAccountStatementTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	acc1 := createRandomAccountWithBalance(t, 1000, util.USD)
+	acc2 := createRandomAccountWithBalance(t, 1000, util.USD)
+
+	n := 3
+	amount := int64(10)
+	for range n {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: acc1.ID,
+			ToAccountID:   acc2.ID,
+			Amount:        amount,
+		})
+		require.NoError(t, err)
+	}
+
+	arg := AccountStatementTxParams{
+		AccountID: acc1.ID,
+		PageSize:  2,
+	}
+
+	result, err := store.AccountStatementTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, acc1.Balance, result.OpeningBalance)
+	require.Equal(t, acc1.Balance-int64(n)*amount, result.ClosingBalance)
+	require.Len(t, result.Entries, 2)
+	require.Equal(t, acc1.Balance-amount, result.Entries[0].RunningBalance)
+	require.Equal(t, acc1.Balance-2*amount, result.Entries[1].RunningBalance)
+	require.Equal(t, result.Entries[1].ID, result.NextAfterID)
+
+	entry := result.Entries[0]
+	require.NotNil(t, entry.TransferID)
+	require.Equal(t, &acc1.ID, entry.FromAccountID)
+	require.Equal(t, &acc2.ID, entry.ToAccountID)
+
+	arg.AfterID = result.NextAfterID
+	result, err = store.AccountStatementTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, result.Entries, 1)
+	require.Equal(t, acc1.Balance-3*amount, result.Entries[0].RunningBalance)
+	require.Zero(t, result.NextAfterID)
+}
+
+func TestCreateUserTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	hashedPassword, err := util.HashPassword(util.RandomString(6))
+	require.NoError(t, err)
+
+	arg := CreateUserTxParams{
+		CreateUserParams: CreateUserParams{
+			Username:       util.RandomOwner(),
+			HashedPassword: hashedPassword,
+			FullName:       util.RandomOwner(),
+			Email:          util.RandomEmail(),
+		},
+	}
+
+	var created User
+	arg.AfterCreate = func(user User) error {
+		created = user
+		return nil
+	}
+
+	result, err := store.CreateUserTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, result.User.Username)
+	require.Equal(t, result.User, created)
+
+	_, err = store.GetUser(context.Background(), arg.Username)
+	require.NoError(t, err)
+}
+
+func TestCreateUserTxAfterCreateError(t *testing.T) {
+	store := NewStore(testDB)
+
+	hashedPassword, err := util.HashPassword(util.RandomString(6))
+	require.NoError(t, err)
+
+	afterCreateErr := errors.New("cannot enqueue task")
+	arg := CreateUserTxParams{
+		CreateUserParams: CreateUserParams{
+			Username:       util.RandomOwner(),
+			HashedPassword: hashedPassword,
+			FullName:       util.RandomOwner(),
+			Email:          util.RandomEmail(),
+		},
+		AfterCreate: func(user User) error {
+			return afterCreateErr
+		},
+	}
+
+	_, err = store.CreateUserTx(context.Background(), arg)
+	require.ErrorIs(t, err, afterCreateErr)
+
+	// the user is rolled back with the failed task
+	_, err = store.GetUser(context.Background(), arg.Username)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestVerifyEmailTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	verifyEmail := createRandomVerifyEmail(t, user)
+
+	arg := VerifyEmailTxParams{
+		EmailID:    verifyEmail.ID,
+		SecretCode: verifyEmail.SecretCode,
+	}
+
+	result, err := store.VerifyEmailTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, result.VerifyEmail.IsUsed)
+	require.True(t, result.User.IsEmailVerified)
+	require.Equal(t, user.Username, result.User.Username)
+
+	_, err = store.VerifyEmailTx(context.Background(), arg)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestVerifyEmailTxOutdated(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	verifyEmail := createRandomVerifyEmail(t, user)
+
+	newEmail := util.RandomEmail()
+	_, err := store.UpdateUser(context.Background(), UpdateUserParams{
+		Username: user.Username,
+		Email:    &newEmail,
+	})
+	require.NoError(t, err)
+
+	_, err = store.VerifyEmailTx(context.Background(), VerifyEmailTxParams{
+		EmailID:    verifyEmail.ID,
+		SecretCode: verifyEmail.SecretCode,
+	})
+	require.ErrorIs(t, err, ErrVerifyEmailOutdated)
+
+	// the code and the user are rolled back
+	got, err := store.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.False(t, got.IsEmailVerified)
+}
+
+func TestUpdateUserTxEmailChange(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	isEmailVerified := true
+	user, err := store.UpdateUser(context.Background(), UpdateUserParams{
+		Username:        user.Username,
+		IsEmailVerified: &isEmailVerified,
+	})
+	require.NoError(t, err)
+
+	var changed []User
+	arg := UpdateUserTxParams{
+		UpdateUserParams: UpdateUserParams{
+			Username: user.Username,
+			Email:    &user.Email,
+		},
+		AfterEmailChange: func(user User) error {
+			changed = append(changed, user)
+			return nil
+		},
+	}
+
+	// the same email address stays verified
+	updated, err := store.UpdateUserTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, updated.IsEmailVerified)
+	require.Empty(t, changed)
+
+	// the email address isn't changed if AfterEmailChange fails
+	email := util.RandomEmail()
+	arg.Email = &email
+	afterEmailChange := arg.AfterEmailChange
+	arg.AfterEmailChange = func(user User) error {
+		return errors.New("cannot enqueue task")
+	}
+	_, err = store.UpdateUserTx(context.Background(), arg)
+	require.Error(t, err)
+
+	got, err := store.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, user.Email, got.Email)
+	require.True(t, got.IsEmailVerified)
+
+	arg.AfterEmailChange = afterEmailChange
+	updated, err = store.UpdateUserTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, email, updated.Email)
+	require.False(t, updated.IsEmailVerified)
+	require.Len(t, changed, 1)
+	require.Equal(t, email, changed[0].Email)
+}
+
+func TestRotateSessionTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	oldSession := createRandomSession(t, user)
+
+	newArg := CreateSessionParams{
+		ID:           pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Username:     user.Username,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    oldSession.UserAgent,
+		ClientIp:     oldSession.ClientIp,
+		ExpiredAt:    oldSession.ExpiredAt,
+	}
+
+	result, err := store.RotateSessionTx(context.Background(), RotateSessionTxParams{
+		OldSession: oldSession,
+		NewSession: newArg,
+	})
+	require.NoError(t, err)
+	require.Equal(t, newArg.ID, result.Session.ID)
+	require.Equal(t, oldSession.FamilyID, result.Session.FamilyID)
+	require.False(t, result.Session.IsUsed)
+
+	got, err := store.GetSession(context.Background(), oldSession.ID)
+	require.NoError(t, err)
+	require.True(t, got.IsUsed)
+
+	// replaying the old refresh token blocks the whole family
+	newArg.ID = pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	_, err = store.RotateSessionTx(context.Background(), RotateSessionTxParams{
+		OldSession: oldSession,
+		NewSession: newArg,
+	})
+	require.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	got, err = store.GetSession(context.Background(), result.Session.ID)
+	require.NoError(t, err)
+	require.True(t, got.IsBlocked)
+
+	_, err = store.GetSession(context.Background(), newArg.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestEnableMFATx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	createRandomUserMFA(t, user)
+
+	arg := EnableMFATxParams{
+		Username:            user.Username,
+		LastUsedStep:        100,
+		HashedRecoveryCodes: []string{util.RandomString(64), util.RandomString(64)},
+	}
+
+	result, err := store.EnableMFATx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, result.UserMFA.IsEnabled)
+	require.Equal(t, arg.LastUsedStep, result.UserMFA.LastUsedStep)
+
+	rows, err := store.UseMFARecoveryCode(context.Background(), UseMFARecoveryCodeParams{
+		Username:   user.Username,
+		HashedCode: arg.HashedRecoveryCodes[1],
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	// MFA cannot be enabled twice
+	_, err = store.EnableMFATx(context.Background(), arg)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	err = store.DisableMFATx(context.Background(), user.Username)
+	require.NoError(t, err)
+
+	_, err = store.GetUserMFA(context.Background(), user.Username)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	rows, err = store.UseMFARecoveryCode(context.Background(), UseMFARecoveryCodeParams{
+		Username:   user.Username,
+		HashedCode: arg.HashedRecoveryCodes[0],
+	})
+	require.NoError(t, err)
+	require.Zero(t, rows)
+}
+
+func TestResetPasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	passwordReset := createRandomPasswordReset(t, user, time.Minute)
+	otherReset := createRandomPasswordReset(t, user, time.Minute)
+	for range 2 {
+		createRandomSession(t, user)
+	}
+
+	hashedPassword, err := util.HashPassword(util.RandomString(8))
+	require.NoError(t, err)
+
+	arg := ResetPasswordTxParams{
+		HashedToken:    passwordReset.HashedToken,
+		HashedPassword: hashedPassword,
+	}
+
+	result, err := store.ResetPasswordTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, result.User.Username)
+	require.Equal(t, hashedPassword, result.User.HashedPassword)
+	require.True(t, result.User.PasswordChangedAt.Time.After(user.PasswordChangedAt.Time))
+	require.Equal(t, int64(2), result.BlockedSessions)
+
+	hashedPasswords, err := store.ListPasswordHistory(context.Background(), ListPasswordHistoryParams{
+		Username: user.Username,
+		Limit:    5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{user.HashedPassword}, hashedPasswords)
+
+	// neither the used token nor the user's other tokens can reset the password again
+	_, err = store.ResetPasswordTx(context.Background(), arg)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	arg.HashedToken = otherReset.HashedToken
+	_, err = store.ResetPasswordTx(context.Background(), arg)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestUpdateUserTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	// the password history is only kept when the password changes
+	fullName := util.RandomOwner()
+	updated, err := store.UpdateUserTx(context.Background(), UpdateUserTxParams{
+		UpdateUserParams: UpdateUserParams{
+			Username: user.Username,
+			FullName: &fullName,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, fullName, updated.FullName)
+
+	hashedPassword, err := util.HashPassword(util.RandomString(8))
+	require.NoError(t, err)
+
+	updated, err = store.UpdateUserTx(context.Background(), UpdateUserTxParams{
+		UpdateUserParams: UpdateUserParams{
+			Username:       user.Username,
+			HashedPassword: &hashedPassword,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, hashedPassword, updated.HashedPassword)
+
+	hashedPasswords, err := store.ListPasswordHistory(context.Background(), ListPasswordHistoryParams{
+		Username: user.Username,
+		Limit:    5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{user.HashedPassword}, hashedPasswords)
+}
+
+func TestTransferTxFrozenAccount(t *testing.T) {
+	store := NewStore(testDB)
+
+	acc1 := createRandomAccountWithBalance(t, 1000, util.USD)
+	acc2 := createRandomAccountWithBalance(t, 1000, util.USD)
+
+	_, err := store.FreezeAccount(context.Background(), acc2.ID)
+	require.NoError(t, err)
+
+	// neither sending from nor receiving into a frozen account is allowed
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: acc2.ID,
+		ToAccountID:   acc1.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	account, err := store.GetAccount(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	require.Equal(t, acc1.Balance, account.Balance)
+}
+
+func TestReconcileBatchTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	// reconciled: every balance change of the accounts went through the ledger
+	reconciled := createRandomAccountWithBalance(t, 0, util.USD)
+	postTestEntry(t, reconciled, 50)
+	other := createRandomAccountWithBalance(t, 0, util.USD)
+	transferResult, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: reconciled.ID,
+		ToAccountID:   other.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	// drifted: the balance was set without any entry
+	drifted := createRandomAccountWithBalance(t, 100, util.USD)
+
+	// opened: the balance was written before the journal existed, and the migration posted it as an opening balance
+	opened := createRandomAccountWithBalance(t, 100, util.USD)
+	_, err = testDB.Exec(context.Background(), "SELECT post_opening_balance($1)", opened.ID)
+	require.NoError(t, err)
+
+	// broken: the transfer was recorded without its entries
+	broken1 := createRandomAccountWithBalance(t, 0, util.USD)
+	broken2 := createRandomAccountWithBalance(t, 0, util.USD)
+	brokenTransfer, err := store.CreateTransfer(context.Background(), CreateTransferParams{
+		FromAccountID: broken1.ID,
+		ToAccountID:   broken2.ID,
+		Amount:        10,
+		ToAmount:      10,
+		ExchangeRate:  pgtype.Numeric{Int: big.NewInt(1), Valid: true},
+		RoundingMode:  string(fx.DefaultRoundingMode),
+	})
+	require.NoError(t, err)
+
+	// other tests may create accounts concurrently, so the batch covers more than the accounts above
+	result, err := store.ReconcileBatchTx(context.Background(), ReconcileBatchTxParams{
+		AfterAccountID: reconciled.ID - 1,
+		BatchSize:      1000,
+	})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, result.AccountsScanned, 7)
+	require.Empty(t, result.FrozenAccountIDs)
+
+	mismatchedAccounts := make(map[int64]ReconcileAccountBalancesRow)
+	for _, account := range result.Accounts {
+		mismatchedAccounts[account.ID] = account
+	}
+	require.NotContains(t, mismatchedAccounts, reconciled.ID)
+	require.NotContains(t, mismatchedAccounts, other.ID)
+	require.NotContains(t, mismatchedAccounts, opened.ID)
+	require.Contains(t, mismatchedAccounts, drifted.ID)
+	require.Equal(t, int64(100), mismatchedAccounts[drifted.ID].Balance)
+	require.Zero(t, mismatchedAccounts[drifted.ID].EntriesBalance)
+	require.Zero(t, mismatchedAccounts[drifted.ID].EntryCount)
+
+	mismatchedTransfers := make(map[int64]ReconcileTransferEntriesRow)
+	for _, transfer := range result.Transfers {
+		mismatchedTransfers[transfer.ID] = transfer
+	}
+	require.NotContains(t, mismatchedTransfers, transferResult.Transfer.ID)
+	require.Contains(t, mismatchedTransfers, brokenTransfer.ID)
+	require.Zero(t, mismatchedTransfers[brokenTransfer.ID].FromEntryCount)
+	require.Zero(t, mismatchedTransfers[brokenTransfer.ID].ToEntryCount)
+
+	// a full batch points to the next one
+	result, err = store.ReconcileBatchTx(context.Background(), ReconcileBatchTxParams{
+		AfterAccountID: reconciled.ID - 1,
+		BatchSize:      1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.AccountsScanned)
+	require.Empty(t, result.Accounts)
+	require.Equal(t, reconciled.ID, result.NextAfterID)
+
+	result, err = store.ReconcileBatchTx(context.Background(), ReconcileBatchTxParams{
+		AfterAccountID: drifted.ID - 1,
+		BatchSize:      1,
+		Freeze:         true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{drifted.ID}, result.FrozenAccountIDs)
+
+	account, err := store.GetAccount(context.Background(), drifted.ID)
+	require.NoError(t, err)
+	require.True(t, account.IsFrozen)
+
+	result, err = store.ReconcileBatchTx(context.Background(), ReconcileBatchTxParams{
+		AfterAccountID: broken1.ID - 1,
+		BatchSize:      1,
+		Freeze:         true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{broken1.ID, broken2.ID}, result.FrozenAccountIDs)
+}
+
+func TestReverseTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	staff := createRandomUser(t)
+
+	acc1 := createRandomAccountWithBalance(t, 1000, util.USD)
+	acc2 := createRandomAccountWithBalance(t, 1000, util.USD)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	arg := ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     30,
+		ReasonCode: util.ReversalReasonCustomerRequest,
+		ReversedBy: staff.Username,
+	}
+	result, err := store.ReverseTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// the compensating transfer goes from the destination back to the source
+	require.Equal(t, original.Transfer, result.OriginalTransfer)
+	require.Equal(t, acc2.ID, result.Transfer.FromAccountID)
+	require.Equal(t, acc1.ID, result.Transfer.ToAccountID)
+	require.Equal(t, int64(30), result.Transfer.Amount)
+	require.Equal(t, int64(30), result.Transfer.ToAmount)
+	require.Equal(t, int64(-30), result.FromEntry.Amount)
+	require.Equal(t, int64(30), result.ToEntry.Amount)
+	require.Equal(t, ledger.KindReversal, result.JournalTransaction.Kind)
+	require.Equal(t, int64(930), result.ToAccount.Balance)
+	require.Equal(t, int64(1070), result.FromAccount.Balance)
+	require.Equal(t, int64(70), result.RemainingAmount)
+
+	require.Equal(t, original.Transfer.ID, result.Reversal.TransferID)
+	require.Equal(t, result.Transfer.ID, result.Reversal.ReversalTransferID)
+	require.Equal(t, arg.ReasonCode, result.Reversal.ReasonCode)
+	require.Equal(t, staff.Username, result.Reversal.ReversedBy)
+
+	// a reversal can't be reversed
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: result.Transfer.ID,
+		Amount:     10,
+		ReasonCode: util.ReversalReasonProcessingError,
+		ReversedBy: staff.Username,
+	})
+	require.ErrorIs(t, err, ErrReversalOfReversal)
+
+	// the reversals can't add up to more than the transfer
+	arg.Amount = 71
+	_, err = store.ReverseTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	arg.Amount = 70
+	result, err = store.ReverseTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, result.RemainingAmount)
+	require.Equal(t, acc1.Balance, result.ToAccount.Balance)
+	require.Equal(t, acc2.Balance, result.FromAccount.Balance)
+
+	arg.Amount = 1
+	_, err = store.ReverseTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	reversals, err := store.ListTransferReversals(context.Background(), original.Transfer.ID)
+	require.NoError(t, err)
+	require.Len(t, reversals, 2)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID + 1000000,
+		Amount:     1,
+		ReasonCode: util.ReversalReasonDuplicate,
+		ReversedBy: staff.Username,
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestReverseTransferTxCrossCurrency(t *testing.T) {
+	store := NewStore(testDB)
+	staff := createRandomUser(t)
+
+	acc1 := createRandomAccountWithBalance(t, 1000, util.USD)
+	acc2 := createRandomAccountWithBalance(t, 0, util.VND)
+
+	rate, err := fx.NewRate(util.USD, util.VND, big.NewRat(25400, 1))
+	require.NoError(t, err)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        150,
+		ExchangeRate:  &rate,
+		RoundingMode:  fx.RoundDown,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(38100), original.Transfer.ToAmount)
+
+	// a third of the refund takes back a third of the credit, without a new exchange rate
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     50,
+		ReasonCode: util.ReversalReasonFraud,
+		ReversedBy: staff.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(12700), result.Transfer.Amount)
+	require.Equal(t, int64(50), result.Transfer.ToAmount)
+
+	storedRate, err := result.Transfer.ExchangeRate.Float64Value()
+	require.NoError(t, err)
+	require.InDelta(t, 1.0/25400, storedRate.Float64, 1e-10)
+
+	// the clearing accounts are used again to balance each currency
+	entries, err := store.ListJournalTransactionEntries(context.Background(), &result.JournalTransaction.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	result, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     100,
+		ReasonCode: util.ReversalReasonFraud,
+		ReversedBy: staff.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(25400), result.Transfer.Amount)
+	require.Equal(t, acc1.Balance, result.ToAccount.Balance)
+	require.Equal(t, acc2.Balance, result.FromAccount.Balance)
+}
+
+func TestReverseTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	staff := createRandomUser(t)
+
+	acc1 := createRandomAccountWithBalance(t, 1000, util.USD)
+	acc2 := createRandomAccountWithBalance(t, 0, util.USD)
+	acc3 := createRandomAccountWithBalance(t, 0, util.USD)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	// the money has already left the destination account
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: acc2.ID,
+		ToAccountID:   acc3.ID,
+		Amount:        80,
+	})
+	require.NoError(t, err)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     100,
+		ReasonCode: util.ReversalReasonFraud,
+		ReversedBy: staff.Username,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     20,
+		ReasonCode: util.ReversalReasonFraud,
+		ReversedBy: staff.Username,
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.FromAccount.Balance)
+	require.Equal(t, int64(80), result.RemainingAmount)
+}
+
+func TestAdjustAccountBalanceTx(t *testing.T) {
+	store := NewStore(testDB)
+	staff := createRandomUser(t)
+	account := createRandomAccountWithBalance(t, 100, util.USD)
+
+	result, err := store.AdjustAccountBalanceTx(context.Background(), AdjustAccountBalanceTxParams{
+		AccountID:  account.ID,
+		Amount:     -150,
+		Reason:     "duplicate deposit",
+		AdjustedBy: staff.Username,
+	})
+	require.NoError(t, err)
+
+	// an adjustment can go below the overdraft limit
+	require.Equal(t, int64(-50), result.Account.Balance)
+	require.Equal(t, account.ID, result.Entry.AccountID)
+	require.Equal(t, int64(-150), result.Entry.Amount)
+	require.Equal(t, &result.JournalTransaction.ID, result.Entry.JournalTransactionID)
+	require.Equal(t, ledger.KindAdjustment, result.JournalTransaction.Kind)
+	require.Nil(t, result.JournalTransaction.TransferID)
+
+	require.Equal(t, account.ID, result.Adjustment.AccountID)
+	require.Equal(t, result.JournalTransaction.ID, result.Adjustment.JournalTransactionID)
+	require.Equal(t, int64(-150), result.Adjustment.Amount)
+	require.Equal(t, "duplicate deposit", result.Adjustment.Reason)
+	require.Equal(t, staff.Username, result.Adjustment.AdjustedBy)
+
+	// the other side of the adjustment is posted to the adjustments account of the currency
+	entries, err := store.ListJournalTransactionEntries(context.Background(), &result.JournalTransaction.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	adjustmentAccount, err := store.GetAccount(context.Background(), entries[1].AccountID)
+	require.NoError(t, err)
+	require.Equal(t, ledger.AdjustmentOwner, adjustmentAccount.Owner)
+	require.Equal(t, util.USD, adjustmentAccount.Currency)
+	require.Equal(t, int64(150), entries[1].Amount)
+
+	adjustments, err := store.ListBalanceAdjustments(context.Background(), ListBalanceAdjustmentsParams{
+		AccountID: account.ID,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []BalanceAdjustment{result.Adjustment}, adjustments)
+
+	_, err = store.AdjustAccountBalanceTx(context.Background(), AdjustAccountBalanceTxParams{
+		AccountID:  adjustmentAccount.ID,
+		Amount:     10,
+		Reason:     "test",
+		AdjustedBy: staff.Username,
+	})
+	require.ErrorIs(t, err, ErrSystemAccount)
+
+	_, err = store.AdjustAccountBalanceTx(context.Background(), AdjustAccountBalanceTxParams{
+		AccountID:  account.ID,
+		Amount:     0,
+		Reason:     "test",
+		AdjustedBy: staff.Username,
+	})
+	require.ErrorIs(t, err, ledger.ErrZeroPosting)
+}
+
+func TestExecuteScheduledTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	fromAccount := createRandomAccountWithBalance(t, 100, util.USD)
+	toAccount := createRandomAccountWithBalance(t, 0, util.USD)
+	verifyTestUserEmail(t, fromAccount.Owner)
+
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	scheduled := createRandomScheduledTransfer(t, fromAccount, toAccount, now)
+	scheduled, err := store.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:             scheduled.ID,
+		Amount:         60,
+		Schedule:       scheduled.Schedule,
+		MaxOccurrences: scheduled.MaxOccurrences,
+		NextRunAt:      scheduled.NextRunAt,
+		Status:         ScheduledTransferActive,
+	})
+	require.NoError(t, err)
+
+	arg := ExecuteScheduledTransferTxParams{
+		ScheduledTransferID: scheduled.ID,
+		ScheduledAt:         now,
+		Now:                 now.Add(time.Minute),
+	}
+	result, err := store.ExecuteScheduledTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, result.Executed)
+
+	require.Equal(t, ScheduledTransferRunSucceeded, result.Run.Status)
+	require.Equal(t, &result.Transfer.Transfer.ID, result.Run.TransferID)
+	require.Nil(t, result.Run.FailureReason)
+	require.Equal(t, int64(60), result.Transfer.Transfer.Amount)
+	require.Equal(t, int64(40), result.Transfer.FromAccount.Balance)
+	require.Equal(t, int64(60), result.Transfer.ToAccount.Balance)
+
+	require.Equal(t, int32(1), result.ScheduledTransfer.Occurrences)
+	require.Equal(t, ScheduledTransferActive, result.ScheduledTransfer.Status)
+	require.True(t, result.ScheduledTransfer.NextRunAt.Time.Equal(time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)))
+
+	// a retry of the same occurrence does nothing
+	retried, err := store.ExecuteScheduledTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, retried.Executed)
+	require.Equal(t, result.ScheduledTransfer, retried.ScheduledTransfer)
+
+	account, err := store.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(40), account.Balance)
+
+	// the next occurrence can't be covered, the run is recorded as failed and the schedule moves on
+	arg.ScheduledAt = result.ScheduledTransfer.NextRunAt.Time
+	arg.Now = arg.ScheduledAt
+	result, err = store.ExecuteScheduledTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, result.Executed)
+
+	require.Equal(t, ScheduledTransferRunFailed, result.Run.Status)
+	require.Nil(t, result.Run.TransferID)
+	require.NotNil(t, result.Run.FailureReason)
+	require.Contains(t, *result.Run.FailureReason, ErrInsufficientFunds.Error())
+	require.Zero(t, result.Transfer.Transfer.ID)
+	require.Equal(t, int32(2), result.ScheduledTransfer.Occurrences)
+	require.True(t, result.ScheduledTransfer.NextRunAt.Time.Equal(time.Date(2026, 12, 1, 9, 0, 0, 0, time.UTC)))
+
+	runs, err := store.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduled.ID,
+		Limit:               5,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	require.Equal(t, result.Run, runs[0])
+}
+
+func TestExecuteScheduledTransferTxCompleted(t *testing.T) {
+	store := NewStore(testDB)
+	fromAccount := createRandomAccountWithBalance(t, 100, util.USD)
+	toAccount := createRandomAccountWithBalance(t, 0, util.USD)
+	verifyTestUserEmail(t, fromAccount.Owner)
+
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	maxOccurrences := int32(1)
+	scheduled, err := store.CreateScheduledTransfer(context.Background(), CreateScheduledTransferParams{
+		Owner:          fromAccount.Owner,
+		FromAccountID:  fromAccount.ID,
+		ToAccountID:    toAccount.ID,
+		Amount:         10,
+		Schedule:       "0 9 1 * *",
+		StartAt:        pgtype.Timestamptz{Time: now, Valid: true},
+		MaxOccurrences: &maxOccurrences,
+		NextRunAt:      pgtype.Timestamptz{Time: now, Valid: true},
+	})
+	require.NoError(t, err)
+
+	result, err := store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{
+		ScheduledTransferID: scheduled.ID,
+		ScheduledAt:         now,
+		Now:                 now,
+	})
+	require.NoError(t, err)
+	require.True(t, result.Executed)
+	require.Equal(t, ScheduledTransferCompleted, result.ScheduledTransfer.Status)
+	require.False(t, result.ScheduledTransfer.NextRunAt.Valid)
+	require.Equal(t, int32(1), result.ScheduledTransfer.Occurrences)
+}
+
+func TestExecuteScheduledTransferTxChecks(t *testing.T) {
+	store := NewStore(testDB)
+	fromAccount := createRandomAccountWithBalance(t, 1000, util.USD)
+	toAccount := createRandomAccountWithBalance(t, 0, util.USD)
+
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	scheduled := createRandomScheduledTransfer(t, fromAccount, toAccount, now)
+	scheduled, err := store.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:             scheduled.ID,
+		Amount:         200,
+		Schedule:       scheduled.Schedule,
+		MaxOccurrences: scheduled.MaxOccurrences,
+		NextRunAt:      scheduled.NextRunAt,
+		Status:         ScheduledTransferActive,
+	})
+	require.NoError(t, err)
+
+	execute := func() ExecuteScheduledTransferTxResult {
+		result, err := store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{
+			ScheduledTransferID: scheduled.ID,
+			ScheduledAt:         scheduled.NextRunAt.Time,
+			Now:                 scheduled.NextRunAt.Time,
+			MFAThresholds:       mfa.TransferThresholds{util.USD: 100},
+		})
+		require.NoError(t, err)
+		require.True(t, result.Executed)
+
+		scheduled = result.ScheduledTransfer
+		return result
+	}
+
+	// the owner's email address was never verified
+	result := execute()
+	require.Equal(t, ScheduledTransferRunFailed, result.Run.Status)
+	require.Equal(t, ErrEmailNotVerified.Error(), *result.Run.FailureReason)
+
+	// the amount is above the threshold, which doesn't apply until the owner enables two-factor authentication
+	owner := verifyTestUserEmail(t, fromAccount.Owner)
+	result = execute()
+	require.Equal(t, ScheduledTransferRunSucceeded, result.Run.Status)
+
+	createRandomUserMFA(t, owner)
+	_, err = store.EnableUserMFA(context.Background(), EnableUserMFAParams{
+		Username:     owner.Username,
+		LastUsedStep: 100,
+	})
+	require.NoError(t, err)
+
+	result = execute()
+	require.Equal(t, ScheduledTransferRunFailed, result.Run.Status)
+	require.Contains(t, *result.Run.FailureReason, ErrTOTPCodeRequired.Error())
+	require.Nil(t, result.Run.TransferID)
+
+	// the owner authorized the amount with a TOTP code
+	authorizedAmount := scheduled.Amount
+	scheduled, err = store.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:                  scheduled.ID,
+		Amount:              scheduled.Amount,
+		Schedule:            scheduled.Schedule,
+		MaxOccurrences:      scheduled.MaxOccurrences,
+		NextRunAt:           scheduled.NextRunAt,
+		Status:              scheduled.Status,
+		MfaAuthorizedAmount: &authorizedAmount,
+	})
+	require.NoError(t, err)
+
+	result = execute()
+	require.Equal(t, ScheduledTransferRunSucceeded, result.Run.Status)
+	require.Equal(t, int64(600), result.Transfer.FromAccount.Balance)
+}
+
+func TestUpdateScheduledTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	fromAccount := createRandomAccountWithBalance(t, 100, util.USD)
+	toAccount := createRandomAccountWithBalance(t, 0, util.USD)
+	scheduled := createRandomScheduledTransfer(t, fromAccount, toAccount, time.Now())
+
+	errRejected := errors.New("rejected")
+	_, err := store.UpdateScheduledTransferTx(context.Background(), UpdateScheduledTransferTxParams{
+		ID: scheduled.ID,
+		Update: func(locked ScheduledTransfer) (UpdateScheduledTransferParams, error) {
+			return UpdateScheduledTransferParams{}, errRejected
+		},
+	})
+	require.ErrorIs(t, err, errRejected)
+
+	updated, err := store.UpdateScheduledTransferTx(context.Background(), UpdateScheduledTransferTxParams{
+		ID: scheduled.ID,
+		Update: func(locked ScheduledTransfer) (UpdateScheduledTransferParams, error) {
+			require.Equal(t, scheduled, locked)
+			return UpdateScheduledTransferParams{
+				Amount:         locked.Amount,
+				Schedule:       locked.Schedule,
+				EndAt:          locked.EndAt,
+				MaxOccurrences: locked.MaxOccurrences,
+				Status:         ScheduledTransferCancelled,
+			}, nil
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, scheduled.ID, updated.ID)
+	require.Equal(t, ScheduledTransferCancelled, updated.Status)
+	require.False(t, updated.NextRunAt.Valid)
+
+	_, err = store.UpdateScheduledTransferTx(context.Background(), UpdateScheduledTransferTxParams{
+		ID: -1,
+		Update: func(locked ScheduledTransfer) (UpdateScheduledTransferParams, error) {
+			return UpdateScheduledTransferParams{}, nil
+		},
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
@@ -7,6 +7,8 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createTransfer = `-- name: CreateTransfer :one
@@ -14,19 +16,32 @@ INSERT INTO transfers
 (
   from_account_id, 
   to_account_id,
-  amount
-) VALUES ($1, $2, $3)
-RETURNING id, from_account_id, to_account_id, amount, created_at
+  amount,
+  to_amount,
+  exchange_rate,
+  rounding_mode
+) VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rounding_mode
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64          `json:"from_account_id"`
+	ToAccountID   int64          `json:"to_account_id"`
+	Amount        int64          `json:"amount"`
+	ToAmount      int64          `json:"to_amount"`
+	ExchangeRate  pgtype.Numeric `json:"exchange_rate"`
+	RoundingMode  string         `json:"rounding_mode"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRow(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRow(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ToAmount,
+		arg.ExchangeRate,
+		arg.RoundingMode,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -34,22 +49,15 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.RoundingMode,
 	)
 	return i, err
 }
 
-const deleteTransfer = `-- name: DeleteTransfer :exec
-DELETE FROM transfers
-WHERE id = $1
-`
-
-func (q *Queries) DeleteTransfer(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, deleteTransfer, id)
-	return err
-}
-
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rounding_mode FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -62,12 +70,15 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.RoundingMode,
 	)
 	return i, err
 }
 
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rounding_mode FROM transfers
 ORDER BY id
 LIMIT $1 OFFSET $2
 `
@@ -92,6 +103,9 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.RoundingMode,
 		); err != nil {
 			return nil, err
 		}
@@ -103,27 +117,72 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 	return items, nil
 }
 
-const updateTransfer = `-- name: UpdateTransfer :one
-UPDATE transfers
-SET amount = $2
-WHERE id = $1
-RETURNING id, from_account_id, to_account_id, amount, created_at
+const reconcileTransferEntries = `-- name: ReconcileTransferEntries :many
+SELECT
+  transfers.id,
+  transfers.from_account_id,
+  transfers.to_account_id,
+  transfers.amount,
+  transfers.to_amount,
+  COUNT(entries.id) FILTER (
+    WHERE entries.account_id = transfers.from_account_id AND entries.amount = -transfers.amount
+  ) AS from_entry_count,
+  COUNT(entries.id) FILTER (
+    WHERE entries.account_id = transfers.to_account_id AND entries.amount = transfers.to_amount
+  ) AS to_entry_count
+FROM transfers
+LEFT JOIN entries ON entries.transfer_id = transfers.id
+WHERE transfers.from_account_id > $1
+  AND transfers.from_account_id <= $2
+GROUP BY transfers.id
+HAVING COUNT(entries.id) FILTER (
+    WHERE entries.account_id = transfers.from_account_id AND entries.amount = -transfers.amount
+  ) <> 1
+  OR COUNT(entries.id) FILTER (
+    WHERE entries.account_id = transfers.to_account_id AND entries.amount = transfers.to_amount
+  ) <> 1
+ORDER BY transfers.id
 `
 
-type UpdateTransferParams struct {
-	ID     int64 `json:"id"`
-	Amount int64 `json:"amount"`
+type ReconcileTransferEntriesParams struct {
+	AfterAccountID int64 `json:"after_account_id"`
+	LastAccountID  int64 `json:"last_account_id"`
 }
 
-func (q *Queries) UpdateTransfer(ctx context.Context, arg UpdateTransferParams) (Transfer, error) {
-	row := q.db.QueryRow(ctx, updateTransfer, arg.ID, arg.Amount)
-	var i Transfer
-	err := row.Scan(
-		&i.ID,
-		&i.FromAccountID,
-		&i.ToAccountID,
-		&i.Amount,
-		&i.CreatedAt,
-	)
-	return i, err
+type ReconcileTransferEntriesRow struct {
+	ID             int64 `json:"id"`
+	FromAccountID  int64 `json:"from_account_id"`
+	ToAccountID    int64 `json:"to_account_id"`
+	Amount         int64 `json:"amount"`
+	ToAmount       int64 `json:"to_amount"`
+	FromEntryCount int64 `json:"from_entry_count"`
+	ToEntryCount   int64 `json:"to_entry_count"`
+}
+
+func (q *Queries) ReconcileTransferEntries(ctx context.Context, arg ReconcileTransferEntriesParams) ([]ReconcileTransferEntriesRow, error) {
+	rows, err := q.db.Query(ctx, reconcileTransferEntries, arg.AfterAccountID, arg.LastAccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ReconcileTransferEntriesRow{}
+	for rows.Next() {
+		var i ReconcileTransferEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.ToAmount,
+			&i.FromEntryCount,
+			&i.ToEntryCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
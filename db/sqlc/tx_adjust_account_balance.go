@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hykura1501/simple_bank/ledger"
+)
+
+// ErrSystemAccount is returned by AdjustAccountBalanceTx when the account belongs to a system user of the ledger
+var ErrSystemAccount = errors.New("system accounts cannot be adjusted")
+
+// AdjustAccountBalanceTxParams contains the input parameters of the adjust account balance transaction
+type AdjustAccountBalanceTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// Amount is added to the balance, in the account currency. It is negative for a debit
+	Amount     int64  `json:"amount"`
+	Reason     string `json:"reason"`
+	AdjustedBy string `json:"adjusted_by"`
+}
+
+// AdjustAccountBalanceTxResult is the result of the adjust account balance transaction
+type AdjustAccountBalanceTxResult struct {
+	Adjustment         BalanceAdjustment  `json:"adjustment"`
+	Account            Account            `json:"account"`
+	Entry              Entry              `json:"entry"`
+	JournalTransaction JournalTransaction `json:"journal_transaction"`
+}
+
+// AdjustAccountBalanceTx corrects the balance of an account without rewriting its history.
+// It posts a compensating journal transaction between the account and the adjustments account of its currency,
+// and records who adjusted the balance and why.
+// An adjustment can take the balance below the overdraft limit, and applies to frozen accounts as well,
+// since it corrects a mistake rather than moving the owner's money.
+// It returns pgx.ErrNoRows if the account doesn't exist, ErrSystemAccount, and ledger.ErrZeroPosting
+// if the amount is zero
+func (store *SQLStore) AdjustAccountBalanceTx(ctx context.Context, arg AdjustAccountBalanceTxParams) (AdjustAccountBalanceTxResult, error) {
+	var result AdjustAccountBalanceTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		switch account.Owner {
+		case ledger.AdjustmentOwner, ledger.FXClearingOwner, ledger.OpeningBalanceOwner:
+			return fmt.Errorf("%w: account [%d] belongs to %s", ErrSystemAccount, account.ID, account.Owner)
+		}
+
+		adjustmentAccountID, err := systemAccountID(ctx, q, ledger.AdjustmentOwner, account.Currency)
+		if err != nil {
+			return err
+		}
+
+		posted, err := postTransaction(ctx, q, ledger.Transaction{
+			Kind: ledger.KindAdjustment,
+			Postings: []ledger.Posting{
+				{AccountID: account.ID, Currency: account.Currency, Amount: arg.Amount},
+				{AccountID: adjustmentAccountID, Currency: account.Currency, Amount: -arg.Amount},
+			},
+		}, nil)
+		if err != nil {
+			return err
+		}
+
+		result.Entry = posted.Entries[0]
+		result.Account = posted.Accounts[account.ID]
+		result.JournalTransaction = posted.JournalTransaction
+
+		result.Adjustment, err = q.CreateBalanceAdjustment(ctx, CreateBalanceAdjustmentParams{
+			AccountID:            account.ID,
+			JournalTransactionID: posted.JournalTransaction.ID,
+			Amount:               arg.Amount,
+			Reason:               arg.Reason,
+			AdjustedBy:           arg.AdjustedBy,
+		})
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,37 @@
+package db
+
+import "context"
+
+// CreateUserTxParams contains the input parameters of the create user transaction.
+// AfterCreate is called with the new user before the transaction commits,
+// and the user isn't created if it returns an error
+type CreateUserTxParams struct {
+	CreateUserParams
+	AfterCreate func(user User) error
+}
+
+// CreateUserTxResult is the result of the create user transaction
+type CreateUserTxResult struct {
+	User User
+}
+
+// CreateUserTx creates a user and runs AfterCreate in the same database transaction
+func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error) {
+	var result CreateUserTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.User, err = q.CreateUser(ctx, arg.CreateUserParams)
+		if err != nil {
+			return err
+		}
+
+		if arg.AfterCreate == nil {
+			return nil
+		}
+		return arg.AfterCreate(result.User)
+	})
+
+	return result, err
+}
@@ -0,0 +1,185 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	operationTransfer      = "transfer"
+	operationCreateAccount = "create_account"
+)
+
+// ErrIdempotencyKeyReused is returned when an idempotency key is sent again with a different request payload
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different request")
+
+// IdempotencyParams identifies a client request that must be executed at most once
+type IdempotencyParams struct {
+	Key      string
+	Username string
+	Duration time.Duration
+}
+
+// IdempotentTransferTx performs TransferTx at most once per idempotency key.
+// A retry with the same key and payload replays the original result
+func (store *SQLStore) IdempotentTransferTx(ctx context.Context, idempotency IdempotencyParams, arg TransferTxParams) (TransferTxResult, error) {
+	return execIdempotentTx(ctx, store, idempotency, operationTransfer, idempotentTransferRequest(arg), func(q *Queries) (TransferTxResult, error) {
+		return transferTx(ctx, q, arg)
+	})
+}
+
+// ReplayIdempotentTransfer returns the result of the transfer already made with the idempotency key, without making a transfer.
+// It returns false if no transfer was recorded with the key yet,
+// and ErrIdempotencyKeyReused if the key was used for a different transfer.
+// It lets a retry be answered before checking what only the first request needs, e.g. a single-use TOTP code
+func (store *SQLStore) ReplayIdempotentTransfer(ctx context.Context, idempotency IdempotencyParams, arg TransferTxParams) (TransferTxResult, bool, error) {
+	var result TransferTxResult
+
+	requestHash, err := hashRequest(operationTransfer, idempotentTransferRequest(arg))
+	if err != nil {
+		return result, false, err
+	}
+
+	key, err := store.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+		Username: idempotency.Username,
+		Key:      idempotency.Key,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, false, nil
+		}
+		return result, false, err
+	}
+
+	// an expired key can be reused, and a key without a response belongs to a request that is still running
+	if !key.ExpiredAt.Time.After(time.Now()) || key.Response == nil {
+		return result, false, nil
+	}
+
+	if key.RequestHash != requestHash {
+		return result, false, ErrIdempotencyKeyReused
+	}
+
+	err = json.Unmarshal(key.Response, &result)
+	if err != nil {
+		return result, false, fmt.Errorf("failed to unmarshal idempotent response: %w", err)
+	}
+	return result, true, nil
+}
+
+// idempotentTransferRequest is the part of a transfer that a retry must repeat.
+// The exchange rate is looked up by the server, so a retry may carry a different one
+func idempotentTransferRequest(arg TransferTxParams) TransferTxParams {
+	return TransferTxParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+	}
+}
+
+// IdempotentCreateAccountTx creates an account at most once per idempotency key.
+// A retry with the same key and payload replays the original account
+func (store *SQLStore) IdempotentCreateAccountTx(ctx context.Context, idempotency IdempotencyParams, arg CreateAccountParams) (Account, error) {
+	return execIdempotentTx(ctx, store, idempotency, operationCreateAccount, arg, func(q *Queries) (Account, error) {
+		return q.CreateAccount(ctx, arg)
+	})
+}
+
+// execIdempotentTx stores the idempotency key and the result of fn in the same database transaction as fn itself,
+// so a request is either executed and recorded, or not executed at all
+func execIdempotentTx[T any](
+	ctx context.Context,
+	store *SQLStore,
+	idempotency IdempotencyParams,
+	operation string,
+	request any,
+	fn func(q *Queries) (T, error),
+) (T, error) {
+	var result T
+
+	requestHash, err := hashRequest(operation, request)
+	if err != nil {
+		return result, err
+	}
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		_, err := q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+			Key:         idempotency.Key,
+			Username:    idempotency.Username,
+			RequestHash: requestHash,
+			ExpiredAt: pgtype.Timestamptz{
+				Time:  time.Now().Add(idempotency.Duration),
+				Valid: true,
+			},
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				// the key is still active, so the request has already been executed
+				result, err = replayIdempotentResult[T](ctx, q, idempotency, requestHash)
+			}
+			return err
+		}
+
+		result, err = fn(q)
+		if err != nil {
+			return err
+		}
+
+		response, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("failed to marshal idempotent response: %w", err)
+		}
+
+		return q.UpdateIdempotencyKeyResponse(ctx, UpdateIdempotencyKeyResponseParams{
+			Username: idempotency.Username,
+			Key:      idempotency.Key,
+			Response: response,
+		})
+	})
+
+	return result, err
+}
+
+func replayIdempotentResult[T any](ctx context.Context, q *Queries, idempotency IdempotencyParams, requestHash string) (T, error) {
+	var result T
+
+	key, err := q.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+		Username: idempotency.Username,
+		Key:      idempotency.Key,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if key.RequestHash != requestHash {
+		return result, ErrIdempotencyKeyReused
+	}
+
+	if key.Response == nil {
+		return result, fmt.Errorf("idempotency key %s has no recorded response", idempotency.Key)
+	}
+
+	err = json.Unmarshal(key.Response, &result)
+	if err != nil {
+		return result, fmt.Errorf("failed to unmarshal idempotent response: %w", err)
+	}
+	return result, nil
+}
+
+func hashRequest(operation string, request any) (string, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal idempotent request: %w", err)
+	}
+
+	hash := sha256.Sum256(append([]byte(operation+":"), data...))
+	return hex.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+)
+
+// EnableMFATxParams contains the input parameters of the enable MFA transaction
+type EnableMFATxParams struct {
+	Username string
+	// LastUsedStep is the time step of the TOTP code that confirmed the enrollment
+	LastUsedStep        int64
+	HashedRecoveryCodes []string
+}
+
+// EnableMFATxResult is the result of the enable MFA transaction
+type EnableMFATxResult struct {
+	UserMFA UserMfa
+}
+
+// EnableMFATx enables the user's pending MFA enrollment and replaces their recovery codes in a single transaction.
+// It returns pgx.ErrNoRows if the user has no pending enrollment
+func (store *SQLStore) EnableMFATx(ctx context.Context, arg EnableMFATxParams) (EnableMFATxResult, error) {
+	var result EnableMFATxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.UserMFA, err = q.EnableUserMFA(ctx, EnableUserMFAParams{
+			Username:     arg.Username,
+			LastUsedStep: arg.LastUsedStep,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteMFARecoveryCodes(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateMFARecoveryCodes(ctx, CreateMFARecoveryCodesParams{
+			Username:    arg.Username,
+			HashedCodes: arg.HashedRecoveryCodes,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// DisableMFATx removes the user's MFA secret and recovery codes in a single transaction
+func (store *SQLStore) DisableMFATx(ctx context.Context, username string) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		err := q.DeleteMFARecoveryCodes(ctx, username)
+		if err != nil {
+			return err
+		}
+		return q.DeleteUserMFA(ctx, username)
+	})
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ResetPasswordTxParams contains the input parameters of the reset password transaction
+type ResetPasswordTxParams struct {
+	HashedToken    string
+	HashedPassword string
+}
+
+// ResetPasswordTxResult is the result of the reset password transaction
+type ResetPasswordTxResult struct {
+	User            User
+	BlockedSessions int64
+}
+
+// ResetPasswordTx uses the reset token, changes the user's password and blocks all of their sessions
+// in a single transaction. The replaced password is added to the user's password history,
+// and the user's other outstanding reset tokens are used up as well.
+// It returns pgx.ErrNoRows if the token doesn't exist, was already used or has expired
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (ResetPasswordTxResult, error) {
+	var result ResetPasswordTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		passwordReset, err := q.UsePasswordReset(ctx, arg.HashedToken)
+		if err != nil {
+			return err
+		}
+
+		err = q.ArchiveUserPassword(ctx, passwordReset.Username)
+		if err != nil {
+			return err
+		}
+
+		result.User, err = q.UpdateUser(ctx, UpdateUserParams{
+			Username:       passwordReset.Username,
+			HashedPassword: &arg.HashedPassword,
+			PasswordChangedAt: pgtype.Timestamptz{
+				Time:  time.Now(),
+				Valid: true,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.ExpireUserPasswordResets(ctx, passwordReset.Username)
+		if err != nil {
+			return err
+		}
+
+		result.BlockedSessions, err = q.BlockUserSessions(ctx, passwordReset.Username)
+		return err
+	})
+
+	return result, err
+}
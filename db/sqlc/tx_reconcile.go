@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"slices"
+)
+
+// ReconcileBatchTxParams contains the input parameters of the reconcile batch transaction
+type ReconcileBatchTxParams struct {
+	// AfterAccountID is the keyset cursor: only accounts with a greater ID are checked
+	AfterAccountID int64 `json:"after_account_id"`
+	BatchSize      int32 `json:"batch_size"`
+	// Freeze freezes the accounts of every mismatch found in the batch
+	Freeze bool `json:"freeze"`
+}
+
+// ReconcileBatchTxResult is what the reconciliation of a batch of accounts found
+type ReconcileBatchTxResult struct {
+	AccountsScanned int `json:"accounts_scanned"`
+	// Accounts are the accounts whose balance isn't the sum of their entries
+	Accounts []ReconcileAccountBalancesRow `json:"accounts"`
+	// Transfers are the transfers from the batch's accounts that don't have
+	// exactly one debit entry of the source account and one credit entry of the destination account
+	Transfers []ReconcileTransferEntriesRow `json:"transfers"`
+	// FrozenAccountIDs are the accounts frozen because of the mismatches, in ascending order
+	FrozenAccountIDs []int64 `json:"frozen_account_ids"`
+	// NextAfterID is the cursor of the next batch, or 0 if this batch is the last one
+	NextAfterID int64 `json:"next_after_id"`
+}
+
+// ReconcileBatchTx checks a batch of accounts against the ledger:
+// each balance must equal the sum of the account's entries,
+// and each transfer from the accounts must have exactly two matching entries.
+// The checks read from the same snapshot, so a transfer committed during the scan can't cause a false mismatch.
+// With Freeze, the accounts of the mismatches are then frozen in a separate transaction
+func (store *SQLStore) ReconcileBatchTx(ctx context.Context, arg ReconcileBatchTxParams) (ReconcileBatchTxResult, error) {
+	var result ReconcileBatchTxResult
+
+	err := store.execReadOnlyTx(ctx, func(q *Queries) error {
+		accounts, err := q.ReconcileAccountBalances(ctx, ReconcileAccountBalancesParams{
+			AfterID:   arg.AfterAccountID,
+			BatchSize: arg.BatchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.AccountsScanned = len(accounts)
+		result.Accounts = []ReconcileAccountBalancesRow{}
+		result.Transfers = []ReconcileTransferEntriesRow{}
+		if len(accounts) == 0 {
+			return nil
+		}
+
+		for _, account := range accounts {
+			if account.Balance != account.EntriesBalance {
+				result.Accounts = append(result.Accounts, account)
+			}
+		}
+
+		lastAccountID := accounts[len(accounts)-1].ID
+		result.Transfers, err = q.ReconcileTransferEntries(ctx, ReconcileTransferEntriesParams{
+			AfterAccountID: arg.AfterAccountID,
+			LastAccountID:  lastAccountID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(accounts) == int(arg.BatchSize) {
+			result.NextAfterID = lastAccountID
+		}
+		return nil
+	})
+	if err != nil || !arg.Freeze {
+		return result, err
+	}
+
+	result.FrozenAccountIDs = result.mismatchedAccountIDs()
+	if len(result.FrozenAccountIDs) == 0 {
+		return result, nil
+	}
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		for _, accountID := range result.FrozenAccountIDs {
+			_, err := q.FreezeAccount(ctx, accountID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// mismatchedAccountIDs returns the accounts involved in the mismatches of the batch, in ascending order
+func (result ReconcileBatchTxResult) mismatchedAccountIDs() []int64 {
+	ids := []int64{}
+	for _, account := range result.Accounts {
+		ids = append(ids, account.ID)
+	}
+	for _, transfer := range result.Transfers {
+		ids = append(ids, transfer.FromAccountID, transfer.ToAccountID)
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/hykura1501/simple_bank/fx"
+	"github.com/hykura1501/simple_bank/ledger"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrReversalExceedsTransfer is returned by ReverseTransferTx when the amount is more than what is left to reverse
+var ErrReversalExceedsTransfer = errors.New("reversal exceeds the amount left to reverse")
+
+// ErrReversalOfReversal is returned by ReverseTransferTx when the transfer is itself a reversal
+var ErrReversalOfReversal = errors.New("a reversal cannot be reversed")
+
+// ReverseTransferTxParams contains the input parameters of the reverse transfer transaction
+type ReverseTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+	// Amount is refunded to the source account of the transfer, in its currency
+	Amount     int64  `json:"amount"`
+	ReasonCode string `json:"reason_code"`
+	ReversedBy string `json:"reversed_by"`
+}
+
+// ReverseTransferTxResult is the result of the reverse transfer transaction.
+// Its TransferTxResult is the compensating transfer, from the destination of the original transfer back to its source
+type ReverseTransferTxResult struct {
+	TransferTxResult
+	Reversal         TransferReversal `json:"reversal"`
+	OriginalTransfer Transfer         `json:"original_transfer"`
+	// RemainingAmount is what is left to reverse of the original transfer, in its source currency
+	RemainingAmount int64 `json:"remaining_amount"`
+}
+
+// ReverseTransferTx undoes all or part of a transfer with a compensating transfer linked to it.
+// The source account is refunded Amount in its currency, and the destination account is debited
+// the same share of what it was credited, so reversing a cross-currency transfer doesn't need a new exchange rate.
+// The reversals of a transfer can't add up to more than its amount.
+// A frozen account can still be reversed, but the destination account must be able to cover the debit.
+// It returns pgx.ErrNoRows if the transfer doesn't exist, ErrReversalOfReversal, ErrReversalExceedsTransfer
+// and ErrInsufficientFunds
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.OriginalTransfer, err = q.GetTransfer(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+		original := result.OriginalTransfer
+
+		isReversal, err := q.IsReversalTransfer(ctx, original.ID)
+		if err != nil {
+			return err
+		}
+		if isReversal {
+			return fmt.Errorf("%w: transfer [%d]", ErrReversalOfReversal, original.ID)
+		}
+
+		// locking both accounts also serializes the reversals of the transfer
+		fromAccount, toAccount, err := lockAccounts(ctx, q, original.ToAccountID, original.FromAccountID)
+		if err != nil {
+			return err
+		}
+
+		reversed, err := q.GetTransferReversedAmounts(ctx, original.ID)
+		if err != nil {
+			return err
+		}
+
+		remaining := original.Amount - reversed.RefundedAmount
+		if arg.Amount > remaining {
+			return fmt.Errorf("%w: transfer [%d] has %d left to reverse, cannot reverse %d",
+				ErrReversalExceedsTransfer, original.ID, remaining, arg.Amount)
+		}
+
+		debit := reversalDebit(original, reversed, arg.Amount)
+		if debit <= 0 {
+			return fmt.Errorf("%w: reversing %d of transfer [%d]", fx.ErrAmountTooSmall, arg.Amount, original.ID)
+		}
+
+		if fromAccount.Balance-debit < -fromAccount.OverdraftLimit {
+			return fmt.Errorf("%w: account [%d] has balance %d and overdraft limit %d, cannot send %d",
+				ErrInsufficientFunds, fromAccount.ID, fromAccount.Balance, fromAccount.OverdraftLimit, debit)
+		}
+
+		exchangeRate, err := invertExchangeRate(original.ExchangeRate, fromAccount.Currency, toAccount.Currency)
+		if err != nil {
+			return err
+		}
+
+		transfer, err := q.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        debit,
+			ToAmount:      arg.Amount,
+			ExchangeRate:  exchangeRate,
+			RoundingMode:  original.RoundingMode,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.TransferTxResult, err = postTransfer(ctx, q, ledger.KindReversal, transfer, fromAccount, toAccount)
+		if err != nil {
+			return err
+		}
+
+		result.Reversal, err = q.CreateTransferReversal(ctx, CreateTransferReversalParams{
+			TransferID:         original.ID,
+			ReversalTransferID: transfer.ID,
+			ReasonCode:         arg.ReasonCode,
+			ReversedBy:         arg.ReversedBy,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.RemainingAmount = remaining - arg.Amount
+		return nil
+	})
+
+	return result, err
+}
+
+// reversalDebit works out how much of the original credit is taken back from the destination account
+// to refund amount to the source account. The share is rounded down, and the last reversal takes
+// whatever is left, so the reversals of a transfer always add up to exactly what it credited
+func reversalDebit(original Transfer, reversed GetTransferReversedAmountsRow, amount int64) int64 {
+	if amount == original.Amount-reversed.RefundedAmount {
+		return original.ToAmount - reversed.DebitedAmount
+	}
+
+	debit := new(big.Int).Mul(big.NewInt(amount), big.NewInt(original.ToAmount))
+	debit.Quo(debit, big.NewInt(original.Amount))
+	return debit.Int64()
+}
+
+// invertExchangeRate returns the rate of a reversal, from the destination back to the source currency of a transfer
+func invertExchangeRate(rate pgtype.Numeric, from string, to string) (pgtype.Numeric, error) {
+	inverted := pgtype.Numeric{Int: big.NewInt(1), Valid: true}
+	if from == to {
+		return inverted, nil
+	}
+
+	text, err := rate.Value()
+	if err != nil {
+		return inverted, err
+	}
+
+	value, ok := new(big.Rat).SetString(fmt.Sprint(text))
+	if !ok || value.Sign() <= 0 {
+		return inverted, fmt.Errorf("invalid %s/%s exchange rate: %v", to, from, text)
+	}
+
+	fxRate, err := fx.NewRate(from, to, value.Inv(value))
+	if err != nil {
+		return inverted, err
+	}
+
+	err = inverted.Scan(fxRate.String())
+	return inverted, err
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrRefreshTokenReused is returned when a refresh token that was already rotated is presented again
+var ErrRefreshTokenReused = errors.New("refresh token was already used")
+
+// RotateSessionTxParams contains the input parameters of the rotate session transaction.
+// NewSession is created in the family of the old session, which FamilyID is overwritten with
+type RotateSessionTxParams struct {
+	OldSession Session
+	NewSession CreateSessionParams
+}
+
+// RotateSessionTxResult is the result of the rotate session transaction
+type RotateSessionTxResult struct {
+	Session Session
+}
+
+// RotateSessionTx marks the old session used and creates the new session in its family.
+// If the old session was already used, the refresh token has been replayed, so every session
+// of the family is blocked and ErrRefreshTokenReused is returned
+func (store *SQLStore) RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (RotateSessionTxResult, error) {
+	var result RotateSessionTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		_, err := q.MarkSessionUsed(ctx, arg.OldSession.ID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrRefreshTokenReused
+			}
+			return err
+		}
+
+		newSession := arg.NewSession
+		newSession.FamilyID = arg.OldSession.FamilyID
+		result.Session, err = q.CreateSession(ctx, newSession)
+		return err
+	})
+
+	if errors.Is(err, ErrRefreshTokenReused) {
+		if _, blockErr := store.BlockSessionFamily(ctx, arg.OldSession.FamilyID); blockErr != nil {
+			return result, blockErr
+		}
+	}
+
+	return result, err
+}
@@ -0,0 +1,240 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hykura1501/simple_bank/mfa"
+	"github.com/hykura1501/simple_bank/schedule"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Statuses of a scheduled transfer
+const (
+	ScheduledTransferActive    = "active"
+	ScheduledTransferPaused    = "paused"
+	ScheduledTransferCompleted = "completed"
+	ScheduledTransferCancelled = "cancelled"
+)
+
+// Statuses of a run of a scheduled transfer
+const (
+	ScheduledTransferRunSucceeded = "succeeded"
+	ScheduledTransferRunFailed    = "failed"
+)
+
+// ErrEmailNotVerified is returned when the owner of a scheduled transfer no longer has a verified email address
+var ErrEmailNotVerified = errors.New("email address must be verified before creating transfers")
+
+// ErrTOTPCodeRequired is returned when a scheduled transfer is above the two-factor authentication threshold,
+// and its owner didn't authorize the amount with a TOTP code
+var ErrTOTPCodeRequired = errors.New("totp code is required")
+
+// Recurrence parses the schedule of the scheduled transfer with its end date and max occurrences
+func (scheduled ScheduledTransfer) Recurrence() (schedule.Recurrence, error) {
+	var recurrence schedule.Recurrence
+
+	var err error
+	recurrence.Schedule, err = schedule.Parse(scheduled.Schedule)
+	if err != nil {
+		return recurrence, err
+	}
+
+	if scheduled.EndAt.Valid {
+		recurrence.EndAt = scheduled.EndAt.Time
+	}
+	if scheduled.MaxOccurrences != nil {
+		recurrence.MaxOccurrences = *scheduled.MaxOccurrences
+	}
+	return recurrence, nil
+}
+
+// ExecuteScheduledTransferTxParams contains the input parameters of the execute scheduled transfer transaction
+type ExecuteScheduledTransferTxParams struct {
+	ScheduledTransferID int64 `json:"scheduled_transfer_id"`
+	// ScheduledAt is the occurrence to run, the next run of the scheduled transfer when it was found due
+	ScheduledAt time.Time `json:"scheduled_at"`
+	// Now is the time of the run, the occurrences before it are skipped when the next run is worked out
+	Now time.Time `json:"now"`
+	// MFAThresholds are the current two-factor authentication thresholds of transfers
+	MFAThresholds mfa.TransferThresholds `json:"mfa_thresholds"`
+}
+
+// ExecuteScheduledTransferTxResult is the result of the execute scheduled transfer transaction
+type ExecuteScheduledTransferTxResult struct {
+	ScheduledTransfer ScheduledTransfer `json:"scheduled_transfer"`
+	// Executed is false if the occurrence had already been run,
+	// or the scheduled transfer was paused, cancelled or rescheduled since it was found due
+	Executed bool                 `json:"executed"`
+	Run      ScheduledTransferRun `json:"run"`
+	// Transfer is only set if the run succeeded
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// ExecuteScheduledTransferTx runs one occurrence of a scheduled transfer through the transfer transaction,
+// records the outcome of the run and moves the scheduled transfer to its next run, or completes it.
+// The scheduled transfer is locked and its next run must still be ScheduledAt,
+// so a retried or concurrent execution of the same occurrence does nothing.
+// A run must pass the checks of a transfer made by the owner at the time of the run, see checkScheduledTransferRun.
+// A transfer that can't be made, e.g. because of insufficient funds, is recorded as a failed run
+// and doesn't fail the transaction: the occurrence isn't retried
+func (store *SQLStore) ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ExecuteScheduledTransferTxResult, error) {
+	var result ExecuteScheduledTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		scheduled, err := q.GetScheduledTransferForUpdate(ctx, arg.ScheduledTransferID)
+		if err != nil {
+			return err
+		}
+		result.ScheduledTransfer = scheduled
+
+		if scheduled.Status != ScheduledTransferActive || !scheduled.NextRunAt.Valid || !scheduled.NextRunAt.Time.Equal(arg.ScheduledAt) {
+			return nil
+		}
+
+		recurrence, err := scheduled.Recurrence()
+		if err != nil {
+			return err
+		}
+
+		runArg := CreateScheduledTransferRunParams{
+			ScheduledTransferID: scheduled.ID,
+			ScheduledAt:         scheduled.NextRunAt,
+			Status:              ScheduledTransferRunSucceeded,
+		}
+
+		var transfer TransferTxResult
+		err = checkScheduledTransferRun(ctx, q, scheduled, arg.MFAThresholds)
+		if err == nil {
+			// the scheduled transfer only links accounts of the same currency, so no exchange rate is needed
+			transfer, err = transferTx(ctx, q, TransferTxParams{
+				FromAccountID: scheduled.FromAccountID,
+				ToAccountID:   scheduled.ToAccountID,
+				Amount:        scheduled.Amount,
+			})
+		}
+		switch {
+		case err == nil:
+			result.Transfer = transfer
+			runArg.TransferID = &transfer.Transfer.ID
+		case isScheduledTransferRunFailure(err):
+			// the transfer is rejected before it writes anything, so the database transaction can go on
+			failureReason := err.Error()
+			runArg.Status = ScheduledTransferRunFailed
+			runArg.FailureReason = &failureReason
+		default:
+			return err
+		}
+
+		result.Run, err = q.CreateScheduledTransferRun(ctx, runArg)
+		if err != nil {
+			return err
+		}
+
+		advanceArg := AdvanceScheduledTransferParams{
+			ID:     scheduled.ID,
+			Status: ScheduledTransferActive,
+		}
+		next, ok := recurrence.Next(arg.ScheduledAt, arg.Now, scheduled.Occurrences+1)
+		if ok {
+			advanceArg.NextRunAt = pgtype.Timestamptz{Time: next, Valid: true}
+		} else {
+			advanceArg.Status = ScheduledTransferCompleted
+		}
+
+		result.ScheduledTransfer, err = q.AdvanceScheduledTransfer(ctx, advanceArg)
+		if err != nil {
+			return err
+		}
+
+		result.Executed = true
+		return nil
+	})
+
+	return result, err
+}
+
+// checkScheduledTransferRun checks what the owner would have to pass to make the transfer now,
+// since their account may have changed after the transfer was scheduled:
+// the email address must still be verified, and an amount above the current threshold of the currency
+// must have been authorized with a TOTP code if the owner enabled two-factor authentication
+func checkScheduledTransferRun(ctx context.Context, q *Queries, scheduled ScheduledTransfer, thresholds mfa.TransferThresholds) error {
+	owner, err := q.GetUser(ctx, scheduled.Owner)
+	if err != nil {
+		return err
+	}
+	if !owner.IsEmailVerified {
+		return ErrEmailNotVerified
+	}
+
+	fromAccount, err := q.GetAccount(ctx, scheduled.FromAccountID)
+	if err != nil {
+		return err
+	}
+
+	threshold, exceeds := thresholds.Exceeds(fromAccount.Currency, scheduled.Amount)
+	if !exceeds {
+		return nil
+	}
+	if scheduled.MfaAuthorizedAmount != nil && scheduled.Amount <= *scheduled.MfaAuthorizedAmount {
+		return nil
+	}
+
+	userMFA, err := q.GetUserMFA(ctx, scheduled.Owner)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if !userMFA.IsEnabled {
+		return nil
+	}
+	return fmt.Errorf("%w for transfers above %d %s, update the scheduled transfer with one", ErrTOTPCodeRequired, threshold, fromAccount.Currency)
+}
+
+// isScheduledTransferRunFailure reports whether the transfer of a run was rejected
+// for a reason that retrying the run wouldn't fix
+func isScheduledTransferRunFailure(err error) bool {
+	return errors.Is(err, ErrInsufficientFunds) ||
+		errors.Is(err, ErrAccountFrozen) ||
+		errors.Is(err, ErrExchangeRateMismatch) ||
+		errors.Is(err, ErrEmailNotVerified) ||
+		errors.Is(err, ErrTOTPCodeRequired)
+}
+
+// UpdateScheduledTransferTxParams contains the input parameters of the update scheduled transfer transaction.
+// Update is called with the locked scheduled transfer and returns its new values,
+// so they can't be based on a state that a concurrent run has just changed.
+// The scheduled transfer isn't updated if Update returns an error
+type UpdateScheduledTransferTxParams struct {
+	ID     int64
+	Update func(scheduled ScheduledTransfer) (UpdateScheduledTransferParams, error)
+}
+
+// UpdateScheduledTransferTx updates a scheduled transfer with the values returned by arg.Update.
+// It returns pgx.ErrNoRows if the scheduled transfer doesn't exist
+func (store *SQLStore) UpdateScheduledTransferTx(ctx context.Context, arg UpdateScheduledTransferTxParams) (ScheduledTransfer, error) {
+	var result ScheduledTransfer
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		scheduled, err := q.GetScheduledTransferForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		updateArg, err := arg.Update(scheduled)
+		if err != nil {
+			return err
+		}
+		updateArg.ID = scheduled.ID
+
+		result, err = q.UpdateScheduledTransfer(ctx, updateArg)
+		return err
+	})
+
+	return result, err
+}
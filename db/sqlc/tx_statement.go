@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Directions to filter account entries by
+const (
+	EntryDirectionCredit = "credit"
+	EntryDirectionDebit  = "debit"
+)
+
+// AccountStatementTxParams contains the input parameters of the account statement transaction.
+// FromTime and ToTime are optional, and so is Direction, which is either EntryDirectionCredit or EntryDirectionDebit
+type AccountStatementTxParams struct {
+	AccountID int64              `json:"account_id"`
+	FromTime  pgtype.Timestamptz `json:"from_time"`
+	ToTime    pgtype.Timestamptz `json:"to_time"`
+	Direction *string            `json:"direction"`
+	// AfterID is the keyset cursor: only entries with a greater ID are returned
+	AfterID  int64 `json:"after_id"`
+	PageSize int32 `json:"page_size"`
+}
+
+// AccountStatementTxResult is a page of an account statement
+type AccountStatementTxResult struct {
+	// OpeningBalance and ClosingBalance are the account balance at FromTime and ToTime
+	OpeningBalance int64                   `json:"opening_balance"`
+	ClosingBalance int64                   `json:"closing_balance"`
+	Entries        []ListAccountEntriesRow `json:"entries"`
+	// NextAfterID is the cursor of the next page, or 0 if this page is the last one
+	NextAfterID int64 `json:"next_after_id"`
+}
+
+// CounterpartyAccountID returns the other account of the transfer that created the entry,
+// or 0 if the entry wasn't created by a transfer
+func (entry ListAccountEntriesRow) CounterpartyAccountID() int64 {
+	if entry.FromAccountID == nil || entry.ToAccountID == nil {
+		return 0
+	}
+	if *entry.FromAccountID == entry.AccountID {
+		return *entry.ToAccountID
+	}
+	return *entry.FromAccountID
+}
+
+// AccountStatementTx gets a page of the account entries with their running balance,
+// along with the balance summary of the statement period.
+// Both are read from the same snapshot, so they always agree with each other
+func (store *SQLStore) AccountStatementTx(ctx context.Context, arg AccountStatementTxParams) (AccountStatementTxResult, error) {
+	var result AccountStatementTxResult
+
+	err := store.execReadOnlyTx(ctx, func(q *Queries) error {
+		summary, err := q.GetAccountStatementSummary(ctx, GetAccountStatementSummaryParams{
+			FromTime:  arg.FromTime,
+			ToTime:    arg.ToTime,
+			AccountID: arg.AccountID,
+		})
+		if err != nil {
+			return err
+		}
+		result.OpeningBalance = summary.OpeningBalance
+		result.ClosingBalance = summary.ClosingBalance
+
+		result.Entries, err = q.ListAccountEntries(ctx, ListAccountEntriesParams{
+			AccountID: arg.AccountID,
+			AfterID:   arg.AfterID,
+			FromTime:  arg.FromTime,
+			ToTime:    arg.ToTime,
+			Direction: arg.Direction,
+			PageSize:  arg.PageSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(result.Entries) > 0 && len(result.Entries) == int(arg.PageSize) {
+			result.NextAfterID = result.Entries[len(result.Entries)-1].ID
+		}
+		return nil
+	})
+
+	return result, err
+}
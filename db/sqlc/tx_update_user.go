@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+)
+
+// UpdateUserTxParams contains the input parameters of the update user transaction.
+// AfterEmailChange is called with the updated user before the transaction commits
+// when the email address changes, and the user isn't updated if it returns an error
+type UpdateUserTxParams struct {
+	UpdateUserParams
+	AfterEmailChange func(user User) error
+}
+
+// UpdateUserTx updates the user, and when the password changes, adds the replaced password
+// to the user's password history in a single transaction.
+// When the email address changes, it is no longer verified and AfterEmailChange is called
+func (store *SQLStore) UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		if arg.HashedPassword != nil {
+			err = q.ArchiveUserPassword(ctx, arg.Username)
+			if err != nil {
+				return err
+			}
+		}
+
+		emailChanged := false
+		if arg.Email != nil {
+			user, err = q.GetUser(ctx, arg.Username)
+			if err != nil {
+				return err
+			}
+
+			emailChanged = user.Email != *arg.Email
+			if emailChanged {
+				isEmailVerified := false
+				arg.IsEmailVerified = &isEmailVerified
+			}
+		}
+
+		user, err = q.UpdateUser(ctx, arg.UpdateUserParams)
+		if err != nil {
+			return err
+		}
+
+		if !emailChanged || arg.AfterEmailChange == nil {
+			return nil
+		}
+		return arg.AfterEmailChange(user)
+	})
+
+	return user, err
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrVerifyEmailOutdated is returned when the user's email changed after the verification code was sent
+var ErrVerifyEmailOutdated = errors.New("verification code was sent to a different email address")
+
+// VerifyEmailTxParams contains the input parameters of the verify email transaction
+type VerifyEmailTxParams struct {
+	EmailID    int64
+	SecretCode string
+}
+
+// VerifyEmailTxResult is the result of the verify email transaction
+type VerifyEmailTxResult struct {
+	User        User
+	VerifyEmail VerifyEmail
+}
+
+// VerifyEmailTx marks the verification code used and the user's email verified in a single transaction.
+// It returns pgx.ErrNoRows if the code doesn't exist, was already used or has expired
+func (store *SQLStore) VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (VerifyEmailTxResult, error) {
+	var result VerifyEmailTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.VerifyEmail, err = q.UpdateVerifyEmail(ctx, UpdateVerifyEmailParams{
+			ID:         arg.EmailID,
+			SecretCode: arg.SecretCode,
+		})
+		if err != nil {
+			return err
+		}
+
+		isEmailVerified := true
+		result.User, err = q.UpdateUser(ctx, UpdateUserParams{
+			Username:        result.VerifyEmail.Username,
+			IsEmailVerified: &isEmailVerified,
+		})
+		if err != nil {
+			return err
+		}
+
+		if result.User.Email != result.VerifyEmail.Email {
+			return ErrVerifyEmailOutdated
+		}
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomUserMFA(t *testing.T, user User) UserMfa {
+	arg := SetupUserMFAParams{
+		Username:        user.Username,
+		EncryptedSecret: []byte(util.RandomString(48)),
+	}
+
+	userMFA, err := testQueries.SetupUserMFA(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, userMFA)
+
+	require.Equal(t, arg.Username, userMFA.Username)
+	require.Equal(t, arg.EncryptedSecret, userMFA.EncryptedSecret)
+	require.False(t, userMFA.IsEnabled)
+	require.Zero(t, userMFA.LastUsedStep)
+	require.NotZero(t, userMFA.CreatedAt)
+	require.False(t, userMFA.EnabledAt.Valid)
+
+	return userMFA
+}
+
+func TestSetupUserMFA(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomUserMFA(t, user)
+
+	// a pending enrollment can be restarted with a new secret
+	userMFA := createRandomUserMFA(t, user)
+
+	_, err := testQueries.EnableUserMFA(context.Background(), EnableUserMFAParams{
+		Username:     user.Username,
+		LastUsedStep: 100,
+	})
+	require.NoError(t, err)
+
+	// an enabled secret cannot be replaced
+	_, err = testQueries.SetupUserMFA(context.Background(), SetupUserMFAParams{
+		Username:        user.Username,
+		EncryptedSecret: []byte(util.RandomString(48)),
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	got, err := testQueries.GetUserMFA(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, userMFA.EncryptedSecret, got.EncryptedSecret)
+	require.True(t, got.IsEnabled)
+	require.True(t, got.EnabledAt.Valid)
+}
+
+func TestUseUserMFAStep(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomUserMFA(t, user)
+
+	arg := UseUserMFAStepParams{
+		Username:     user.Username,
+		LastUsedStep: 101,
+	}
+
+	// codes are only accepted once MFA is enabled
+	rows, err := testQueries.UseUserMFAStep(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	_, err = testQueries.EnableUserMFA(context.Background(), EnableUserMFAParams{
+		Username:     user.Username,
+		LastUsedStep: 100,
+	})
+	require.NoError(t, err)
+
+	rows, err = testQueries.UseUserMFAStep(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	// the code of a time step cannot be used twice, nor can an older one
+	rows, err = testQueries.UseUserMFAStep(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	arg.LastUsedStep = 100
+	rows, err = testQueries.UseUserMFAStep(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+}
+
+func TestUseMFARecoveryCode(t *testing.T) {
+	user := createRandomUser(t)
+	hashedCodes := []string{util.RandomString(64), util.RandomString(64)}
+
+	rows, err := testQueries.CreateMFARecoveryCodes(context.Background(), CreateMFARecoveryCodesParams{
+		Username:    user.Username,
+		HashedCodes: hashedCodes,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(len(hashedCodes)), rows)
+
+	arg := UseMFARecoveryCodeParams{
+		Username:   user.Username,
+		HashedCode: hashedCodes[0],
+	}
+
+	rows, err = testQueries.UseMFARecoveryCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	// a recovery code can only be used once
+	rows, err = testQueries.UseMFARecoveryCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	// nor by another user
+	rows, err = testQueries.UseMFARecoveryCode(context.Background(), UseMFARecoveryCodeParams{
+		Username:   createRandomUser(t).Username,
+		HashedCode: hashedCodes[1],
+	})
+	require.NoError(t, err)
+	require.Zero(t, rows)
+}
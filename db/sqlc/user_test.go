@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 
 	"github.com/stretchr/testify/require"
@@ -31,6 +32,21 @@ func createRandomUser(t *testing.T) User {
 	require.Equal(t, arg.Email, user.Email)
 	require.True(t, user.PasswordChangedAt.Time.IsZero())
 	require.NotZero(t, user.CreatedAt)
+	require.False(t, user.IsEmailVerified)
+	require.Equal(t, util.DepositorRole, user.Role)
+
+	return user
+}
+
+// verifyTestUserEmail marks the email address of the user verified, as VerifyEmailTx would
+func verifyTestUserEmail(t *testing.T, username string) User {
+	isEmailVerified := true
+	user, err := testQueries.UpdateUser(context.Background(), UpdateUserParams{
+		Username:        username,
+		IsEmailVerified: &isEmailVerified,
+	})
+	require.NoError(t, err)
+	require.True(t, user.IsEmailVerified)
 
 	return user
 }
@@ -53,6 +69,46 @@ func TestGetUser(t *testing.T) {
 	require.WithinDuration(t, user1.CreatedAt.Time, user2.CreatedAt.Time, time.Second)
 }
 
+func TestGetUserByEmail(t *testing.T) {
+	user1 := createRandomUser(t)
+	user2, err := testQueries.GetUserByEmail(context.Background(), user1.Email)
+	require.NoError(t, err)
+	require.Equal(t, user1.Username, user2.Username)
+	require.Equal(t, user1.Email, user2.Email)
+
+	_, err = testQueries.GetUserByEmail(context.Background(), util.RandomEmail())
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestRehashUserPassword(t *testing.T) {
+	user := createRandomUser(t)
+
+	newHashedPassword, err := util.HashPassword(util.RandomString(6))
+	require.NoError(t, err)
+
+	// the hash is only replaced if it didn't change in the meantime
+	rows, err := testQueries.RehashUserPassword(context.Background(), RehashUserPasswordParams{
+		NewHashedPassword: newHashedPassword,
+		Username:          user.Username,
+		OldHashedPassword: util.RandomString(60),
+	})
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	rows, err = testQueries.RehashUserPassword(context.Background(), RehashUserPasswordParams{
+		NewHashedPassword: newHashedPassword,
+		Username:          user.Username,
+		OldHashedPassword: user.HashedPassword,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	updated, err := testQueries.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, newHashedPassword, updated.HashedPassword)
+	require.WithinDuration(t, user.PasswordChangedAt.Time, updated.PasswordChangedAt.Time, time.Second)
+}
+
 func TestUpdateUserFullNameOnly(t *testing.T) {
 	user := createRandomUser(t)
 	newValue := util.RandomOwner()
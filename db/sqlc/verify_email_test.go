@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomVerifyEmail(t *testing.T, user User) VerifyEmail {
+	arg := CreateVerifyEmailParams{
+		Username:   user.Username,
+		Email:      user.Email,
+		SecretCode: util.RandomString(32),
+	}
+
+	verifyEmail, err := testQueries.CreateVerifyEmail(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, verifyEmail)
+
+	require.Equal(t, arg.Username, verifyEmail.Username)
+	require.Equal(t, arg.Email, verifyEmail.Email)
+	require.Equal(t, arg.SecretCode, verifyEmail.SecretCode)
+	require.False(t, verifyEmail.IsUsed)
+	require.NotZero(t, verifyEmail.CreatedAt)
+	require.True(t, verifyEmail.ExpiredAt.Time.After(verifyEmail.CreatedAt.Time))
+
+	return verifyEmail
+}
+
+func TestCreateVerifyEmail(t *testing.T) {
+	createRandomVerifyEmail(t, createRandomUser(t))
+}
+
+func TestUpdateVerifyEmail(t *testing.T) {
+	verifyEmail := createRandomVerifyEmail(t, createRandomUser(t))
+
+	arg := UpdateVerifyEmailParams{
+		ID:         verifyEmail.ID,
+		SecretCode: util.RandomString(32),
+	}
+
+	_, err := testQueries.UpdateVerifyEmail(context.Background(), arg)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	arg.SecretCode = verifyEmail.SecretCode
+	updated, err := testQueries.UpdateVerifyEmail(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, updated.IsUsed)
+
+	// a code can only be used once
+	_, err = testQueries.UpdateVerifyEmail(context.Background(), arg)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
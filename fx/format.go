@@ -0,0 +1,32 @@
+package fx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FormatAmount formats an amount in the minor unit of the currency as a decimal number
+// in its major unit, e.g. 12345 USD is "123.45"
+func FormatAmount(amount int64, currency string) (string, error) {
+	units, ok := minorUnits[currency]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+	}
+
+	sign := ""
+	digits := strconv.FormatUint(uint64(amount), 10)
+	if amount < 0 {
+		sign = "-"
+		digits = strconv.FormatUint(uint64(-(amount+1))+1, 10)
+	}
+
+	if units == 0 {
+		return sign + digits, nil
+	}
+
+	if len(digits) <= units {
+		digits = strings.Repeat("0", units-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-units] + "." + digits[len(digits)-units:], nil
+}
@@ -0,0 +1,35 @@
+package fx
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatAmount(t *testing.T) {
+	testCases := []struct {
+		amount   int64
+		currency string
+		expected string
+	}{
+		{12345, util.USD, "123.45"},
+		{5, util.EUR, "0.05"},
+		{0, util.CAD, "0.00"},
+		{-1050, util.USD, "-10.50"},
+		{-7, util.USD, "-0.07"},
+		{25400, util.VND, "25400"},
+		{-25400, util.VND, "-25400"},
+		{math.MinInt64, util.USD, "-92233720368547758.08"},
+	}
+
+	for _, tc := range testCases {
+		formatted, err := FormatAmount(tc.amount, tc.currency)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, formatted)
+	}
+
+	_, err := FormatAmount(100, "XYZ")
+	require.ErrorIs(t, err, ErrUnsupportedCurrency)
+}
@@ -0,0 +1,80 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const httpProviderTimeout = 5 * time.Second
+
+// HTTPProvider fetches exchange rates from a rates API that answers
+// GET <url>?base=USD&symbols=EUR with {"base": "USD", "rates": {"EUR": 0.92}}
+type HTTPProvider struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewHTTPProvider creates a provider for the rates API at baseURL.
+// If client is nil, a client with a short timeout is used
+func NewHTTPProvider(baseURL string, client *http.Client) (*HTTPProvider, error) {
+	if _, err := url.ParseRequestURI(baseURL); err != nil {
+		return nil, fmt.Errorf("invalid rates API url: %w", err)
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: httpProviderTimeout}
+	}
+
+	return &HTTPProvider{
+		client:  client,
+		baseURL: baseURL,
+	}, nil
+}
+
+func (provider *HTTPProvider) GetRate(ctx context.Context, from string, to string) (Rate, error) {
+	query := url.Values{}
+	query.Set("base", from)
+	query.Set("symbols", to)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, provider.baseURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return Rate{}, fmt.Errorf("cannot create rates request: %w", err)
+	}
+
+	rsp, err := provider.client.Do(req)
+	if err != nil {
+		return Rate{}, fmt.Errorf("cannot fetch exchange rate: %w", err)
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return Rate{}, fmt.Errorf("cannot fetch exchange rate: unexpected status %s", rsp.Status)
+	}
+
+	var table ratesTable
+	decoder := json.NewDecoder(rsp.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&table); err != nil {
+		return Rate{}, fmt.Errorf("cannot decode exchange rate: %w", err)
+	}
+
+	if table.Base != from {
+		return Rate{}, fmt.Errorf("%w: %s/%s", ErrRateNotFound, from, to)
+	}
+
+	value, ok := table.Rates[to]
+	if !ok {
+		return Rate{}, fmt.Errorf("%w: %s/%s", ErrRateNotFound, from, to)
+	}
+
+	rate, ok := new(big.Rat).SetString(value.String())
+	if !ok {
+		return Rate{}, fmt.Errorf("invalid %s/%s exchange rate: %s", from, to, value)
+	}
+	return NewRate(from, to, rate)
+}
@@ -0,0 +1,49 @@
+package fx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		base := r.URL.Query().Get("base")
+		symbol := r.URL.Query().Get("symbols")
+		if base != util.USD || symbol != util.EUR {
+			fmt.Fprintf(w, `{"base": %q, "rates": {}}`, base)
+			return
+		}
+		fmt.Fprint(w, `{"base": "USD", "rates": {"EUR": 0.92}}`)
+	}))
+	defer server.Close()
+
+	provider, err := NewHTTPProvider(server.URL, server.Client())
+	require.NoError(t, err)
+
+	rate, err := provider.GetRate(context.Background(), util.USD, util.EUR)
+	require.NoError(t, err)
+	require.Equal(t, "0.9200000000", rate.String())
+
+	_, err = provider.GetRate(context.Background(), util.USD, util.VND)
+	require.ErrorIs(t, err, ErrRateNotFound)
+}
+
+func TestHTTPProviderUnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	provider, err := NewHTTPProvider(server.URL, server.Client())
+	require.NoError(t, err)
+
+	_, err = provider.GetRate(context.Background(), util.USD, util.EUR)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrRateNotFound)
+}
@@ -0,0 +1,26 @@
+package fx
+
+import (
+	"fmt"
+
+	"github.com/hykura1501/simple_bank/util"
+)
+
+const (
+	ProviderFile = "file"
+	ProviderHTTP = "http"
+)
+
+// NewRateProvider builds the rate provider selected by FX_RATE_PROVIDER.
+// When no provider is configured, only same-currency transfers are possible
+func NewRateProvider(config util.Config) (RateProvider, error) {
+	switch config.FXRateProvider {
+	case "":
+		return NewStaticProvider("", nil)
+	case ProviderFile:
+		return NewFileProvider(config.FXRatesFile)
+	case ProviderHTTP:
+		return NewHTTPProvider(config.FXRateURL, nil)
+	}
+	return nil, fmt.Errorf("unsupported exchange rate provider: %s", config.FXRateProvider)
+}
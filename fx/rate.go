@@ -0,0 +1,109 @@
+// Package fx converts money between the currencies supported by the bank.
+//
+// Amounts are always expressed in the minor unit of their currency (e.g. cents for USD),
+// while exchange rates are quoted per major unit, the way rate providers publish them.
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/hykura1501/simple_bank/util"
+)
+
+// RateScale is the number of decimal places exchange rates are rounded to,
+// so the rate used for a conversion can be stored exactly
+const RateScale = 10
+
+var (
+	ErrRateNotFound        = errors.New("exchange rate not found")
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrAmountTooSmall      = errors.New("amount is too small to be converted")
+	ErrAmountTooLarge      = errors.New("converted amount is too large")
+)
+
+// minorUnits is the number of decimal places of each currency's minor unit
+var minorUnits = map[string]int{
+	util.USD: 2,
+	util.EUR: 2,
+	util.CAD: 2,
+	util.VND: 0,
+}
+
+// RateProvider returns the exchange rate to convert money from one currency to another
+type RateProvider interface {
+	GetRate(ctx context.Context, from string, to string) (Rate, error)
+}
+
+// Rate is the price of one major unit of From in major units of To
+type Rate struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Value *big.Rat `json:"value"`
+}
+
+// NewRate creates a rate rounded to RateScale decimal places
+func NewRate(from string, to string, value *big.Rat) (Rate, error) {
+	if value == nil || value.Sign() <= 0 {
+		return Rate{}, fmt.Errorf("invalid %s/%s exchange rate: must be positive", from, to)
+	}
+
+	scale := new(big.Rat).SetInt(pow10(RateScale))
+	scaled := roundHalfEven(new(big.Rat).Mul(value, scale))
+	rounded := new(big.Rat).SetFrac(scaled, pow10(RateScale))
+	if rounded.Sign() <= 0 {
+		return Rate{}, fmt.Errorf("invalid %s/%s exchange rate: too small", from, to)
+	}
+
+	return Rate{
+		From:  from,
+		To:    to,
+		Value: rounded,
+	}, nil
+}
+
+// String returns the rate as a decimal number with RateScale decimal places
+func (rate Rate) String() string {
+	return rate.Value.FloatString(RateScale)
+}
+
+// Convert converts an amount in the minor unit of rate.From into the minor unit of rate.To
+func Convert(amount int64, rate Rate, mode RoundingMode) (int64, error) {
+	fromUnits, ok := minorUnits[rate.From]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, rate.From)
+	}
+
+	toUnits, ok := minorUnits[rate.To]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, rate.To)
+	}
+
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(amount), rate.Value)
+	if toUnits > fromUnits {
+		value.Mul(value, new(big.Rat).SetInt(pow10(toUnits-fromUnits)))
+	} else if toUnits < fromUnits {
+		value.Quo(value, new(big.Rat).SetInt(pow10(fromUnits-toUnits)))
+	}
+
+	converted, err := mode.round(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if !converted.IsInt64() {
+		return 0, ErrAmountTooLarge
+	}
+
+	result := converted.Int64()
+	if amount > 0 && result <= 0 {
+		return 0, ErrAmountTooSmall
+	}
+	return result, nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
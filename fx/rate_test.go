@@ -0,0 +1,128 @@
+package fx
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRate(t *testing.T, from string, to string, value string) Rate {
+	ratio, ok := new(big.Rat).SetString(value)
+	require.True(t, ok)
+
+	rate, err := NewRate(from, to, ratio)
+	require.NoError(t, err)
+	return rate
+}
+
+func TestNewRate(t *testing.T) {
+	rate := newTestRate(t, util.EUR, util.USD, "1.086956521739130434")
+	require.Equal(t, "1.0869565217", rate.String())
+
+	_, err := NewRate(util.USD, util.EUR, big.NewRat(0, 1))
+	require.Error(t, err)
+
+	_, err = NewRate(util.USD, util.EUR, big.NewRat(1, 1e12))
+	require.Error(t, err)
+
+	_, err = NewRate(util.USD, util.EUR, nil)
+	require.Error(t, err)
+}
+
+func TestConvert(t *testing.T) {
+	testCases := []struct {
+		name     string
+		amount   int64
+		rate     Rate
+		mode     RoundingMode
+		expected int64
+	}{
+		{
+			name:     "SameMinorUnit",
+			amount:   1000,
+			rate:     newTestRate(t, util.USD, util.EUR, "0.92"),
+			mode:     RoundHalfEven,
+			expected: 920,
+		},
+		{
+			name:     "MoreMinorUnits",
+			amount:   150,
+			rate:     newTestRate(t, util.USD, util.VND, "25400"),
+			mode:     RoundHalfEven,
+			expected: 38100,
+		},
+		{
+			name:     "FewerMinorUnits",
+			amount:   25400,
+			rate:     newTestRate(t, util.VND, util.USD, "0.0000393700787"),
+			mode:     RoundHalfEven,
+			expected: 100,
+		},
+		{
+			name:     "HalfEvenTieToEven",
+			amount:   25,
+			rate:     newTestRate(t, util.USD, util.EUR, "0.5"),
+			mode:     RoundHalfEven,
+			expected: 12,
+		},
+		{
+			name:     "HalfEvenTieToEvenUp",
+			amount:   15,
+			rate:     newTestRate(t, util.USD, util.EUR, "0.5"),
+			mode:     RoundHalfEven,
+			expected: 8,
+		},
+		{
+			name:     "HalfUpTie",
+			amount:   25,
+			rate:     newTestRate(t, util.USD, util.EUR, "0.5"),
+			mode:     RoundHalfUp,
+			expected: 13,
+		},
+		{
+			name:     "DownTruncates",
+			amount:   29,
+			rate:     newTestRate(t, util.USD, util.EUR, "0.5"),
+			mode:     RoundDown,
+			expected: 14,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			converted, err := Convert(tc.amount, tc.rate, tc.mode)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, converted)
+		})
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	_, err := Convert(1, newTestRate(t, util.USD, util.EUR, "0.1"), RoundHalfEven)
+	require.ErrorIs(t, err, ErrAmountTooSmall)
+
+	_, err = Convert(math.MaxInt64, newTestRate(t, util.USD, util.VND, "25400"), RoundHalfEven)
+	require.ErrorIs(t, err, ErrAmountTooLarge)
+
+	_, err = Convert(100, newTestRate(t, "XYZ", util.EUR, "1"), RoundHalfEven)
+	require.ErrorIs(t, err, ErrUnsupportedCurrency)
+
+	_, err = Convert(100, newTestRate(t, util.USD, util.EUR, "1"), RoundingMode("up"))
+	require.Error(t, err)
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	mode, err := ParseRoundingMode("")
+	require.NoError(t, err)
+	require.Equal(t, DefaultRoundingMode, mode)
+
+	mode, err = ParseRoundingMode("half_up")
+	require.NoError(t, err)
+	require.Equal(t, RoundHalfUp, mode)
+
+	_, err = ParseRoundingMode("ceiling")
+	require.Error(t, err)
+}
@@ -0,0 +1,61 @@
+package fx
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// RoundingMode decides how a converted amount is rounded to a whole minor unit
+type RoundingMode string
+
+const (
+	// RoundHalfEven rounds to the nearest minor unit, ties to the even one (banker's rounding)
+	RoundHalfEven RoundingMode = "half_even"
+	// RoundHalfUp rounds to the nearest minor unit, ties away from zero
+	RoundHalfUp RoundingMode = "half_up"
+	// RoundDown truncates towards zero
+	RoundDown RoundingMode = "down"
+)
+
+// DefaultRoundingMode is used when no rounding mode is configured
+const DefaultRoundingMode = RoundHalfEven
+
+// ParseRoundingMode parses a configured rounding mode. An empty string means DefaultRoundingMode
+func ParseRoundingMode(mode string) (RoundingMode, error) {
+	switch RoundingMode(mode) {
+	case "":
+		return DefaultRoundingMode, nil
+	case RoundHalfEven, RoundHalfUp, RoundDown:
+		return RoundingMode(mode), nil
+	}
+	return "", fmt.Errorf("unsupported rounding mode: %s", mode)
+}
+
+func (mode RoundingMode) round(value *big.Rat) (*big.Int, error) {
+	switch mode {
+	case RoundHalfEven:
+		return roundHalfEven(value), nil
+	case RoundHalfUp:
+		return roundHalfUp(value), nil
+	case RoundDown:
+		return new(big.Int).Quo(value.Num(), value.Denom()), nil
+	}
+	return nil, fmt.Errorf("unsupported rounding mode: %s", mode)
+}
+
+func roundHalfEven(value *big.Rat) *big.Int {
+	quo, rem := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+	cmp := new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(value.Denom())
+	if cmp > 0 || (cmp == 0 && quo.Bit(0) == 1) {
+		quo.Add(quo, big.NewInt(int64(value.Sign())))
+	}
+	return quo
+}
+
+func roundHalfUp(value *big.Rat) *big.Int {
+	quo, rem := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(value.Denom()) >= 0 {
+		quo.Add(quo, big.NewInt(int64(value.Sign())))
+	}
+	return quo
+}
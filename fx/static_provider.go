@@ -0,0 +1,74 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// ratesTable is the JSON format of a rates file, and of the HTTP provider's responses:
+// the value of one unit of Base in every other currency
+type ratesTable struct {
+	Base  string                 `json:"base"`
+	Rates map[string]json.Number `json:"rates"`
+}
+
+// StaticProvider serves exchange rates from a fixed table quoted against a base currency.
+// Rates between two non-base currencies are derived from their rates against the base
+type StaticProvider struct {
+	base  string
+	rates map[string]*big.Rat
+}
+
+// NewStaticProvider creates a provider from the value of one unit of base in every other currency
+func NewStaticProvider(base string, rates map[string]string) (*StaticProvider, error) {
+	provider := &StaticProvider{
+		base:  base,
+		rates: map[string]*big.Rat{base: big.NewRat(1, 1)},
+	}
+
+	for currency, value := range rates {
+		rate, ok := new(big.Rat).SetString(value)
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid %s/%s exchange rate: %s", base, currency, value)
+		}
+		provider.rates[currency] = rate
+	}
+	return provider, nil
+}
+
+// NewFileProvider creates a static provider from a JSON rates file such as
+// {"base": "USD", "rates": {"EUR": "0.92", "VND": "25400"}}
+func NewFileProvider(path string) (*StaticProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read rates file: %w", err)
+	}
+
+	var table ratesTable
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("cannot parse rates file: %w", err)
+	}
+
+	rates := make(map[string]string, len(table.Rates))
+	for currency, value := range table.Rates {
+		rates[currency] = value.String()
+	}
+	return NewStaticProvider(table.Base, rates)
+}
+
+func (provider *StaticProvider) GetRate(ctx context.Context, from string, to string) (Rate, error) {
+	fromRate, ok := provider.rates[from]
+	if !ok {
+		return Rate{}, fmt.Errorf("%w: %s/%s", ErrRateNotFound, from, to)
+	}
+
+	toRate, ok := provider.rates[to]
+	if !ok {
+		return Rate{}, fmt.Errorf("%w: %s/%s", ErrRateNotFound, from, to)
+	}
+
+	return NewRate(from, to, new(big.Rat).Quo(toRate, fromRate))
+}
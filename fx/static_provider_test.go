@@ -0,0 +1,55 @@
+package fx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticProvider(t *testing.T) {
+	provider, err := NewStaticProvider(util.USD, map[string]string{
+		util.EUR: "0.8",
+		util.VND: "25000",
+	})
+	require.NoError(t, err)
+
+	rate, err := provider.GetRate(context.Background(), util.USD, util.EUR)
+	require.NoError(t, err)
+	require.Equal(t, util.USD, rate.From)
+	require.Equal(t, util.EUR, rate.To)
+	require.Equal(t, "0.8000000000", rate.String())
+
+	rate, err = provider.GetRate(context.Background(), util.EUR, util.USD)
+	require.NoError(t, err)
+	require.Equal(t, "1.2500000000", rate.String())
+
+	rate, err = provider.GetRate(context.Background(), util.EUR, util.VND)
+	require.NoError(t, err)
+	require.Equal(t, "31250.0000000000", rate.String())
+
+	_, err = provider.GetRate(context.Background(), util.USD, util.CAD)
+	require.ErrorIs(t, err, ErrRateNotFound)
+
+	_, err = NewStaticProvider(util.USD, map[string]string{util.EUR: "-1"})
+	require.Error(t, err)
+}
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	err := os.WriteFile(path, []byte(`{"base": "USD", "rates": {"EUR": 0.92, "VND": "25400"}}`), 0o600)
+	require.NoError(t, err)
+
+	provider, err := NewFileProvider(path)
+	require.NoError(t, err)
+
+	rate, err := provider.GetRate(context.Background(), util.USD, util.VND)
+	require.NoError(t, err)
+	require.Equal(t, "25400.0000000000", rate.String())
+
+	_, err = NewFileProvider(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
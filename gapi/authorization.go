@@ -4,10 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/hykura1501/simple_bank/token"
+	"github.com/hykura1501/simple_bank/util"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -15,14 +19,57 @@ const (
 	authorizationBearer = "bearer"
 )
 
-func (server *Server) authorizeUser(ctx context.Context) (*token.Payload, error) {
+var (
+	// allRoles can access the users and accounts they own
+	allRoles = []string{util.DepositorRole, util.BankerRole, util.AdminRole}
+	// staffRoles can access any user and account
+	staffRoles = []string{util.BankerRole, util.AdminRole}
+)
+
+// authorizeUser verifies the access token of the request and checks that its role is one of accessibleRoles.
+// The returned error is a status error
+func (server *Server) authorizeUser(ctx context.Context, accessibleRoles []string) (*token.Payload, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return nil, errors.New("missing metadata")
+		return nil, unauthenticatedError(errors.New("missing metadata"))
+	}
+
+	payload, err := server.verifyAuthorizationHeader(ctx, md.Get(authorizationHeader))
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if !slices.Contains(accessibleRoles, payload.Role) {
+		return nil, status.Errorf(codes.PermissionDenied, "role %q is not allowed to perform this action", payload.Role)
+	}
+	return payload, nil
+}
+
+// roleRanks orders the roles by privilege
+var roleRanks = map[string]int{
+	util.DepositorRole: 0,
+	util.BankerRole:    1,
+	util.AdminRole:     2,
+}
+
+// outranks reports whether role has more privileges than otherRole
+func outranks(role string, otherRole string) bool {
+	rank, ok := roleRanks[role]
+	if !ok {
+		return false
 	}
+	otherRank, ok := roleRanks[otherRole]
+	return !ok || rank > otherRank
+}
 
-	values := md.Get(authorizationHeader)
+// canAccess reports whether the payload's user can access resources owned by owner
+func canAccess(payload *token.Payload, owner string) bool {
+	return payload.Username == owner || slices.Contains(staffRoles, payload.Role)
+}
 
+// verifyAuthorizationHeader verifies the bearer access token of the authorization header values
+// and checks that it wasn't revoked
+func (server *Server) verifyAuthorizationHeader(ctx context.Context, values []string) (*token.Payload, error) {
 	if len(values) == 0 {
 		return nil, errors.New("missing authorization header")
 	}
@@ -46,5 +93,13 @@ func (server *Server) authorizeUser(ctx context.Context) (*token.Payload, error)
 		return nil, fmt.Errorf("invalid access token: %s", err)
 	}
 
+	if err := payload.CheckType(token.TokenTypeAccess); err != nil {
+		return nil, fmt.Errorf("invalid access token: %s", err)
+	}
+
+	if err := server.revocations.Check(ctx, payload); err != nil {
+		return nil, fmt.Errorf("invalid access token: %s", err)
+	}
+
 	return payload, nil
 }
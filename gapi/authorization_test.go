@@ -0,0 +1,85 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hykura1501/simple_bank/token"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthorizeUser(t *testing.T) {
+	user := randomUser(util.DepositorRole)
+
+	testCases := []struct {
+		name      string
+		setupAuth func(t *testing.T, tokenMaker token.Maker) context.Context
+		roles     []string
+		code      codes.Code
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role)
+			},
+			roles: allRoles,
+			code:  codes.OK,
+		},
+		{
+			name: "NoAuthorization",
+			setupAuth: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			roles: allRoles,
+			code:  codes.Unauthenticated,
+		},
+		{
+			name: "RefreshToken",
+			setupAuth: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				refreshToken, _, err := tokenMaker.CreateToken(user.Username, user.Role, pgtype.UUID{}, token.TokenTypeRefresh, time.Minute)
+				require.NoError(t, err)
+
+				md := metadata.Pairs(authorizationHeader, fmt.Sprintf("%s %s", authorizationBearer, refreshToken))
+				return metadata.NewIncomingContext(context.Background(), md)
+			},
+			roles: allRoles,
+			code:  codes.Unauthenticated,
+		},
+		{
+			name: "ForbiddenRole",
+			setupAuth: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role)
+			},
+			roles: staffRoles,
+			code:  codes.PermissionDenied,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil, nil)
+			ctx := tc.setupAuth(t, server.tokenMaker)
+
+			payload, err := server.authorizeUser(ctx, tc.roles)
+			if tc.code == codes.OK {
+				require.NoError(t, err)
+				require.Equal(t, user.Username, payload.Username)
+				require.Equal(t, token.TokenTypeAccess, payload.Type)
+				return
+			}
+
+			require.Error(t, err)
+			st, ok := status.FromError(err)
+			require.True(t, ok)
+			require.Equal(t, tc.code, st.Code())
+			require.Nil(t, payload)
+		})
+	}
+}
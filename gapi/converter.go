@@ -3,6 +3,7 @@ package gapi
 import (
 	db "github.com/hykura1501/simple_bank/db/sqlc"
 	"github.com/hykura1501/simple_bank/pb"
+	"github.com/jackc/pgx/v5/pgtype"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -13,5 +14,120 @@ func convertUser(user db.User) *pb.User {
 		Email:             user.Email,
 		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt.Time),
 		CreatedAt:         timestamppb.New(user.CreatedAt.Time),
+		IsEmailVerified:   user.IsEmailVerified,
+		Role:              user.Role,
 	}
 }
+
+func convertAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Id:             account.ID,
+		Owner:          account.Owner,
+		Balance:        account.Balance,
+		Currency:       account.Currency,
+		CreatedAt:      timestamppb.New(account.CreatedAt.Time),
+		OverdraftLimit: account.OverdraftLimit,
+		IsFrozen:       account.IsFrozen,
+	}
+}
+
+func convertEntry(entry db.Entry) *pb.Entry {
+	return &pb.Entry{
+		Id:        entry.ID,
+		AccountId: entry.AccountID,
+		Amount:    entry.Amount,
+		CreatedAt: timestamppb.New(entry.CreatedAt.Time),
+	}
+}
+
+func convertStatementEntry(entry db.ListAccountEntriesRow) *pb.StatementEntry {
+	statementEntry := &pb.StatementEntry{
+		Id:                    entry.ID,
+		AccountId:             entry.AccountID,
+		Amount:                entry.Amount,
+		RunningBalance:        entry.RunningBalance,
+		CreatedAt:             timestamppb.New(entry.CreatedAt.Time),
+		CounterpartyAccountId: entry.CounterpartyAccountID(),
+	}
+	if entry.TransferID != nil {
+		statementEntry.TransferId = *entry.TransferID
+	}
+	return statementEntry
+}
+
+func convertTransfer(transfer db.Transfer) *pb.Transfer {
+	return &pb.Transfer{
+		Id:            transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt.Time),
+		ToAmount:      transfer.ToAmount,
+		ExchangeRate:  convertNumeric(transfer.ExchangeRate),
+		RoundingMode:  transfer.RoundingMode,
+	}
+}
+
+func convertScheduledTransfer(scheduled db.ScheduledTransfer) *pb.ScheduledTransfer {
+	pbScheduled := &pb.ScheduledTransfer{
+		Id:            scheduled.ID,
+		Owner:         scheduled.Owner,
+		FromAccountId: scheduled.FromAccountID,
+		ToAccountId:   scheduled.ToAccountID,
+		Amount:        scheduled.Amount,
+		Schedule:      scheduled.Schedule,
+		StartTime:     timestamppb.New(scheduled.StartAt.Time),
+		Occurrences:   scheduled.Occurrences,
+		Status:        scheduled.Status,
+		CreatedAt:     timestamppb.New(scheduled.CreatedAt.Time),
+		UpdatedAt:     timestamppb.New(scheduled.UpdatedAt.Time),
+	}
+	if scheduled.EndAt.Valid {
+		pbScheduled.EndTime = timestamppb.New(scheduled.EndAt.Time)
+	}
+	if scheduled.MaxOccurrences != nil {
+		pbScheduled.MaxOccurrences = *scheduled.MaxOccurrences
+	}
+	if scheduled.NextRunAt.Valid {
+		pbScheduled.NextRunTime = timestamppb.New(scheduled.NextRunAt.Time)
+	}
+	return pbScheduled
+}
+
+func convertScheduledTransferRun(run db.ScheduledTransferRun) *pb.ScheduledTransferRun {
+	pbRun := &pb.ScheduledTransferRun{
+		Id:            run.ID,
+		ScheduledTime: timestamppb.New(run.ScheduledAt.Time),
+		Status:        run.Status,
+		CreatedAt:     timestamppb.New(run.CreatedAt.Time),
+	}
+	if run.TransferID != nil {
+		pbRun.TransferId = *run.TransferID
+	}
+	if run.FailureReason != nil {
+		pbRun.FailureReason = *run.FailureReason
+	}
+	return pbRun
+}
+
+func convertSession(session db.ListActiveSessionsRow) *pb.Session {
+	return &pb.Session{
+		Id:         session.FamilyID.String(),
+		UserAgent:  session.UserAgent,
+		ClientIp:   session.ClientIp,
+		LoggedInAt: timestamppb.New(session.LoggedInAt.Time),
+		RenewedAt:  timestamppb.New(session.RenewedAt.Time),
+		ExpiresAt:  timestamppb.New(session.ExpiredAt.Time),
+	}
+}
+
+func convertNumeric(value pgtype.Numeric) string {
+	if !value.Valid {
+		return ""
+	}
+	data, err := value.MarshalJSON()
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,21 @@
+package gapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/hykura1501/simple_bank/token"
+	"github.com/rs/zerolog/log"
+)
+
+// JWKSHandler serves GET /.well-known/jwks.json, the public keys that tokens signed by the keyring are verified with.
+// A new signing key should be published as a verification key for longer than the response is cached before it is used
+func JWKSHandler(keyring *token.Keyring) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		if err := json.NewEncoder(w).Encode(keyring.JWKS()); err != nil {
+			log.Error().Err(err).Msg("fail to write jwks")
+		}
+	})
+}
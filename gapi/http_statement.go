@@ -0,0 +1,136 @@
+package gapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/hykura1501/simple_bank/statement"
+	"github.com/hykura1501/simple_bank/validation"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// StatementHandler serves GET /v1/accounts/{id}/statement?format=csv|ofx|pdf&from_time=...&to_time=...
+// to the owner of the account. The statement is streamed, as it may be too large for a gRPC response.
+// Times are RFC 3339 timestamps or dates, and to_time is exclusive
+func (server *Server) StatementHandler() http.Handler {
+	return http.HandlerFunc(server.exportStatement)
+}
+
+func (server *Server) exportStatement(w http.ResponseWriter, r *http.Request) {
+	payload, err := server.verifyAuthorizationHeader(r.Context(), r.Header.Values(authorizationHeader))
+	if err != nil {
+		writeHTTPError(w, unauthenticatedError(err))
+		return
+	}
+
+	accountID, format, fromTime, toTime, violations := parseExportStatementRequest(r)
+	if violations != nil {
+		writeHTTPError(w, invalidArgumentError(violations))
+		return
+	}
+
+	account, err := server.store.GetAccount(r.Context(), accountID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeHTTPError(w, status.Errorf(codes.NotFound, "account not found"))
+			return
+		}
+		writeHTTPError(w, status.Errorf(codes.Internal, "fail to get account: %s", err))
+		return
+	}
+
+	if !canAccess(payload, account.Owner) {
+		writeHTTPError(w, status.Errorf(codes.PermissionDenied, "account doesn't belong to the authenticated user"))
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, format.FileName(account.ID, fromTime, toTime)))
+
+	params := statement.Params{
+		Account:  account,
+		FromTime: fromTime,
+		ToTime:   toTime,
+	}
+
+	// once the first bytes are sent, the status can't be changed anymore,
+	// so a failure midway can only be logged and the response cut short
+	err = statement.Export(r.Context(), server.store, params, format, w)
+	if err != nil {
+		log.Error().Err(err).Int64("account_id", account.ID).Msg("fail to export statement")
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func parseExportStatementRequest(r *http.Request) (
+	accountID int64,
+	format statement.Format,
+	fromTime time.Time,
+	toTime time.Time,
+	violations []*errdetails.BadRequest_FieldViolation,
+) {
+	query := r.URL.Query()
+
+	accountID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err == nil {
+		err = validation.ValidateID(accountID)
+	}
+	if err != nil {
+		violations = append(violations, fieldViolation("id", errors.New("must be a positive integer")))
+	}
+
+	format, err = statement.ParseFormat(query.Get("format"))
+	if err != nil {
+		violations = append(violations, fieldViolation("format", err))
+	}
+
+	fromTime, err = parseStatementTime(query.Get("from_time"))
+	if err != nil {
+		violations = append(violations, fieldViolation("from_time", err))
+	}
+
+	toTime, err = parseStatementTime(query.Get("to_time"))
+	if err != nil {
+		violations = append(violations, fieldViolation("to_time", err))
+	} else if !fromTime.IsZero() && !toTime.IsZero() && !fromTime.Before(toTime) {
+		violations = append(violations, fieldViolation("to_time", errors.New("must be after from_time")))
+	}
+	return
+}
+
+// parseStatementTime parses an RFC 3339 timestamp or a date. An empty value is the zero time
+func parseStatementTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, errors.New("must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+}
+
+// writeHTTPError writes a status error in the same JSON format as the gateway does
+func writeHTTPError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	body, marshalErr := protojson.Marshal(st.Proto())
+	if marshalErr != nil {
+		http.Error(w, st.Message(), runtime.HTTPStatusFromCode(st.Code()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(runtime.HTTPStatusFromCode(st.Code()))
+	w.Write(body)
+}
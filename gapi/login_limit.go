@@ -0,0 +1,46 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hykura1501/simple_bank/lockout"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// errInvalidCredentials is returned for an unknown username as well as for a wrong password,
+// so that the response doesn't reveal which usernames exist
+var errInvalidCredentials = status.Error(codes.Unauthenticated, "invalid username or password")
+
+// reserveLoginAttempt counts the login as a failure until releaseLoginAttempt is called,
+// and rejects it if the username or the client IP failed to log in too many times
+func (server *Server) reserveLoginAttempt(ctx context.Context, username string, clientIP string) error {
+	err := server.loginLimiter.Reserve(ctx, username, clientIP)
+	if err == nil {
+		return nil
+	}
+
+	var lockedErr *lockout.LockedError
+	if !errors.As(err, &lockedErr) {
+		return status.Errorf(codes.Internal, "fail to check login attempts: %s", err)
+	}
+
+	st := status.New(codes.ResourceExhausted, lockedErr.Error())
+	detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(lockedErr.RetryAfter)})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// releaseLoginAttempt takes back the attempt reserved for a login whose credentials were correct,
+// or that failed for another reason, and returns rspErr, the error of the response
+func (server *Server) releaseLoginAttempt(ctx context.Context, username string, clientIP string, rspErr error) error {
+	if err := server.loginLimiter.Release(ctx, username, clientIP); err != nil {
+		return status.Errorf(codes.Internal, "fail to release login attempt: %s", err)
+	}
+	return rspErr
+}
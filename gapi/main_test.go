@@ -0,0 +1,95 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/mfa"
+	"github.com/hykura1501/simple_bank/revocation"
+	"github.com/hykura1501/simple_bank/token"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/hykura1501/simple_bank/worker"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func newTestServer(t *testing.T, store db.Store, taskDistributor worker.TaskDistributor) *Server {
+	config := util.Config{
+		TokenSymmetricKey:      util.RandomString(32),
+		AccessTokenDuration:    time.Minute,
+		IdempotencyKeyDuration: time.Hour,
+		MFAEncryptionKey:       util.RandomString(32),
+		MFAChallengeKey:        util.RandomString(32),
+		PasswordHashAlgorithm:  util.HashAlgorithmBcrypt,
+		PasswordBcryptCost:     4,
+	}
+
+	server, err := NewServer(config, store, taskDistributor, nil)
+	require.NoError(t, err)
+
+	// the revocation cache would query the mock store on every authenticated request
+	server.revocations = revocation.NewCache(noRevocations{}, time.Minute)
+	return server
+}
+
+// noRevocations is a revocation store where no token is ever revoked
+type noRevocations struct{}
+
+func (noRevocations) IsSessionRevoked(ctx context.Context, sessionID pgtype.UUID) (bool, error) {
+	return false, nil
+}
+
+func (noRevocations) RevokedBefore(ctx context.Context, username string) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func newContextWithBearerToken(t *testing.T, tokenMaker token.Maker, username string, role string) context.Context {
+	accessToken, _, err := tokenMaker.CreateToken(username, role, pgtype.UUID{}, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	md := metadata.MD{
+		authorizationHeader: []string{
+			fmt.Sprintf("%s %s", authorizationBearer, accessToken),
+		},
+	}
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func randomUser(role string) db.User {
+	return db.User{
+		Username:        util.RandomOwner(),
+		Role:            role,
+		FullName:        util.RandomOwner(),
+		Email:           util.RandomEmail(),
+		IsEmailVerified: true,
+	}
+}
+
+// randomEnabledUserMFA enables two-factor authentication for the user with a new secret sealed by the server.
+// It returns the secret, to generate the TOTP codes of the user
+func randomEnabledUserMFA(t *testing.T, server *Server, user db.User) (db.UserMfa, []byte) {
+	secret, err := mfa.GenerateSecret()
+	require.NoError(t, err)
+
+	sealed, err := server.mfaSecrets.Seal(user.Username, secret)
+	require.NoError(t, err)
+
+	return db.UserMfa{
+		Username:        user.Username,
+		EncryptedSecret: sealed,
+		IsEnabled:       true,
+	}, secret
+}
+
+func randomAccount(owner string, currency string) db.Account {
+	return db.Account{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    owner,
+		Balance:  util.RandomMoney(),
+		Currency: currency,
+	}
+}
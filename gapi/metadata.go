@@ -2,7 +2,11 @@ package gapi
 
 import (
 	"context"
+	"net"
+	"net/netip"
+	"strings"
 
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 )
@@ -11,6 +15,7 @@ const (
 	grpcgatewayUserAgentHeader = "grpcgateway-user-agent"
 	userAgent                  = "user-agent"
 	xForwardedFor              = "x-forwarded-for"
+	idempotencyKeyHeader       = "idempotency-key"
 )
 
 type Metadata struct {
@@ -18,25 +23,115 @@ type Metadata struct {
 	ClientIP  string
 }
 
-func extractMetadataFromContext(ctx context.Context) *Metadata {
+// extractMetadataFromContext reads the user agent and the client IP of the request.
+// Only the x-forwarded-for hops appended by trustedProxies are believed, see clientIP
+func extractMetadataFromContext(ctx context.Context, trustedProxies []netip.Prefix) *Metadata {
 	mtdt := &Metadata{}
-	if md, ok := metadata.FromIncomingContext(ctx); ok {
-		if userAgents := md.Get(grpcgatewayUserAgentHeader); len(userAgents) > 0 {
-			mtdt.UserAgent = userAgents[0]
-		}
+	md, _ := metadata.FromIncomingContext(ctx)
 
-		if userAgents := md.Get(userAgent); len(userAgents) > 0 {
-			mtdt.UserAgent = userAgents[0]
-		}
+	if userAgents := md.Get(grpcgatewayUserAgentHeader); len(userAgents) > 0 {
+		mtdt.UserAgent = userAgents[0]
+	}
 
-		if clientIPs := md.Get(xForwardedFor); len(clientIPs) > 0 {
-			mtdt.ClientIP = clientIPs[0]
+	if userAgents := md.Get(userAgent); len(userAgents) > 0 {
+		mtdt.UserAgent = userAgents[0]
+	}
+
+	var hops []string
+	for _, value := range md.Get(xForwardedFor) {
+		for _, hop := range strings.Split(value, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
 		}
 	}
 
+	// a gRPC client is connected directly, while the gateway appends the remote address
+	// of the HTTP request to x-forwarded-for itself
 	if p, ok := peer.FromContext(ctx); ok {
-		mtdt.ClientIP = p.Addr.String()
+		hops = append(hops, peerIP(p.Addr))
 	}
 
+	mtdt.ClientIP = clientIP(hops, trustedProxies)
 	return mtdt
 }
+
+// clientIP returns the rightmost hop that isn't a trusted proxy.
+// Every hop left of it could have been written by the client, e.g. to avoid the per-IP login lockout
+func clientIP(hops []string, trustedProxies []netip.Prefix) string {
+	for i := len(hops) - 1; i > 0; i-- {
+		if !isTrustedProxy(hops[i], trustedProxies) {
+			return hops[i]
+		}
+	}
+	if len(hops) == 0 {
+		return ""
+	}
+	return hops[0]
+}
+
+func isTrustedProxy(hop string, trustedProxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(hop)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func peerIP(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+// parseTrustedProxies parses the IP addresses and CIDR ranges of TRUSTED_PROXIES
+func parseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	var trustedProxies []netip.Prefix
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if strings.Contains(value, "/") {
+			prefix, err := netip.ParsePrefix(value)
+			if err != nil {
+				return nil, err
+			}
+			trustedProxies = append(trustedProxies, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return nil, err
+		}
+		addr = addr.Unmap()
+		trustedProxies = append(trustedProxies, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return trustedProxies, nil
+}
+
+func extractIdempotencyKeyFromContext(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if keys := md.Get(idempotencyKeyHeader); len(keys) > 0 {
+			return keys[0]
+		}
+	}
+	return ""
+}
+
+// HeaderMatcher forwards the HTTP headers the gRPC handlers rely on from the gateway into the incoming metadata
+func HeaderMatcher(key string) (string, bool) {
+	if strings.ToLower(key) == idempotencyKeyHeader {
+		return idempotencyKeyHeader, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
@@ -0,0 +1,113 @@
+package gapi
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestExtractClientIP(t *testing.T) {
+	trustedProxies, err := parseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	require.NoError(t, err)
+
+	grpcPeer := &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 51234}}
+	proxyPeer := &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 51234}}
+
+	testCases := []struct {
+		name           string
+		peer           *peer.Peer
+		xForwardedFor  []string
+		trustedProxies bool
+		clientIP       string
+	}{
+		{
+			name:     "GRPCPeer",
+			peer:     grpcPeer,
+			clientIP: "203.0.113.7",
+		},
+		{
+			name:          "GRPCPeerIgnoresForwardedFor",
+			peer:          grpcPeer,
+			xForwardedFor: []string{"198.51.100.1"},
+			clientIP:      "203.0.113.7",
+		},
+		{
+			name:          "GRPCPeerUntrustedProxy",
+			peer:          proxyPeer,
+			xForwardedFor: []string{"198.51.100.1"},
+			clientIP:      "10.0.0.2",
+		},
+		{
+			name:           "GRPCPeerTrustedProxy",
+			peer:           proxyPeer,
+			xForwardedFor:  []string{"198.51.100.1"},
+			trustedProxies: true,
+			clientIP:       "198.51.100.1",
+		},
+		{
+			name:          "Gateway",
+			xForwardedFor: []string{"198.51.100.1"},
+			clientIP:      "198.51.100.1",
+		},
+		{
+			name:          "GatewayIgnoresSpoofedHops",
+			xForwardedFor: []string{"1.1.1.1, 2.2.2.2, 198.51.100.1"},
+			clientIP:      "198.51.100.1",
+		},
+		{
+			name:           "GatewayBehindTrustedProxies",
+			xForwardedFor:  []string{"1.1.1.1, 198.51.100.1, 10.1.2.3", "192.168.1.1"},
+			trustedProxies: true,
+			clientIP:       "198.51.100.1",
+		},
+		{
+			name:           "OnlyTrustedProxies",
+			xForwardedFor:  []string{"10.1.2.3, 192.168.1.1"},
+			trustedProxies: true,
+			clientIP:       "10.1.2.3",
+		},
+		{
+			name: "NoClientIP",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.peer != nil {
+				ctx = peer.NewContext(ctx, tc.peer)
+			}
+			if tc.xForwardedFor != nil {
+				md := metadata.MD{xForwardedFor: tc.xForwardedFor}
+				ctx = metadata.NewIncomingContext(ctx, md)
+			}
+
+			proxies := trustedProxies
+			if !tc.trustedProxies {
+				proxies = nil
+			}
+
+			mtdt := extractMetadataFromContext(ctx, proxies)
+			require.Equal(t, tc.clientIP, mtdt.ClientIP)
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	trustedProxies, err := parseTrustedProxies([]string{"", "10.0.0.1/8", "::ffff:192.168.1.1", "2001:db8::/32"})
+	require.NoError(t, err)
+	require.Len(t, trustedProxies, 3)
+	require.Equal(t, "10.0.0.0/8", trustedProxies[0].String())
+	require.Equal(t, "192.168.1.1/32", trustedProxies[1].String())
+	require.Equal(t, "2001:db8::/32", trustedProxies[2].String())
+
+	_, err = parseTrustedProxies([]string{"proxy.local"})
+	require.Error(t, err)
+
+	_, err = parseTrustedProxies([]string{"10.0.0.0/33"})
+	require.Error(t, err)
+}
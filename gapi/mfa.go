@@ -0,0 +1,152 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/mfa"
+	"github.com/hykura1501/simple_bank/validation"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// enabledMFA gets the user's two-factor authentication secret.
+// It returns false if the user hasn't enabled two-factor authentication
+func (server *Server) enabledMFA(ctx context.Context, username string) (db.UserMfa, bool, error) {
+	userMFA, err := server.store.GetUserMFA(ctx, username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return userMFA, false, nil
+		}
+		return userMFA, false, status.Errorf(codes.Internal, "fail to get two-factor authentication: %s", err)
+	}
+	return userMFA, userMFA.IsEnabled, nil
+}
+
+// useTOTPCode checks a code of the user's authenticator app and records its time step,
+// so that the same code cannot be used again
+func (server *Server) useTOTPCode(ctx context.Context, userMFA db.UserMfa, code string) error {
+	step, err := server.checkTOTPCode(userMFA, code)
+	if err != nil {
+		return err
+	}
+	return server.useMFAStep(ctx, step)
+}
+
+// checkTOTPCode checks a code of the user's authenticator app without using it up.
+// It returns the time step of the code, which must be recorded once the code is used
+func (server *Server) checkTOTPCode(userMFA db.UserMfa, code string) (db.UseUserMFAStepParams, error) {
+	secret, err := server.mfaSecrets.Open(userMFA.Username, userMFA.EncryptedSecret)
+	if err != nil {
+		return db.UseUserMFAStepParams{}, status.Errorf(codes.Internal, "%s", err)
+	}
+
+	step, err := mfa.ValidateCode(secret, code, time.Now())
+	if err != nil {
+		return db.UseUserMFAStepParams{}, status.Errorf(codes.Unauthenticated, "%s", err)
+	}
+
+	return db.UseUserMFAStepParams{
+		Username:     userMFA.Username,
+		LastUsedStep: step,
+	}, nil
+}
+
+// useMFAStep records the time step of a TOTP code, so that the code cannot be used again
+func (server *Server) useMFAStep(ctx context.Context, step db.UseUserMFAStepParams) error {
+	rows, err := server.store.UseUserMFAStep(ctx, step)
+	if err != nil {
+		return status.Errorf(codes.Internal, "fail to use code: %s", err)
+	}
+	if rows == 0 {
+		return status.Errorf(codes.Unauthenticated, "code was already used")
+	}
+	return nil
+}
+
+// useRecoveryCode consumes one of the user's recovery codes
+func (server *Server) useRecoveryCode(ctx context.Context, username string, code string) error {
+	rows, err := server.store.UseMFARecoveryCode(ctx, db.UseMFARecoveryCodeParams{
+		Username:   username,
+		HashedCode: mfa.HashRecoveryCode(code),
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "fail to use recovery code: %s", err)
+	}
+	if rows == 0 {
+		return status.Errorf(codes.Unauthenticated, "invalid or already used recovery code")
+	}
+	return nil
+}
+
+// useMFACode consumes the TOTP code, or the recovery code if no TOTP code is given
+func (server *Server) useMFACode(ctx context.Context, userMFA db.UserMfa, totpCode string, recoveryCode string) error {
+	if totpCode != "" {
+		return server.useTOTPCode(ctx, userMFA, totpCode)
+	}
+	return server.useRecoveryCode(ctx, userMFA.Username, recoveryCode)
+}
+
+// requireTransferMFA checks and uses up the TOTP code of a transfer, see transferMFAStep.
+// It reports whether a TOTP code authorized the amount
+func (server *Server) requireTransferMFA(ctx context.Context, username string, currency string, amount int64, totpCode string) (bool, error) {
+	step, err := server.transferMFAStep(ctx, username, currency, amount, totpCode)
+	if err != nil || step == nil {
+		return false, err
+	}
+
+	err = server.useMFAStep(ctx, *step)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// transferMFAStep checks the TOTP code of a transfer above the threshold of its currency,
+// in the minor units of the source account's currency.
+// Two-factor authentication is opt-in: users who haven't enabled it don't need a code.
+// It returns the time step that the transfer must record, or nil if the transfer doesn't need a code
+func (server *Server) transferMFAStep(ctx context.Context, username string, currency string, amount int64, totpCode string) (*db.UseUserMFAStepParams, error) {
+	threshold, exceeds := server.mfaThresholds.Exceeds(currency, amount)
+	if !exceeds {
+		return nil, nil
+	}
+
+	userMFA, enabled, err := server.enabledMFA(ctx, username)
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	if totpCode == "" {
+		return nil, status.Errorf(codes.Unauthenticated, "totp_code is required for transfers above %d %s", threshold, currency)
+	}
+
+	step, err := server.checkTOTPCode(userMFA, totpCode)
+	if err != nil {
+		return nil, err
+	}
+	return &step, nil
+}
+
+// validateMFACodes checks that exactly one of a TOTP code and a recovery code is given
+func validateMFACodes(totpCode string, recoveryCode string) (violations []*errdetails.BadRequest_FieldViolation) {
+	if totpCode == "" && recoveryCode == "" {
+		return append(violations, fieldViolation("totp_code", errors.New("either totp_code or recovery_code is required")))
+	}
+	if totpCode != "" && recoveryCode != "" {
+		return append(violations, fieldViolation("recovery_code", errors.New("must not be set with totp_code")))
+	}
+
+	if totpCode != "" {
+		if err := validation.ValidateTOTPCode(totpCode); err != nil {
+			violations = append(violations, fieldViolation("totp_code", err))
+		}
+	} else if err := validation.ValidateRecoveryCode(recoveryCode); err != nil {
+		violations = append(violations, fieldViolation("recovery_code", err))
+	}
+	return
+}
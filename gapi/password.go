@@ -0,0 +1,60 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// passwordPolicyViolations checks a new password of the user against the password policy.
+// It returns a violation of the field for every rule the password breaks
+func (server *Server) passwordPolicyViolations(field string, password string, username string, email string) (violations []*errdetails.BadRequest_FieldViolation) {
+	for _, err := range server.passwordPolicy.Validate(password, username, email) {
+		violations = append(violations, fieldViolation(field, err))
+	}
+	return
+}
+
+// passwordHistoryViolations checks a new password against the current and the replaced passwords of the user
+func (server *Server) passwordHistoryViolations(ctx context.Context, field string, password string, user db.User) ([]*errdetails.BadRequest_FieldViolation, error) {
+	historySize := server.passwordPolicy.HistorySize
+	if historySize < 1 {
+		return nil, nil
+	}
+
+	hashedPasswords := []string{user.HashedPassword}
+	if historySize > 1 {
+		replaced, err := server.store.ListPasswordHistory(ctx, db.ListPasswordHistoryParams{
+			Username: user.Username,
+			Limit:    int32(historySize - 1),
+		})
+		if err != nil {
+			return nil, err
+		}
+		hashedPasswords = append(hashedPasswords, replaced...)
+	}
+
+	if err := server.passwordPolicy.CheckHistory(password, hashedPasswords); err != nil {
+		return []*errdetails.BadRequest_FieldViolation{fieldViolation(field, err)}, nil
+	}
+	return nil, nil
+}
+
+// rehashPassword replaces the hash of the user's password with one of the configured algorithm and parameters.
+// A failure doesn't fail the login, the hash is replaced on a later login instead
+func (server *Server) rehashPassword(ctx context.Context, user db.User, password string) {
+	hashedPassword, err := server.passwordHasher.Hash(password)
+	if err == nil {
+		// the hash is left alone if the password changed since it was read
+		_, err = server.store.RehashUserPassword(ctx, db.RehashUserPasswordParams{
+			NewHashedPassword: hashedPassword,
+			Username:          user.Username,
+			OldHashedPassword: user.HashedPassword,
+		})
+	}
+	if err != nil {
+		log.Error().Err(err).Str("username", user.Username).Msg("fail to rehash password")
+	}
+}
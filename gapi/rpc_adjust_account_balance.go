@@ -0,0 +1,68 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/validation"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) AdjustAccountBalance(ctx context.Context, req *pb.AdjustAccountBalanceRequest) (*pb.AdjustAccountBalanceResponse, error) {
+	payload, err := server.authorizeUser(ctx, staffRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateAdjustAccountBalanceRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	arg := db.AdjustAccountBalanceTxParams{
+		AccountID:  req.GetAccountId(),
+		Amount:     req.GetAmount(),
+		Reason:     req.GetReason(),
+		AdjustedBy: payload.Username,
+	}
+
+	result, err := server.store.AdjustAccountBalanceTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		if errors.Is(err, db.ErrSystemAccount) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "fail to adjust account balance: %s", err)
+	}
+
+	rsp := &pb.AdjustAccountBalanceResponse{
+		Account:      convertAccount(result.Account),
+		Entry:        convertEntry(result.Entry),
+		AdjustmentId: result.Adjustment.ID,
+		Reason:       result.Adjustment.Reason,
+		AdjustedBy:   result.Adjustment.AdjustedBy,
+	}
+	return rsp, nil
+}
+
+func validateAdjustAccountBalanceRequest(req *pb.AdjustAccountBalanceRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	if err := validation.ValidateAdjustmentAmount(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := validation.ValidateAdjustmentReason(req.GetReason()); err != nil {
+		violations = append(violations, fieldViolation("reason", err))
+	}
+	return
+}
@@ -0,0 +1,70 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/validation"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	payload, err := server.authorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateCreateAccountRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	idempotency, hasIdempotencyKey, err := server.idempotencyParams(ctx, payload.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	arg := db.CreateAccountParams{
+		Owner:    payload.Username,
+		Currency: req.GetCurrency(),
+	}
+
+	var account db.Account
+	if hasIdempotencyKey {
+		account, err = server.store.IdempotentCreateAccountTx(ctx, idempotency, arg)
+	} else {
+		account, err = server.store.CreateAccount(ctx, arg)
+	}
+
+	if err != nil {
+		if errors.Is(err, db.ErrIdempotencyKeyReused) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		if pgErr, ok := err.(*pgconn.PgError); ok {
+			switch pgErr.ConstraintName {
+			case "owner_currency_key":
+				return nil, status.Errorf(codes.AlreadyExists, "account already exists: %s", err)
+			case "owner_username_fk":
+				return nil, status.Errorf(codes.PermissionDenied, "owner doesn't exist: %s", err)
+			}
+		}
+		return nil, status.Errorf(codes.Internal, "fail to create account: %s", err)
+	}
+
+	rsp := &pb.CreateAccountResponse{
+		Account: convertAccount(account),
+	}
+	return rsp, nil
+}
+
+func validateCreateAccountRequest(req *pb.CreateAccountRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+	return
+}
@@ -0,0 +1,127 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateAccountAPI(t *testing.T) {
+	user := randomUser(util.DepositorRole)
+	account := randomAccount(user.Username, util.USD)
+	account.Balance = 0
+
+	testCases := []struct {
+		name          string
+		caller        *db.User
+		currency      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.CreateAccountResponse, err error)
+	}{
+		{
+			name:     "OK",
+			caller:   &user,
+			currency: account.Currency,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateAccountParams{
+					Owner:    user.Username,
+					Currency: account.Currency,
+				}
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.ID, rsp.GetAccount().GetId())
+				require.Equal(t, user.Username, rsp.GetAccount().GetOwner())
+				require.Equal(t, account.Currency, rsp.GetAccount().GetCurrency())
+				require.Zero(t, rsp.GetAccount().GetBalance())
+			},
+		},
+		{
+			name:     "AlreadyExists",
+			caller:   &user,
+			currency: account.Currency,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, &pgconn.PgError{Code: "23505", ConstraintName: "owner_currency_key"})
+			},
+			checkResponse: requireCreateAccountCode(codes.AlreadyExists),
+		},
+		{
+			name:          "InvalidCurrency",
+			caller:        &user,
+			currency:      "XYZ",
+			buildStubs:    expectNoCreateAccount,
+			checkResponse: requireCreateAccountCode(codes.InvalidArgument),
+		},
+		{
+			name:          "NoAuthorization",
+			currency:      account.Currency,
+			buildStubs:    expectNoCreateAccount,
+			checkResponse: requireCreateAccountCode(codes.Unauthenticated),
+		},
+		{
+			name:     "InternalError",
+			caller:   &user,
+			currency: account.Currency,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, errors.New("connection reset"))
+			},
+			checkResponse: requireCreateAccountCode(codes.Internal),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+
+			ctx := context.Background()
+			if tc.caller != nil {
+				ctx = newContextWithBearerToken(t, server.tokenMaker, tc.caller.Username, tc.caller.Role)
+			}
+
+			rsp, err := server.CreateAccount(ctx, &pb.CreateAccountRequest{
+				Currency: tc.currency,
+			})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func expectNoCreateAccount(store *mockdb.MockStore) {
+	store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+}
+
+func requireCreateAccountCode(code codes.Code) func(t *testing.T, rsp *pb.CreateAccountResponse, err error) {
+	return func(t *testing.T, rsp *pb.CreateAccountResponse, err error) {
+		require.Error(t, err)
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, code, st.Code())
+		require.Nil(t, rsp)
+	}
+}
@@ -0,0 +1,149 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/schedule"
+	"github.com/hykura1501/simple_bank/validation"
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateScheduledTransfer(ctx context.Context, req *pb.CreateScheduledTransferRequest) (*pb.CreateScheduledTransferResponse, error) {
+	payload, err := server.authorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateCreateScheduledTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	fromAccount, err := server.validAccount(ctx, req.GetFromAccountId(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAccount.Owner != payload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "from account doesn't belong to the authenticated user")
+	}
+
+	if err := server.requireVerifiedEmail(ctx, payload.Username); err != nil {
+		return nil, err
+	}
+
+	mfaAuthorized, err := server.requireTransferMFA(ctx, payload.Username, fromAccount.Currency, req.GetAmount(), req.GetTotpCode())
+	if err != nil {
+		return nil, err
+	}
+
+	// scheduled transfers don't convert currencies, the rate on the day of a run isn't known yet
+	if _, err := server.validAccount(ctx, req.GetToAccountId(), req.GetCurrency()); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	arg := db.CreateScheduledTransferParams{
+		Owner:          payload.Username,
+		FromAccountID:  req.GetFromAccountId(),
+		ToAccountID:    req.GetToAccountId(),
+		Amount:         req.GetAmount(),
+		Schedule:       req.GetSchedule(),
+		StartAt:        pgtype.Timestamptz{Time: now, Valid: true},
+		MaxOccurrences: req.MaxOccurrences,
+	}
+	if req.StartTime != nil {
+		arg.StartAt.Time = req.GetStartTime().AsTime()
+	}
+	if req.EndTime != nil {
+		arg.EndAt = pgtype.Timestamptz{Time: req.GetEndTime().AsTime(), Valid: true}
+	}
+	if mfaAuthorized {
+		// the runs are made without the owner, so they rely on this code if the amount needs one
+		arg.MfaAuthorizedAmount = &arg.Amount
+	}
+
+	recurrence, err := db.ScheduledTransfer{
+		Schedule:       arg.Schedule,
+		EndAt:          arg.EndAt,
+		MaxOccurrences: arg.MaxOccurrences,
+	}.Recurrence()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "fail to parse schedule: %s", err)
+	}
+
+	nextRunAt, ok := firstScheduledRun(recurrence, arg.StartAt.Time, now, 0)
+	if !ok {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{
+			fieldViolation("schedule", errors.New("has no occurrence between start_time and end_time")),
+		})
+	}
+	arg.NextRunAt = pgtype.Timestamptz{Time: nextRunAt, Valid: true}
+
+	scheduled, err := server.store.CreateScheduledTransfer(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "fail to create scheduled transfer: %s", err)
+	}
+
+	rsp := &pb.CreateScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(scheduled),
+	}
+	return rsp, nil
+}
+
+// firstScheduledRun returns the next run of a scheduled transfer that starts at startAt,
+// once occurrences runs have been made. The occurrences before now are never run
+func firstScheduledRun(recurrence schedule.Recurrence, startAt time.Time, now time.Time, occurrences int32) (time.Time, bool) {
+	if startAt.Before(now) {
+		startAt = now
+	}
+	return recurrence.From(startAt, occurrences)
+}
+
+func validateCreateScheduledTransferRequest(req *pb.CreateScheduledTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(req.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+
+	if err := validation.ValidateID(req.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	} else if req.GetToAccountId() == req.GetFromAccountId() {
+		violations = append(violations, fieldViolation("to_account_id", errors.New("must be different from from_account_id")))
+	}
+
+	if err := validation.ValidateAmount(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := validation.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	if err := validation.ValidateSchedule(req.GetSchedule()); err != nil {
+		violations = append(violations, fieldViolation("schedule", err))
+	}
+
+	if req.StartTime != nil && req.EndTime != nil && !req.GetStartTime().AsTime().Before(req.GetEndTime().AsTime()) {
+		violations = append(violations, fieldViolation("end_time", errors.New("must be after start_time")))
+	}
+
+	if req.MaxOccurrences != nil {
+		if err := validation.ValidateMaxOccurrences(req.GetMaxOccurrences()); err != nil {
+			violations = append(violations, fieldViolation("max_occurrences", err))
+		}
+	}
+
+	if req.GetTotpCode() != "" {
+		if err := validation.ValidateTOTPCode(req.GetTotpCode()); err != nil {
+			violations = append(violations, fieldViolation("totp_code", err))
+		}
+	}
+	return
+}
@@ -0,0 +1,190 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/fx"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/validation"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	payload, err := server.authorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateCreateTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	fromAccount, err := server.validAccount(ctx, req.GetFromAccountId(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAccount.Owner != payload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "from account doesn't belong to the authenticated user")
+	}
+
+	if err := server.requireVerifiedEmail(ctx, payload.Username); err != nil {
+		return nil, err
+	}
+
+	toAccount, err := server.existingAccount(ctx, req.GetToAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	idempotency, hasIdempotencyKey, err := server.idempotencyParams(ctx, payload.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	arg := db.TransferTxParams{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        req.GetAmount(),
+	}
+
+	// a retry is answered before the TOTP code is checked, the code was used up by the first request
+	if hasIdempotencyKey {
+		result, replayed, err := server.store.ReplayIdempotentTransfer(ctx, idempotency, arg)
+		if err != nil {
+			return nil, transferError(err)
+		}
+		if replayed {
+			return convertTransferTxResult(result), nil
+		}
+	}
+
+	// the code is only used up by the transfer transaction, so a transfer that fails doesn't burn it
+	arg.MFAStep, err = server.transferMFAStep(ctx, payload.Username, fromAccount.Currency, req.GetAmount(), req.GetTotpCode())
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAccount.Currency != toAccount.Currency {
+		rate, err := server.rateProvider.GetRate(ctx, fromAccount.Currency, toAccount.Currency)
+		if err != nil {
+			if errors.Is(err, fx.ErrRateNotFound) {
+				return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+			}
+			return nil, status.Errorf(codes.Unavailable, "fail to get exchange rate: %s", err)
+		}
+		arg.ExchangeRate = &rate
+		arg.RoundingMode = server.roundingMode
+	}
+
+	var result db.TransferTxResult
+	if hasIdempotencyKey {
+		result, err = server.store.IdempotentTransferTx(ctx, idempotency, arg)
+	} else {
+		result, err = server.store.TransferTx(ctx, arg)
+	}
+
+	if err != nil {
+		return nil, transferError(err)
+	}
+
+	return convertTransferTxResult(result), nil
+}
+
+// transferError converts an error of the transfer transaction to a status error
+func transferError(err error) error {
+	if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrAccountFrozen) || errors.Is(err, db.ErrIdempotencyKeyReused) {
+		return status.Errorf(codes.FailedPrecondition, "%s", err)
+	}
+	if errors.Is(err, db.ErrExchangeRateMismatch) || errors.Is(err, fx.ErrAmountTooSmall) || errors.Is(err, fx.ErrAmountTooLarge) {
+		return status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+	if errors.Is(err, db.ErrTOTPCodeUsed) {
+		return status.Errorf(codes.Unauthenticated, "%s", err)
+	}
+	return status.Errorf(codes.Internal, "fail to transfer: %s", err)
+}
+
+func convertTransferTxResult(result db.TransferTxResult) *pb.CreateTransferResponse {
+	return &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+		ToEntry:     convertEntry(result.ToEntry),
+	}
+}
+
+// existingAccount gets the account, converting a lookup failure to a status error
+func (server *Server) existingAccount(ctx context.Context, accountID int64) (db.Account, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return account, status.Errorf(codes.NotFound, "account [%d] not found", accountID)
+		}
+		return account, status.Errorf(codes.Internal, "fail to get account: %s", err)
+	}
+	return account, nil
+}
+
+// requireVerifiedEmail checks that the user has verified their email address
+func (server *Server) requireVerifiedEmail(ctx context.Context, username string) error {
+	user, err := server.store.GetUser(ctx, username)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return status.Errorf(codes.NotFound, "user not found")
+		}
+		return status.Errorf(codes.Internal, "fail to get user: %s", err)
+	}
+
+	if !user.IsEmailVerified {
+		return status.Errorf(codes.PermissionDenied, "email address must be verified before creating transfers")
+	}
+	return nil
+}
+
+// validAccount checks that the account exists and its currency matches the transfer currency
+func (server *Server) validAccount(ctx context.Context, accountID int64, currency string) (db.Account, error) {
+	account, err := server.existingAccount(ctx, accountID)
+	if err != nil {
+		return account, err
+	}
+
+	if account.Currency != currency {
+		return account, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
+	}
+	return account, nil
+}
+
+func validateCreateTransferRequest(req *pb.CreateTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(req.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+
+	if err := validation.ValidateID(req.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	} else if req.GetToAccountId() == req.GetFromAccountId() {
+		violations = append(violations, fieldViolation("to_account_id", errors.New("must be different from from_account_id")))
+	}
+
+	if err := validation.ValidateAmount(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := validation.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	if req.GetTotpCode() != "" {
+		if err := validation.ValidateTOTPCode(req.GetTotpCode()); err != nil {
+			violations = append(violations, fieldViolation("totp_code", err))
+		}
+	}
+	return
+}
@@ -0,0 +1,450 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/mfa"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateTransferAPI(t *testing.T) {
+	user := randomUser(util.DepositorRole)
+	otherUser := randomUser(util.DepositorRole)
+	fromAccount := randomAccount(user.Username, util.USD)
+	toAccount := randomAccount(otherUser.Username, util.USD)
+	toAccount.ID = fromAccount.ID + 1
+	amount := int64(10)
+
+	testCases := []struct {
+		name          string
+		caller        *db.User
+		fromAccountID int64
+		toAccountID   int64
+		amount        int64
+		currency      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.CreateTransferResponse, err error)
+	}{
+		{
+			name:          "OK",
+			caller:        &user,
+			fromAccountID: fromAccount.ID,
+			toAccountID:   toAccount.ID,
+			amount:        amount,
+			currency:      util.USD,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectTransferAccounts(store, user, fromAccount, toAccount)
+				expectTransferTx(t, store, func(arg db.TransferTxParams) {
+					require.Equal(t, fromAccount.ID, arg.FromAccountID)
+					require.Equal(t, toAccount.ID, arg.ToAccountID)
+					require.Equal(t, amount, arg.Amount)
+				})
+			},
+			checkResponse: requireTransferCreated,
+		},
+		{
+			name:          "FromAccountOfOtherUser",
+			caller:        &otherUser,
+			fromAccountID: fromAccount.ID,
+			toAccountID:   toAccount.ID,
+			amount:        amount,
+			currency:      util.USD,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(0)
+				expectNoTransferTx(store)
+			},
+			checkResponse: requireTransferCode(codes.PermissionDenied),
+		},
+		{
+			name:          "FromAccountNotFound",
+			caller:        &user,
+			fromAccountID: fromAccount.ID,
+			toAccountID:   toAccount.ID,
+			amount:        amount,
+			currency:      util.USD,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(db.Account{}, pgx.ErrNoRows)
+				expectNoTransferTx(store)
+			},
+			checkResponse: requireTransferCode(codes.NotFound),
+		},
+		{
+			name:          "ToAccountNotFound",
+			caller:        &user,
+			fromAccountID: fromAccount.ID,
+			toAccountID:   toAccount.ID,
+			amount:        amount,
+			currency:      util.USD,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(db.Account{}, pgx.ErrNoRows)
+				expectNoTransferTx(store)
+			},
+			checkResponse: requireTransferCode(codes.NotFound),
+		},
+		{
+			name:          "CurrencyMismatch",
+			caller:        &user,
+			fromAccountID: fromAccount.ID,
+			toAccountID:   toAccount.ID,
+			amount:        amount,
+			currency:      util.EUR,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				expectNoTransferTx(store)
+			},
+			checkResponse: requireTransferCode(codes.InvalidArgument),
+		},
+		{
+			name:          "InsufficientFunds",
+			caller:        &user,
+			fromAccountID: fromAccount.ID,
+			toAccountID:   toAccount.ID,
+			amount:        amount,
+			currency:      util.USD,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectTransferAccounts(store, user, fromAccount, toAccount)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: requireTransferCode(codes.FailedPrecondition),
+		},
+		{
+			name:          "SameAccount",
+			caller:        &user,
+			fromAccountID: fromAccount.ID,
+			toAccountID:   fromAccount.ID,
+			amount:        amount,
+			currency:      util.USD,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				expectNoTransferTx(store)
+			},
+			checkResponse: requireTransferCode(codes.InvalidArgument),
+		},
+		{
+			name:          "NegativeAmount",
+			caller:        &user,
+			fromAccountID: fromAccount.ID,
+			toAccountID:   toAccount.ID,
+			amount:        -amount,
+			currency:      util.USD,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				expectNoTransferTx(store)
+			},
+			checkResponse: requireTransferCode(codes.InvalidArgument),
+		},
+		{
+			name:          "NoAuthorization",
+			fromAccountID: fromAccount.ID,
+			toAccountID:   toAccount.ID,
+			amount:        amount,
+			currency:      util.USD,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				expectNoTransferTx(store)
+			},
+			checkResponse: requireTransferCode(codes.Unauthenticated),
+		},
+		{
+			name:          "InternalError",
+			caller:        &user,
+			fromAccountID: fromAccount.ID,
+			toAccountID:   toAccount.ID,
+			amount:        amount,
+			currency:      util.USD,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectTransferAccounts(store, user, fromAccount, toAccount)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, errors.New("connection reset"))
+			},
+			checkResponse: requireTransferCode(codes.Internal),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+
+			ctx := context.Background()
+			if tc.caller != nil {
+				ctx = newContextWithBearerToken(t, server.tokenMaker, tc.caller.Username, tc.caller.Role)
+			}
+
+			rsp, err := server.CreateTransfer(ctx, &pb.CreateTransferRequest{
+				FromAccountId: tc.fromAccountID,
+				ToAccountId:   tc.toAccountID,
+				Amount:        tc.amount,
+				Currency:      tc.currency,
+			})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func TestCreateTransferMFAAPI(t *testing.T) {
+	user := randomUser(util.DepositorRole)
+	fromAccount := randomAccount(user.Username, util.USD)
+	toAccount := randomAccount(util.RandomOwner(), util.USD)
+	toAccount.ID = fromAccount.ID + 1
+	threshold := int64(100)
+	idempotencyKey := util.RandomString(16)
+
+	testCases := []struct {
+		name           string
+		amount         int64
+		totpCode       func(secret []byte) string
+		idempotencyKey string
+		buildStubs     func(store *mockdb.MockStore, userMFA db.UserMfa)
+		checkResponse  func(t *testing.T, rsp *pb.CreateTransferResponse, err error)
+	}{
+		{
+			name:   "BelowThreshold",
+			amount: threshold,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectTransferAccounts(store, user, fromAccount, toAccount)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Any()).Times(0)
+				expectTransferTx(t, store, func(arg db.TransferTxParams) {
+					require.Nil(t, arg.MFAStep)
+				})
+			},
+			checkResponse: requireTransferCreated,
+		},
+		{
+			name:     "AboveThresholdWithCode",
+			amount:   threshold + 1,
+			totpCode: currentTOTPCode,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectTransferAccounts(store, user, fromAccount, toAccount)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(userMFA, nil)
+				// the code is used up by the transfer transaction, not by the handler
+				store.EXPECT().UseUserMFAStep(gomock.Any(), gomock.Any()).Times(0)
+				expectTransferTx(t, store, func(arg db.TransferTxParams) {
+					require.NotNil(t, arg.MFAStep)
+					require.Equal(t, user.Username, arg.MFAStep.Username)
+				})
+			},
+			checkResponse: requireTransferCreated,
+		},
+		{
+			name:   "AboveThresholdWithoutCode",
+			amount: threshold + 1,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectTransferAccounts(store, user, fromAccount, toAccount)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(userMFA, nil)
+				expectNoTransferTx(store)
+			},
+			checkResponse: requireTransferCode(codes.Unauthenticated),
+		},
+		{
+			name:   "AboveThresholdWrongCode",
+			amount: threshold + 1,
+			totpCode: func(secret []byte) string {
+				return mfa.GenerateCode(secret, time.Now().Add(-time.Hour))
+			},
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectTransferAccounts(store, user, fromAccount, toAccount)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(userMFA, nil)
+				expectNoTransferTx(store)
+			},
+			checkResponse: requireTransferCode(codes.Unauthenticated),
+		},
+		{
+			name:     "AboveThresholdCodeAlreadyUsed",
+			amount:   threshold + 1,
+			totpCode: currentTOTPCode,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectTransferAccounts(store, user, fromAccount, toAccount)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(userMFA, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrTOTPCodeUsed)
+			},
+			checkResponse: requireTransferCode(codes.Unauthenticated),
+		},
+		{
+			name:   "AboveThresholdWithoutMFA",
+			amount: threshold + 1,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectTransferAccounts(store, user, fromAccount, toAccount)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.UserMfa{}, pgx.ErrNoRows)
+				expectTransferTx(t, store, func(arg db.TransferTxParams) {
+					require.Nil(t, arg.MFAStep)
+				})
+			},
+			checkResponse: requireTransferCreated,
+		},
+		{
+			name:   "EmailNotVerified",
+			amount: threshold + 1,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				unverified := user
+				unverified.IsEmailVerified = false
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(unverified, nil)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Any()).Times(0)
+				expectNoTransferTx(store)
+			},
+			checkResponse: requireTransferCode(codes.PermissionDenied),
+		},
+		{
+			name:           "IdempotentReplay",
+			amount:         threshold + 1,
+			idempotencyKey: idempotencyKey,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectTransferAccounts(store, user, fromAccount, toAccount)
+				// the retry is answered without a code, the first request used it up
+				store.EXPECT().
+					ReplayIdempotentTransfer(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, idempotency db.IdempotencyParams, arg db.TransferTxParams) (db.TransferTxResult, bool, error) {
+						require.Equal(t, idempotencyKey, idempotency.Key)
+						require.Equal(t, user.Username, idempotency.Username)
+						return db.TransferTxResult{}, true, nil
+					})
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().IdempotentTransferTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: requireTransferCreated,
+		},
+		{
+			name:           "IdempotentFirstRequest",
+			amount:         threshold + 1,
+			totpCode:       currentTOTPCode,
+			idempotencyKey: idempotencyKey,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectTransferAccounts(store, user, fromAccount, toAccount)
+				store.EXPECT().
+					ReplayIdempotentTransfer(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, false, nil)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(userMFA, nil)
+				store.EXPECT().
+					IdempotentTransferTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, idempotency db.IdempotencyParams, arg db.TransferTxParams) (db.TransferTxResult, error) {
+						require.NotNil(t, arg.MFAStep)
+						return db.TransferTxResult{}, nil
+					})
+			},
+			checkResponse: requireTransferCreated,
+		},
+		{
+			name:           "IdempotentFirstRequestWithoutCode",
+			amount:         threshold + 1,
+			idempotencyKey: idempotencyKey,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectTransferAccounts(store, user, fromAccount, toAccount)
+				store.EXPECT().
+					ReplayIdempotentTransfer(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, false, nil)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(userMFA, nil)
+				store.EXPECT().IdempotentTransferTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: requireTransferCode(codes.Unauthenticated),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store, nil)
+			server.mfaThresholds = mfa.TransferThresholds{util.USD: threshold}
+
+			userMFA, secret := randomEnabledUserMFA(t, server, user)
+			tc.buildStubs(store, userMFA)
+
+			req := &pb.CreateTransferRequest{
+				FromAccountId: fromAccount.ID,
+				ToAccountId:   toAccount.ID,
+				Amount:        tc.amount,
+				Currency:      util.USD,
+			}
+			if tc.totpCode != nil {
+				req.TotpCode = tc.totpCode(secret)
+			}
+
+			ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, user.Role)
+			if tc.idempotencyKey != "" {
+				md, _ := metadata.FromIncomingContext(ctx)
+				md = metadata.Join(md, metadata.Pairs(idempotencyKeyHeader, tc.idempotencyKey))
+				ctx = metadata.NewIncomingContext(ctx, md)
+			}
+
+			rsp, err := server.CreateTransfer(ctx, req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func currentTOTPCode(secret []byte) string {
+	return mfa.GenerateCode(secret, time.Now())
+}
+
+// expectTransferAccounts expects the lookups that come before the 2FA check of a transfer
+func expectTransferAccounts(store *mockdb.MockStore, user db.User, fromAccount db.Account, toAccount db.Account) {
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+}
+
+// expectTransferTx runs the transfer transaction and checks its parameters
+func expectTransferTx(t *testing.T, store *mockdb.MockStore, check func(arg db.TransferTxParams)) {
+	store.EXPECT().
+		TransferTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.TransferTxParams) (db.TransferTxResult, error) {
+			check(arg)
+			return db.TransferTxResult{}, nil
+		})
+}
+
+func expectNoTransferTx(store *mockdb.MockStore) {
+	store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().IdempotentTransferTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+}
+
+func requireTransferCreated(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+	require.NoError(t, err)
+	require.NotNil(t, rsp)
+}
+
+func requireTransferCode(code codes.Code) func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+	return func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+		require.Error(t, err)
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, code, st.Code())
+		require.Nil(t, rsp)
+	}
+}
@@ -0,0 +1,71 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/validation"
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DeleteScheduledTransfer cancels the scheduled transfer rather than deleting it, so its runs keep their history
+func (server *Server) DeleteScheduledTransfer(ctx context.Context, req *pb.DeleteScheduledTransferRequest) (*pb.DeleteScheduledTransferResponse, error) {
+	payload, err := server.authorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateDeleteScheduledTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	scheduled, err := server.existingScheduledTransfer(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if scheduled.Owner != payload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "scheduled transfer doesn't belong to the authenticated user")
+	}
+
+	scheduled, err = server.store.UpdateScheduledTransferTx(ctx, db.UpdateScheduledTransferTxParams{
+		ID: scheduled.ID,
+		Update: func(scheduled db.ScheduledTransfer) (db.UpdateScheduledTransferParams, error) {
+			if err := requireScheduledTransferNotEnded(scheduled); err != nil {
+				return db.UpdateScheduledTransferParams{}, err
+			}
+			return db.UpdateScheduledTransferParams{
+				ID:             scheduled.ID,
+				Amount:         scheduled.Amount,
+				Schedule:       scheduled.Schedule,
+				EndAt:          scheduled.EndAt,
+				MaxOccurrences: scheduled.MaxOccurrences,
+				NextRunAt:      pgtype.Timestamptz{},
+				Status:         db.ScheduledTransferCancelled,
+			}, nil
+		},
+	})
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, status.Errorf(codes.Internal, "fail to cancel scheduled transfer: %s", err)
+	}
+
+	rsp := &pb.DeleteScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(scheduled),
+	}
+	return rsp, nil
+}
+
+func validateDeleteScheduledTransferRequest(req *pb.DeleteScheduledTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+	return
+}
@@ -0,0 +1,46 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/hykura1501/simple_bank/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) DisableMFA(ctx context.Context, req *pb.DisableMFARequest) (*pb.DisableMFAResponse, error) {
+	payload, err := server.authorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateDisableMFARequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	userMFA, enabled, err := server.enabledMFA(ctx, payload.Username)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, status.Errorf(codes.FailedPrecondition, "two-factor authentication is not enabled")
+	}
+
+	err = server.useMFACode(ctx, userMFA, req.GetTotpCode(), req.GetRecoveryCode())
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.store.DisableMFATx(ctx, payload.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "fail to disable two-factor authentication: %s", err)
+	}
+
+	return &pb.DisableMFAResponse{}, nil
+}
+
+func validateDisableMFARequest(req *pb.DisableMFARequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	return validateMFACodes(req.GetTotpCode(), req.GetRecoveryCode())
+}
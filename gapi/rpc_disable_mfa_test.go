@@ -0,0 +1,221 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/mfa"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDisableMFAAPI(t *testing.T) {
+	user := randomUser(util.DepositorRole)
+	recoveryCode := randomRecoveryCode(t)
+
+	testCases := []struct {
+		name          string
+		caller        *db.User
+		totpCode      func(secret []byte) string
+		recoveryCode  string
+		buildStubs    func(store *mockdb.MockStore, userMFA db.UserMfa)
+		checkResponse func(t *testing.T, err error)
+	}{
+		{
+			name:     "TOTPCode",
+			caller:   &user,
+			totpCode: currentTOTPCode,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectGetUserMFA(store, userMFA)
+				expectUseUserMFAStep(t, store, user.Username, 1)
+				expectDisableMFATx(store, user.Username)
+			},
+			checkResponse: requireMFADisabled,
+		},
+		{
+			name:         "RecoveryCode",
+			caller:       &user,
+			recoveryCode: recoveryCode,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectGetUserMFA(store, userMFA)
+				expectUseMFARecoveryCode(store, user.Username, recoveryCode, 1)
+				expectDisableMFATx(store, user.Username)
+			},
+			checkResponse: requireMFADisabled,
+		},
+		{
+			name:   "WrongCode",
+			caller: &user,
+			totpCode: func(secret []byte) string {
+				return mfa.GenerateCode(secret, time.Now().Add(-time.Hour))
+			},
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectGetUserMFA(store, userMFA)
+				store.EXPECT().UseUserMFAStep(gomock.Any(), gomock.Any()).Times(0)
+				expectNoDisableMFATx(store)
+			},
+			checkResponse: requireDisableMFACode(codes.Unauthenticated),
+		},
+		{
+			name:     "ReplayedCode",
+			caller:   &user,
+			totpCode: currentTOTPCode,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectGetUserMFA(store, userMFA)
+				// the time step of the code was already used
+				expectUseUserMFAStep(t, store, user.Username, 0)
+				expectNoDisableMFATx(store)
+			},
+			checkResponse: requireDisableMFACode(codes.Unauthenticated),
+		},
+		{
+			name:         "UsedRecoveryCode",
+			caller:       &user,
+			recoveryCode: recoveryCode,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectGetUserMFA(store, userMFA)
+				expectUseMFARecoveryCode(store, user.Username, recoveryCode, 0)
+				expectNoDisableMFATx(store)
+			},
+			checkResponse: requireDisableMFACode(codes.Unauthenticated),
+		},
+		{
+			name:     "NotEnabled",
+			caller:   &user,
+			totpCode: currentTOTPCode,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				store.EXPECT().
+					GetUserMFA(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserMfa{}, pgx.ErrNoRows)
+				expectNoDisableMFATx(store)
+			},
+			checkResponse: requireDisableMFACode(codes.FailedPrecondition),
+		},
+		{
+			name:         "BothCodes",
+			caller:       &user,
+			totpCode:     currentTOTPCode,
+			recoveryCode: recoveryCode,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Any()).Times(0)
+				expectNoDisableMFATx(store)
+			},
+			checkResponse: requireDisableMFACode(codes.InvalidArgument),
+		},
+		{
+			name:     "NoAuthorization",
+			totpCode: currentTOTPCode,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Any()).Times(0)
+				expectNoDisableMFATx(store)
+			},
+			checkResponse: requireDisableMFACode(codes.Unauthenticated),
+		},
+		{
+			name:     "InternalError",
+			caller:   &user,
+			totpCode: currentTOTPCode,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectGetUserMFA(store, userMFA)
+				expectUseUserMFAStep(t, store, user.Username, 1)
+				store.EXPECT().
+					DisableMFATx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(errors.New("connection reset"))
+			},
+			checkResponse: requireDisableMFACode(codes.Internal),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store, nil)
+
+			userMFA, secret := randomEnabledUserMFA(t, server, user)
+			tc.buildStubs(store, userMFA)
+
+			ctx := context.Background()
+			if tc.caller != nil {
+				ctx = newContextWithBearerToken(t, server.tokenMaker, tc.caller.Username, tc.caller.Role)
+			}
+
+			req := &pb.DisableMFARequest{
+				RecoveryCode: tc.recoveryCode,
+			}
+			if tc.totpCode != nil {
+				req.TotpCode = tc.totpCode(secret)
+			}
+
+			_, err := server.DisableMFA(ctx, req)
+			tc.checkResponse(t, err)
+		})
+	}
+}
+
+func randomRecoveryCode(t *testing.T) string {
+	recoveryCodes, err := mfa.GenerateRecoveryCodes(1)
+	require.NoError(t, err)
+	return recoveryCodes[0]
+}
+
+// expectUseUserMFAStep records the time step of a TOTP code, rows is 0 if the step was already used
+func expectUseUserMFAStep(t *testing.T, store *mockdb.MockStore, username string, rows int64) {
+	store.EXPECT().
+		UseUserMFAStep(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.UseUserMFAStepParams) (int64, error) {
+			require.Equal(t, username, arg.Username)
+			require.NotZero(t, arg.LastUsedStep)
+			return rows, nil
+		})
+}
+
+// expectUseMFARecoveryCode consumes the recovery code, rows is 0 if it was already used
+func expectUseMFARecoveryCode(store *mockdb.MockStore, username string, recoveryCode string, rows int64) {
+	arg := db.UseMFARecoveryCodeParams{
+		Username:   username,
+		HashedCode: mfa.HashRecoveryCode(recoveryCode),
+	}
+	store.EXPECT().
+		UseMFARecoveryCode(gomock.Any(), gomock.Eq(arg)).
+		Times(1).
+		Return(rows, nil)
+}
+
+func expectDisableMFATx(store *mockdb.MockStore, username string) {
+	store.EXPECT().
+		DisableMFATx(gomock.Any(), gomock.Eq(username)).
+		Times(1).
+		Return(nil)
+}
+
+func expectNoDisableMFATx(store *mockdb.MockStore) {
+	store.EXPECT().DisableMFATx(gomock.Any(), gomock.Any()).Times(0)
+}
+
+func requireMFADisabled(t *testing.T, err error) {
+	require.NoError(t, err)
+}
+
+func requireDisableMFACode(code codes.Code) func(t *testing.T, err error) {
+	return func(t *testing.T, err error) {
+		require.Error(t, err)
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, code, st.Code())
+	}
+}
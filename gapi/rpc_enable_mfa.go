@@ -0,0 +1,84 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/mfa"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/validation"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) EnableMFA(ctx context.Context, req *pb.EnableMFARequest) (*pb.EnableMFAResponse, error) {
+	payload, err := server.authorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateEnableMFARequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	userMFA, err := server.store.GetUserMFA(ctx, payload.Username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.FailedPrecondition, "two-factor authentication is not set up")
+		}
+		return nil, status.Errorf(codes.Internal, "fail to get two-factor authentication: %s", err)
+	}
+
+	if userMFA.IsEnabled {
+		return nil, status.Errorf(codes.AlreadyExists, "two-factor authentication is already enabled")
+	}
+
+	secret, err := server.mfaSecrets.Open(userMFA.Username, userMFA.EncryptedSecret)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%s", err)
+	}
+
+	step, err := mfa.ValidateCode(secret, req.GetTotpCode(), time.Now())
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "%s", err)
+	}
+
+	recoveryCodes, err := mfa.GenerateRecoveryCodes(mfa.RecoveryCodeCount)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%s", err)
+	}
+
+	hashedRecoveryCodes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		hashedRecoveryCodes[i] = mfa.HashRecoveryCode(code)
+	}
+
+	_, err = server.store.EnableMFATx(ctx, db.EnableMFATxParams{
+		Username:            payload.Username,
+		LastUsedStep:        step,
+		HashedRecoveryCodes: hashedRecoveryCodes,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.AlreadyExists, "two-factor authentication is already enabled")
+		}
+		return nil, status.Errorf(codes.Internal, "fail to enable two-factor authentication: %s", err)
+	}
+
+	rsp := &pb.EnableMFAResponse{
+		RecoveryCodes: recoveryCodes,
+	}
+	return rsp, nil
+}
+
+func validateEnableMFARequest(req *pb.EnableMFARequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateTOTPCode(req.GetTotpCode()); err != nil {
+		violations = append(violations, fieldViolation("totp_code", err))
+	}
+	return
+}
@@ -0,0 +1,181 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/mfa"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestEnableMFAAPI(t *testing.T) {
+	user := randomUser(util.DepositorRole)
+
+	testCases := []struct {
+		name          string
+		caller        *db.User
+		totpCode      func(secret []byte) string
+		buildStubs    func(store *mockdb.MockStore, userMFA db.UserMfa)
+		checkResponse func(t *testing.T, rsp *pb.EnableMFAResponse, err error)
+	}{
+		{
+			name:     "OK",
+			caller:   &user,
+			totpCode: currentTOTPCode,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectGetUserMFA(store, userMFA)
+				store.EXPECT().
+					EnableMFATx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.EnableMFATxParams) (db.EnableMFATxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						// the confirming code is used up, so it cannot log in again
+						require.NotZero(t, arg.LastUsedStep)
+						require.Len(t, arg.HashedRecoveryCodes, mfa.RecoveryCodeCount)
+						return db.EnableMFATxResult{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, rsp *pb.EnableMFAResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, rsp.GetRecoveryCodes(), mfa.RecoveryCodeCount)
+			},
+		},
+		{
+			name:   "WrongCode",
+			caller: &user,
+			totpCode: func(secret []byte) string {
+				return mfa.GenerateCode(secret, time.Now().Add(-time.Hour))
+			},
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectGetUserMFA(store, userMFA)
+				expectNoEnableMFATx(store)
+			},
+			checkResponse: requireEnableMFACode(codes.Unauthenticated),
+		},
+		{
+			name:     "InvalidCode",
+			caller:   &user,
+			totpCode: func(secret []byte) string { return "12ab" },
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Any()).Times(0)
+				expectNoEnableMFATx(store)
+			},
+			checkResponse: requireEnableMFACode(codes.InvalidArgument),
+		},
+		{
+			name:     "NotSetUp",
+			caller:   &user,
+			totpCode: currentTOTPCode,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				store.EXPECT().
+					GetUserMFA(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserMfa{}, pgx.ErrNoRows)
+				expectNoEnableMFATx(store)
+			},
+			checkResponse: requireEnableMFACode(codes.FailedPrecondition),
+		},
+		{
+			name:     "AlreadyEnabled",
+			caller:   &user,
+			totpCode: currentTOTPCode,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				userMFA.IsEnabled = true
+				expectGetUserMFA(store, userMFA)
+				expectNoEnableMFATx(store)
+			},
+			checkResponse: requireEnableMFACode(codes.AlreadyExists),
+		},
+		{
+			name:     "EnabledConcurrently",
+			caller:   &user,
+			totpCode: currentTOTPCode,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectGetUserMFA(store, userMFA)
+				store.EXPECT().
+					EnableMFATx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.EnableMFATxResult{}, pgx.ErrNoRows)
+			},
+			checkResponse: requireEnableMFACode(codes.AlreadyExists),
+		},
+		{
+			name:     "NoAuthorization",
+			totpCode: currentTOTPCode,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Any()).Times(0)
+				expectNoEnableMFATx(store)
+			},
+			checkResponse: requireEnableMFACode(codes.Unauthenticated),
+		},
+		{
+			name:     "InternalError",
+			caller:   &user,
+			totpCode: currentTOTPCode,
+			buildStubs: func(store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectGetUserMFA(store, userMFA)
+				store.EXPECT().
+					EnableMFATx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.EnableMFATxResult{}, errors.New("connection reset"))
+			},
+			checkResponse: requireEnableMFACode(codes.Internal),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store, nil)
+
+			// the enrollment is pending until the first code confirms it
+			userMFA, secret := randomEnabledUserMFA(t, server, user)
+			userMFA.IsEnabled = false
+			tc.buildStubs(store, userMFA)
+
+			ctx := context.Background()
+			if tc.caller != nil {
+				ctx = newContextWithBearerToken(t, server.tokenMaker, tc.caller.Username, tc.caller.Role)
+			}
+
+			rsp, err := server.EnableMFA(ctx, &pb.EnableMFARequest{
+				TotpCode: tc.totpCode(secret),
+			})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func expectGetUserMFA(store *mockdb.MockStore, userMFA db.UserMfa) {
+	store.EXPECT().
+		GetUserMFA(gomock.Any(), gomock.Eq(userMFA.Username)).
+		Times(1).
+		Return(userMFA, nil)
+}
+
+func expectNoEnableMFATx(store *mockdb.MockStore) {
+	store.EXPECT().EnableMFATx(gomock.Any(), gomock.Any()).Times(0)
+}
+
+func requireEnableMFACode(code codes.Code) func(t *testing.T, rsp *pb.EnableMFAResponse, err error) {
+	return func(t *testing.T, rsp *pb.EnableMFAResponse, err error) {
+		require.Error(t, err)
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, code, st.Code())
+		require.Nil(t, rsp)
+	}
+}
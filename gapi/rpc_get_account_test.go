@@ -0,0 +1,150 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetAccountAPI(t *testing.T) {
+	user := randomUser(util.DepositorRole)
+	otherUser := randomUser(util.DepositorRole)
+	banker := randomUser(util.BankerRole)
+	account := randomAccount(user.Username, util.USD)
+
+	testCases := []struct {
+		name          string
+		caller        *db.User
+		accountID     int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.GetAccountResponse, err error)
+	}{
+		{
+			name:      "OK",
+			caller:    &user,
+			accountID: account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectGetAccount(store, account)
+			},
+			checkResponse: requireAccountFound(account),
+		},
+		{
+			name:      "OtherOwner",
+			caller:    &otherUser,
+			accountID: account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectGetAccount(store, account)
+			},
+			checkResponse: requireGetAccountCode(codes.PermissionDenied),
+		},
+		{
+			name:      "BankerOtherOwner",
+			caller:    &banker,
+			accountID: account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectGetAccount(store, account)
+			},
+			checkResponse: requireAccountFound(account),
+		},
+		{
+			name:      "NotFound",
+			caller:    &user,
+			accountID: account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.Account{}, pgx.ErrNoRows)
+			},
+			checkResponse: requireGetAccountCode(codes.NotFound),
+		},
+		{
+			name:          "InvalidID",
+			caller:        &user,
+			accountID:     0,
+			buildStubs:    expectNoGetAccount,
+			checkResponse: requireGetAccountCode(codes.InvalidArgument),
+		},
+		{
+			name:          "NoAuthorization",
+			accountID:     account.ID,
+			buildStubs:    expectNoGetAccount,
+			checkResponse: requireGetAccountCode(codes.Unauthenticated),
+		},
+		{
+			name:      "InternalError",
+			caller:    &user,
+			accountID: account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.Account{}, errors.New("connection reset"))
+			},
+			checkResponse: requireGetAccountCode(codes.Internal),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+
+			ctx := context.Background()
+			if tc.caller != nil {
+				ctx = newContextWithBearerToken(t, server.tokenMaker, tc.caller.Username, tc.caller.Role)
+			}
+
+			rsp, err := server.GetAccount(ctx, &pb.GetAccountRequest{
+				Id: tc.accountID,
+			})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func expectGetAccount(store *mockdb.MockStore, account db.Account) {
+	store.EXPECT().
+		GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+		Times(1).
+		Return(account, nil)
+}
+
+func expectNoGetAccount(store *mockdb.MockStore) {
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+}
+
+func requireAccountFound(account db.Account) func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+	return func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+		require.NoError(t, err)
+		require.Equal(t, account.ID, rsp.GetAccount().GetId())
+		require.Equal(t, account.Owner, rsp.GetAccount().GetOwner())
+		require.Equal(t, account.Balance, rsp.GetAccount().GetBalance())
+		require.Equal(t, account.Currency, rsp.GetAccount().GetCurrency())
+	}
+}
+
+func requireGetAccountCode(code codes.Code) func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+	return func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+		require.Error(t, err)
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, code, st.Code())
+		require.Nil(t, rsp)
+	}
+}
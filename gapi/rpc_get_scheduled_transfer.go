@@ -0,0 +1,73 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/validation"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recentScheduledTransferRuns is the number of runs returned with a scheduled transfer
+const recentScheduledTransferRuns = 10
+
+func (server *Server) GetScheduledTransfer(ctx context.Context, req *pb.GetScheduledTransferRequest) (*pb.GetScheduledTransferResponse, error) {
+	payload, err := server.authorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateGetScheduledTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	scheduled, err := server.existingScheduledTransfer(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if !canAccess(payload, scheduled.Owner) {
+		return nil, status.Errorf(codes.PermissionDenied, "scheduled transfer doesn't belong to the authenticated user")
+	}
+
+	runs, err := server.store.ListScheduledTransferRuns(ctx, db.ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduled.ID,
+		Limit:               recentScheduledTransferRuns,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "fail to list scheduled transfer runs: %s", err)
+	}
+
+	rsp := &pb.GetScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(scheduled),
+		RecentRuns:        make([]*pb.ScheduledTransferRun, 0, len(runs)),
+	}
+	for _, run := range runs {
+		rsp.RecentRuns = append(rsp.RecentRuns, convertScheduledTransferRun(run))
+	}
+	return rsp, nil
+}
+
+// existingScheduledTransfer gets the scheduled transfer, converting a lookup failure to a status error
+func (server *Server) existingScheduledTransfer(ctx context.Context, id int64) (db.ScheduledTransfer, error) {
+	scheduled, err := server.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return scheduled, status.Errorf(codes.NotFound, "scheduled transfer not found")
+		}
+		return scheduled, status.Errorf(codes.Internal, "fail to get scheduled transfer: %s", err)
+	}
+	return scheduled, nil
+}
+
+func validateGetScheduledTransferRequest(req *pb.GetScheduledTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+	return
+}
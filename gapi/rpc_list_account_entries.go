@@ -0,0 +1,97 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/validation"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const maxEntriesPageSize = 100
+
+func (server *Server) ListAccountEntries(ctx context.Context, req *pb.ListAccountEntriesRequest) (*pb.ListAccountEntriesResponse, error) {
+	payload, err := server.authorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateListAccountEntriesRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := server.store.GetAccount(ctx, req.GetAccountId())
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		return nil, status.Errorf(codes.Internal, "fail to get account: %s", err)
+	}
+
+	if !canAccess(payload, account.Owner) {
+		return nil, status.Errorf(codes.PermissionDenied, "account doesn't belong to the authenticated user")
+	}
+
+	arg := db.AccountStatementTxParams{
+		AccountID: account.ID,
+		Direction: req.Direction,
+		AfterID:   req.GetCursor(),
+		PageSize:  req.GetPageSize(),
+	}
+	if req.FromTime != nil {
+		arg.FromTime = pgtype.Timestamptz{Time: req.GetFromTime().AsTime(), Valid: true}
+	}
+	if req.ToTime != nil {
+		arg.ToTime = pgtype.Timestamptz{Time: req.GetToTime().AsTime(), Valid: true}
+	}
+
+	result, err := server.store.AccountStatementTx(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "fail to list account entries: %s", err)
+	}
+
+	rsp := &pb.ListAccountEntriesResponse{
+		AccountId:      account.ID,
+		Currency:       account.Currency,
+		OpeningBalance: result.OpeningBalance,
+		ClosingBalance: result.ClosingBalance,
+		Entries:        make([]*pb.StatementEntry, 0, len(result.Entries)),
+		NextCursor:     result.NextAfterID,
+	}
+	for _, entry := range result.Entries {
+		rsp.Entries = append(rsp.Entries, convertStatementEntry(entry))
+	}
+	return rsp, nil
+}
+
+func validateListAccountEntriesRequest(req *pb.ListAccountEntriesRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	if req.Direction != nil {
+		if err := validation.ValidateEntryDirection(req.GetDirection()); err != nil {
+			violations = append(violations, fieldViolation("direction", err))
+		}
+	}
+
+	if req.GetCursor() < 0 {
+		violations = append(violations, fieldViolation("cursor", errors.New("must not be negative")))
+	}
+
+	if err := validation.ValidatePageSize(req.GetPageSize(), maxEntriesPageSize); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+
+	if req.FromTime != nil && req.ToTime != nil && !req.GetFromTime().AsTime().Before(req.GetToTime().AsTime()) {
+		violations = append(violations, fieldViolation("to_time", errors.New("must be after from_time")))
+	}
+	return
+}
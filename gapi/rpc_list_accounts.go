@@ -0,0 +1,71 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const maxAccountsPageSize = 10
+
+func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	payload, err := server.authorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateListAccountsRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	owner := payload.Username
+	if req.Owner != nil {
+		owner = req.GetOwner()
+	}
+
+	if !canAccess(payload, owner) {
+		return nil, status.Errorf(codes.PermissionDenied, "cannot list other user's accounts")
+	}
+
+	arg := db.ListAccountsParams{
+		Owner:  owner,
+		Limit:  req.GetPageSize(),
+		Offset: (req.GetPageId() - 1) * req.GetPageSize(),
+	}
+
+	accounts, err := server.store.ListAccounts(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "fail to list accounts: %s", err)
+	}
+
+	rsp := &pb.ListAccountsResponse{
+		Accounts: make([]*pb.Account, 0, len(accounts)),
+	}
+	for _, account := range accounts {
+		rsp.Accounts = append(rsp.Accounts, convertAccount(account))
+	}
+	return rsp, nil
+}
+
+func validateListAccountsRequest(req *pb.ListAccountsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(int64(req.GetPageId())); err != nil {
+		violations = append(violations, fieldViolation("page_id", err))
+	}
+
+	if err := validation.ValidatePageSize(req.GetPageSize(), maxAccountsPageSize); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+
+	if req.Owner != nil {
+		if err := validation.ValidateUsername(req.GetOwner()); err != nil {
+			violations = append(violations, fieldViolation("owner", err))
+		}
+	}
+	return
+}
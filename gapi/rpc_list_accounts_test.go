@@ -0,0 +1,171 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestListAccountsAPI(t *testing.T) {
+	depositor := randomUser(util.DepositorRole)
+	otherDepositor := randomUser(util.DepositorRole)
+	banker := randomUser(util.BankerRole)
+	admin := randomUser(util.AdminRole)
+
+	accounts := []db.Account{
+		randomAccount(otherDepositor.Username, util.USD),
+		randomAccount(otherDepositor.Username, util.EUR),
+	}
+
+	testCases := []struct {
+		name          string
+		caller        *db.User
+		owner         *string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.ListAccountsResponse, err error)
+	}{
+		{
+			name:   "OwnAccounts",
+			caller: &depositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectListAccounts(store, depositor.Username, accounts)
+			},
+			checkResponse: requireAccountsListed(accounts),
+		},
+		{
+			name:   "OwnAccountsByOwner",
+			caller: &depositor,
+			owner:  proto.String(depositor.Username),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectListAccounts(store, depositor.Username, accounts)
+			},
+			checkResponse: requireAccountsListed(accounts),
+		},
+		{
+			name:          "DepositorOtherOwner",
+			caller:        &depositor,
+			owner:         proto.String(otherDepositor.Username),
+			buildStubs:    expectNoListAccounts,
+			checkResponse: requireListAccountsCode(codes.PermissionDenied),
+		},
+		{
+			name:   "BankerOtherOwner",
+			caller: &banker,
+			owner:  proto.String(otherDepositor.Username),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectListAccounts(store, otherDepositor.Username, accounts)
+			},
+			checkResponse: requireAccountsListed(accounts),
+		},
+		{
+			name:   "AdminOtherOwner",
+			caller: &admin,
+			owner:  proto.String(otherDepositor.Username),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectListAccounts(store, otherDepositor.Username, accounts)
+			},
+			checkResponse: requireAccountsListed(accounts),
+		},
+		{
+			name:   "BankerOwnAccounts",
+			caller: &banker,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectListAccounts(store, banker.Username, nil)
+			},
+			checkResponse: requireAccountsListed(nil),
+		},
+		{
+			name:          "InvalidOwner",
+			caller:        &banker,
+			owner:         proto.String("not a username"),
+			buildStubs:    expectNoListAccounts,
+			checkResponse: requireListAccountsCode(codes.InvalidArgument),
+		},
+		{
+			name:          "NoAuthorization",
+			buildStubs:    expectNoListAccounts,
+			checkResponse: requireListAccountsCode(codes.Unauthenticated),
+		},
+		{
+			name:   "InternalError",
+			caller: &depositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccounts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, errors.New("connection reset"))
+			},
+			checkResponse: requireListAccountsCode(codes.Internal),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			ctx := context.Background()
+			if tc.caller != nil {
+				ctx = newContextWithBearerToken(t, server.tokenMaker, tc.caller.Username, tc.caller.Role)
+			}
+
+			rsp, err := server.ListAccounts(ctx, &pb.ListAccountsRequest{
+				PageId:   1,
+				PageSize: 5,
+				Owner:    tc.owner,
+			})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func expectListAccounts(store *mockdb.MockStore, owner string, accounts []db.Account) {
+	arg := db.ListAccountsParams{
+		Owner:  owner,
+		Limit:  5,
+		Offset: 0,
+	}
+	store.EXPECT().
+		ListAccounts(gomock.Any(), gomock.Eq(arg)).
+		Times(1).
+		Return(accounts, nil)
+}
+
+func expectNoListAccounts(store *mockdb.MockStore) {
+	store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+}
+
+func requireAccountsListed(accounts []db.Account) func(t *testing.T, rsp *pb.ListAccountsResponse, err error) {
+	return func(t *testing.T, rsp *pb.ListAccountsResponse, err error) {
+		require.NoError(t, err)
+		require.Len(t, rsp.GetAccounts(), len(accounts))
+		for i, account := range accounts {
+			require.Equal(t, account.ID, rsp.GetAccounts()[i].GetId())
+			require.Equal(t, account.Owner, rsp.GetAccounts()[i].GetOwner())
+		}
+	}
+}
+
+func requireListAccountsCode(code codes.Code) func(t *testing.T, rsp *pb.ListAccountsResponse, err error) {
+	return func(t *testing.T, rsp *pb.ListAccountsResponse, err error) {
+		require.Error(t, err)
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, code, st.Code())
+		require.Nil(t, rsp)
+	}
+}
@@ -0,0 +1,56 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const maxScheduledTransfersPageSize = 10
+
+func (server *Server) ListScheduledTransfers(ctx context.Context, req *pb.ListScheduledTransfersRequest) (*pb.ListScheduledTransfersResponse, error) {
+	payload, err := server.authorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateListScheduledTransfersRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	arg := db.ListScheduledTransfersParams{
+		Owner:  payload.Username,
+		Limit:  req.GetPageSize(),
+		Offset: (req.GetPageId() - 1) * req.GetPageSize(),
+	}
+
+	scheduledTransfers, err := server.store.ListScheduledTransfers(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "fail to list scheduled transfers: %s", err)
+	}
+
+	rsp := &pb.ListScheduledTransfersResponse{
+		ScheduledTransfers: make([]*pb.ScheduledTransfer, 0, len(scheduledTransfers)),
+	}
+	for _, scheduled := range scheduledTransfers {
+		rsp.ScheduledTransfers = append(rsp.ScheduledTransfers, convertScheduledTransfer(scheduled))
+	}
+	return rsp, nil
+}
+
+func validateListScheduledTransfersRequest(req *pb.ListScheduledTransfersRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(int64(req.GetPageId())); err != nil {
+		violations = append(violations, fieldViolation("page_id", err))
+	}
+
+	if err := validation.ValidatePageSize(req.GetPageSize(), maxScheduledTransfersPageSize); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+	return
+}
@@ -0,0 +1,56 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const maxSessionsPageSize = 20
+
+func (server *Server) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	payload, err := server.authorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateListSessionsRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	arg := db.ListActiveSessionsParams{
+		Username: payload.Username,
+		Limit:    req.GetPageSize(),
+		Offset:   (req.GetPageId() - 1) * req.GetPageSize(),
+	}
+
+	sessions, err := server.store.ListActiveSessions(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "fail to list sessions: %s", err)
+	}
+
+	rsp := &pb.ListSessionsResponse{
+		Sessions: make([]*pb.Session, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		rsp.Sessions = append(rsp.Sessions, convertSession(session))
+	}
+	return rsp, nil
+}
+
+func validateListSessionsRequest(req *pb.ListSessionsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(int64(req.GetPageId())); err != nil {
+		violations = append(violations, fieldViolation("page_id", err))
+	}
+
+	if err := validation.ValidatePageSize(req.GetPageSize(), maxSessionsPageSize); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+	return
+}
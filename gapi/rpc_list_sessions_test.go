@@ -0,0 +1,136 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestListSessionsAPI(t *testing.T) {
+	user := randomUser(util.DepositorRole)
+
+	sessions := []db.ListActiveSessionsRow{
+		randomActiveSession(),
+		randomActiveSession(),
+	}
+
+	testCases := []struct {
+		name          string
+		caller        *db.User
+		pageSize      int32
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.ListSessionsResponse, err error)
+	}{
+		{
+			name:     "OK",
+			caller:   &user,
+			pageSize: 5,
+			buildStubs: func(store *mockdb.MockStore) {
+				// only the sessions of the caller are listed
+				arg := db.ListActiveSessionsParams{
+					Username: user.Username,
+					Limit:    5,
+					Offset:   0,
+				}
+				store.EXPECT().
+					ListActiveSessions(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(sessions, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ListSessionsResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, rsp.GetSessions(), len(sessions))
+				for i, session := range sessions {
+					require.Equal(t, session.FamilyID.String(), rsp.GetSessions()[i].GetId())
+					require.Equal(t, session.UserAgent, rsp.GetSessions()[i].GetUserAgent())
+					require.Equal(t, session.ClientIp, rsp.GetSessions()[i].GetClientIp())
+				}
+			},
+		},
+		{
+			name:     "NoAuthorization",
+			pageSize: 5,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListActiveSessions(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: requireListSessionsCode(codes.Unauthenticated),
+		},
+		{
+			name:     "InvalidPageSize",
+			caller:   &user,
+			pageSize: maxSessionsPageSize + 1,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListActiveSessions(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: requireListSessionsCode(codes.InvalidArgument),
+		},
+		{
+			name:     "InternalError",
+			caller:   &user,
+			pageSize: 5,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListActiveSessions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, errors.New("connection reset"))
+			},
+			checkResponse: requireListSessionsCode(codes.Internal),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			ctx := context.Background()
+			if tc.caller != nil {
+				ctx = newContextWithBearerToken(t, server.tokenMaker, tc.caller.Username, tc.caller.Role)
+			}
+
+			rsp, err := server.ListSessions(ctx, &pb.ListSessionsRequest{
+				PageId:   1,
+				PageSize: tc.pageSize,
+			})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func randomActiveSession() db.ListActiveSessionsRow {
+	now := time.Now()
+	return db.ListActiveSessionsRow{
+		FamilyID:   pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		UserAgent:  util.RandomString(12),
+		ClientIp:   "203.0.113.7",
+		LoggedInAt: pgtype.Timestamptz{Time: now.Add(-time.Hour), Valid: true},
+		RenewedAt:  pgtype.Timestamptz{Time: now, Valid: true},
+		ExpiredAt:  pgtype.Timestamptz{Time: now.Add(time.Hour), Valid: true},
+	}
+}
+
+func requireListSessionsCode(code codes.Code) func(t *testing.T, rsp *pb.ListSessionsResponse, err error) {
+	return func(t *testing.T, rsp *pb.ListSessionsResponse, err error) {
+		require.Error(t, err)
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, code, st.Code())
+		require.Nil(t, rsp)
+	}
+}
@@ -3,9 +3,10 @@ package gapi
 import (
 	"context"
 
+	"github.com/google/uuid"
 	db "github.com/hykura1501/simple_bank/db/sqlc"
 	"github.com/hykura1501/simple_bank/pb"
-	"github.com/hykura1501/simple_bank/util"
+	"github.com/hykura1501/simple_bank/token"
 	"github.com/hykura1501/simple_bank/validation"
 
 	"github.com/jackc/pgx/v5"
@@ -21,34 +22,86 @@ func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (
 	if violations != nil {
 		return nil, invalidArgumentError(violations)
 	}
+
+	clientIP := extractMetadataFromContext(ctx, server.trustedProxies).ClientIP
+	if err := server.reserveLoginAttempt(ctx, req.GetUsername(), clientIP); err != nil {
+		return nil, err
+	}
+
 	user, err := server.store.GetUser(ctx, req.GetUsername())
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, status.Errorf(codes.NotFound, "user not found: %s", err)
-
+			server.passwordHasher.VerifyUnknownUser(req.GetPassword())
+			return nil, errInvalidCredentials
 		}
-		return nil, status.Errorf(codes.Internal, "cannot get user: %s", err)
+		return nil, server.releaseLoginAttempt(ctx, req.GetUsername(), clientIP, status.Errorf(codes.Internal, "cannot get user: %s", err))
+	}
+
+	needsRehash, err := server.passwordHasher.Verify(req.GetPassword(), user.HashedPassword)
+	if err != nil {
+		return nil, errInvalidCredentials
+	}
+	if err := server.releaseLoginAttempt(ctx, req.GetUsername(), clientIP, nil); err != nil {
+		return nil, err
+	}
+	if needsRehash {
+		server.rehashPassword(ctx, user, req.GetPassword())
+	}
+
+	_, mfaEnabled, err := server.enabledMFA(ctx, user.Username)
+	if err != nil {
+		return nil, err
+	}
+	if mfaEnabled {
+		return server.mfaChallenge(user)
+	}
+
+	return server.createLoginSession(ctx, user)
+}
+
+// mfaChallenge returns the response of a login that must be completed with VerifyLoginMFA.
+// The mfa token is signed with its own key, so it cannot be used as an access token
+func (server *Server) mfaChallenge(user db.User) (*pb.LoginUserResponse, error) {
+	mfaToken, mfaTokenPayload, err := server.mfaChallengeMaker.CreateToken(user.Username, user.Role, pgtype.UUID{}, token.TokenTypeMFAChallenge, server.config.MFAChallengeDuration)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot create mfa token: %s", err)
+	}
+
+	rsp := &pb.LoginUserResponse{
+		MfaRequired:       true,
+		MfaToken:          mfaToken,
+		MfaTokenExpiresAt: timestamppb.New(mfaTokenPayload.ExpiredAt),
+	}
+	return rsp, nil
+}
+
+// createLoginSession starts a new session for the authenticated user, and forgets their failed logins
+func (server *Server) createLoginSession(ctx context.Context, user db.User) (*pb.LoginUserResponse, error) {
+	if err := server.loginLimiter.RecordSuccess(ctx, user.Username); err != nil {
+		return nil, status.Errorf(codes.Internal, "fail to reset failed logins: %s", err)
 	}
 
-	if err := util.CheckPassword(req.Password, user.HashedPassword); err != nil {
-		return nil, status.Errorf(codes.Unauthenticated, "invalid password: %s", err)
+	sessionID := pgtype.UUID{
+		Bytes: uuid.New(),
+		Valid: true,
 	}
 
-	accessToken, accessTokenPayload, err := server.tokenMaker.CreateToken(req.Username, server.config.AccessTokenDuration)
+	accessToken, accessTokenPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, sessionID, token.TokenTypeAccess, server.config.AccessTokenDuration)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "cannot create access token: %s", err)
 	}
 
-	refreshToken, refreshTokenPayload, err := server.tokenMaker.CreateToken(req.Username, server.config.RefreshTokenDuration)
+	refreshToken, refreshTokenPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, sessionID, token.TokenTypeRefresh, server.config.RefreshTokenDuration)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "cannot create refresh token: %s", err)
 	}
 
-	metadata := extractMetadataFromContext(ctx)
+	metadata := extractMetadataFromContext(ctx, server.trustedProxies)
 
 	arg := db.CreateSessionParams{
 		ID:           refreshTokenPayload.ID,
-		Username:     req.GetUsername(),
+		FamilyID:     sessionID,
+		Username:     user.Username,
 		RefreshToken: refreshToken,
 		UserAgent:    metadata.UserAgent,
 		ClientIp:     metadata.ClientIP,
@@ -64,7 +117,7 @@ func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (
 		return nil, status.Errorf(codes.Internal, "cannot create session: %s", err)
 	}
 	loginUserResponse := &pb.LoginUserResponse{
-		SessionId:             session.ID.String(),
+		SessionId:             session.FamilyID.String(),
 		AccessToken:           accessToken,
 		AccessTokenExpiresAt:  timestamppb.New(accessTokenPayload.ExpiredAt),
 		RefreshToken:          refreshToken,
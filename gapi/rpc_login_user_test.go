@@ -0,0 +1,170 @@
+package gapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/lockout"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoginUserLockoutAPI(t *testing.T) {
+	user := randomUser(util.DepositorRole)
+	password := util.RandomString(12)
+
+	testCases := []struct {
+		name             string
+		maxFailures      int64
+		maxFailuresPerIP int64
+		buildStubs       func(store *mockdb.MockStore)
+		login            func(t *testing.T, server *Server)
+	}{
+		{
+			name:        "LockedUsername",
+			maxFailures: 2,
+			buildStubs: func(store *mockdb.MockStore) {
+				// the locked out login doesn't reach the store
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(2).Return(user, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			login: func(t *testing.T, server *Server) {
+				for range 2 {
+					_, err := loginUser(server, "203.0.113.7", user.Username, "incorrect")
+					requireLoginCode(t, err, codes.Unauthenticated)
+				}
+
+				_, err := loginUser(server, "203.0.113.7", user.Username, password)
+				requireLoginLocked(t, err)
+			},
+		},
+		{
+			name:        "SuccessResetsFailures",
+			maxFailures: 2,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(4).Return(user, nil)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.UserMfa{}, pgx.ErrNoRows)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, nil)
+			},
+			login: func(t *testing.T, server *Server) {
+				_, err := loginUser(server, "203.0.113.7", user.Username, "incorrect")
+				requireLoginCode(t, err, codes.Unauthenticated)
+
+				rsp, err := loginUser(server, "203.0.113.7", user.Username, password)
+				require.NoError(t, err)
+				require.NotEmpty(t, rsp.GetAccessToken())
+
+				for range 2 {
+					_, err := loginUser(server, "203.0.113.7", user.Username, "incorrect")
+					requireLoginCode(t, err, codes.Unauthenticated)
+				}
+			},
+		},
+		{
+			name:             "LockedPeerIP",
+			maxFailures:      100,
+			maxFailuresPerIP: 2,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(3).Return(db.User{}, pgx.ErrNoRows)
+			},
+			login: func(t *testing.T, server *Server) {
+				for range 2 {
+					_, err := loginUser(server, "203.0.113.7", util.RandomOwner(), "incorrect")
+					requireLoginCode(t, err, codes.Unauthenticated)
+				}
+
+				_, err := loginUser(server, "203.0.113.7", util.RandomOwner(), "incorrect")
+				requireLoginLocked(t, err)
+
+				// other clients can still log in
+				_, err = loginUser(server, "203.0.113.8", util.RandomOwner(), "incorrect")
+				requireLoginCode(t, err, codes.Unauthenticated)
+			},
+		},
+		{
+			name:             "SpoofedForwardedFor",
+			maxFailures:      100,
+			maxFailuresPerIP: 2,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(2).Return(db.User{}, pgx.ErrNoRows)
+			},
+			login: func(t *testing.T, server *Server) {
+				// x-forwarded-for is set by the client, so a new value per request must not reset the per-IP lockout
+				for _, forwardedFor := range []string{"198.51.100.1", "198.51.100.2"} {
+					ctx := metadata.NewIncomingContext(peerContext("203.0.113.7"), metadata.Pairs(xForwardedFor, forwardedFor))
+					_, err := server.LoginUser(ctx, &pb.LoginUserRequest{Username: util.RandomOwner(), Password: "incorrect"})
+					requireLoginCode(t, err, codes.Unauthenticated)
+				}
+
+				ctx := metadata.NewIncomingContext(peerContext("203.0.113.7"), metadata.Pairs(xForwardedFor, "198.51.100.3"))
+				_, err := server.LoginUser(ctx, &pb.LoginUserRequest{Username: util.RandomOwner(), Password: "incorrect"})
+				requireLoginLocked(t, err)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			server.loginLimiter = lockout.NewLimiter(lockout.NewMemoryStore(), util.Config{
+				LoginMaxFailures:      tc.maxFailures,
+				LoginMaxFailuresPerIP: tc.maxFailuresPerIP,
+				LoginLockoutDuration:  time.Minute,
+			})
+
+			hashedPassword, err := server.passwordHasher.Hash(password)
+			require.NoError(t, err)
+			user.HashedPassword = hashedPassword
+
+			tc.login(t, server)
+		})
+	}
+}
+
+func loginUser(server *Server, clientIP string, username string, password string) (*pb.LoginUserResponse, error) {
+	return server.LoginUser(peerContext(clientIP), &pb.LoginUserRequest{
+		Username: username,
+		Password: password,
+	})
+}
+
+func peerContext(ip string) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 51234},
+	})
+}
+
+func requireLoginCode(t *testing.T, err error, code codes.Code) {
+	require.Error(t, err)
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, code, st.Code())
+}
+
+func requireLoginLocked(t *testing.T, err error) {
+	requireLoginCode(t, err, codes.ResourceExhausted)
+
+	st, _ := status.FromError(err)
+	require.Len(t, st.Details(), 1)
+	retryInfo, ok := st.Details()[0].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	require.InDelta(t, time.Minute, retryInfo.GetRetryDelay().AsDuration(), float64(time.Second))
+}
@@ -0,0 +1,38 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hykura1501/simple_bank/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	violations := validateLogoutRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	_, session, err := server.verifySession(ctx, req.GetRefreshToken())
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = server.store.BlockSessionFamily(ctx, session.FamilyID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot block session: %s", err)
+	}
+	server.revocations.RevokeSession(session.FamilyID)
+
+	return &pb.LogoutResponse{}, nil
+}
+
+func validateLogoutRequest(req *pb.LogoutRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetRefreshToken() == "" {
+		violations = append(violations, fieldViolation("refresh_token", errors.New("must not be empty")))
+	}
+	return
+}
@@ -0,0 +1,26 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/hykura1501/simple_bank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) LogoutAll(ctx context.Context, req *pb.LogoutAllRequest) (*pb.LogoutAllResponse, error) {
+	payload, err := server.authorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	blockedSessions, err := server.store.BlockUserSessions(ctx, payload.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot block sessions: %s", err)
+	}
+
+	rsp := &pb.LogoutAllResponse{
+		BlockedSessions: blockedSessions,
+	}
+	return rsp, nil
+}
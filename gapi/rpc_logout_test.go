@@ -0,0 +1,208 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/revocation"
+	"github.com/hykura1501/simple_bank/token"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLogoutAPI(t *testing.T) {
+	user := randomUser(util.DepositorRole)
+
+	testCases := []struct {
+		name          string
+		tokenType     token.TokenType
+		buildStubs    func(store *mockdb.MockStore, session db.Session)
+		checkResponse func(t *testing.T, server *Server, session db.Session, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				expectGetSession(store, session)
+				store.EXPECT().
+					BlockSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).
+					Times(1).
+					Return(int64(1), nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, session db.Session, err error) {
+				require.NoError(t, err)
+
+				payload, err := token.NewPayload(session.Username, user.Role, session.FamilyID, token.TokenTypeAccess, time.Minute)
+				require.NoError(t, err)
+				require.ErrorIs(t, server.revocations.Check(context.Background(), payload), revocation.ErrTokenRevoked)
+			},
+		},
+		{
+			name: "ReusedRefreshToken",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.IsUsed = true
+				expectGetSession(store, session)
+				// the family is blocked for the reuse, not for the logout
+				store.EXPECT().
+					BlockSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).
+					Times(1).
+					Return(int64(2), nil)
+			},
+			checkResponse: requireLogoutCode(codes.Unauthenticated),
+		},
+		{
+			name: "BlockedSession",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.IsBlocked = true
+				expectGetSession(store, session)
+				expectNoBlockSessionFamily(store)
+			},
+			checkResponse: requireLogoutCode(codes.Unauthenticated),
+		},
+		{
+			name: "ExpiredSession",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.ExpiredAt = pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true}
+				expectGetSession(store, session)
+				expectNoBlockSessionFamily(store)
+			},
+			checkResponse: requireLogoutCode(codes.Unauthenticated),
+		},
+		{
+			name:      "AccessToken",
+			tokenType: token.TokenTypeAccess,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+				expectNoBlockSessionFamily(store)
+			},
+			checkResponse: requireLogoutCode(codes.Unauthenticated),
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				expectGetSession(store, session)
+				store.EXPECT().
+					BlockSessionFamily(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), errors.New("connection reset"))
+			},
+			checkResponse: requireLogoutCode(codes.Internal),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store, nil)
+
+			tokenType := tc.tokenType
+			if tokenType == "" {
+				tokenType = token.TokenTypeRefresh
+			}
+			session := randomSession(t, server.tokenMaker, user, tokenType)
+			tc.buildStubs(store, session)
+
+			_, err := server.Logout(context.Background(), &pb.LogoutRequest{
+				RefreshToken: session.RefreshToken,
+			})
+			tc.checkResponse(t, server, session, err)
+		})
+	}
+}
+
+func TestLogoutAllAPI(t *testing.T) {
+	user := randomUser(util.DepositorRole)
+
+	testCases := []struct {
+		name          string
+		caller        *db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.LogoutAllResponse, err error)
+	}{
+		{
+			name:   "OK",
+			caller: &user,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BlockUserSessions(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(int64(3), nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.LogoutAllResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(3), rsp.GetBlockedSessions())
+			},
+		},
+		{
+			name: "NoAuthorization",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockUserSessions(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: requireLogoutAllCode(codes.Unauthenticated),
+		},
+		{
+			name:   "InternalError",
+			caller: &user,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BlockUserSessions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), errors.New("connection reset"))
+			},
+			checkResponse: requireLogoutAllCode(codes.Internal),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			ctx := context.Background()
+			if tc.caller != nil {
+				ctx = newContextWithBearerToken(t, server.tokenMaker, tc.caller.Username, tc.caller.Role)
+			}
+
+			rsp, err := server.LogoutAll(ctx, &pb.LogoutAllRequest{})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func expectNoBlockSessionFamily(store *mockdb.MockStore) {
+	store.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Any()).Times(0)
+}
+
+func requireLogoutCode(code codes.Code) func(t *testing.T, server *Server, session db.Session, err error) {
+	return func(t *testing.T, server *Server, session db.Session, err error) {
+		require.Error(t, err)
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, code, st.Code())
+	}
+}
+
+func requireLogoutAllCode(code codes.Code) func(t *testing.T, rsp *pb.LogoutAllResponse, err error) {
+	return func(t *testing.T, rsp *pb.LogoutAllResponse, err error) {
+		require.Error(t, err)
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, code, st.Code())
+		require.Nil(t, rsp)
+	}
+}
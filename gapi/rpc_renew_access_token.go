@@ -0,0 +1,81 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/token"
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (server *Server) RenewAccessToken(ctx context.Context, req *pb.RenewAccessTokenRequest) (*pb.RenewAccessTokenResponse, error) {
+	violations := validateRenewAccessTokenRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	payload, session, err := server.verifySession(ctx, req.GetRefreshToken())
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, accessTokenPayload, err := server.tokenMaker.CreateToken(payload.Username, payload.Role, session.FamilyID, token.TokenTypeAccess, server.config.AccessTokenDuration)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot create access token: %s", err)
+	}
+
+	// the rotated refresh token keeps the expiry of the login session
+	refreshToken, refreshTokenPayload, err := server.tokenMaker.CreateToken(payload.Username, payload.Role, session.FamilyID, token.TokenTypeRefresh, time.Until(session.ExpiredAt.Time))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot create refresh token: %s", err)
+	}
+
+	metadata := extractMetadataFromContext(ctx, server.trustedProxies)
+
+	arg := db.RotateSessionTxParams{
+		OldSession: session,
+		NewSession: db.CreateSessionParams{
+			ID:           refreshTokenPayload.ID,
+			Username:     session.Username,
+			RefreshToken: refreshToken,
+			UserAgent:    metadata.UserAgent,
+			ClientIp:     metadata.ClientIP,
+			IsBlocked:    false,
+			ExpiredAt: pgtype.Timestamptz{
+				Time:  refreshTokenPayload.ExpiredAt,
+				Valid: true,
+			},
+		},
+	}
+
+	_, err = server.store.RotateSessionTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrRefreshTokenReused) {
+			server.revocations.RevokeSession(session.FamilyID)
+			return nil, status.Errorf(codes.Unauthenticated, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "cannot rotate session: %s", err)
+	}
+
+	rsp := &pb.RenewAccessTokenResponse{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  timestamppb.New(accessTokenPayload.ExpiredAt),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: timestamppb.New(refreshTokenPayload.ExpiredAt),
+	}
+	return rsp, nil
+}
+
+func validateRenewAccessTokenRequest(req *pb.RenewAccessTokenRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetRefreshToken() == "" {
+		violations = append(violations, fieldViolation("refresh_token", errors.New("must not be empty")))
+	}
+	return
+}
@@ -0,0 +1,216 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/revocation"
+	"github.com/hykura1501/simple_bank/token"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRenewAccessTokenAPI(t *testing.T) {
+	user := randomUser(util.DepositorRole)
+
+	testCases := []struct {
+		name          string
+		tokenType     token.TokenType
+		buildStubs    func(store *mockdb.MockStore, session db.Session)
+		checkResponse func(t *testing.T, server *Server, session db.Session, rsp *pb.RenewAccessTokenResponse, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				expectGetSession(store, session)
+				store.EXPECT().
+					RotateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.RotateSessionTxParams) (db.RotateSessionTxResult, error) {
+						require.Equal(t, session, arg.OldSession)
+						require.Equal(t, session.Username, arg.NewSession.Username)
+						require.NotEqual(t, session.ID, arg.NewSession.ID)
+						require.NotEqual(t, session.RefreshToken, arg.NewSession.RefreshToken)
+						require.WithinDuration(t, session.ExpiredAt.Time, arg.NewSession.ExpiredAt.Time, time.Second)
+						return db.RotateSessionTxResult{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, server *Server, session db.Session, rsp *pb.RenewAccessTokenResponse, err error) {
+				require.NoError(t, err)
+				require.NotEqual(t, session.RefreshToken, rsp.GetRefreshToken())
+				require.WithinDuration(t, session.ExpiredAt.Time, rsp.GetRefreshTokenExpiresAt().AsTime(), time.Second)
+
+				accessPayload, err := server.tokenMaker.VerifyToken(rsp.GetAccessToken())
+				require.NoError(t, err)
+				require.Equal(t, token.TokenTypeAccess, accessPayload.Type)
+				require.Equal(t, session.FamilyID, accessPayload.SessionID)
+
+				refreshPayload, err := server.tokenMaker.VerifyToken(rsp.GetRefreshToken())
+				require.NoError(t, err)
+				require.Equal(t, token.TokenTypeRefresh, refreshPayload.Type)
+				require.Equal(t, session.FamilyID, refreshPayload.SessionID)
+			},
+		},
+		{
+			name: "ReusedRefreshToken",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.IsUsed = true
+				expectGetSession(store, session)
+				store.EXPECT().
+					BlockSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).
+					Times(1).
+					Return(int64(2), nil)
+				expectNoRotateSession(store)
+			},
+			checkResponse: requireSessionFamilyRevoked,
+		},
+		{
+			name: "ConcurrentReuse",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				expectGetSession(store, session)
+				store.EXPECT().
+					RotateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RotateSessionTxResult{}, db.ErrRefreshTokenReused)
+			},
+			checkResponse: requireSessionFamilyRevoked,
+		},
+		{
+			name: "BlockedSession",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.IsBlocked = true
+				expectGetSession(store, session)
+				expectNoRotateSession(store)
+			},
+			checkResponse: requireRenewAccessTokenCode(codes.Unauthenticated),
+		},
+		{
+			name: "ExpiredSession",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.ExpiredAt = pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true}
+				expectGetSession(store, session)
+				expectNoRotateSession(store)
+			},
+			checkResponse: requireRenewAccessTokenCode(codes.Unauthenticated),
+		},
+		{
+			name: "MismatchedRefreshToken",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.RefreshToken = util.RandomString(32)
+				expectGetSession(store, session)
+				expectNoRotateSession(store)
+			},
+			checkResponse: requireRenewAccessTokenCode(codes.Unauthenticated),
+		},
+		{
+			name: "SessionNotFound",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(db.Session{}, pgx.ErrNoRows)
+				expectNoRotateSession(store)
+			},
+			checkResponse: requireRenewAccessTokenCode(codes.NotFound),
+		},
+		{
+			name:      "AccessToken",
+			tokenType: token.TokenTypeAccess,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+				expectNoRotateSession(store)
+			},
+			checkResponse: requireRenewAccessTokenCode(codes.Unauthenticated),
+		},
+		{
+			name: "RotateError",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				expectGetSession(store, session)
+				store.EXPECT().
+					RotateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RotateSessionTxResult{}, errors.New("connection reset"))
+			},
+			checkResponse: requireRenewAccessTokenCode(codes.Internal),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store, nil)
+
+			tokenType := tc.tokenType
+			if tokenType == "" {
+				tokenType = token.TokenTypeRefresh
+			}
+			session := randomSession(t, server.tokenMaker, user, tokenType)
+			tc.buildStubs(store, session)
+
+			rsp, err := server.RenewAccessToken(context.Background(), &pb.RenewAccessTokenRequest{
+				RefreshToken: session.RefreshToken,
+			})
+			tc.checkResponse(t, server, session, rsp, err)
+		})
+	}
+}
+
+// randomSession creates a token of tokenType for the user and the session it was issued for
+func randomSession(t *testing.T, tokenMaker token.Maker, user db.User, tokenType token.TokenType) db.Session {
+	familyID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	refreshToken, payload, err := tokenMaker.CreateToken(user.Username, user.Role, familyID, tokenType, time.Hour)
+	require.NoError(t, err)
+
+	return db.Session{
+		ID:           payload.ID,
+		Username:     user.Username,
+		RefreshToken: refreshToken,
+		ExpiredAt:    pgtype.Timestamptz{Time: payload.ExpiredAt, Valid: true},
+		FamilyID:     familyID,
+	}
+}
+
+func expectGetSession(store *mockdb.MockStore, session db.Session) {
+	store.EXPECT().
+		GetSession(gomock.Any(), gomock.Eq(session.ID)).
+		Times(1).
+		Return(session, nil)
+}
+
+func expectNoRotateSession(store *mockdb.MockStore) {
+	store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(0)
+}
+
+// requireSessionFamilyRevoked checks that a reused refresh token is rejected,
+// and that the access tokens of its session family are revoked right away
+func requireSessionFamilyRevoked(t *testing.T, server *Server, session db.Session, rsp *pb.RenewAccessTokenResponse, err error) {
+	requireRenewAccessTokenCode(codes.Unauthenticated)(t, server, session, rsp, err)
+
+	payload, err := token.NewPayload(session.Username, util.DepositorRole, session.FamilyID, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	require.ErrorIs(t, server.revocations.Check(context.Background(), payload), revocation.ErrTokenRevoked)
+}
+
+func requireRenewAccessTokenCode(code codes.Code) func(t *testing.T, server *Server, session db.Session, rsp *pb.RenewAccessTokenResponse, err error) {
+	return func(t *testing.T, server *Server, session db.Session, rsp *pb.RenewAccessTokenResponse, err error) {
+		require.Error(t, err)
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, code, st.Code())
+		require.Nil(t, rsp)
+	}
+}
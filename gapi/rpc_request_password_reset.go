@@ -0,0 +1,78 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hibiken/asynq"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/hykura1501/simple_bank/validation"
+	"github.com/hykura1501/simple_bank/worker"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	violations := validateRequestPasswordResetRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	rsp := &pb.RequestPasswordResetResponse{}
+
+	user, err := server.store.GetUserByEmail(ctx, req.GetEmail())
+	if err != nil {
+		// the response doesn't tell whether the email address is registered
+		if errors.Is(err, pgx.ErrNoRows) {
+			return rsp, nil
+		}
+		return nil, status.Errorf(codes.Internal, "fail to get user: %s", err)
+	}
+
+	resetToken, err := util.RandomSecretCode()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "fail to generate password reset token: %s", err)
+	}
+
+	_, err = server.store.CreatePasswordReset(ctx, db.CreatePasswordResetParams{
+		Username:    user.Username,
+		HashedToken: util.HashSecretCode(resetToken),
+		ExpiredAt: pgtype.Timestamptz{
+			Time:  time.Now().Add(server.config.PasswordResetDuration),
+			Valid: true,
+		},
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "fail to create password reset: %s", err)
+	}
+
+	taskPayload := &worker.PayloadSendPasswordReset{
+		Username: user.Username,
+		Token:    resetToken,
+	}
+	opts := []asynq.Option{
+		asynq.MaxRetry(10),
+		asynq.Queue(worker.QueueCritical),
+	}
+
+	err = server.taskDistributor.DistributeTaskSendPasswordReset(ctx, taskPayload, opts...)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "fail to send password reset email: %s", err)
+	}
+
+	return rsp, nil
+}
+
+func validateRequestPasswordResetRequest(req *pb.RequestPasswordResetRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateEmail(req.GetEmail()); err != nil {
+		violations = append(violations, fieldViolation("email", err))
+	}
+
+	return
+}
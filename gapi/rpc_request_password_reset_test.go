@@ -0,0 +1,158 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/hykura1501/simple_bank/worker"
+	mockwk "github.com/hykura1501/simple_bank/worker/mock"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRequestPasswordResetAPI(t *testing.T) {
+	user := randomUser(util.DepositorRole)
+	resetDuration := 15 * time.Minute
+
+	testCases := []struct {
+		name          string
+		email         string
+		buildStubs    func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor)
+		checkResponse func(t *testing.T, rsp *pb.RequestPasswordResetResponse, err error)
+	}{
+		{
+			name:  "OK",
+			email: user.Email,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				expectGetUserByEmail(store, user)
+
+				var hashedToken string
+				store.EXPECT().
+					CreatePasswordReset(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreatePasswordResetParams) (db.PasswordReset, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.WithinDuration(t, time.Now().Add(resetDuration), arg.ExpiredAt.Time, time.Second)
+						hashedToken = arg.HashedToken
+						return db.PasswordReset{Username: arg.Username, HashedToken: arg.HashedToken, ExpiredAt: arg.ExpiredAt}, nil
+					})
+				distributor.EXPECT().
+					DistributeTaskSendPasswordReset(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, payload *worker.PayloadSendPasswordReset, _ ...any) error {
+						require.Equal(t, user.Username, payload.Username)
+						// only the hash of the emailed token is stored
+						require.NotEqual(t, hashedToken, payload.Token)
+						require.Equal(t, hashedToken, util.HashSecretCode(payload.Token))
+						return nil
+					})
+			},
+			checkResponse: requirePasswordResetRequested,
+		},
+		{
+			name:  "UnknownEmail",
+			email: util.RandomEmail(),
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				// the response is the same as for a registered email address
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, pgx.ErrNoRows)
+				expectNoPasswordReset(store, distributor)
+			},
+			checkResponse: requirePasswordResetRequested,
+		},
+		{
+			name:  "InvalidEmail",
+			email: "not an email",
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Times(0)
+				expectNoPasswordReset(store, distributor)
+			},
+			checkResponse: requireRequestPasswordResetCode(codes.InvalidArgument),
+		},
+		{
+			name:  "GetUserError",
+			email: user.Email,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, errors.New("connection reset"))
+				expectNoPasswordReset(store, distributor)
+			},
+			checkResponse: requireRequestPasswordResetCode(codes.Internal),
+		},
+		{
+			name:  "DistributeTaskError",
+			email: user.Email,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				expectGetUserByEmail(store, user)
+				store.EXPECT().
+					CreatePasswordReset(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PasswordReset{}, nil)
+				distributor.EXPECT().
+					DistributeTaskSendPasswordReset(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(errors.New("cannot enqueue task"))
+			},
+			checkResponse: requireRequestPasswordResetCode(codes.Internal),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			distributor := mockwk.NewMockTaskDistributor(ctrl)
+			tc.buildStubs(store, distributor)
+
+			server := newTestServer(t, store, distributor)
+			server.config.PasswordResetDuration = resetDuration
+
+			rsp, err := server.RequestPasswordReset(context.Background(), &pb.RequestPasswordResetRequest{
+				Email: tc.email,
+			})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func expectGetUserByEmail(store *mockdb.MockStore, user db.User) {
+	store.EXPECT().
+		GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+		Times(1).
+		Return(user, nil)
+}
+
+func expectNoPasswordReset(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+	store.EXPECT().CreatePasswordReset(gomock.Any(), gomock.Any()).Times(0)
+	distributor.EXPECT().DistributeTaskSendPasswordReset(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+}
+
+func requirePasswordResetRequested(t *testing.T, rsp *pb.RequestPasswordResetResponse, err error) {
+	require.NoError(t, err)
+	require.NotNil(t, rsp)
+}
+
+func requireRequestPasswordResetCode(code codes.Code) func(t *testing.T, rsp *pb.RequestPasswordResetResponse, err error) {
+	return func(t *testing.T, rsp *pb.RequestPasswordResetResponse, err error) {
+		require.Error(t, err)
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, code, st.Code())
+		require.Nil(t, rsp)
+	}
+}
@@ -0,0 +1,86 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/hykura1501/simple_bank/validation"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errInvalidPasswordResetToken = status.Error(codes.InvalidArgument, "password reset token is invalid, used or expired")
+
+func (server *Server) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	violations := validateResetPasswordRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	hashedToken := util.HashSecretCode(req.GetToken())
+	passwordReset, err := server.store.GetActivePasswordReset(ctx, hashedToken)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errInvalidPasswordResetToken
+		}
+		return nil, status.Errorf(codes.Internal, "fail to get password reset: %s", err)
+	}
+
+	user, err := server.store.GetUser(ctx, passwordReset.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "fail to get user: %s", err)
+	}
+
+	violations = server.passwordPolicyViolations("password", req.GetPassword(), user.Username, user.Email)
+	historyViolations, err := server.passwordHistoryViolations(ctx, "password", req.GetPassword(), user)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "fail to check password history: %s", err)
+	}
+	violations = append(violations, historyViolations...)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	hashedPassword, err := server.passwordHasher.Hash(req.GetPassword())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "fail to hash password: %s", err)
+	}
+
+	txResult, err := server.store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		HashedToken:    hashedToken,
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errInvalidPasswordResetToken
+		}
+		return nil, status.Errorf(codes.Internal, "fail to reset password: %s", err)
+	}
+
+	// tokens issued before the password change are revoked
+	server.revocations.InvalidateUser(txResult.User.Username)
+
+	// the user proved they own the email address, so an earlier lockout no longer applies
+	if err := server.loginLimiter.RecordSuccess(ctx, txResult.User.Username); err != nil {
+		log.Error().Err(err).Str("username", txResult.User.Username).Msg("fail to reset failed logins")
+	}
+
+	rsp := &pb.ResetPasswordResponse{
+		BlockedSessions: txResult.BlockedSessions,
+	}
+	return rsp, nil
+}
+
+func validateResetPasswordRequest(req *pb.ResetPasswordRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateSecretCode(req.GetToken()); err != nil {
+		violations = append(violations, fieldViolation("token", err))
+	}
+
+	return
+}
@@ -0,0 +1,183 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/lockout"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestResetPasswordAPI(t *testing.T) {
+	user := randomUser(util.DepositorRole)
+	oldPassword := util.RandomString(16)
+	newPassword := util.RandomString(16)
+
+	resetToken, err := util.RandomSecretCode()
+	require.NoError(t, err)
+	passwordReset := db.PasswordReset{
+		ID:          util.RandomInt(1, 1000),
+		Username:    user.Username,
+		HashedToken: util.HashSecretCode(resetToken),
+		ExpiredAt:   pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+
+	testCases := []struct {
+		name          string
+		token         string
+		password      string
+		buildStubs    func(t *testing.T, server *Server, store *mockdb.MockStore)
+		checkResponse func(t *testing.T, server *Server, rsp *pb.ResetPasswordResponse, err error)
+	}{
+		{
+			name:     "OK",
+			token:    resetToken,
+			password: newPassword,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) {
+				expectGetActivePasswordReset(store, passwordReset)
+				expectGetUser(store, user)
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ResetPasswordTxParams) (db.ResetPasswordTxResult, error) {
+						require.Equal(t, passwordReset.HashedToken, arg.HashedToken)
+						_, err := server.passwordHasher.Verify(newPassword, arg.HashedPassword)
+						require.NoError(t, err)
+						return db.ResetPasswordTxResult{User: user, BlockedSessions: 2}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, server *Server, rsp *pb.ResetPasswordResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(2), rsp.GetBlockedSessions())
+
+				// the user proved they own the email address, so they are no longer locked out
+				require.NoError(t, server.loginLimiter.Reserve(context.Background(), user.Username, ""))
+			},
+		},
+		{
+			name:     "UsedOrExpiredToken",
+			token:    resetToken,
+			password: newPassword,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) {
+				// used and expired resets are not active
+				store.EXPECT().
+					GetActivePasswordReset(gomock.Any(), gomock.Eq(passwordReset.HashedToken)).
+					Times(1).
+					Return(db.PasswordReset{}, pgx.ErrNoRows)
+				expectNoResetPasswordTx(store)
+			},
+			checkResponse: requireResetPasswordCode(codes.InvalidArgument),
+		},
+		{
+			name:     "TokenUsedConcurrently",
+			token:    resetToken,
+			password: newPassword,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) {
+				expectGetActivePasswordReset(store, passwordReset)
+				expectGetUser(store, user)
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResetPasswordTxResult{}, pgx.ErrNoRows)
+			},
+			checkResponse: requireResetPasswordCode(codes.InvalidArgument),
+		},
+		{
+			name:     "InvalidToken",
+			token:    "invalid",
+			password: newPassword,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) {
+				store.EXPECT().GetActivePasswordReset(gomock.Any(), gomock.Any()).Times(0)
+				expectNoResetPasswordTx(store)
+			},
+			checkResponse: requireResetPasswordCode(codes.InvalidArgument),
+		},
+		{
+			name:     "CurrentPassword",
+			token:    resetToken,
+			password: oldPassword,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) {
+				server.passwordPolicy.HistorySize = 1
+				expectGetActivePasswordReset(store, passwordReset)
+				expectGetUser(store, user)
+				expectNoResetPasswordTx(store)
+			},
+			checkResponse: requireResetPasswordCode(codes.InvalidArgument),
+		},
+		{
+			name:     "InternalError",
+			token:    resetToken,
+			password: newPassword,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) {
+				expectGetActivePasswordReset(store, passwordReset)
+				expectGetUser(store, user)
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResetPasswordTxResult{}, errors.New("connection reset"))
+			},
+			checkResponse: requireResetPasswordCode(codes.Internal),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store, nil)
+			server.loginLimiter = lockout.NewLimiter(lockout.NewMemoryStore(), util.Config{
+				LoginMaxFailures:     1,
+				LoginLockoutDuration: time.Minute,
+			})
+
+			hashedPassword, err := server.passwordHasher.Hash(oldPassword)
+			require.NoError(t, err)
+			user.HashedPassword = hashedPassword
+
+			// a failed login locks the user out until the password is reset
+			require.NoError(t, server.loginLimiter.Reserve(context.Background(), user.Username, ""))
+
+			tc.buildStubs(t, server, store)
+
+			rsp, err := server.ResetPassword(context.Background(), &pb.ResetPasswordRequest{
+				Token:    tc.token,
+				Password: tc.password,
+			})
+			tc.checkResponse(t, server, rsp, err)
+		})
+	}
+}
+
+func expectGetActivePasswordReset(store *mockdb.MockStore, passwordReset db.PasswordReset) {
+	store.EXPECT().
+		GetActivePasswordReset(gomock.Any(), gomock.Eq(passwordReset.HashedToken)).
+		Times(1).
+		Return(passwordReset, nil)
+}
+
+func expectNoResetPasswordTx(store *mockdb.MockStore) {
+	store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).Times(0)
+}
+
+func requireResetPasswordCode(code codes.Code) func(t *testing.T, server *Server, rsp *pb.ResetPasswordResponse, err error) {
+	return func(t *testing.T, server *Server, rsp *pb.ResetPasswordResponse, err error) {
+		require.Error(t, err)
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, code, st.Code())
+		require.Nil(t, rsp)
+	}
+}
@@ -0,0 +1,76 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/fx"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/validation"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ReverseTransfer(ctx context.Context, req *pb.ReverseTransferRequest) (*pb.ReverseTransferResponse, error) {
+	payload, err := server.authorizeUser(ctx, staffRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateReverseTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	arg := db.ReverseTransferTxParams{
+		TransferID: req.GetTransferId(),
+		Amount:     req.GetAmount(),
+		ReasonCode: req.GetReasonCode(),
+		ReversedBy: payload.Username,
+	}
+
+	result, err := server.store.ReverseTransferTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "transfer not found")
+		}
+		if errors.Is(err, db.ErrReversalExceedsTransfer) || errors.Is(err, db.ErrReversalOfReversal) || errors.Is(err, db.ErrInsufficientFunds) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		if errors.Is(err, fx.ErrAmountTooSmall) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "fail to reverse transfer: %s", err)
+	}
+
+	rsp := &pb.ReverseTransferResponse{
+		Transfer:         convertTransfer(result.Transfer),
+		OriginalTransfer: convertTransfer(result.OriginalTransfer),
+		FromAccount:      convertAccount(result.FromAccount),
+		ToAccount:        convertAccount(result.ToAccount),
+		FromEntry:        convertEntry(result.FromEntry),
+		ToEntry:          convertEntry(result.ToEntry),
+		ReasonCode:       result.Reversal.ReasonCode,
+		ReversedBy:       result.Reversal.ReversedBy,
+		RemainingAmount:  result.RemainingAmount,
+	}
+	return rsp, nil
+}
+
+func validateReverseTransferRequest(req *pb.ReverseTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(req.GetTransferId()); err != nil {
+		violations = append(violations, fieldViolation("transfer_id", err))
+	}
+
+	if err := validation.ValidateAmount(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := validation.ValidateReversalReason(req.GetReasonCode()); err != nil {
+		violations = append(violations, fieldViolation("reason_code", err))
+	}
+	return
+}
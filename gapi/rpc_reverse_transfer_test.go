@@ -0,0 +1,205 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/fx"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReverseTransferAPI(t *testing.T) {
+	depositor := randomUser(util.DepositorRole)
+	banker := randomUser(util.BankerRole)
+	admin := randomUser(util.AdminRole)
+
+	transferID := util.RandomInt(1, 1000)
+	amount := util.RandomInt(1, 100)
+
+	testCases := []struct {
+		name          string
+		caller        *db.User
+		reasonCode    string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.ReverseTransferResponse, err error)
+	}{
+		{
+			name:       "Banker",
+			caller:     &banker,
+			reasonCode: util.ReversalReasonDuplicate,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectReverseTransferTx(t, store, banker.Username, util.ReversalReasonDuplicate)
+			},
+			checkResponse: requireTransferReversed(banker.Username, util.ReversalReasonDuplicate),
+		},
+		{
+			name:       "Admin",
+			caller:     &admin,
+			reasonCode: util.ReversalReasonFraud,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectReverseTransferTx(t, store, admin.Username, util.ReversalReasonFraud)
+			},
+			checkResponse: requireTransferReversed(admin.Username, util.ReversalReasonFraud),
+		},
+		{
+			name:          "Depositor",
+			caller:        &depositor,
+			reasonCode:    util.ReversalReasonCustomerRequest,
+			buildStubs:    expectNoReverseTransferTx,
+			checkResponse: requireReverseTransferCode(codes.PermissionDenied),
+		},
+		{
+			name:          "NoAuthorization",
+			reasonCode:    util.ReversalReasonCustomerRequest,
+			buildStubs:    expectNoReverseTransferTx,
+			checkResponse: requireReverseTransferCode(codes.Unauthenticated),
+		},
+		{
+			name:          "UnsupportedReasonCode",
+			caller:        &banker,
+			reasonCode:    "changed_my_mind",
+			buildStubs:    expectNoReverseTransferTx,
+			checkResponse: requireReverseTransferCode(codes.InvalidArgument),
+		},
+		{
+			name:          "MissingReasonCode",
+			caller:        &banker,
+			buildStubs:    expectNoReverseTransferTx,
+			checkResponse: requireReverseTransferCode(codes.InvalidArgument),
+		},
+		{
+			name:       "TransferNotFound",
+			caller:     &banker,
+			reasonCode: util.ReversalReasonProcessingError,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectReverseTransferTxError(store, pgx.ErrNoRows)
+			},
+			checkResponse: requireReverseTransferCode(codes.NotFound),
+		},
+		{
+			name:       "ExceedsTransfer",
+			caller:     &banker,
+			reasonCode: util.ReversalReasonProcessingError,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectReverseTransferTxError(store, db.ErrReversalExceedsTransfer)
+			},
+			checkResponse: requireReverseTransferCode(codes.FailedPrecondition),
+		},
+		{
+			name:       "ReversalOfReversal",
+			caller:     &banker,
+			reasonCode: util.ReversalReasonProcessingError,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectReverseTransferTxError(store, db.ErrReversalOfReversal)
+			},
+			checkResponse: requireReverseTransferCode(codes.FailedPrecondition),
+		},
+		{
+			name:       "InsufficientFunds",
+			caller:     &banker,
+			reasonCode: util.ReversalReasonProcessingError,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectReverseTransferTxError(store, db.ErrInsufficientFunds)
+			},
+			checkResponse: requireReverseTransferCode(codes.FailedPrecondition),
+		},
+		{
+			name:       "AmountTooSmall",
+			caller:     &banker,
+			reasonCode: util.ReversalReasonProcessingError,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectReverseTransferTxError(store, fx.ErrAmountTooSmall)
+			},
+			checkResponse: requireReverseTransferCode(codes.InvalidArgument),
+		},
+		{
+			name:       "InternalError",
+			caller:     &banker,
+			reasonCode: util.ReversalReasonProcessingError,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectReverseTransferTxError(store, errors.New("connection reset"))
+			},
+			checkResponse: requireReverseTransferCode(codes.Internal),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			ctx := context.Background()
+			if tc.caller != nil {
+				ctx = newContextWithBearerToken(t, server.tokenMaker, tc.caller.Username, tc.caller.Role)
+			}
+
+			rsp, err := server.ReverseTransfer(ctx, &pb.ReverseTransferRequest{
+				TransferId: transferID,
+				Amount:     amount,
+				ReasonCode: tc.reasonCode,
+			})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+// expectReverseTransferTx checks that the reversal is recorded with the reason code and the staff member who reversed it
+func expectReverseTransferTx(t *testing.T, store *mockdb.MockStore, reversedBy string, reasonCode string) {
+	store.EXPECT().
+		ReverseTransferTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.ReverseTransferTxParams) (db.ReverseTransferTxResult, error) {
+			require.Equal(t, reversedBy, arg.ReversedBy)
+			require.Equal(t, reasonCode, arg.ReasonCode)
+
+			return db.ReverseTransferTxResult{
+				Reversal: db.TransferReversal{
+					TransferID: arg.TransferID,
+					ReasonCode: arg.ReasonCode,
+					ReversedBy: arg.ReversedBy,
+				},
+			}, nil
+		})
+}
+
+func expectReverseTransferTxError(store *mockdb.MockStore, err error) {
+	store.EXPECT().
+		ReverseTransferTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.ReverseTransferTxResult{}, err)
+}
+
+func expectNoReverseTransferTx(store *mockdb.MockStore) {
+	store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+}
+
+func requireTransferReversed(reversedBy string, reasonCode string) func(t *testing.T, rsp *pb.ReverseTransferResponse, err error) {
+	return func(t *testing.T, rsp *pb.ReverseTransferResponse, err error) {
+		require.NoError(t, err)
+		require.Equal(t, reversedBy, rsp.GetReversedBy())
+		require.Equal(t, reasonCode, rsp.GetReasonCode())
+	}
+}
+
+func requireReverseTransferCode(code codes.Code) func(t *testing.T, rsp *pb.ReverseTransferResponse, err error) {
+	return func(t *testing.T, rsp *pb.ReverseTransferResponse, err error) {
+		require.Error(t, err)
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, code, st.Code())
+		require.Nil(t, rsp)
+	}
+}
@@ -0,0 +1,52 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/validation"
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	payload, err := server.authorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateRevokeSessionRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	var sessionID pgtype.UUID
+	if err := sessionID.Scan(req.GetSessionId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid session id: %s", err)
+	}
+
+	// scoping the update to the caller hides whether sessions of other users exist
+	blocked, err := server.store.BlockUserSessionFamily(ctx, db.BlockUserSessionFamilyParams{
+		FamilyID: sessionID,
+		Username: payload.Username,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "fail to revoke session: %s", err)
+	}
+	if blocked == 0 {
+		return nil, status.Errorf(codes.NotFound, "session not found")
+	}
+	server.revocations.RevokeSession(sessionID)
+
+	return &pb.RevokeSessionResponse{}, nil
+}
+
+func validateRevokeSessionRequest(req *pb.RevokeSessionRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateUUID(req.GetSessionId()); err != nil {
+		violations = append(violations, fieldViolation("session_id", err))
+	}
+	return
+}
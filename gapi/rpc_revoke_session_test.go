@@ -0,0 +1,137 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/revocation"
+	"github.com/hykura1501/simple_bank/token"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRevokeSessionAPI(t *testing.T) {
+	user := randomUser(util.DepositorRole)
+	sessionID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	testCases := []struct {
+		name          string
+		caller        *db.User
+		sessionID     string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, server *Server, err error)
+	}{
+		{
+			name:      "OK",
+			caller:    &user,
+			sessionID: sessionID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectBlockUserSessionFamily(store, user.Username, sessionID, 2)
+			},
+			checkResponse: func(t *testing.T, server *Server, err error) {
+				require.NoError(t, err)
+
+				// the access tokens of the session are rejected without waiting for the cache to expire
+				payload, err := token.NewPayload(user.Username, user.Role, sessionID, token.TokenTypeAccess, time.Minute)
+				require.NoError(t, err)
+				require.ErrorIs(t, server.revocations.Check(context.Background(), payload), revocation.ErrTokenRevoked)
+			},
+		},
+		{
+			name:      "SessionOfOtherUser",
+			caller:    &user,
+			sessionID: sessionID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				// the session is scoped to the caller, so a session of another user is not found
+				expectBlockUserSessionFamily(store, user.Username, sessionID, 0)
+			},
+			checkResponse: func(t *testing.T, server *Server, err error) {
+				requireRevokeSessionCode(codes.NotFound)(t, server, err)
+
+				payload, err := token.NewPayload(util.RandomOwner(), util.DepositorRole, sessionID, token.TokenTypeAccess, time.Minute)
+				require.NoError(t, err)
+				require.NoError(t, server.revocations.Check(context.Background(), payload))
+			},
+		},
+		{
+			name:      "InvalidSessionID",
+			caller:    &user,
+			sessionID: "invalid",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockUserSessionFamily(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: requireRevokeSessionCode(codes.InvalidArgument),
+		},
+		{
+			name:      "NoAuthorization",
+			sessionID: sessionID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockUserSessionFamily(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: requireRevokeSessionCode(codes.Unauthenticated),
+		},
+		{
+			name:      "InternalError",
+			caller:    &user,
+			sessionID: sessionID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BlockUserSessionFamily(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), errors.New("connection reset"))
+			},
+			checkResponse: requireRevokeSessionCode(codes.Internal),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			ctx := context.Background()
+			if tc.caller != nil {
+				ctx = newContextWithBearerToken(t, server.tokenMaker, tc.caller.Username, tc.caller.Role)
+			}
+
+			_, err := server.RevokeSession(ctx, &pb.RevokeSessionRequest{
+				SessionId: tc.sessionID,
+			})
+			tc.checkResponse(t, server, err)
+		})
+	}
+}
+
+func expectBlockUserSessionFamily(store *mockdb.MockStore, username string, sessionID pgtype.UUID, blocked int64) {
+	arg := db.BlockUserSessionFamilyParams{
+		FamilyID: sessionID,
+		Username: username,
+	}
+	store.EXPECT().
+		BlockUserSessionFamily(gomock.Any(), gomock.Eq(arg)).
+		Times(1).
+		Return(blocked, nil)
+}
+
+func requireRevokeSessionCode(code codes.Code) func(t *testing.T, server *Server, err error) {
+	return func(t *testing.T, server *Server, err error) {
+		require.Error(t, err)
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, code, st.Code())
+	}
+}
@@ -0,0 +1,49 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/mfa"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SetupMFA generates a new TOTP secret for the user. Two-factor authentication
+// stays disabled until the secret is confirmed with EnableMFA
+func (server *Server) SetupMFA(ctx context.Context, req *pb.SetupMFARequest) (*pb.SetupMFAResponse, error) {
+	payload, err := server.authorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := mfa.GenerateSecret()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%s", err)
+	}
+
+	encryptedSecret, err := server.mfaSecrets.Seal(payload.Username, secret)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "fail to encrypt secret: %s", err)
+	}
+
+	_, err = server.store.SetupUserMFA(ctx, db.SetupUserMFAParams{
+		Username:        payload.Username,
+		EncryptedSecret: encryptedSecret,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.AlreadyExists, "two-factor authentication is already enabled")
+		}
+		return nil, status.Errorf(codes.Internal, "fail to set up two-factor authentication: %s", err)
+	}
+
+	rsp := &pb.SetupMFAResponse{
+		Secret:          mfa.EncodeSecret(secret),
+		ProvisioningUri: mfa.ProvisioningURI(server.config.MFAIssuer, payload.Username, secret),
+	}
+	return rsp, nil
+}
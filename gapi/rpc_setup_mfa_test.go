@@ -0,0 +1,116 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/mfa"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSetupMFAAPI(t *testing.T) {
+	user := randomUser(util.DepositorRole)
+
+	testCases := []struct {
+		name          string
+		caller        *db.User
+		buildStubs    func(t *testing.T, server *Server, store *mockdb.MockStore, secret *[]byte)
+		checkResponse func(t *testing.T, rsp *pb.SetupMFAResponse, secret []byte, err error)
+	}{
+		{
+			name:   "OK",
+			caller: &user,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore, secret *[]byte) {
+				store.EXPECT().
+					SetupUserMFA(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.SetupUserMFAParams) (db.UserMfa, error) {
+						require.Equal(t, user.Username, arg.Username)
+
+						// the secret is stored encrypted
+						opened, err := server.mfaSecrets.Open(user.Username, arg.EncryptedSecret)
+						require.NoError(t, err)
+						require.NotEqual(t, opened, arg.EncryptedSecret)
+						*secret = opened
+
+						return db.UserMfa{Username: arg.Username, EncryptedSecret: arg.EncryptedSecret}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, rsp *pb.SetupMFAResponse, secret []byte, err error) {
+				require.NoError(t, err)
+				require.Equal(t, mfa.EncodeSecret(secret), rsp.GetSecret())
+				require.Contains(t, rsp.GetProvisioningUri(), "otpauth://totp/")
+				require.Contains(t, rsp.GetProvisioningUri(), rsp.GetSecret())
+			},
+		},
+		{
+			name:   "AlreadyEnabled",
+			caller: &user,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore, secret *[]byte) {
+				store.EXPECT().
+					SetupUserMFA(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserMfa{}, pgx.ErrNoRows)
+			},
+			checkResponse: requireSetupMFACode(codes.AlreadyExists),
+		},
+		{
+			name: "NoAuthorization",
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore, secret *[]byte) {
+				store.EXPECT().SetupUserMFA(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: requireSetupMFACode(codes.Unauthenticated),
+		},
+		{
+			name:   "InternalError",
+			caller: &user,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore, secret *[]byte) {
+				store.EXPECT().
+					SetupUserMFA(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserMfa{}, errors.New("connection reset"))
+			},
+			checkResponse: requireSetupMFACode(codes.Internal),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store, nil)
+
+			var secret []byte
+			tc.buildStubs(t, server, store, &secret)
+
+			ctx := context.Background()
+			if tc.caller != nil {
+				ctx = newContextWithBearerToken(t, server.tokenMaker, tc.caller.Username, tc.caller.Role)
+			}
+
+			rsp, err := server.SetupMFA(ctx, &pb.SetupMFARequest{})
+			tc.checkResponse(t, rsp, secret, err)
+		})
+	}
+}
+
+func requireSetupMFACode(code codes.Code) func(t *testing.T, rsp *pb.SetupMFAResponse, secret []byte, err error) {
+	return func(t *testing.T, rsp *pb.SetupMFAResponse, secret []byte, err error) {
+		require.Error(t, err)
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, code, st.Code())
+		require.Nil(t, rsp)
+	}
+}
@@ -0,0 +1,54 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/validation"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) UpdateAccount(ctx context.Context, req *pb.UpdateAccountRequest) (*pb.UpdateAccountResponse, error) {
+	_, err := server.authorizeUser(ctx, staffRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateUpdateAccountRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	arg := db.UpdateAccountOverdraftLimitParams{
+		ID:             req.GetId(),
+		OverdraftLimit: req.GetOverdraftLimit(),
+	}
+
+	account, err := server.store.UpdateAccountOverdraftLimit(ctx, arg)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		return nil, status.Errorf(codes.Internal, "fail to update account: %s", err)
+	}
+
+	rsp := &pb.UpdateAccountResponse{
+		Account: convertAccount(account),
+	}
+	return rsp, nil
+}
+
+func validateUpdateAccountRequest(req *pb.UpdateAccountRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	if err := validation.ValidateOverdraftLimit(req.GetOverdraftLimit()); err != nil {
+		violations = append(violations, fieldViolation("overdraft_limit", err))
+	}
+	return
+}
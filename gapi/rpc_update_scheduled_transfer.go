@@ -0,0 +1,185 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/validation"
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) UpdateScheduledTransfer(ctx context.Context, req *pb.UpdateScheduledTransferRequest) (*pb.UpdateScheduledTransferResponse, error) {
+	payload, err := server.authorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateUpdateScheduledTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	scheduled, err := server.existingScheduledTransfer(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if scheduled.Owner != payload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "scheduled transfer doesn't belong to the authenticated user")
+	}
+
+	// a new amount, or a paused scheduled transfer that is resumed, must pass the threshold that applies now
+	resumed := req.Paused != nil && !req.GetPaused() && scheduled.Status == db.ScheduledTransferPaused
+	mfaAuthorized := false
+	if req.Amount != nil || resumed {
+		amount := scheduled.Amount
+		if req.Amount != nil {
+			amount = req.GetAmount()
+		}
+
+		fromAccount, err := server.existingAccount(ctx, scheduled.FromAccountID)
+		if err != nil {
+			return nil, err
+		}
+		mfaAuthorized, err = server.requireTransferMFA(ctx, payload.Username, fromAccount.Currency, amount, req.GetTotpCode())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	scheduled, err = server.store.UpdateScheduledTransferTx(ctx, db.UpdateScheduledTransferTxParams{
+		ID: scheduled.ID,
+		Update: func(scheduled db.ScheduledTransfer) (db.UpdateScheduledTransferParams, error) {
+			return updateScheduledTransferParams(scheduled, req, now, mfaAuthorized)
+		},
+	})
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, status.Errorf(codes.Internal, "fail to update scheduled transfer: %s", err)
+	}
+
+	rsp := &pb.UpdateScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(scheduled),
+	}
+	return rsp, nil
+}
+
+// updateScheduledTransferParams applies the request to the locked scheduled transfer.
+// Its next run is worked out again when the schedule or its limits change, or when it is resumed,
+// and it is completed if it has no occurrence left.
+// mfaAuthorized is true if a TOTP code of the request authorized the new amount of the runs
+func updateScheduledTransferParams(scheduled db.ScheduledTransfer, req *pb.UpdateScheduledTransferRequest, now time.Time, mfaAuthorized bool) (db.UpdateScheduledTransferParams, error) {
+	if err := requireScheduledTransferNotEnded(scheduled); err != nil {
+		return db.UpdateScheduledTransferParams{}, err
+	}
+
+	rescheduled := false
+	if req.Amount != nil {
+		scheduled.Amount = req.GetAmount()
+	}
+	if req.Schedule != nil {
+		scheduled.Schedule = req.GetSchedule()
+		rescheduled = true
+	}
+	if req.EndTime != nil {
+		scheduled.EndAt = pgtype.Timestamptz{Time: req.GetEndTime().AsTime(), Valid: true}
+		rescheduled = true
+	}
+	if req.MaxOccurrences != nil {
+		scheduled.MaxOccurrences = req.MaxOccurrences
+		rescheduled = true
+	}
+	if mfaAuthorized {
+		amount := scheduled.Amount
+		scheduled.MfaAuthorizedAmount = &amount
+	}
+	if req.Paused != nil {
+		if req.GetPaused() {
+			scheduled.Status = db.ScheduledTransferPaused
+		} else if scheduled.Status == db.ScheduledTransferPaused {
+			scheduled.Status = db.ScheduledTransferActive
+			rescheduled = true
+		}
+	}
+
+	switch {
+	case scheduled.Status == db.ScheduledTransferPaused:
+		scheduled.NextRunAt = pgtype.Timestamptz{}
+	case rescheduled:
+		recurrence, err := scheduled.Recurrence()
+		if err != nil {
+			return db.UpdateScheduledTransferParams{}, status.Errorf(codes.Internal, "fail to parse schedule: %s", err)
+		}
+
+		nextRunAt, ok := firstScheduledRun(recurrence, scheduled.StartAt.Time, now, scheduled.Occurrences)
+		if ok {
+			scheduled.NextRunAt = pgtype.Timestamptz{Time: nextRunAt, Valid: true}
+		} else {
+			scheduled.NextRunAt = pgtype.Timestamptz{}
+			scheduled.Status = db.ScheduledTransferCompleted
+		}
+	}
+
+	return db.UpdateScheduledTransferParams{
+		ID:                  scheduled.ID,
+		Amount:              scheduled.Amount,
+		Schedule:            scheduled.Schedule,
+		EndAt:               scheduled.EndAt,
+		MaxOccurrences:      scheduled.MaxOccurrences,
+		NextRunAt:           scheduled.NextRunAt,
+		Status:              scheduled.Status,
+		MfaAuthorizedAmount: scheduled.MfaAuthorizedAmount,
+	}, nil
+}
+
+// requireScheduledTransferNotEnded checks that the scheduled transfer is neither completed nor cancelled
+func requireScheduledTransferNotEnded(scheduled db.ScheduledTransfer) error {
+	if scheduled.Status == db.ScheduledTransferCompleted || scheduled.Status == db.ScheduledTransferCancelled {
+		return status.Errorf(codes.FailedPrecondition, "scheduled transfer is %s", scheduled.Status)
+	}
+	return nil
+}
+
+func validateUpdateScheduledTransferRequest(req *pb.UpdateScheduledTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	if req.Amount != nil {
+		if err := validation.ValidateAmount(req.GetAmount()); err != nil {
+			violations = append(violations, fieldViolation("amount", err))
+		}
+	}
+
+	if req.Schedule != nil {
+		if err := validation.ValidateSchedule(req.GetSchedule()); err != nil {
+			violations = append(violations, fieldViolation("schedule", err))
+		}
+	}
+
+	if req.MaxOccurrences != nil {
+		if err := validation.ValidateMaxOccurrences(req.GetMaxOccurrences()); err != nil {
+			violations = append(violations, fieldViolation("max_occurrences", err))
+		}
+	}
+
+	if req.Amount == nil && req.Schedule == nil && req.EndTime == nil && req.MaxOccurrences == nil && req.Paused == nil {
+		violations = append(violations, fieldViolation("id", errors.New("nothing to update")))
+	}
+
+	if req.GetTotpCode() != "" {
+		if err := validation.ValidateTOTPCode(req.GetTotpCode()); err != nil {
+			violations = append(violations, fieldViolation("totp_code", err))
+		}
+	}
+	return
+}
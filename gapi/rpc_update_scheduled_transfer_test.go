@@ -0,0 +1,179 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/mfa"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUpdateScheduledTransferResumeAPI(t *testing.T) {
+	user := randomUser(util.DepositorRole)
+	fromAccount := randomAccount(user.Username, util.USD)
+	threshold := int64(100)
+	paused := false
+	pause := true
+
+	testCases := []struct {
+		name          string
+		amount        int64
+		paused        *bool
+		withTOTPCode  bool
+		buildStubs    func(store *mockdb.MockStore, scheduled db.ScheduledTransfer, userMFA db.UserMfa)
+		checkResponse func(t *testing.T, rsp *pb.UpdateScheduledTransferResponse, err error)
+	}{
+		{
+			name:         "AboveThresholdWithCode",
+			amount:       threshold + 1,
+			paused:       &paused,
+			withTOTPCode: true,
+			buildStubs: func(store *mockdb.MockStore, scheduled db.ScheduledTransfer, userMFA db.UserMfa) {
+				expectScheduledTransferAccount(store, scheduled, fromAccount)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(userMFA, nil)
+				store.EXPECT().UseUserMFAStep(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				expectScheduledTransferUpdate(t, store, scheduled, func(arg db.UpdateScheduledTransferParams) {
+					require.Equal(t, db.ScheduledTransferActive, arg.Status)
+					require.True(t, arg.NextRunAt.Valid)
+					require.Equal(t, &scheduled.Amount, arg.MfaAuthorizedAmount)
+				})
+			},
+			checkResponse: requireScheduledTransferStatus(db.ScheduledTransferActive),
+		},
+		{
+			name:   "AboveThresholdWithoutCode",
+			amount: threshold + 1,
+			paused: &paused,
+			buildStubs: func(store *mockdb.MockStore, scheduled db.ScheduledTransfer, userMFA db.UserMfa) {
+				expectScheduledTransferAccount(store, scheduled, fromAccount)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(userMFA, nil)
+				store.EXPECT().UseUserMFAStep(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.UpdateScheduledTransferResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+				require.Nil(t, rsp)
+			},
+		},
+		{
+			name:   "AboveThresholdWithoutMFA",
+			amount: threshold + 1,
+			paused: &paused,
+			buildStubs: func(store *mockdb.MockStore, scheduled db.ScheduledTransfer, userMFA db.UserMfa) {
+				expectScheduledTransferAccount(store, scheduled, fromAccount)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.UserMfa{}, pgx.ErrNoRows)
+				expectScheduledTransferUpdate(t, store, scheduled, func(arg db.UpdateScheduledTransferParams) {
+					require.Equal(t, db.ScheduledTransferActive, arg.Status)
+					require.Nil(t, arg.MfaAuthorizedAmount)
+				})
+			},
+			checkResponse: requireScheduledTransferStatus(db.ScheduledTransferActive),
+		},
+		{
+			name:   "BelowThreshold",
+			amount: threshold,
+			paused: &paused,
+			buildStubs: func(store *mockdb.MockStore, scheduled db.ScheduledTransfer, userMFA db.UserMfa) {
+				expectScheduledTransferAccount(store, scheduled, fromAccount)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Any()).Times(0)
+				expectScheduledTransferUpdate(t, store, scheduled, func(arg db.UpdateScheduledTransferParams) {
+					require.Equal(t, db.ScheduledTransferActive, arg.Status)
+					require.Nil(t, arg.MfaAuthorizedAmount)
+				})
+			},
+			checkResponse: requireScheduledTransferStatus(db.ScheduledTransferActive),
+		},
+		{
+			name:   "Pause",
+			amount: threshold + 1,
+			paused: &pause,
+			buildStubs: func(store *mockdb.MockStore, scheduled db.ScheduledTransfer, userMFA db.UserMfa) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Any()).Times(0)
+				expectScheduledTransferUpdate(t, store, scheduled, func(arg db.UpdateScheduledTransferParams) {
+					require.Equal(t, db.ScheduledTransferPaused, arg.Status)
+				})
+			},
+			checkResponse: requireScheduledTransferStatus(db.ScheduledTransferPaused),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store, nil)
+			server.mfaThresholds = mfa.TransferThresholds{util.USD: threshold}
+
+			scheduled := db.ScheduledTransfer{
+				ID:            util.RandomInt(1, 1000),
+				Owner:         user.Username,
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   fromAccount.ID + 1,
+				Amount:        tc.amount,
+				Schedule:      "0 9 1 * *",
+				StartAt:       pgtype.Timestamptz{Time: time.Now(), Valid: true},
+				Status:        db.ScheduledTransferPaused,
+			}
+			userMFA, secret := randomEnabledUserMFA(t, server, user)
+			tc.buildStubs(store, scheduled, userMFA)
+
+			req := &pb.UpdateScheduledTransferRequest{
+				Id:     scheduled.ID,
+				Paused: tc.paused,
+			}
+			if tc.withTOTPCode {
+				req.TotpCode = mfa.GenerateCode(secret, time.Now())
+			}
+
+			ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, user.Role)
+			rsp, err := server.UpdateScheduledTransfer(ctx, req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func expectScheduledTransferAccount(store *mockdb.MockStore, scheduled db.ScheduledTransfer, fromAccount db.Account) {
+	store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+}
+
+// expectScheduledTransferUpdate runs the update of the transaction on the scheduled transfer and checks its values
+func expectScheduledTransferUpdate(t *testing.T, store *mockdb.MockStore, scheduled db.ScheduledTransfer, check func(arg db.UpdateScheduledTransferParams)) {
+	store.EXPECT().
+		UpdateScheduledTransferTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.UpdateScheduledTransferTxParams) (db.ScheduledTransfer, error) {
+			updateArg, err := arg.Update(scheduled)
+			require.NoError(t, err)
+			check(updateArg)
+
+			scheduled.Status = updateArg.Status
+			scheduled.NextRunAt = updateArg.NextRunAt
+			scheduled.MfaAuthorizedAmount = updateArg.MfaAuthorizedAmount
+			return scheduled, nil
+		})
+}
+
+func requireScheduledTransferStatus(wantStatus string) func(t *testing.T, rsp *pb.UpdateScheduledTransferResponse, err error) {
+	return func(t *testing.T, rsp *pb.UpdateScheduledTransferResponse, err error) {
+		require.NoError(t, err)
+		require.Equal(t, wantStatus, rsp.GetScheduledTransfer().GetStatus())
+	}
+}
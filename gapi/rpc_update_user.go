@@ -2,11 +2,12 @@ package gapi
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	db "github.com/hykura1501/simple_bank/db/sqlc"
 	"github.com/hykura1501/simple_bank/pb"
-	"github.com/hykura1501/simple_bank/util"
+	"github.com/hykura1501/simple_bank/token"
 	"github.com/hykura1501/simple_bank/validation"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -16,9 +17,9 @@ import (
 )
 
 func (server *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
-	payload, err := server.authorizeUser(ctx)
+	payload, err := server.authorizeUser(ctx, allRoles)
 	if err != nil {
-		return nil, unauthenticatedError(err)
+		return nil, err
 	}
 
 	violations := validateUpdateUserRequest(req)
@@ -26,18 +27,32 @@ func (server *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 		return nil, invalidArgumentError(violations)
 	}
 
-	if req.GetUsername() != payload.Username {
-		return nil, status.Errorf(codes.PermissionDenied, "cannot update other user's info")
+	if payload.Username != req.GetUsername() {
+		if err := server.authorizeStaffUpdate(ctx, payload, req); err != nil {
+			return nil, err
+		}
 	}
 
-	arg := db.UpdateUserParams{
-		Username: req.GetUsername(),
-		FullName: req.FullName,
-		Email:    req.Email,
+	arg := db.UpdateUserTxParams{
+		UpdateUserParams: db.UpdateUserParams{
+			Username: req.GetUsername(),
+			FullName: req.FullName,
+			Email:    req.Email,
+		},
+	}
+	if req.Email != nil {
+		// the new email address must be verified again
+		arg.AfterEmailChange = func(user db.User) error {
+			return server.distributeVerifyEmail(ctx, user)
+		}
 	}
 
 	if req.Password != nil {
-		hashedPassword, err := util.HashPassword(req.GetPassword())
+		if err := server.validateNewPassword(ctx, req); err != nil {
+			return nil, err
+		}
+
+		hashedPassword, err := server.passwordHasher.Hash(req.GetPassword())
 		if err != nil {
 			return nil, status.Errorf(codes.Internal, "fail to hash password: %s", err)
 		}
@@ -50,7 +65,7 @@ func (server *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 		arg.HashedPassword = &hashedPassword
 	}
 
-	user, err := server.store.UpdateUser(ctx, arg)
+	user, err := server.store.UpdateUserTx(ctx, arg)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -58,12 +73,70 @@ func (server *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 		}
 		return nil, status.Errorf(codes.Internal, "failed to update user: %s", err)
 	}
+
+	if req.Password != nil {
+		// tokens issued before the password change are revoked
+		server.revocations.InvalidateUser(user.Username)
+	}
 	rsp := &pb.UpdateUserResponse{
 		User: convertUser(user),
 	}
 	return rsp, nil
 }
 
+// authorizeStaffUpdate checks that a banker or admin may update another user.
+// Staff can only change the full name of a user with a lower role: the password and email
+// are the credentials of the account, so changing them would let staff log in as the user
+func (server *Server) authorizeStaffUpdate(ctx context.Context, payload *token.Payload, req *pb.UpdateUserRequest) error {
+	if !canAccess(payload, req.GetUsername()) {
+		return status.Errorf(codes.PermissionDenied, "cannot update other user's info")
+	}
+
+	if req.Password != nil || req.Email != nil {
+		return status.Errorf(codes.PermissionDenied, "cannot change other user's password or email")
+	}
+
+	user, err := server.store.GetUser(ctx, req.GetUsername())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return status.Errorf(codes.NotFound, "user not found")
+		}
+		return status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+
+	if !outranks(payload.Role, user.Role) {
+		return status.Errorf(codes.PermissionDenied, "cannot update a user with an equal or higher role")
+	}
+	return nil
+}
+
+// validateNewPassword checks the new password against the password policy and the user's password history
+func (server *Server) validateNewPassword(ctx context.Context, req *pb.UpdateUserRequest) error {
+	user, err := server.store.GetUser(ctx, req.GetUsername())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return status.Errorf(codes.NotFound, "user not found")
+		}
+		return status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+
+	email := user.Email
+	if req.Email != nil {
+		email = req.GetEmail()
+	}
+
+	violations := server.passwordPolicyViolations("password", req.GetPassword(), user.Username, email)
+	historyViolations, err := server.passwordHistoryViolations(ctx, "password", req.GetPassword(), user)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to check password history: %s", err)
+	}
+	violations = append(violations, historyViolations...)
+	if violations != nil {
+		return invalidArgumentError(violations)
+	}
+	return nil
+}
+
 func validateUpdateUserRequest(req *pb.UpdateUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
 	if err := validation.ValidateUsername(req.GetUsername()); err != nil {
 		violations = append(violations, fieldViolation("username", err))
@@ -81,11 +154,5 @@ func validateUpdateUserRequest(req *pb.UpdateUserRequest) (violations []*errdeta
 		}
 	}
 
-	if req.Password != nil {
-		if err := validation.ValidatePassword(req.GetPassword()); err != nil {
-			violations = append(violations, fieldViolation("password", err))
-		}
-	}
-
 	return
 }
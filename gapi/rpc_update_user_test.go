@@ -0,0 +1,314 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/hykura1501/simple_bank/worker"
+	mockwk "github.com/hykura1501/simple_bank/worker/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUpdateUserAPI(t *testing.T) {
+	depositor := randomUser(util.DepositorRole)
+	otherDepositor := randomUser(util.DepositorRole)
+	banker := randomUser(util.BankerRole)
+	otherBanker := randomUser(util.BankerRole)
+	admin := randomUser(util.AdminRole)
+	otherAdmin := randomUser(util.AdminRole)
+
+	newName := util.RandomOwner()
+	newEmail := util.RandomEmail()
+	newPassword := util.RandomString(12) + "A1!"
+
+	testCases := []struct {
+		name          string
+		caller        db.User
+		req           *pb.UpdateUserRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.UpdateUserResponse, err error)
+	}{
+		{
+			name:   "OwnFullName",
+			caller: depositor,
+			req: &pb.UpdateUserRequest{
+				Username: depositor.Username,
+				FullName: &newName,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUpdateFullName(store, depositor, newName)
+			},
+			checkResponse: requireUpdatedFullName(newName),
+		},
+		{
+			name:   "DepositorUpdatesOtherUser",
+			caller: depositor,
+			req: &pb.UpdateUserRequest{
+				Username: otherDepositor.Username,
+				FullName: &newName,
+			},
+			buildStubs:    expectNoUpdate,
+			checkResponse: requireUpdateDenied,
+		},
+		{
+			name:   "BankerUpdatesDepositorFullName",
+			caller: banker,
+			req: &pb.UpdateUserRequest{
+				Username: depositor.Username,
+				FullName: &newName,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(depositor.Username)).
+					Times(1).
+					Return(depositor, nil)
+				expectUpdateFullName(store, depositor, newName)
+			},
+			checkResponse: requireUpdatedFullName(newName),
+		},
+		{
+			name:   "BankerChangesDepositorEmail",
+			caller: banker,
+			req: &pb.UpdateUserRequest{
+				Username: depositor.Username,
+				Email:    &newEmail,
+			},
+			buildStubs:    expectNoUpdate,
+			checkResponse: requireUpdateDenied,
+		},
+		{
+			name:   "BankerChangesDepositorPassword",
+			caller: banker,
+			req: &pb.UpdateUserRequest{
+				Username: depositor.Username,
+				Password: &newPassword,
+			},
+			buildStubs:    expectNoUpdate,
+			checkResponse: requireUpdateDenied,
+		},
+		{
+			name:   "BankerUpdatesOtherBanker",
+			caller: banker,
+			req: &pb.UpdateUserRequest{
+				Username: otherBanker.Username,
+				FullName: &newName,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(otherBanker.Username)).
+					Times(1).
+					Return(otherBanker, nil)
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: requireUpdateDenied,
+		},
+		{
+			name:   "BankerUpdatesAdmin",
+			caller: banker,
+			req: &pb.UpdateUserRequest{
+				Username: admin.Username,
+				FullName: &newName,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(admin.Username)).
+					Times(1).
+					Return(admin, nil)
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: requireUpdateDenied,
+		},
+		{
+			name:   "BankerChangesAdminPassword",
+			caller: banker,
+			req: &pb.UpdateUserRequest{
+				Username: admin.Username,
+				Password: &newPassword,
+			},
+			buildStubs:    expectNoUpdate,
+			checkResponse: requireUpdateDenied,
+		},
+		{
+			name:   "AdminUpdatesBankerFullName",
+			caller: admin,
+			req: &pb.UpdateUserRequest{
+				Username: banker.Username,
+				FullName: &newName,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(banker.Username)).
+					Times(1).
+					Return(banker, nil)
+				expectUpdateFullName(store, banker, newName)
+			},
+			checkResponse: requireUpdatedFullName(newName),
+		},
+		{
+			name:   "AdminUpdatesOtherAdmin",
+			caller: admin,
+			req: &pb.UpdateUserRequest{
+				Username: otherAdmin.Username,
+				FullName: &newName,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(otherAdmin.Username)).
+					Times(1).
+					Return(otherAdmin, nil)
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: requireUpdateDenied,
+		},
+		{
+			name:   "AdminChangesBankerEmail",
+			caller: admin,
+			req: &pb.UpdateUserRequest{
+				Username: banker.Username,
+				Email:    &newEmail,
+			},
+			buildStubs:    expectNoUpdate,
+			checkResponse: requireUpdateDenied,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, tc.caller.Username, tc.caller.Role)
+
+			rsp, err := server.UpdateUser(ctx, tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func TestUpdateUserEmailAPI(t *testing.T) {
+	user := randomUser(util.DepositorRole)
+	newEmail := util.RandomEmail()
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor)
+		checkResponse func(t *testing.T, rsp *pb.UpdateUserResponse, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				updated := user
+				updated.Email = newEmail
+				updated.IsEmailVerified = false
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateUserTxParams) (db.User, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, &newEmail, arg.Email)
+						require.NotNil(t, arg.AfterEmailChange)
+						return updated, arg.AfterEmailChange(updated)
+					})
+				distributor.EXPECT().
+					DistributeTaskSendVerifyEmail(gomock.Any(), gomock.Eq(&worker.PayloadSendVerifyEmail{Username: user.Username}), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.UpdateUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, newEmail, rsp.GetUser().GetEmail())
+				require.False(t, rsp.GetUser().GetIsEmailVerified())
+			},
+		},
+		{
+			name: "DistributeTaskError",
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateUserTxParams) (db.User, error) {
+						updated := user
+						updated.Email = newEmail
+						return db.User{}, arg.AfterEmailChange(updated)
+					})
+				distributor.EXPECT().
+					DistributeTaskSendVerifyEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(errors.New("cannot enqueue task"))
+			},
+			checkResponse: func(t *testing.T, rsp *pb.UpdateUserResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Internal, st.Code())
+				require.Nil(t, rsp)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			distributor := mockwk.NewMockTaskDistributor(ctrl)
+			tc.buildStubs(store, distributor)
+
+			server := newTestServer(t, store, distributor)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, user.Role)
+
+			rsp, err := server.UpdateUser(ctx, &pb.UpdateUserRequest{
+				Username: user.Username,
+				Email:    &newEmail,
+			})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func expectUpdateFullName(store *mockdb.MockStore, user db.User, fullName string) {
+	updated := user
+	updated.FullName = fullName
+	store.EXPECT().
+		UpdateUserTx(gomock.Any(), gomock.Eq(db.UpdateUserTxParams{
+			UpdateUserParams: db.UpdateUserParams{
+				Username: user.Username,
+				FullName: &fullName,
+			},
+		})).
+		Times(1).
+		Return(updated, nil)
+}
+
+func expectNoUpdate(store *mockdb.MockStore) {
+	store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+}
+
+func requireUpdatedFullName(fullName string) func(t *testing.T, rsp *pb.UpdateUserResponse, err error) {
+	return func(t *testing.T, rsp *pb.UpdateUserResponse, err error) {
+		require.NoError(t, err)
+		require.Equal(t, fullName, rsp.GetUser().GetFullName())
+	}
+}
+
+func requireUpdateDenied(t *testing.T, rsp *pb.UpdateUserResponse, err error) {
+	require.Error(t, err)
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, codes.PermissionDenied, st.Code())
+	require.Nil(t, rsp)
+}
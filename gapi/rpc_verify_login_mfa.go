@@ -0,0 +1,82 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/token"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// VerifyLoginMFA completes the login of a user with two-factor authentication,
+// creating the session that LoginUser held back
+func (server *Server) VerifyLoginMFA(ctx context.Context, req *pb.VerifyLoginMFARequest) (*pb.LoginUserResponse, error) {
+	violations := validateVerifyLoginMFARequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	payload, err := server.mfaChallengeMaker.VerifyToken(req.GetMfaToken())
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid mfa token: %s", err)
+	}
+
+	if err := payload.CheckType(token.TokenTypeMFAChallenge); err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid mfa token: %s", err)
+	}
+
+	// the challenge is void if the password changed after it was issued
+	if err := server.revocations.Check(ctx, payload); err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid mfa token: %s", err)
+	}
+
+	clientIP := extractMetadataFromContext(ctx, server.trustedProxies).ClientIP
+	if err := server.reserveLoginAttempt(ctx, payload.Username, clientIP); err != nil {
+		return nil, err
+	}
+
+	user, err := server.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = status.Errorf(codes.NotFound, "user not found")
+		} else {
+			err = status.Errorf(codes.Internal, "cannot get user: %s", err)
+		}
+		return nil, server.releaseLoginAttempt(ctx, payload.Username, clientIP, err)
+	}
+
+	userMFA, enabled, err := server.enabledMFA(ctx, user.Username)
+	if err != nil {
+		return nil, server.releaseLoginAttempt(ctx, user.Username, clientIP, err)
+	}
+	if !enabled {
+		err := status.Errorf(codes.FailedPrecondition, "two-factor authentication is not enabled")
+		return nil, server.releaseLoginAttempt(ctx, user.Username, clientIP, err)
+	}
+
+	err = server.useMFACode(ctx, userMFA, req.GetTotpCode(), req.GetRecoveryCode())
+	if err != nil {
+		// a wrong code stays counted as a failed login
+		if status.Code(err) == codes.Unauthenticated {
+			return nil, err
+		}
+		return nil, server.releaseLoginAttempt(ctx, user.Username, clientIP, err)
+	}
+	if err := server.releaseLoginAttempt(ctx, user.Username, clientIP, nil); err != nil {
+		return nil, err
+	}
+
+	return server.createLoginSession(ctx, user)
+}
+
+func validateVerifyLoginMFARequest(req *pb.VerifyLoginMFARequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetMfaToken() == "" {
+		violations = append(violations, fieldViolation("mfa_token", errors.New("must not be empty")))
+	}
+	violations = append(violations, validateMFACodes(req.GetTotpCode(), req.GetRecoveryCode())...)
+	return
+}
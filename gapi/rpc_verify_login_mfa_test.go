@@ -0,0 +1,253 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/lockout"
+	"github.com/hykura1501/simple_bank/mfa"
+	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/token"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestVerifyLoginMFAAPI(t *testing.T) {
+	user := randomUser(util.DepositorRole)
+	recoveryCode := randomRecoveryCode(t)
+
+	testCases := []struct {
+		name          string
+		mfaToken      func(t *testing.T, server *Server) string
+		totpCode      func(secret []byte) string
+		recoveryCode  string
+		buildStubs    func(t *testing.T, store *mockdb.MockStore, userMFA db.UserMfa)
+		checkResponse func(t *testing.T, server *Server, rsp *pb.LoginUserResponse, err error)
+	}{
+		{
+			name:     "TOTPCode",
+			totpCode: currentTOTPCode,
+			buildStubs: func(t *testing.T, store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectGetUser(store, user)
+				expectGetUserMFA(store, userMFA)
+				expectUseUserMFAStep(t, store, user.Username, 1)
+				expectCreateSession(store, user)
+			},
+			checkResponse: requireLoggedIn(user),
+		},
+		{
+			name:         "RecoveryCode",
+			recoveryCode: recoveryCode,
+			buildStubs: func(t *testing.T, store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectGetUser(store, user)
+				expectGetUserMFA(store, userMFA)
+				expectUseMFARecoveryCode(store, user.Username, recoveryCode, 1)
+				expectCreateSession(store, user)
+			},
+			checkResponse: requireLoggedIn(user),
+		},
+		{
+			name: "WrongCode",
+			totpCode: func(secret []byte) string {
+				return mfa.GenerateCode(secret, time.Now().Add(-time.Hour))
+			},
+			buildStubs: func(t *testing.T, store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectGetUser(store, user)
+				expectGetUserMFA(store, userMFA)
+				store.EXPECT().UseUserMFAStep(gomock.Any(), gomock.Any()).Times(0)
+				expectNoCreateSession(store)
+			},
+			checkResponse: requireWrongMFACodeCounted(user),
+		},
+		{
+			name:     "ReplayedCode",
+			totpCode: currentTOTPCode,
+			buildStubs: func(t *testing.T, store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectGetUser(store, user)
+				expectGetUserMFA(store, userMFA)
+				// the code already logged in once
+				expectUseUserMFAStep(t, store, user.Username, 0)
+				expectNoCreateSession(store)
+			},
+			checkResponse: requireWrongMFACodeCounted(user),
+		},
+		{
+			name:         "UsedRecoveryCode",
+			recoveryCode: recoveryCode,
+			buildStubs: func(t *testing.T, store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectGetUser(store, user)
+				expectGetUserMFA(store, userMFA)
+				expectUseMFARecoveryCode(store, user.Username, recoveryCode, 0)
+				expectNoCreateSession(store)
+			},
+			checkResponse: requireWrongMFACodeCounted(user),
+		},
+		{
+			name: "ExpiredMFAToken",
+			mfaToken: func(t *testing.T, server *Server) string {
+				return newMFAToken(t, server.mfaChallengeMaker, user, token.TokenTypeMFAChallenge, -time.Minute)
+			},
+			totpCode: currentTOTPCode,
+			buildStubs: func(t *testing.T, store *mockdb.MockStore, userMFA db.UserMfa) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				expectNoCreateSession(store)
+			},
+			checkResponse: requireVerifyLoginMFACode(codes.Unauthenticated),
+		},
+		{
+			name: "AccessTokenAsMFAToken",
+			mfaToken: func(t *testing.T, server *Server) string {
+				return newMFAToken(t, server.tokenMaker, user, token.TokenTypeAccess, time.Minute)
+			},
+			totpCode: currentTOTPCode,
+			buildStubs: func(t *testing.T, store *mockdb.MockStore, userMFA db.UserMfa) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				expectNoCreateSession(store)
+			},
+			checkResponse: requireVerifyLoginMFACode(codes.Unauthenticated),
+		},
+		{
+			name: "WrongTokenType",
+			mfaToken: func(t *testing.T, server *Server) string {
+				return newMFAToken(t, server.mfaChallengeMaker, user, token.TokenTypeAccess, time.Minute)
+			},
+			totpCode: currentTOTPCode,
+			buildStubs: func(t *testing.T, store *mockdb.MockStore, userMFA db.UserMfa) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				expectNoCreateSession(store)
+			},
+			checkResponse: requireVerifyLoginMFACode(codes.Unauthenticated),
+		},
+		{
+			name:     "MFANotEnabled",
+			totpCode: currentTOTPCode,
+			buildStubs: func(t *testing.T, store *mockdb.MockStore, userMFA db.UserMfa) {
+				expectGetUser(store, user)
+				store.EXPECT().
+					GetUserMFA(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserMfa{}, pgx.ErrNoRows)
+				expectNoCreateSession(store)
+			},
+			checkResponse: requireVerifyLoginMFACode(codes.FailedPrecondition),
+		},
+		{
+			name:         "BothCodes",
+			totpCode:     currentTOTPCode,
+			recoveryCode: recoveryCode,
+			buildStubs: func(t *testing.T, store *mockdb.MockStore, userMFA db.UserMfa) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				expectNoCreateSession(store)
+			},
+			checkResponse: requireVerifyLoginMFACode(codes.InvalidArgument),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store, nil)
+			server.loginLimiter = lockout.NewLimiter(lockout.NewMemoryStore(), util.Config{
+				LoginMaxFailures:     1,
+				LoginLockoutDuration: time.Minute,
+			})
+
+			userMFA, secret := randomEnabledUserMFA(t, server, user)
+			tc.buildStubs(t, store, userMFA)
+
+			mfaToken := newMFAToken(t, server.mfaChallengeMaker, user, token.TokenTypeMFAChallenge, time.Minute)
+			if tc.mfaToken != nil {
+				mfaToken = tc.mfaToken(t, server)
+			}
+
+			req := &pb.VerifyLoginMFARequest{
+				MfaToken:     mfaToken,
+				RecoveryCode: tc.recoveryCode,
+			}
+			if tc.totpCode != nil {
+				req.TotpCode = tc.totpCode(secret)
+			}
+
+			rsp, err := server.VerifyLoginMFA(peerContext("203.0.113.7"), req)
+			tc.checkResponse(t, server, rsp, err)
+		})
+	}
+}
+
+func newMFAToken(t *testing.T, tokenMaker token.Maker, user db.User, tokenType token.TokenType, duration time.Duration) string {
+	mfaToken, _, err := tokenMaker.CreateToken(user.Username, user.Role, pgtype.UUID{}, tokenType, duration)
+	require.NoError(t, err)
+	return mfaToken
+}
+
+func expectGetUser(store *mockdb.MockStore, user db.User) {
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+}
+
+func expectCreateSession(store *mockdb.MockStore, user db.User) {
+	store.EXPECT().
+		CreateSession(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+			return db.Session{
+				ID:       arg.ID,
+				FamilyID: arg.FamilyID,
+				Username: arg.Username,
+			}, nil
+		})
+}
+
+func expectNoCreateSession(store *mockdb.MockStore) {
+	store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+}
+
+// requireLoggedIn checks that the session is created, and that the code didn't count as a failed login
+func requireLoggedIn(user db.User) func(t *testing.T, server *Server, rsp *pb.LoginUserResponse, err error) {
+	return func(t *testing.T, server *Server, rsp *pb.LoginUserResponse, err error) {
+		require.NoError(t, err)
+		require.False(t, rsp.GetMfaRequired())
+		require.NotEmpty(t, rsp.GetSessionId())
+		require.Equal(t, user.Username, rsp.GetUser().GetUsername())
+
+		payload, err := server.tokenMaker.VerifyToken(rsp.GetAccessToken())
+		require.NoError(t, err)
+		require.Equal(t, token.TokenTypeAccess, payload.Type)
+
+		require.NoError(t, server.loginLimiter.Reserve(context.Background(), user.Username, "203.0.113.7"))
+	}
+}
+
+// requireWrongMFACodeCounted checks that a wrong or reused code counts as a failed login,
+// so the codes of the user cannot be guessed without being locked out
+func requireWrongMFACodeCounted(user db.User) func(t *testing.T, server *Server, rsp *pb.LoginUserResponse, err error) {
+	return func(t *testing.T, server *Server, rsp *pb.LoginUserResponse, err error) {
+		requireVerifyLoginMFACode(codes.Unauthenticated)(t, server, rsp, err)
+
+		var lockedErr *lockout.LockedError
+		require.ErrorAs(t, server.loginLimiter.Reserve(context.Background(), user.Username, "203.0.113.7"), &lockedErr)
+	}
+}
+
+func requireVerifyLoginMFACode(code codes.Code) func(t *testing.T, server *Server, rsp *pb.LoginUserResponse, err error) {
+	return func(t *testing.T, server *Server, rsp *pb.LoginUserResponse, err error) {
+		require.Error(t, err)
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, code, st.Code())
+		require.Nil(t, rsp)
+	}
+}
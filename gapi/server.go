@@ -1,33 +1,133 @@
 package gapi
 
 import (
+	"context"
 	"fmt"
+	"net/netip"
 
 	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/fx"
+	"github.com/hykura1501/simple_bank/lockout"
+	"github.com/hykura1501/simple_bank/mfa"
 	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/revocation"
 	"github.com/hykura1501/simple_bank/token"
 	"github.com/hykura1501/simple_bank/util"
+	"github.com/hykura1501/simple_bank/validation"
+	"github.com/hykura1501/simple_bank/worker"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 )
 
 type Server struct {
 	pb.UnimplementedSimpleBankServer
-	store      db.Store
-	tokenMaker token.Maker
-	config     util.Config
+	store             db.Store
+	tokenMaker        token.Maker
+	rateProvider      fx.RateProvider
+	roundingMode      fx.RoundingMode
+	config            util.Config
+	taskDistributor   worker.TaskDistributor
+	revocations       *revocation.Cache
+	mfaSecrets        *mfa.SecretBox
+	mfaChallengeMaker token.Maker
+	mfaThresholds     mfa.TransferThresholds
+	loginLimiter      *lockout.Limiter
+	trustedProxies    []netip.Prefix
+	passwordPolicy    *validation.PasswordPolicy
+	passwordHasher    *util.PasswordHasher
 }
 
-func NewServer(config util.Config, store db.Store) (*Server, error) {
-	tokenMaker, err := token.NewJWTMaker(config.TokenSymmetricKey)
+func NewServer(config util.Config, store db.Store, taskDistributor worker.TaskDistributor, keyring *token.Keyring) (*Server, error) {
+	tokenMaker, err := token.NewMaker(config, keyring)
 
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
+	rateProvider, err := fx.NewRateProvider(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create exchange rate provider: %w", err)
+	}
+
+	roundingMode, err := fx.ParseRoundingMode(config.FXRoundingMode)
+	if err != nil {
+		return nil, err
+	}
+
+	mfaSecrets, err := mfa.NewSecretBox(config.MFAEncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create mfa secret box: %w", err)
+	}
+
+	if config.MFAChallengeKey == config.TokenSymmetricKey {
+		return nil, fmt.Errorf("MFA_CHALLENGE_KEY must be different from TOKEN_SYMMETRIC_KEY")
+	}
+	mfaChallengeMaker, err := token.NewPasetoMaker(config.MFAChallengeKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create mfa challenge token maker: %w", err)
+	}
+
+	mfaThresholds, err := mfa.ParseTransferThresholds(config.MFATransferThresholds)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse MFA_TRANSFER_THRESHOLDS: %w", err)
+	}
+
+	trustedProxies, err := parseTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse TRUSTED_PROXIES: %w", err)
+	}
+
+	loginAttempts, err := lockout.NewStore(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create login attempt store: %w", err)
+	}
+
+	passwordPolicy, err := validation.NewPasswordPolicy(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password policy: %w", err)
+	}
+
+	passwordHasher, err := util.NewPasswordHasher(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password hasher: %w", err)
+	}
+
 	server := &Server{
-		store:      store,
-		config:     config,
-		tokenMaker: tokenMaker,
+		store:             store,
+		config:            config,
+		tokenMaker:        tokenMaker,
+		rateProvider:      rateProvider,
+		roundingMode:      roundingMode,
+		taskDistributor:   taskDistributor,
+		revocations:       revocation.NewCache(revocation.NewDBStore(store), config.RevocationCacheTTL),
+		mfaSecrets:        mfaSecrets,
+		mfaChallengeMaker: mfaChallengeMaker,
+		mfaThresholds:     mfaThresholds,
+		loginLimiter:      lockout.NewLimiter(loginAttempts, config),
+		trustedProxies:    trustedProxies,
+		passwordPolicy:    passwordPolicy,
+		passwordHasher:    passwordHasher,
 	}
 
 	return server, nil
 }
+
+// idempotencyParams reads the optional idempotency key of the request.
+// It returns false if the key is missing
+func (server *Server) idempotencyParams(ctx context.Context, username string) (db.IdempotencyParams, bool, error) {
+	key := extractIdempotencyKeyFromContext(ctx)
+	if key == "" {
+		return db.IdempotencyParams{}, false, nil
+	}
+
+	if err := validation.ValidateIdempotencyKey(key); err != nil {
+		return db.IdempotencyParams{}, false, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{
+			fieldViolation(idempotencyKeyHeader, err),
+		})
+	}
+
+	return db.IdempotencyParams{
+		Key:      key,
+		Username: username,
+		Duration: server.config.IdempotencyKeyDuration,
+	}, true, nil
+}
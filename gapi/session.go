@@ -0,0 +1,64 @@
+package gapi
+
+import (
+	"context"
+	"time"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/token"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// verifySession verifies the refresh token and checks that its session is still active.
+// The returned error is a status error
+func (server *Server) verifySession(ctx context.Context, refreshToken string) (*token.Payload, db.Session, error) {
+	payload, err := server.tokenMaker.VerifyToken(refreshToken)
+	if err != nil {
+		return nil, db.Session{}, unauthenticatedError(err)
+	}
+
+	if err := payload.CheckType(token.TokenTypeRefresh); err != nil {
+		return nil, db.Session{}, unauthenticatedError(err)
+	}
+
+	if err := server.revocations.Check(ctx, payload); err != nil {
+		return nil, db.Session{}, unauthenticatedError(err)
+	}
+
+	session, err := server.store.GetSession(ctx, payload.ID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, session, status.Errorf(codes.NotFound, "session not found")
+		}
+		return nil, session, status.Errorf(codes.Internal, "cannot get session: %s", err)
+	}
+
+	if session.IsUsed {
+		// a rotated refresh token is presented again, so it may have been stolen
+		if _, err := server.store.BlockSessionFamily(ctx, session.FamilyID); err != nil {
+			return nil, session, status.Errorf(codes.Internal, "cannot block session family: %s", err)
+		}
+		server.revocations.RevokeSession(session.FamilyID)
+		return nil, session, status.Errorf(codes.Unauthenticated, "refresh token was already used")
+	}
+
+	if session.IsBlocked {
+		return nil, session, status.Errorf(codes.Unauthenticated, "blocked session")
+	}
+
+	if session.Username != payload.Username {
+		return nil, session, status.Errorf(codes.Unauthenticated, "incorrect session user")
+	}
+
+	if session.RefreshToken != refreshToken {
+		return nil, session, status.Errorf(codes.Unauthenticated, "mismatched session token")
+	}
+
+	if time.Now().After(session.ExpiredAt.Time) {
+		return nil, session, status.Errorf(codes.Unauthenticated, "expired session")
+	}
+
+	return payload, session, nil
+}
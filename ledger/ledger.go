@@ -0,0 +1,92 @@
+// Package ledger describes money movements as double-entry journal transactions.
+// Every movement of money is a Transaction whose postings sum to zero in each currency,
+// and the store only writes entries by posting such a transaction
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Kinds of journal transactions
+const (
+	KindTransfer       = "transfer"
+	KindReversal       = "reversal"
+	KindAdjustment     = "adjustment"
+	KindOpeningBalance = "opening_balance"
+)
+
+// FXClearingOwner owns the clearing account of each currency that cross-currency transfers pass through
+const FXClearingOwner = "fx_clearing"
+
+// OpeningBalanceOwner owns the equity account of each currency that the balances
+// written before the journal existed are posted against
+const OpeningBalanceOwner = "opening_balances"
+
+// AdjustmentOwner owns the account of each currency that balance adjustments are posted against
+const AdjustmentOwner = "adjustments"
+
+var (
+	ErrNoPostings       = errors.New("journal transaction has fewer than two postings")
+	ErrZeroPosting      = errors.New("posting amount must not be zero")
+	ErrUnbalanced       = errors.New("journal transaction is not balanced")
+	ErrCurrencyMismatch = errors.New("posting currency doesn't match the account currency")
+)
+
+// Posting moves Amount minor units of Currency into the account, or out of it when the amount is negative
+type Posting struct {
+	AccountID int64
+	Currency  string
+	Amount    int64
+}
+
+// Transaction is a set of postings recorded together as one journal transaction
+type Transaction struct {
+	Kind     string
+	Postings []Posting
+}
+
+// Validate checks that the transaction has at least two non-zero postings that sum to zero in every currency
+func (txn Transaction) Validate() error {
+	if txn.Kind == "" {
+		return errors.New("journal transaction kind is missing")
+	}
+	if len(txn.Postings) < 2 {
+		return ErrNoPostings
+	}
+
+	totals := make(map[string]int64)
+	for _, posting := range txn.Postings {
+		if posting.Amount == 0 {
+			return fmt.Errorf("%w: account [%d]", ErrZeroPosting, posting.AccountID)
+		}
+		totals[posting.Currency] += posting.Amount
+	}
+
+	for currency, total := range totals {
+		if total != 0 {
+			return fmt.Errorf("%w: postings in %s sum to %d", ErrUnbalanced, currency, total)
+		}
+	}
+	return nil
+}
+
+// AccountIDs returns the accounts of the postings in ascending order, the order they are locked in
+func (txn Transaction) AccountIDs() []int64 {
+	ids := make([]int64, 0, len(txn.Postings))
+	for _, posting := range txn.Postings {
+		ids = append(ids, posting.AccountID)
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+// Net returns the sum of the postings of each account
+func (txn Transaction) Net() map[int64]int64 {
+	net := make(map[int64]int64)
+	for _, posting := range txn.Postings {
+		net[posting.AccountID] += posting.Amount
+	}
+	return net
+}
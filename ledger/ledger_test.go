@@ -0,0 +1,90 @@
+package ledger
+
+import (
+	"testing"
+
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		postings []Posting
+		err      error
+	}{
+		{
+			name: "Balanced",
+			postings: []Posting{
+				{AccountID: 1, Currency: util.USD, Amount: -100},
+				{AccountID: 2, Currency: util.USD, Amount: 60},
+				{AccountID: 3, Currency: util.USD, Amount: 40},
+			},
+		},
+		{
+			name: "BalancedInEachCurrency",
+			postings: []Posting{
+				{AccountID: 1, Currency: util.USD, Amount: -100},
+				{AccountID: 2, Currency: util.USD, Amount: 100},
+				{AccountID: 3, Currency: util.EUR, Amount: -90},
+				{AccountID: 4, Currency: util.EUR, Amount: 90},
+			},
+		},
+		{
+			name: "Unbalanced",
+			postings: []Posting{
+				{AccountID: 1, Currency: util.USD, Amount: -100},
+				{AccountID: 2, Currency: util.USD, Amount: 99},
+			},
+			err: ErrUnbalanced,
+		},
+		{
+			name: "BalancedAcrossCurrencies",
+			postings: []Posting{
+				{AccountID: 1, Currency: util.USD, Amount: -100},
+				{AccountID: 2, Currency: util.EUR, Amount: 100},
+			},
+			err: ErrUnbalanced,
+		},
+		{
+			name: "ZeroPosting",
+			postings: []Posting{
+				{AccountID: 1, Currency: util.USD, Amount: 0},
+				{AccountID: 2, Currency: util.USD, Amount: 0},
+			},
+			err: ErrZeroPosting,
+		},
+		{
+			name: "SinglePosting",
+			postings: []Posting{
+				{AccountID: 1, Currency: util.USD, Amount: 100},
+			},
+			err: ErrNoPostings,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Transaction{Kind: KindTransfer, Postings: tc.postings}.Validate()
+			if tc.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestAccountIDsAndNet(t *testing.T) {
+	txn := Transaction{
+		Kind: KindTransfer,
+		Postings: []Posting{
+			{AccountID: 5, Currency: util.USD, Amount: -100},
+			{AccountID: 2, Currency: util.USD, Amount: 70},
+			{AccountID: 5, Currency: util.USD, Amount: 30},
+		},
+	}
+
+	require.Equal(t, []int64{2, 5}, txn.AccountIDs())
+	require.Equal(t, map[int64]int64{2: 70, 5: -70}, txn.Net())
+}
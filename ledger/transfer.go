@@ -0,0 +1,49 @@
+package ledger
+
+import (
+	"fmt"
+)
+
+// Leg is one side of a transfer: Amount, which must be positive, leaves or enters the account in its currency
+type Leg struct {
+	AccountID int64
+	Currency  string
+	Amount    int64
+}
+
+// NewTransfer returns the journal transaction of a transfer from one account to another.
+// When the currencies differ, the transfer is balanced in each currency through the FX clearing accounts:
+// the clearing account of the source currency receives the debited amount,
+// and the clearing account of the destination currency pays out the credited amount.
+// fxClearing maps each currency to the ID of its clearing account, it is only read for cross-currency transfers
+func NewTransfer(from Leg, to Leg, fxClearing map[string]int64) (Transaction, error) {
+	if from.Amount <= 0 || to.Amount <= 0 {
+		return Transaction{}, fmt.Errorf("transfer amounts must be positive")
+	}
+
+	txn := Transaction{
+		Kind: KindTransfer,
+		Postings: []Posting{
+			{AccountID: from.AccountID, Currency: from.Currency, Amount: -from.Amount},
+			{AccountID: to.AccountID, Currency: to.Currency, Amount: to.Amount},
+		},
+	}
+
+	if from.Currency != to.Currency {
+		fromClearingID, ok := fxClearing[from.Currency]
+		if !ok {
+			return Transaction{}, fmt.Errorf("missing FX clearing account of %s", from.Currency)
+		}
+		toClearingID, ok := fxClearing[to.Currency]
+		if !ok {
+			return Transaction{}, fmt.Errorf("missing FX clearing account of %s", to.Currency)
+		}
+
+		txn.Postings = append(txn.Postings,
+			Posting{AccountID: fromClearingID, Currency: from.Currency, Amount: from.Amount},
+			Posting{AccountID: toClearingID, Currency: to.Currency, Amount: -to.Amount},
+		)
+	}
+
+	return txn, txn.Validate()
+}
@@ -0,0 +1,55 @@
+package ledger
+
+import (
+	"testing"
+
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransfer(t *testing.T) {
+	txn, err := NewTransfer(
+		Leg{AccountID: 1, Currency: util.USD, Amount: 100},
+		Leg{AccountID: 2, Currency: util.USD, Amount: 100},
+		nil,
+	)
+	require.NoError(t, err)
+	require.Equal(t, KindTransfer, txn.Kind)
+	require.Equal(t, []Posting{
+		{AccountID: 1, Currency: util.USD, Amount: -100},
+		{AccountID: 2, Currency: util.USD, Amount: 100},
+	}, txn.Postings)
+}
+
+func TestNewCrossCurrencyTransfer(t *testing.T) {
+	fxClearing := map[string]int64{util.USD: 10, util.EUR: 11}
+
+	txn, err := NewTransfer(
+		Leg{AccountID: 1, Currency: util.USD, Amount: 100},
+		Leg{AccountID: 2, Currency: util.EUR, Amount: 92},
+		fxClearing,
+	)
+	require.NoError(t, err)
+	require.Equal(t, []Posting{
+		{AccountID: 1, Currency: util.USD, Amount: -100},
+		{AccountID: 2, Currency: util.EUR, Amount: 92},
+		{AccountID: 10, Currency: util.USD, Amount: 100},
+		{AccountID: 11, Currency: util.EUR, Amount: -92},
+	}, txn.Postings)
+
+	_, err = NewTransfer(
+		Leg{AccountID: 1, Currency: util.USD, Amount: 100},
+		Leg{AccountID: 2, Currency: util.CAD, Amount: 137},
+		fxClearing,
+	)
+	require.EqualError(t, err, "missing FX clearing account of CAD")
+}
+
+func TestNewTransferInvalidAmount(t *testing.T) {
+	_, err := NewTransfer(
+		Leg{AccountID: 1, Currency: util.USD, Amount: 0},
+		Leg{AccountID: 2, Currency: util.USD, Amount: 0},
+		nil,
+	)
+	require.Error(t, err)
+}
@@ -0,0 +1,156 @@
+package lockout
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/hykura1501/simple_bank/util"
+)
+
+// LockedError is returned when a login is attempted before the username or the client IP may try again
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (err *LockedError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, try again in %s", (err.RetryAfter + time.Second - 1).Truncate(time.Second))
+}
+
+// Policy is how the failed logins of a username or a client IP are limited
+type Policy struct {
+	// MaxFailures is the number of failures after which the key is locked out for LockoutDuration. 0 disables the lockout
+	MaxFailures int64
+	// BackoffDelay is the wait after the first failure, doubled after every other one. 0 disables the backoff
+	BackoffDelay    time.Duration
+	LockoutDuration time.Duration
+}
+
+// retryAt returns the time after which the key may try to log in again
+func (policy Policy) retryAt(attempts Attempts) time.Time {
+	if attempts.Failures == 0 {
+		return time.Time{}
+	}
+	if policy.MaxFailures > 0 && attempts.Failures >= policy.MaxFailures {
+		return attempts.LastFailure.Add(policy.LockoutDuration)
+	}
+	if policy.BackoffDelay <= 0 {
+		return time.Time{}
+	}
+
+	delay := policy.BackoffDelay
+	for i := int64(1); i < attempts.Failures && delay < policy.LockoutDuration; i++ {
+		delay *= 2
+	}
+	return attempts.LastFailure.Add(min(delay, policy.LockoutDuration))
+}
+
+// Limiter slows down and then locks out the usernames and client IPs that fail to log in repeatedly.
+// Failures of unknown usernames are counted too, so that the limiter doesn't reveal which usernames exist
+type Limiter struct {
+	store      Store
+	userPolicy Policy
+	ipPolicy   Policy
+	now        func() time.Time
+}
+
+// NewLimiter creates a limiter with the policies of the LOGIN_* settings.
+// Client IPs are only locked out, without backoff, as many users may share one
+func NewLimiter(store Store, config util.Config) *Limiter {
+	return &Limiter{
+		store: store,
+		userPolicy: Policy{
+			MaxFailures:     config.LoginMaxFailures,
+			BackoffDelay:    config.LoginBackoffDelay,
+			LockoutDuration: config.LoginLockoutDuration,
+		},
+		ipPolicy: Policy{
+			MaxFailures:     config.LoginMaxFailuresPerIP,
+			LockoutDuration: config.LoginLockoutDuration,
+		},
+		now: time.Now,
+	}
+}
+
+// Reserve counts a login attempt of the username from the client IP as a failure before its password is verified,
+// and returns a *LockedError if the username or the client IP must wait before trying to log in again.
+// The lockout is decided from the attempts that the store counted atomically before this one,
+// so parallel logins can't all pass before their failures are recorded.
+// Release must be called once the password turns out to be correct
+func (limiter *Limiter) Reserve(ctx context.Context, username string, clientIP string) error {
+	policies := limiter.policies(username, clientIP)
+
+	// a locked out login isn't counted, so that retrying doesn't extend the lockout
+	if err := limiter.check(ctx, policies); err != nil {
+		return err
+	}
+
+	now := limiter.now()
+	var retryAfter time.Duration
+	for key, policy := range policies {
+		previous, err := limiter.store.AddFailure(ctx, key, now, policy.LockoutDuration)
+		if err != nil {
+			return err
+		}
+		retryAfter = max(retryAfter, policy.retryAt(previous).Sub(now))
+	}
+
+	if retryAfter > 0 {
+		return &LockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// Release takes back the failure reserved for a login of the username from the client IP whose password was correct
+func (limiter *Limiter) Release(ctx context.Context, username string, clientIP string) error {
+	for key := range limiter.policies(username, clientIP) {
+		if err := limiter.store.RemoveFailure(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// check returns a *LockedError if any key of the policies must wait before trying to log in again
+func (limiter *Limiter) check(ctx context.Context, policies map[string]Policy) error {
+	var retryAfter time.Duration
+	for key, policy := range policies {
+		attempts, err := limiter.store.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		retryAfter = max(retryAfter, policy.retryAt(attempts).Sub(limiter.now()))
+	}
+
+	if retryAfter > 0 {
+		return &LockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// RecordSuccess forgets the failures of the username. The failures of the client IP are kept,
+// otherwise an attacker could reset them by logging in to their own account
+func (limiter *Limiter) RecordSuccess(ctx context.Context, username string) error {
+	return limiter.store.Reset(ctx, userKey(username))
+}
+
+func (limiter *Limiter) policies(username string, clientIP string) map[string]Policy {
+	policies := map[string]Policy{userKey(username): limiter.userPolicy}
+	if clientIP != "" {
+		policies[ipKey(clientIP)] = limiter.ipPolicy
+	}
+	return policies
+}
+
+func userKey(username string) string {
+	return "user:" + username
+}
+
+// ipKey drops the port of the client address, which is part of it when it comes from the connection
+func ipKey(clientIP string) string {
+	if host, _, err := net.SplitHostPort(clientIP); err == nil {
+		clientIP = host
+	}
+	return "ip:" + clientIP
+}
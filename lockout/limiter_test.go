@@ -0,0 +1,214 @@
+package lockout
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimiter(t *testing.T) (*Limiter, *time.Time) {
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	limiter := NewLimiter(store, util.Config{
+		LoginMaxFailures:      4,
+		LoginMaxFailuresPerIP: 6,
+		LoginBackoffDelay:     time.Second,
+		LoginLockoutDuration:  15 * time.Minute,
+	})
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+// checkLogin returns the error that a login would get, without counting it
+func checkLogin(limiter *Limiter, username string, clientIP string) error {
+	return limiter.check(context.Background(), limiter.policies(username, clientIP))
+}
+
+func requireLocked(t *testing.T, err error, retryAfter time.Duration) {
+	var lockedErr *LockedError
+	require.ErrorAs(t, err, &lockedErr)
+	require.Equal(t, retryAfter, lockedErr.RetryAfter)
+}
+
+func TestLimiterBackoff(t *testing.T) {
+	limiter, now := newTestLimiter(t)
+	ctx := context.Background()
+	username := util.RandomOwner()
+
+	require.NoError(t, checkLogin(limiter, username, ""))
+
+	// the wait doubles after every failure
+	for _, delay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		require.NoError(t, limiter.Reserve(ctx, username, ""))
+		requireLocked(t, checkLogin(limiter, username, ""), delay)
+
+		*now = now.Add(delay)
+		require.NoError(t, checkLogin(limiter, username, ""))
+	}
+
+	// then the username is locked out
+	require.NoError(t, limiter.Reserve(ctx, username, ""))
+	requireLocked(t, checkLogin(limiter, username, ""), 15*time.Minute)
+
+	// other usernames are not affected
+	require.NoError(t, checkLogin(limiter, util.RandomOwner(), ""))
+
+	*now = now.Add(15 * time.Minute)
+	require.NoError(t, checkLogin(limiter, username, ""))
+}
+
+func TestLimiterClientIP(t *testing.T) {
+	limiter, now := newTestLimiter(t)
+	ctx := context.Background()
+
+	// failures of many usernames from one client add up, whatever the port of the connection
+	for i := range 5 {
+		require.NoError(t, limiter.Reserve(ctx, util.RandomOwner(), fmt.Sprintf("10.0.0.1:%d", 40000+i)))
+		require.NoError(t, checkLogin(limiter, util.RandomOwner(), "10.0.0.1"))
+	}
+
+	require.NoError(t, limiter.Reserve(ctx, util.RandomOwner(), "10.0.0.1:40005"))
+	requireLocked(t, checkLogin(limiter, util.RandomOwner(), "10.0.0.1:50000"), 15*time.Minute)
+	require.NoError(t, checkLogin(limiter, util.RandomOwner(), "10.0.0.2"))
+
+	*now = now.Add(15 * time.Minute)
+	require.NoError(t, checkLogin(limiter, util.RandomOwner(), "10.0.0.1"))
+}
+
+func TestLimiterRecordSuccess(t *testing.T) {
+	limiter, now := newTestLimiter(t)
+	ctx := context.Background()
+	username := util.RandomOwner()
+
+	for range 3 {
+		*now = now.Add(time.Minute)
+		require.NoError(t, limiter.Reserve(ctx, username, "10.0.0.1"))
+	}
+	require.Error(t, checkLogin(limiter, username, ""))
+
+	require.NoError(t, limiter.RecordSuccess(ctx, username))
+	require.NoError(t, checkLogin(limiter, username, ""))
+
+	// the failures of the client are kept
+	attempts, err := limiter.store.Get(ctx, ipKey("10.0.0.1"))
+	require.NoError(t, err)
+	require.Equal(t, int64(3), attempts.Failures)
+}
+
+func TestLimiterRelease(t *testing.T) {
+	limiter, now := newTestLimiter(t)
+	ctx := context.Background()
+	username := util.RandomOwner()
+
+	require.NoError(t, limiter.Reserve(ctx, username, "10.0.0.1"))
+	*now = now.Add(time.Second)
+	require.NoError(t, limiter.Reserve(ctx, username, "10.0.0.1"))
+	require.NoError(t, limiter.Release(ctx, username, "10.0.0.1"))
+
+	for _, key := range []string{userKey(username), ipKey("10.0.0.1")} {
+		attempts, err := limiter.store.Get(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), attempts.Failures)
+	}
+
+	// a locked out login isn't counted
+	requireLocked(t, limiter.Reserve(ctx, username, "10.0.0.1"), time.Second)
+	attempts, err := limiter.store.Get(ctx, userKey(username))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), attempts.Failures)
+}
+
+func TestLimiterConcurrentReserve(t *testing.T) {
+	const maxFailures = 3
+	const logins = 20
+
+	testCases := []struct {
+		name  string
+		store func(t *testing.T) Store
+	}{
+		{
+			name: "Memory",
+			store: func(t *testing.T) Store {
+				return NewMemoryStore()
+			},
+		},
+		{
+			name: "Redis",
+			store: func(t *testing.T) Store {
+				server := miniredis.RunT(t)
+				return NewRedisStore(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			limiter := NewLimiter(tc.store(t), util.Config{
+				LoginMaxFailures:      maxFailures,
+				LoginMaxFailuresPerIP: 100,
+				LoginLockoutDuration:  time.Minute,
+			})
+			username := util.RandomOwner()
+
+			// every login is checked before any of them fails, so only the atomic count can stop them
+			var wg sync.WaitGroup
+			errs := make(chan error, logins)
+			for range logins {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					errs <- limiter.Reserve(context.Background(), username, "10.0.0.1")
+				}()
+			}
+			wg.Wait()
+			close(errs)
+
+			allowed := 0
+			for err := range errs {
+				if err == nil {
+					allowed++
+					continue
+				}
+				var lockedErr *LockedError
+				require.ErrorAs(t, err, &lockedErr)
+			}
+			require.Equal(t, maxFailures, allowed)
+
+			// the logins that passed the check before the lockout was counted stay counted as well
+			attempts, err := limiter.store.Get(context.Background(), userKey(username))
+			require.NoError(t, err)
+			require.GreaterOrEqual(t, attempts.Failures, int64(maxFailures))
+
+			// a login whose password was correct gives its attempt back
+			require.NoError(t, limiter.Release(context.Background(), username, "10.0.0.1"))
+			released, err := limiter.store.Get(context.Background(), userKey(username))
+			require.NoError(t, err)
+			require.Equal(t, attempts.Failures-1, released.Failures)
+		})
+	}
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore(), util.Config{})
+	ctx := context.Background()
+	username := util.RandomOwner()
+
+	for range 10 {
+		require.NoError(t, limiter.Reserve(ctx, username, "10.0.0.1"))
+	}
+	require.NoError(t, checkLogin(limiter, username, "10.0.0.1"))
+}
+
+func TestLockedErrorMessage(t *testing.T) {
+	err := &LockedError{RetryAfter: 1500 * time.Millisecond}
+	require.EqualError(t, err, "too many failed login attempts, try again in 2s")
+}
@@ -0,0 +1,101 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the memory store removes the expired records
+const sweepInterval = time.Minute
+
+type memoryEntry struct {
+	attempts  Attempts
+	expiredAt time.Time
+}
+
+// MemoryStore is an in-process Store
+type MemoryStore struct {
+	now func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	nextSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		now:     time.Now,
+		entries: make(map[string]memoryEntry),
+	}
+}
+
+func (store *MemoryStore) Get(ctx context.Context, key string) (Attempts, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	entry, ok := store.entries[key]
+	if !ok || !store.now().Before(entry.expiredAt) {
+		return Attempts{}, nil
+	}
+	return entry.attempts, nil
+}
+
+func (store *MemoryStore) AddFailure(ctx context.Context, key string, at time.Time, ttl time.Duration) (Attempts, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	entry, ok := store.entries[key]
+	if !ok || !store.now().Before(entry.expiredAt) {
+		entry = memoryEntry{}
+	}
+	previous := entry.attempts
+	entry.attempts.Failures++
+	entry.attempts.LastFailure = at
+	entry.expiredAt = store.now().Add(ttl)
+	store.entries[key] = entry
+
+	store.sweep()
+	return previous, nil
+}
+
+func (store *MemoryStore) RemoveFailure(ctx context.Context, key string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	entry, ok := store.entries[key]
+	if !ok || !store.now().Before(entry.expiredAt) {
+		return nil
+	}
+
+	entry.attempts.Failures--
+	if entry.attempts.Failures <= 0 {
+		delete(store.entries, key)
+		return nil
+	}
+	store.entries[key] = entry
+	return nil
+}
+
+func (store *MemoryStore) Reset(ctx context.Context, key string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.entries, key)
+	return nil
+}
+
+// sweep removes the expired records at most once per sweepInterval. The caller must hold mu
+func (store *MemoryStore) sweep() {
+	now := store.now()
+	if now.Before(store.nextSweep) {
+		return
+	}
+	store.nextSweep = now.Add(sweepInterval)
+
+	for key, entry := range store.entries {
+		if !now.Before(entry.expiredAt) {
+			delete(store.entries, key)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package lockout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	attempts, err := store.Get(ctx, "key")
+	require.NoError(t, err)
+	require.Zero(t, attempts)
+
+	// AddFailure returns the attempts before the failure
+	firstFailure := now
+	attempts, err = store.AddFailure(ctx, "key", now, time.Minute)
+	require.NoError(t, err)
+	require.Zero(t, attempts)
+
+	now = now.Add(30 * time.Second)
+	attempts, err = store.AddFailure(ctx, "key", now, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, Attempts{Failures: 1, LastFailure: firstFailure}, attempts)
+
+	got, err := store.Get(ctx, "key")
+	require.NoError(t, err)
+	require.Equal(t, Attempts{Failures: 2, LastFailure: now}, got)
+
+	require.NoError(t, store.RemoveFailure(ctx, "key"))
+	got, err = store.Get(ctx, "key")
+	require.NoError(t, err)
+	require.Equal(t, Attempts{Failures: 1, LastFailure: now}, got)
+
+	// the record expires ttl after the last failure
+	now = now.Add(time.Minute)
+	got, err = store.Get(ctx, "key")
+	require.NoError(t, err)
+	require.Zero(t, got)
+
+	require.NoError(t, store.RemoveFailure(ctx, "key"))
+	attempts, err = store.AddFailure(ctx, "key", now, time.Minute)
+	require.NoError(t, err)
+	require.Zero(t, attempts)
+
+	require.NoError(t, store.Reset(ctx, "key"))
+	got, err = store.Get(ctx, "key")
+	require.NoError(t, err)
+	require.Zero(t, got)
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := store.AddFailure(ctx, "expired", now, time.Second)
+	require.NoError(t, err)
+
+	now = now.Add(sweepInterval)
+	_, err = store.AddFailure(ctx, "key", now, time.Minute)
+	require.NoError(t, err)
+
+	require.Len(t, store.entries, 1)
+	require.Contains(t, store.entries, "key")
+}
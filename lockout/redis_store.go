@@ -0,0 +1,103 @@
+package lockout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisKeyPrefix        = "login_attempts:"
+	redisFailuresField    = "failures"
+	redisLastFailureField = "last_failure"
+)
+
+// RedisStore keeps the attempts in Redis hashes, so that they are shared by every server
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{
+		client: client,
+	}
+}
+
+func (store *RedisStore) Get(ctx context.Context, key string) (Attempts, error) {
+	fields, err := store.client.HGetAll(ctx, redisKeyPrefix+key).Result()
+	if err != nil {
+		return Attempts{}, fmt.Errorf("cannot get login attempts: %w", err)
+	}
+	if len(fields) == 0 {
+		return Attempts{}, nil
+	}
+
+	failures, err := strconv.ParseInt(fields[redisFailuresField], 10, 64)
+	if err != nil {
+		return Attempts{}, fmt.Errorf("invalid login attempts of %s: %w", key, err)
+	}
+	lastFailure, err := strconv.ParseInt(fields[redisLastFailureField], 10, 64)
+	if err != nil {
+		return Attempts{}, fmt.Errorf("invalid login attempts of %s: %w", key, err)
+	}
+
+	return Attempts{
+		Failures:    failures,
+		LastFailure: time.Unix(0, lastFailure),
+	}, nil
+}
+
+func (store *RedisStore) AddFailure(ctx context.Context, key string, at time.Time, ttl time.Duration) (Attempts, error) {
+	redisKey := redisKeyPrefix + key
+
+	// the commands of the transaction run together, so the previous last failure belongs to the counted failures
+	pipe := store.client.TxPipeline()
+	lastFailure := pipe.HGet(ctx, redisKey, redisLastFailureField)
+	failures := pipe.HIncrBy(ctx, redisKey, redisFailuresField, 1)
+	pipe.HSet(ctx, redisKey, redisLastFailureField, at.UnixNano())
+	pipe.PExpire(ctx, redisKey, ttl)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return Attempts{}, fmt.Errorf("cannot record login failure: %w", err)
+	}
+
+	previous := Attempts{Failures: failures.Val() - 1}
+	if previous.Failures > 0 {
+		nanos, err := strconv.ParseInt(lastFailure.Val(), 10, 64)
+		if err != nil {
+			return Attempts{}, fmt.Errorf("invalid login attempts of %s: %w", key, err)
+		}
+		previous.LastFailure = time.Unix(0, nanos)
+	}
+	return previous, nil
+}
+
+// removeFailureScript decrements the failures of a record that hasn't expired, without creating one
+var removeFailureScript = redis.NewScript(`
+if redis.call("HEXISTS", KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+local failures = redis.call("HINCRBY", KEYS[1], ARGV[1], -1)
+if failures <= 0 then
+	redis.call("DEL", KEYS[1])
+end
+return failures
+`)
+
+func (store *RedisStore) RemoveFailure(ctx context.Context, key string) error {
+	err := removeFailureScript.Run(ctx, store.client, []string{redisKeyPrefix + key}, redisFailuresField).Err()
+	if err != nil {
+		return fmt.Errorf("cannot remove login failure: %w", err)
+	}
+	return nil
+}
+
+func (store *RedisStore) Reset(ctx context.Context, key string) error {
+	if err := store.client.Del(ctx, redisKeyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("cannot reset login attempts: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package lockout
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	StoreMemory = "memory"
+	StoreRedis  = "redis"
+)
+
+// Attempts is the record of the failed logins of a username or a client IP
+type Attempts struct {
+	Failures    int64
+	LastFailure time.Time
+}
+
+// Store keeps the failed login attempts. A record is forgotten ttl after its last failure
+type Store interface {
+	// Get returns the attempts of key, which are zero if it has no failures
+	Get(ctx context.Context, key string) (Attempts, error)
+	// AddFailure atomically records a failure of key at the given time and returns the attempts before it
+	AddFailure(ctx context.Context, key string, at time.Time, ttl time.Duration) (Attempts, error)
+	// RemoveFailure takes back a failure of key, keeping the time of its last failure
+	RemoveFailure(ctx context.Context, key string) error
+	// Reset forgets the failures of key
+	Reset(ctx context.Context, key string) error
+}
+
+// NewStore builds the store selected by LOGIN_ATTEMPT_STORE. The memory store is used by default,
+// but it only limits the logins handled by one server, so a deployment with several instances needs redis
+func NewStore(config util.Config) (Store, error) {
+	switch config.LoginAttemptStore {
+	case "", StoreMemory:
+		return NewMemoryStore(), nil
+	case StoreRedis:
+		return NewRedisStore(redis.NewClient(&redis.Options{Addr: config.RedisAddress})), nil
+	}
+	return nil, fmt.Errorf("unsupported login attempt store: %s", config.LoginAttemptStore)
+}
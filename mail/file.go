@@ -0,0 +1,72 @@
+package mail
+
+import (
+	"errors"
+	"fmt"
+	netmail "net/mail"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hykura1501/simple_bank/util"
+)
+
+// FileSender writes emails to .eml files in an outbox directory instead of sending them.
+// It is meant for development, where the emails can be opened with any mail client
+type FileSender struct {
+	from      netmail.Address
+	outboxDir string
+}
+
+// NewFileSender creates a sender that writes emails from fromAddress to outboxDir
+func NewFileSender(name string, fromAddress string, outboxDir string) (EmailSender, error) {
+	if _, err := netmail.ParseAddress(fromAddress); err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+
+	if outboxDir == "" {
+		return nil, errors.New("missing outbox directory")
+	}
+
+	return &FileSender{
+		from:      netmail.Address{Name: name, Address: fromAddress},
+		outboxDir: outboxDir,
+	}, nil
+}
+
+func (sender *FileSender) SendEmail(
+	subject string,
+	content string,
+	to []string,
+	cc []string,
+	bcc []string,
+	attachFiles []string,
+) error {
+	if _, err := recipients(to, cc, bcc); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	msg := &message{
+		from:        sender.from,
+		subject:     subject,
+		content:     content,
+		to:          to,
+		cc:          cc,
+		attachFiles: attachFiles,
+	}
+	data, err := msg.bytes(now)
+	if err != nil {
+		return fmt.Errorf("cannot build email: %w", err)
+	}
+
+	if err := os.MkdirAll(sender.outboxDir, 0o755); err != nil {
+		return fmt.Errorf("cannot create outbox directory: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), util.RandomString(6))
+	if err := os.WriteFile(filepath.Join(sender.outboxDir, name), data, 0o644); err != nil {
+		return fmt.Errorf("cannot write email: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package mail
+
+import (
+	"encoding/base64"
+	"io"
+	"mime"
+	netmail "net/mail"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSenderSendEmail(t *testing.T) {
+	outboxDir := filepath.Join(t.TempDir(), "outbox")
+
+	sender, err := NewFileSender("Simple Bank", "bank@example.com", outboxDir)
+	require.NoError(t, err)
+
+	subject := "Bienvenue à Simple Bank"
+	content := "<p>Hello</p>"
+	err = sender.SendEmail(subject, content, []string{"alice@example.com"}, nil, []string{"audit@example.com"}, nil)
+	require.NoError(t, err)
+
+	files, err := os.ReadDir(outboxDir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, ".eml", filepath.Ext(files[0].Name()))
+
+	file, err := os.Open(filepath.Join(outboxDir, files[0].Name()))
+	require.NoError(t, err)
+	defer file.Close()
+
+	msg, err := netmail.ReadMessage(file)
+	require.NoError(t, err)
+	require.Equal(t, "alice@example.com", msg.Header.Get("To"))
+	require.Empty(t, msg.Header.Get("Bcc"))
+	require.Contains(t, msg.Header.Get("Content-Type"), "text/html")
+
+	decodedSubject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	require.Equal(t, subject, decodedSubject)
+
+	body, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, msg.Body))
+	require.NoError(t, err)
+	require.Equal(t, content, string(body))
+}
+
+func TestNewEmailSender(t *testing.T) {
+	config := util.Config{
+		EmailSenderType:    SenderFile,
+		EmailSenderName:    "Simple Bank",
+		EmailSenderAddress: "bank@example.com",
+		EmailOutboxDir:     t.TempDir(),
+	}
+
+	sender, err := NewEmailSender(config)
+	require.NoError(t, err)
+	require.IsType(t, &FileSender{}, sender)
+
+	config.EmailSenderType = SenderSMTP
+	config.SMTPServerAddress = "localhost:1025"
+	sender, err = NewEmailSender(config)
+	require.NoError(t, err)
+	require.IsType(t, &SMTPSender{}, sender)
+
+	config.EmailSenderType = "pigeon"
+	_, err = NewEmailSender(config)
+	require.Error(t, err)
+}
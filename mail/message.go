@@ -0,0 +1,126 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	netmail "net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// base64LineLength is the maximum line length of base64 encoded content allowed by RFC 2045
+const base64LineLength = 76
+
+// message is an email before it is encoded
+type message struct {
+	from        netmail.Address
+	subject     string
+	content     string
+	to          []string
+	cc          []string
+	attachFiles []string
+}
+
+// recipients returns the envelope recipients of the email. Bcc addresses are never written in the headers
+func recipients(to []string, cc []string, bcc []string) ([]string, error) {
+	all := make([]string, 0, len(to)+len(cc)+len(bcc))
+	all = append(all, to...)
+	all = append(all, cc...)
+	all = append(all, bcc...)
+	if len(all) == 0 {
+		return nil, errors.New("email has no recipients")
+	}
+
+	for _, address := range all {
+		if _, err := netmail.ParseAddress(address); err != nil {
+			return nil, fmt.Errorf("invalid recipient %q: %w", address, err)
+		}
+	}
+	return all, nil
+}
+
+// bytes encodes the email as a MIME message.
+// The HTML content is sent alone, or in a multipart/mixed message with the attachments
+func (msg *message) bytes(date time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+
+	writeHeader(&buf, "From", msg.from.String())
+	writeHeader(&buf, "To", strings.Join(msg.to, ", "))
+	if len(msg.cc) > 0 {
+		writeHeader(&buf, "Cc", strings.Join(msg.cc, ", "))
+	}
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", msg.subject))
+	writeHeader(&buf, "Date", date.Format(time.RFC1123Z))
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	if len(msg.attachFiles) == 0 {
+		writeHeader(&buf, "Content-Type", `text/html; charset="utf-8"`)
+		writeHeader(&buf, "Content-Transfer-Encoding", "base64")
+		buf.WriteString("\r\n")
+		writeBase64(&buf, []byte(msg.content))
+		return buf.Bytes(), nil
+	}
+
+	writer := multipart.NewWriter(&buf)
+	writeHeader(&buf, "Content-Type", fmt.Sprintf(`multipart/mixed; boundary="%s"`, writer.Boundary()))
+	buf.WriteString("\r\n")
+
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {`text/html; charset="utf-8"`},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	writeBase64(part, []byte(msg.content))
+
+	for _, path := range msg.attachFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read attachment: %w", err)
+		}
+
+		name := filepath.Base(path)
+		contentType := mime.TypeByExtension(filepath.Ext(name))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": name})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		writeBase64(part, data)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, key string, value string) {
+	buf.WriteString(key + ": " + value + "\r\n")
+}
+
+// writeBase64 writes base64 encoded data wrapped to lines of base64LineLength characters
+func writeBase64(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > base64LineLength {
+		w.Write([]byte(encoded[:base64LineLength] + "\r\n"))
+		encoded = encoded[base64LineLength:]
+	}
+	w.Write([]byte(encoded + "\r\n"))
+}
@@ -0,0 +1,41 @@
+// Package mail sends emails to the bank's users.
+package mail
+
+import (
+	"fmt"
+
+	"github.com/hykura1501/simple_bank/util"
+)
+
+const (
+	SenderSMTP = "smtp"
+	SenderFile = "file"
+)
+
+// EmailSender sends an HTML email, with optional attachments read from the given file paths
+type EmailSender interface {
+	SendEmail(
+		subject string,
+		content string,
+		to []string,
+		cc []string,
+		bcc []string,
+		attachFiles []string,
+	) error
+}
+
+// NewEmailSender builds the email sender selected by EMAIL_SENDER_TYPE
+func NewEmailSender(config util.Config) (EmailSender, error) {
+	switch config.EmailSenderType {
+	case SenderSMTP:
+		return NewSMTPSender(
+			config.EmailSenderName,
+			config.EmailSenderAddress,
+			config.EmailSenderPassword,
+			config.SMTPServerAddress,
+		)
+	case SenderFile:
+		return NewFileSender(config.EmailSenderName, config.EmailSenderAddress, config.EmailOutboxDir)
+	}
+	return nil, fmt.Errorf("unsupported email sender type: %s", config.EmailSenderType)
+}
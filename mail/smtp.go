@@ -0,0 +1,71 @@
+package mail
+
+import (
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"time"
+)
+
+// SMTPSender sends emails through an SMTP server, authenticating with the sender's password if it has one
+type SMTPSender struct {
+	from          netmail.Address
+	password      string
+	serverAddress string
+}
+
+// NewSMTPSender creates a sender that sends emails from fromAddress through the SMTP server at serverAddress (host:port)
+func NewSMTPSender(name string, fromAddress string, password string, serverAddress string) (EmailSender, error) {
+	if _, err := netmail.ParseAddress(fromAddress); err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+
+	if _, _, err := net.SplitHostPort(serverAddress); err != nil {
+		return nil, fmt.Errorf("invalid SMTP server address: %w", err)
+	}
+
+	return &SMTPSender{
+		from:          netmail.Address{Name: name, Address: fromAddress},
+		password:      password,
+		serverAddress: serverAddress,
+	}, nil
+}
+
+func (sender *SMTPSender) SendEmail(
+	subject string,
+	content string,
+	to []string,
+	cc []string,
+	bcc []string,
+	attachFiles []string,
+) error {
+	envelope, err := recipients(to, cc, bcc)
+	if err != nil {
+		return err
+	}
+
+	msg := &message{
+		from:        sender.from,
+		subject:     subject,
+		content:     content,
+		to:          to,
+		cc:          cc,
+		attachFiles: attachFiles,
+	}
+	data, err := msg.bytes(time.Now())
+	if err != nil {
+		return fmt.Errorf("cannot build email: %w", err)
+	}
+
+	var auth smtp.Auth
+	if sender.password != "" {
+		host, _, _ := net.SplitHostPort(sender.serverAddress)
+		auth = smtp.PlainAuth("", sender.from.Address, sender.password, host)
+	}
+
+	if err := smtp.SendMail(sender.serverAddress, auth, sender.from.Address, envelope, data); err != nil {
+		return fmt.Errorf("cannot send email: %w", err)
+	}
+	return nil
+}
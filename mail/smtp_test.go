@@ -0,0 +1,174 @@
+package mail
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	netmail "net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// receivedEmail is what the fake SMTP server got in one session
+type receivedEmail struct {
+	auth       string
+	from       string
+	recipients []string
+	data       string
+}
+
+// startFakeSMTPServer starts an SMTP server that accepts a single email and sends it to the returned channel
+func startFakeSMTPServer(t *testing.T) (string, <-chan receivedEmail) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	emails := make(chan receivedEmail, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var email receivedEmail
+		reader := bufio.NewReader(conn)
+		reply := func(line string) {
+			io.WriteString(conn, line+"\r\n")
+		}
+
+		reply("220 localhost fake SMTP server")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			command := strings.ToUpper(strings.Fields(line + " ")[0])
+
+			switch command {
+			case "EHLO", "HELO":
+				reply("250-localhost")
+				reply("250 AUTH PLAIN")
+			case "AUTH":
+				email.auth = strings.TrimPrefix(line, "AUTH PLAIN ")
+				reply("235 authenticated")
+			case "MAIL":
+				email.from = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+				reply("250 ok")
+			case "RCPT":
+				email.recipients = append(email.recipients, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+				reply("250 ok")
+			case "DATA":
+				reply("354 send data")
+				var data strings.Builder
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(strings.TrimPrefix(dataLine, "."))
+				}
+				email.data = data.String()
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				emails <- email
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+
+	return listener.Addr().String(), emails
+}
+
+func TestSMTPSenderSendEmail(t *testing.T) {
+	address, emails := startFakeSMTPServer(t)
+
+	sender, err := NewSMTPSender("Simple Bank", "bank@example.com", "secret", address)
+	require.NoError(t, err)
+
+	attachment := filepath.Join(t.TempDir(), "statement.csv")
+	err = os.WriteFile(attachment, []byte("posted_at,amount\n"), 0o600)
+	require.NoError(t, err)
+
+	subject := "Welcome to Simple Bank"
+	content := "<h1>Hello</h1>"
+	to := []string{"alice@example.com"}
+	cc := []string{"bob@example.com"}
+	bcc := []string{"audit@example.com"}
+
+	err = sender.SendEmail(subject, content, to, cc, bcc, []string{attachment})
+	require.NoError(t, err)
+
+	email := <-emails
+	credentials, err := base64.StdEncoding.DecodeString(email.auth)
+	require.NoError(t, err)
+	require.Equal(t, "\x00bank@example.com\x00secret", string(credentials))
+	require.Equal(t, "bank@example.com", email.from)
+	require.Equal(t, []string{"alice@example.com", "bob@example.com", "audit@example.com"}, email.recipients)
+
+	msg, err := netmail.ReadMessage(strings.NewReader(email.data))
+	require.NoError(t, err)
+	require.Equal(t, `"Simple Bank" <bank@example.com>`, msg.Header.Get("From"))
+	require.Equal(t, "alice@example.com", msg.Header.Get("To"))
+	require.Equal(t, "bob@example.com", msg.Header.Get("Cc"))
+	require.Empty(t, msg.Header.Get("Bcc"))
+	require.Equal(t, subject, msg.Header.Get("Subject"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/mixed", mediaType)
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	part, err := reader.NextPart()
+	require.NoError(t, err)
+	require.Equal(t, content, readBase64Part(t, part))
+
+	part, err = reader.NextPart()
+	require.NoError(t, err)
+	require.Equal(t, "statement.csv", part.FileName())
+	require.Equal(t, "posted_at,amount\n", readBase64Part(t, part))
+
+	_, err = reader.NextPart()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestSMTPSenderInvalidRecipient(t *testing.T) {
+	sender, err := NewSMTPSender("Simple Bank", "bank@example.com", "", "127.0.0.1:1")
+	require.NoError(t, err)
+
+	err = sender.SendEmail("subject", "content", []string{"alice@example.com\r\nBcc: eve@example.com"}, nil, nil, nil)
+	require.Error(t, err)
+
+	err = sender.SendEmail("subject", "content", nil, nil, nil, nil)
+	require.Error(t, err)
+}
+
+func TestNewSMTPSender(t *testing.T) {
+	_, err := NewSMTPSender("Simple Bank", "not an email", "", "localhost:25")
+	require.Error(t, err)
+
+	_, err = NewSMTPSender("Simple Bank", "bank@example.com", "", "localhost")
+	require.Error(t, err)
+}
+
+func readBase64Part(t *testing.T, part *multipart.Part) string {
+	require.Equal(t, "base64", part.Header.Get("Content-Transfer-Encoding"))
+
+	data, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, part))
+	require.NoError(t, err)
+	return string(data)
+}
@@ -2,9 +2,11 @@ package main
 
 import (
 	"context"
+	"flag"
 	"net"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -13,12 +15,17 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/hibiken/asynq"
 	"github.com/hykura1501/simple_bank/api"
 	db "github.com/hykura1501/simple_bank/db/sqlc"
 	_ "github.com/hykura1501/simple_bank/docs/statik"
 	"github.com/hykura1501/simple_bank/gapi"
+	"github.com/hykura1501/simple_bank/mail"
 	"github.com/hykura1501/simple_bank/pb"
+	"github.com/hykura1501/simple_bank/reconcile"
+	"github.com/hykura1501/simple_bank/token"
 	"github.com/hykura1501/simple_bank/util"
+	"github.com/hykura1501/simple_bank/worker"
 	_ "github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rakyll/statik/fs"
@@ -38,11 +45,21 @@ func main() {
 		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "keyring" {
+		runKeyringCommand(os.Args[2:])
+		return
+	}
+
 	conn, err := pgxpool.New(context.Background(), config.DBSource)
 	if err != nil {
 		log.Fatal().Msgf("Failed to connect db: %s", err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		runReconcileCommand(config, db.NewStore(conn), os.Args[2:])
+		return
+	}
+
 	// Run db migration
 	m, err := migrate.New(
 		config.MigrationURL,
@@ -54,16 +71,154 @@ func main() {
 
 	m.Up()
 	store := db.NewStore(conn)
-	go runGatewayServer(config, store)
-	runGrpcServer(config, store)
-}
 
-func runGrpcServer(config util.Config, store db.Store) {
-	server, err := gapi.NewServer(config, store)
+	redisOpt := asynq.RedisClientOpt{
+		Addr: config.RedisAddress,
+	}
+	taskDistributor := worker.NewRedisTaskDistributor(redisOpt)
+
+	mailer, err := mail.NewEmailSender(config)
+	if err != nil {
+		log.Fatal().Msgf("cannot create email sender: %s", err)
+	}
+
+	// the symmetric token types don't need the keyring, even if a keyring file is mounted
+	var keyring *token.Keyring
+	if config.TokenKeyringFile != "" && token.UsesKeyring(config) {
+		keyring, err = token.LoadKeyringFile(config.TokenKeyringFile)
+		if err != nil {
+			log.Fatal().Msgf("cannot load token keyring: %s", err)
+		}
+		go runKeyringReloader(config, keyring)
+	}
+
+	// the gateway and the gRPC server share one server, so that they count the failed logins
+	// and cache the revoked sessions together
+	server, err := gapi.NewServer(config, store, taskDistributor, keyring)
 	if err != nil {
 		log.Fatal().Msgf("cannot create a grcp server: %s", err)
 	}
 
+	go runTaskProcessor(config, redisOpt, store, mailer, taskDistributor)
+	go runTaskScheduler(config, redisOpt)
+	go runGatewayServer(config, server, keyring)
+	runGrpcServer(config, server)
+}
+
+func runTaskProcessor(config util.Config, redisOpt asynq.RedisClientOpt, store db.Store, mailer mail.EmailSender, taskDistributor worker.TaskDistributor) {
+	taskProcessor, err := worker.NewRedisTaskProcessor(redisOpt, store, mailer, taskDistributor, config)
+	if err != nil {
+		log.Fatal().Msgf("cannot create task processor: %s", err)
+	}
+
+	log.Info().Msg("start task processor")
+	err = taskProcessor.Start()
+	if err != nil {
+		log.Fatal().Msgf("failed to start task processor: %s", err)
+	}
+}
+
+func runTaskScheduler(config util.Config, redisOpt asynq.RedisClientOpt) {
+	taskScheduler := worker.NewRedisTaskScheduler(
+		redisOpt,
+		config.SessionCleanupSchedule,
+		config.ReconciliationSchedule,
+		config.ScheduledTransferSchedule,
+	)
+	log.Info().Msg("start task scheduler")
+	err := taskScheduler.Start()
+	if err != nil {
+		log.Fatal().Msgf("failed to start task scheduler: %s", err)
+	}
+}
+
+// runKeyringCommand writes a new token keyring file. The keyring holds the private signing key,
+// so it is never committed nor baked into the image: it is mounted from a secret in production
+func runKeyringCommand(args []string) {
+	flags := flag.NewFlagSet("keyring", flag.ExitOnError)
+	output := flags.String("output", "token/keyring.json", "file to write the keyring to, it must not exist yet")
+	keyID := flags.String("key-id", time.Now().UTC().Format("2006-01"), "id of the signing key")
+	flags.Parse(args)
+
+	err := token.GenerateKeyringFile(*output, *keyID)
+	if err != nil {
+		log.Fatal().Msgf("cannot generate token keyring: %s", err)
+	}
+	log.Info().Str("file", *output).Str("key_id", *keyID).Msg("generated token keyring")
+}
+
+// runReconcileCommand reconciles the accounts once from the command line:
+//
+//	main reconcile [-freeze] [-batch-size N] [-after-id ID] [-output FILE]
+//
+// It prints the JSON report, or writes it to FILE, and exits with status 1 if anything doesn't reconcile
+func runReconcileCommand(config util.Config, store db.Store, args []string) {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	freeze := flags.Bool("freeze", config.ReconciliationFreeze, "freeze the accounts of the mismatches")
+	batchSize := flags.Int("batch-size", int(config.ReconciliationBatchSize), "number of accounts checked at a time")
+	afterID := flags.Int64("after-id", 0, "only check the accounts after this ID")
+	output := flags.String("output", "", "file to write the JSON report to instead of stdout")
+	flags.Parse(args)
+
+	report, err := reconcile.Run(context.Background(), store, reconcile.Params{
+		AfterAccountID: *afterID,
+		BatchSize:      int32(*batchSize),
+		Freeze:         *freeze,
+	})
+	if err != nil {
+		log.Fatal().Msgf("cannot reconcile accounts: %s", err)
+	}
+
+	if *output == "" {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = writeReportFile(*output, report)
+	}
+	if err != nil {
+		log.Fatal().Msgf("cannot write reconciliation report: %s", err)
+	}
+
+	if report.HasMismatches() {
+		log.Error().Int("account_mismatches", len(report.AccountMismatches)).
+			Int("transfer_mismatches", len(report.TransferMismatches)).
+			Int("frozen_accounts", len(report.FrozenAccountIDs)).
+			Msg("reconciliation found mismatches")
+		os.Exit(1)
+	}
+	log.Info().Int64("accounts", report.AccountsScanned).Msg("reconciliation found no mismatches")
+}
+
+func writeReportFile(path string, report reconcile.Report) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = report.WriteJSON(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// runKeyringReloader re-reads the keyring file every KeyringReloadInterval,
+// so token signing keys can be rotated without a restart
+func runKeyringReloader(config util.Config, keyring *token.Keyring) {
+	if config.KeyringReloadInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(config.KeyringReloadInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		err := keyring.ReloadFile(config.TokenKeyringFile)
+		if err != nil {
+			log.Error().Err(err).Msg("cannot reload token keyring")
+		}
+	}
+}
+
+func runGrpcServer(config util.Config, server *gapi.Server) {
 	grpcLogger := grpc.UnaryInterceptor(gapi.GrpcLogger)
 	grpcServer := grpc.NewServer(grpcLogger)
 	pb.RegisterSimpleBankServer(grpcServer, server)
@@ -80,12 +235,7 @@ func runGrpcServer(config util.Config, store db.Store) {
 	}
 }
 
-func runGatewayServer(config util.Config, store db.Store) {
-	server, err := gapi.NewServer(config, store)
-	if err != nil {
-		log.Fatal().Msgf("cannot create a grcp server: %s", err)
-	}
-
+func runGatewayServer(config util.Config, server *gapi.Server, keyring *token.Keyring) {
 	grpcMuxOptions := runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
 		MarshalOptions: protojson.MarshalOptions{
 			UseProtoNames: true,
@@ -95,17 +245,24 @@ func runGatewayServer(config util.Config, store db.Store) {
 		},
 	})
 
-	grpcMux := runtime.NewServeMux(grpcMuxOptions)
+	grpcMux := runtime.NewServeMux(
+		grpcMuxOptions,
+		runtime.WithIncomingHeaderMatcher(gapi.HeaderMatcher),
+	)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	err = pb.RegisterSimpleBankHandlerServer(ctx, grpcMux, server)
+	err := pb.RegisterSimpleBankHandlerServer(ctx, grpcMux, server)
 	if err != nil {
 		log.Fatal().Msgf("cannot register handler server: %s", err)
 	}
 
 	mux := http.NewServeMux()
 	mux.Handle("/", grpcMux)
+	mux.Handle("GET /v1/accounts/{id}/statement", server.StatementHandler())
+	if keyring != nil {
+		mux.Handle("GET /.well-known/jwks.json", gapi.JWKSHandler(keyring))
+	}
 	statikFS, err := fs.New()
 	if err != nil {
 		log.Fatal().Msgf("cannot create statik fs: %s", err)
@@ -127,8 +284,8 @@ func runGatewayServer(config util.Config, store db.Store) {
 	}
 }
 
-func runGinServer(config util.Config, store db.Store) {
-	server, err := api.NewServer(config, store)
+func runGinServer(config util.Config, store db.Store, keyring *token.Keyring) {
+	server, err := api.NewServer(config, store, keyring)
 
 	if err != nil {
 		log.Fatal().Msgf("cannot create a gin server: %s", err)
@@ -137,6 +294,6 @@ func runGinServer(config util.Config, store db.Store) {
 	err = server.StartServer(config.HTTPServerAddress)
 
 	if err != nil {
-		log.Fatal().Msgf("error when starting server! ", err)
+		log.Fatal().Msgf("error when starting server: %s", err)
 	}
 }
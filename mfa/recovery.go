@@ -0,0 +1,50 @@
+package mfa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// RecoveryCodeCount is the number of recovery codes issued when MFA is enabled
+const RecoveryCodeCount = 10
+
+// recoveryCodeAlphabet leaves out the characters that are easily confused: 0, 1, l and o
+const recoveryCodeAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+
+// GenerateRecoveryCodes returns count random single-use codes formatted as xxxxx-xxxxx
+func GenerateRecoveryCodes(count int) ([]string, error) {
+	codes := make([]string, count)
+	for i := range codes {
+		randomBytes := make([]byte, 10)
+		if _, err := rand.Read(randomBytes); err != nil {
+			return nil, fmt.Errorf("cannot generate recovery code: %w", err)
+		}
+
+		var code strings.Builder
+		for j, b := range randomBytes {
+			if j == len(randomBytes)/2 {
+				code.WriteByte('-')
+			}
+			code.WriteByte(recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)])
+		}
+		codes[i] = code.String()
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode returns the hash a recovery code is stored as. The codes are random,
+// so unlike passwords they don't need a slow hash. Case, spaces and dashes are ignored
+func HashRecoveryCode(code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,29 @@
+package mfa
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(RecoveryCodeCount)
+	require.NoError(t, err)
+	require.Len(t, codes, RecoveryCodeCount)
+
+	hashes := make(map[string]bool)
+	for _, code := range codes {
+		require.Regexp(t, `^[a-z2-9]{5}-[a-z2-9]{5}$`, code)
+		hashes[HashRecoveryCode(code)] = true
+	}
+	require.Len(t, hashes, RecoveryCodeCount)
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	hash := HashRecoveryCode("abcde-fghij")
+	require.Len(t, hash, 64)
+	require.Equal(t, hash, HashRecoveryCode(strings.ToUpper("abcde fghij")))
+	require.Equal(t, hash, HashRecoveryCode("abcdefghij"))
+	require.NotEqual(t, hash, HashRecoveryCode("abcde-fghik"))
+}
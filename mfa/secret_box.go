@@ -0,0 +1,53 @@
+package mfa
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+var ErrDecryptSecret = errors.New("cannot decrypt secret")
+
+// SecretBox encrypts TOTP secrets before they are stored, with XChaCha20-Poly1305.
+// A secret is bound to its user, so it cannot be decrypted as the secret of another user
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+func NewSecretBox(key string) (*SecretBox, error) {
+	if len(key) != chacha20poly1305.KeySize {
+		return nil, fmt.Errorf("invalid key size: must be exactly %d characters", chacha20poly1305.KeySize)
+	}
+
+	aead, err := chacha20poly1305.NewX([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+	return &SecretBox{aead: aead}, nil
+}
+
+// Seal encrypts the user's secret. The random nonce is prepended to the ciphertext
+func (box *SecretBox) Seal(username string, secret []byte) ([]byte, error) {
+	nonce := make([]byte, box.aead.NonceSize(), box.aead.NonceSize()+len(secret)+box.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("cannot generate nonce: %w", err)
+	}
+	return box.aead.Seal(nonce, nonce, secret, []byte(username)), nil
+}
+
+// Open decrypts a secret sealed for the user
+func (box *SecretBox) Open(username string, sealed []byte) ([]byte, error) {
+	if len(sealed) < box.aead.NonceSize() {
+		return nil, ErrDecryptSecret
+	}
+
+	nonce, ciphertext := sealed[:box.aead.NonceSize()], sealed[box.aead.NonceSize():]
+	secret, err := box.aead.Open(nil, nonce, ciphertext, []byte(username))
+	if err != nil {
+		return nil, ErrDecryptSecret
+	}
+	return secret, nil
+}
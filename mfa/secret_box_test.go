@@ -0,0 +1,42 @@
+package mfa
+
+import (
+	"testing"
+
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretBox(t *testing.T) {
+	box, err := NewSecretBox(util.RandomString(32))
+	require.NoError(t, err)
+
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	username := util.RandomOwner()
+	sealed, err := box.Seal(username, secret)
+	require.NoError(t, err)
+	require.NotContains(t, string(sealed), string(secret))
+
+	opened, err := box.Open(username, sealed)
+	require.NoError(t, err)
+	require.Equal(t, secret, opened)
+
+	_, err = box.Open(util.RandomOwner(), sealed)
+	require.ErrorIs(t, err, ErrDecryptSecret)
+
+	otherBox, err := NewSecretBox(util.RandomString(32))
+	require.NoError(t, err)
+	_, err = otherBox.Open(username, sealed)
+	require.ErrorIs(t, err, ErrDecryptSecret)
+
+	_, err = box.Open(username, sealed[:10])
+	require.ErrorIs(t, err, ErrDecryptSecret)
+}
+
+func TestNewSecretBoxInvalidKey(t *testing.T) {
+	box, err := NewSecretBox(util.RandomString(16))
+	require.Error(t, err)
+	require.Nil(t, box)
+}
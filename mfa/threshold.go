@@ -0,0 +1,52 @@
+package mfa
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hykura1501/simple_bank/util"
+)
+
+// TransferThresholds are the amounts per currency, in minor units, above which a transfer
+// of a user who enabled two-factor authentication needs a TOTP code.
+// Transfers in a currency without a threshold never need one
+type TransferThresholds map[string]int64
+
+// ParseTransferThresholds parses a comma separated list of thresholds such as "USD=100000,EUR=100000".
+// An empty list disables the check
+func ParseTransferThresholds(value string) (TransferThresholds, error) {
+	thresholds := make(TransferThresholds)
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		currency, amount, ok := strings.Cut(field, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid transfer threshold %q: must be CURRENCY=AMOUNT", field)
+		}
+
+		currency = strings.TrimSpace(currency)
+		if !util.IsSupportedCurrency(currency) {
+			return nil, fmt.Errorf("invalid transfer threshold %q: unsupported currency %s", field, currency)
+		}
+		if _, ok := thresholds[currency]; ok {
+			return nil, fmt.Errorf("duplicate transfer threshold for %s", currency)
+		}
+
+		threshold, err := strconv.ParseInt(strings.TrimSpace(amount), 10, 64)
+		if err != nil || threshold <= 0 {
+			return nil, fmt.Errorf("invalid transfer threshold %q: must be a positive amount in minor units", field)
+		}
+		thresholds[currency] = threshold
+	}
+	return thresholds, nil
+}
+
+// Exceeds returns the threshold of the currency and whether amount is above it
+func (thresholds TransferThresholds) Exceeds(currency string, amount int64) (int64, bool) {
+	threshold, ok := thresholds[currency]
+	return threshold, ok && amount > threshold
+}
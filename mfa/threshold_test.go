@@ -0,0 +1,37 @@
+package mfa
+
+import (
+	"testing"
+
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTransferThresholds(t *testing.T) {
+	thresholds, err := ParseTransferThresholds("USD=100000, VND=2500000000,")
+	require.NoError(t, err)
+	require.Equal(t, TransferThresholds{util.USD: 100000, util.VND: 2500000000}, thresholds)
+
+	threshold, exceeds := thresholds.Exceeds(util.USD, 100000)
+	require.Equal(t, int64(100000), threshold)
+	require.False(t, exceeds)
+
+	_, exceeds = thresholds.Exceeds(util.USD, 100001)
+	require.True(t, exceeds)
+
+	// the same amount is below the threshold of a currency with smaller units
+	_, exceeds = thresholds.Exceeds(util.VND, 100001)
+	require.False(t, exceeds)
+
+	_, exceeds = thresholds.Exceeds(util.EUR, 1<<40)
+	require.False(t, exceeds)
+
+	thresholds, err = ParseTransferThresholds("")
+	require.NoError(t, err)
+	require.Empty(t, thresholds)
+
+	for _, value := range []string{"USD", "USD=0", "USD=-1", "USD=abc", "XYZ=100", "USD=1,USD=2"} {
+		_, err := ParseTransferThresholds(value)
+		require.Error(t, err, value)
+	}
+}
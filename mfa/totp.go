@@ -0,0 +1,105 @@
+package mfa
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// TOTP parameters (RFC 6238). They are the defaults of authenticator apps,
+// some of which ignore the parameters of the provisioning URI
+const (
+	secretSize = 20
+	digits     = 6
+	period     = 30 * time.Second
+	// skew is the number of time steps accepted before and after the current one,
+	// to allow for clock drift and for the time taken to type the code
+	skew = 1
+)
+
+var ErrInvalidCode = errors.New("invalid code")
+
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a random TOTP secret
+func GenerateSecret() ([]byte, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("cannot generate secret: %w", err)
+	}
+	return secret, nil
+}
+
+// EncodeSecret returns the base32 form of the secret that users type in authenticator apps
+func EncodeSecret(secret []byte) string {
+	return secretEncoding.EncodeToString(secret)
+}
+
+// ProvisioningURI returns the otpauth URI that authenticator apps enroll the secret from, usually shown as a QR code
+func ProvisioningURI(issuer string, accountName string, secret []byte) string {
+	query := url.Values{}
+	query.Set("secret", EncodeSecret(secret))
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(digits))
+	query.Set("period", fmt.Sprint(int(period.Seconds())))
+
+	uri := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + accountName,
+		RawQuery: query.Encode(),
+	}
+	return uri.String()
+}
+
+// GenerateCode returns the code of the time step containing t
+func GenerateCode(secret []byte, t time.Time) string {
+	return hotp(secret, timeStep(t))
+}
+
+// ValidateCode checks a code against the time steps around t. It returns the time step
+// the code belongs to, so that the caller can reject a code that was already used
+func ValidateCode(secret []byte, code string, t time.Time) (int64, error) {
+	if len(code) != digits {
+		return 0, ErrInvalidCode
+	}
+
+	current := timeStep(t)
+	for step := current - skew; step <= current+skew; step++ {
+		if subtle.ConstantTimeCompare([]byte(hotp(secret, step)), []byte(code)) == 1 {
+			return step, nil
+		}
+	}
+	return 0, ErrInvalidCode
+}
+
+func timeStep(t time.Time) int64 {
+	return t.Unix() / int64(period.Seconds())
+}
+
+// hotp computes the HOTP value of the counter (RFC 4226)
+func hotp(secret []byte, counter int64) string {
+	var message [8]byte
+	binary.BigEndian.PutUint64(message[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(message[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for range digits {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%modulo)
+}
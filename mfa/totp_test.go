@@ -0,0 +1,75 @@
+package mfa
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret is the SHA1 secret of the RFC 6238 test vectors
+var rfc6238Secret = []byte("12345678901234567890")
+
+func TestGenerateCode(t *testing.T) {
+	// the last 6 digits of the 8 digit codes of RFC 6238 appendix B
+	testCases := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+		{unix: 20000000000, code: "353130"},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.code, GenerateCode(rfc6238Secret, time.Unix(tc.unix, 0)))
+	}
+}
+
+func TestValidateCode(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, secretSize)
+
+	now := time.Unix(1234567890, 0)
+	code := GenerateCode(secret, now)
+
+	step, err := ValidateCode(secret, code, now)
+	require.NoError(t, err)
+	require.Equal(t, timeStep(now), step)
+
+	// a code of the previous time step is still accepted
+	step, err = ValidateCode(secret, code, now.Add(period))
+	require.NoError(t, err)
+	require.Equal(t, timeStep(now), step)
+
+	_, err = ValidateCode(secret, code, now.Add(3*period))
+	require.ErrorIs(t, err, ErrInvalidCode)
+
+	_, err = ValidateCode(secret, "12345", now)
+	require.ErrorIs(t, err, ErrInvalidCode)
+
+	otherSecret, err := GenerateSecret()
+	require.NoError(t, err)
+	_, err = ValidateCode(otherSecret, code, now)
+	require.ErrorIs(t, err, ErrInvalidCode)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(ProvisioningURI("Simple Bank", "alice", rfc6238Secret))
+	require.NoError(t, err)
+
+	require.Equal(t, "otpauth", uri.Scheme)
+	require.Equal(t, "totp", uri.Host)
+	require.Equal(t, "/Simple Bank:alice", uri.Path)
+
+	query := uri.Query()
+	require.Equal(t, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", query.Get("secret"))
+	require.Equal(t, "Simple Bank", query.Get("issuer"))
+	require.Equal(t, "6", query.Get("digits"))
+	require.Equal(t, "30", query.Get("period"))
+}
@@ -0,0 +1,231 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        v3.21.12
+// source: rpc_adjust_account_balance.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AdjustAccountBalanceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     int64                  `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Amount        int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdjustAccountBalanceRequest) Reset() {
+	*x = AdjustAccountBalanceRequest{}
+	mi := &file_rpc_adjust_account_balance_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdjustAccountBalanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdjustAccountBalanceRequest) ProtoMessage() {}
+
+func (x *AdjustAccountBalanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_adjust_account_balance_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdjustAccountBalanceRequest.ProtoReflect.Descriptor instead.
+func (*AdjustAccountBalanceRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_adjust_account_balance_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AdjustAccountBalanceRequest) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *AdjustAccountBalanceRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *AdjustAccountBalanceRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type AdjustAccountBalanceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Account       *Account               `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+	Entry         *Entry                 `protobuf:"bytes,2,opt,name=entry,proto3" json:"entry,omitempty"`
+	AdjustmentId  int64                  `protobuf:"varint,3,opt,name=adjustment_id,json=adjustmentId,proto3" json:"adjustment_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	AdjustedBy    string                 `protobuf:"bytes,5,opt,name=adjusted_by,json=adjustedBy,proto3" json:"adjusted_by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdjustAccountBalanceResponse) Reset() {
+	*x = AdjustAccountBalanceResponse{}
+	mi := &file_rpc_adjust_account_balance_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdjustAccountBalanceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdjustAccountBalanceResponse) ProtoMessage() {}
+
+func (x *AdjustAccountBalanceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_adjust_account_balance_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdjustAccountBalanceResponse.ProtoReflect.Descriptor instead.
+func (*AdjustAccountBalanceResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_adjust_account_balance_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AdjustAccountBalanceResponse) GetAccount() *Account {
+	if x != nil {
+		return x.Account
+	}
+	return nil
+}
+
+func (x *AdjustAccountBalanceResponse) GetEntry() *Entry {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
+func (x *AdjustAccountBalanceResponse) GetAdjustmentId() int64 {
+	if x != nil {
+		return x.AdjustmentId
+	}
+	return 0
+}
+
+func (x *AdjustAccountBalanceResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *AdjustAccountBalanceResponse) GetAdjustedBy() string {
+	if x != nil {
+		return x.AdjustedBy
+	}
+	return ""
+}
+
+var File_rpc_adjust_account_balance_proto protoreflect.FileDescriptor
+
+const file_rpc_adjust_account_balance_proto_rawDesc = "" +
+	"\n" +
+	" rpc_adjust_account_balance.proto\x12\x02pb\x1a\raccount.proto\x1a\ventry.proto\"l\n" +
+	"\x1bAdjustAccountBalanceRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\x03R\taccountId\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"\xc4\x01\n" +
+	"\x1cAdjustAccountBalanceResponse\x12%\n" +
+	"\aaccount\x18\x01 \x01(\v2\v.pb.AccountR\aaccount\x12\x1f\n" +
+	"\x05entry\x18\x02 \x01(\v2\t.pb.EntryR\x05entry\x12#\n" +
+	"\radjustment_id\x18\x03 \x01(\x03R\fadjustmentId\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12\x1f\n" +
+	"\vadjusted_by\x18\x05 \x01(\tR\n" +
+	"adjustedByB&Z$github.com/hykura1501/simple_bank/pbb\x06proto3"
+
+var (
+	file_rpc_adjust_account_balance_proto_rawDescOnce sync.Once
+	file_rpc_adjust_account_balance_proto_rawDescData []byte
+)
+
+func file_rpc_adjust_account_balance_proto_rawDescGZIP() []byte {
+	file_rpc_adjust_account_balance_proto_rawDescOnce.Do(func() {
+		file_rpc_adjust_account_balance_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rpc_adjust_account_balance_proto_rawDesc), len(file_rpc_adjust_account_balance_proto_rawDesc)))
+	})
+	return file_rpc_adjust_account_balance_proto_rawDescData
+}
+
+var file_rpc_adjust_account_balance_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_adjust_account_balance_proto_goTypes = []any{
+	(*AdjustAccountBalanceRequest)(nil),  // 0: pb.AdjustAccountBalanceRequest
+	(*AdjustAccountBalanceResponse)(nil), // 1: pb.AdjustAccountBalanceResponse
+	(*Account)(nil),                      // 2: pb.Account
+	(*Entry)(nil),                        // 3: pb.Entry
+}
+var file_rpc_adjust_account_balance_proto_depIdxs = []int32{
+	2, // 0: pb.AdjustAccountBalanceResponse.account:type_name -> pb.Account
+	3, // 1: pb.AdjustAccountBalanceResponse.entry:type_name -> pb.Entry
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_rpc_adjust_account_balance_proto_init() }
+func file_rpc_adjust_account_balance_proto_init() {
+	if File_rpc_adjust_account_balance_proto != nil {
+		return
+	}
+	file_account_proto_init()
+	file_entry_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rpc_adjust_account_balance_proto_rawDesc), len(file_rpc_adjust_account_balance_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_adjust_account_balance_proto_goTypes,
+		DependencyIndexes: file_rpc_adjust_account_balance_proto_depIdxs,
+		MessageInfos:      file_rpc_adjust_account_balance_proto_msgTypes,
+	}.Build()
+	File_rpc_adjust_account_balance_proto = out.File
+	file_rpc_adjust_account_balance_proto_goTypes = nil
+	file_rpc_adjust_account_balance_proto_depIdxs = nil
+}
@@ -0,0 +1,169 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        v3.21.12
+// source: rpc_enable_mfa.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type EnableMFARequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TotpCode      string                 `protobuf:"bytes,1,opt,name=totp_code,json=totpCode,proto3" json:"totp_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnableMFARequest) Reset() {
+	*x = EnableMFARequest{}
+	mi := &file_rpc_enable_mfa_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnableMFARequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnableMFARequest) ProtoMessage() {}
+
+func (x *EnableMFARequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_enable_mfa_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnableMFARequest.ProtoReflect.Descriptor instead.
+func (*EnableMFARequest) Descriptor() ([]byte, []int) {
+	return file_rpc_enable_mfa_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *EnableMFARequest) GetTotpCode() string {
+	if x != nil {
+		return x.TotpCode
+	}
+	return ""
+}
+
+type EnableMFAResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RecoveryCodes []string               `protobuf:"bytes,1,rep,name=recovery_codes,json=recoveryCodes,proto3" json:"recovery_codes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnableMFAResponse) Reset() {
+	*x = EnableMFAResponse{}
+	mi := &file_rpc_enable_mfa_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnableMFAResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnableMFAResponse) ProtoMessage() {}
+
+func (x *EnableMFAResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_enable_mfa_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnableMFAResponse.ProtoReflect.Descriptor instead.
+func (*EnableMFAResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_enable_mfa_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *EnableMFAResponse) GetRecoveryCodes() []string {
+	if x != nil {
+		return x.RecoveryCodes
+	}
+	return nil
+}
+
+var File_rpc_enable_mfa_proto protoreflect.FileDescriptor
+
+const file_rpc_enable_mfa_proto_rawDesc = "" +
+	"\n" +
+	"\x14rpc_enable_mfa.proto\x12\x02pb\"/\n" +
+	"\x10EnableMFARequest\x12\x1b\n" +
+	"\ttotp_code\x18\x01 \x01(\tR\btotpCode\":\n" +
+	"\x11EnableMFAResponse\x12%\n" +
+	"\x0erecovery_codes\x18\x01 \x03(\tR\rrecoveryCodesB&Z$github.com/hykura1501/simple_bank/pbb\x06proto3"
+
+var (
+	file_rpc_enable_mfa_proto_rawDescOnce sync.Once
+	file_rpc_enable_mfa_proto_rawDescData []byte
+)
+
+func file_rpc_enable_mfa_proto_rawDescGZIP() []byte {
+	file_rpc_enable_mfa_proto_rawDescOnce.Do(func() {
+		file_rpc_enable_mfa_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rpc_enable_mfa_proto_rawDesc), len(file_rpc_enable_mfa_proto_rawDesc)))
+	})
+	return file_rpc_enable_mfa_proto_rawDescData
+}
+
+var file_rpc_enable_mfa_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_enable_mfa_proto_goTypes = []any{
+	(*EnableMFARequest)(nil),  // 0: pb.EnableMFARequest
+	(*EnableMFAResponse)(nil), // 1: pb.EnableMFAResponse
+}
+var file_rpc_enable_mfa_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_rpc_enable_mfa_proto_init() }
+func file_rpc_enable_mfa_proto_init() {
+	if File_rpc_enable_mfa_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rpc_enable_mfa_proto_rawDesc), len(file_rpc_enable_mfa_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_enable_mfa_proto_goTypes,
+		DependencyIndexes: file_rpc_enable_mfa_proto_depIdxs,
+		MessageInfos:      file_rpc_enable_mfa_proto_msgTypes,
+	}.Build()
+	File_rpc_enable_mfa_proto = out.File
+	file_rpc_enable_mfa_proto_goTypes = nil
+	file_rpc_enable_mfa_proto_depIdxs = nil
+}
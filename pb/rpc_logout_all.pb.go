@@ -0,0 +1,160 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        v3.21.12
+// source: rpc_logout_all.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type LogoutAllRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogoutAllRequest) Reset() {
+	*x = LogoutAllRequest{}
+	mi := &file_rpc_logout_all_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogoutAllRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogoutAllRequest) ProtoMessage() {}
+
+func (x *LogoutAllRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_logout_all_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogoutAllRequest.ProtoReflect.Descriptor instead.
+func (*LogoutAllRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_logout_all_proto_rawDescGZIP(), []int{0}
+}
+
+type LogoutAllResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	BlockedSessions int64                  `protobuf:"varint,1,opt,name=blocked_sessions,json=blockedSessions,proto3" json:"blocked_sessions,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *LogoutAllResponse) Reset() {
+	*x = LogoutAllResponse{}
+	mi := &file_rpc_logout_all_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogoutAllResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogoutAllResponse) ProtoMessage() {}
+
+func (x *LogoutAllResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_logout_all_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogoutAllResponse.ProtoReflect.Descriptor instead.
+func (*LogoutAllResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_logout_all_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *LogoutAllResponse) GetBlockedSessions() int64 {
+	if x != nil {
+		return x.BlockedSessions
+	}
+	return 0
+}
+
+var File_rpc_logout_all_proto protoreflect.FileDescriptor
+
+const file_rpc_logout_all_proto_rawDesc = "" +
+	"\n" +
+	"\x14rpc_logout_all.proto\x12\x02pb\"\x12\n" +
+	"\x10LogoutAllRequest\">\n" +
+	"\x11LogoutAllResponse\x12)\n" +
+	"\x10blocked_sessions\x18\x01 \x01(\x03R\x0fblockedSessionsB&Z$github.com/hykura1501/simple_bank/pbb\x06proto3"
+
+var (
+	file_rpc_logout_all_proto_rawDescOnce sync.Once
+	file_rpc_logout_all_proto_rawDescData []byte
+)
+
+func file_rpc_logout_all_proto_rawDescGZIP() []byte {
+	file_rpc_logout_all_proto_rawDescOnce.Do(func() {
+		file_rpc_logout_all_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rpc_logout_all_proto_rawDesc), len(file_rpc_logout_all_proto_rawDesc)))
+	})
+	return file_rpc_logout_all_proto_rawDescData
+}
+
+var file_rpc_logout_all_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_logout_all_proto_goTypes = []any{
+	(*LogoutAllRequest)(nil),  // 0: pb.LogoutAllRequest
+	(*LogoutAllResponse)(nil), // 1: pb.LogoutAllResponse
+}
+var file_rpc_logout_all_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_rpc_logout_all_proto_init() }
+func file_rpc_logout_all_proto_init() {
+	if File_rpc_logout_all_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rpc_logout_all_proto_rawDesc), len(file_rpc_logout_all_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_logout_all_proto_goTypes,
+		DependencyIndexes: file_rpc_logout_all_proto_depIdxs,
+		MessageInfos:      file_rpc_logout_all_proto_msgTypes,
+	}.Build()
+	File_rpc_logout_all_proto = out.File
+	file_rpc_logout_all_proto_goTypes = nil
+	file_rpc_logout_all_proto_depIdxs = nil
+}
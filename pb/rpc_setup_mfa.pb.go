@@ -0,0 +1,169 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        v3.21.12
+// source: rpc_setup_mfa.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SetupMFARequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetupMFARequest) Reset() {
+	*x = SetupMFARequest{}
+	mi := &file_rpc_setup_mfa_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetupMFARequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetupMFARequest) ProtoMessage() {}
+
+func (x *SetupMFARequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_setup_mfa_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetupMFARequest.ProtoReflect.Descriptor instead.
+func (*SetupMFARequest) Descriptor() ([]byte, []int) {
+	return file_rpc_setup_mfa_proto_rawDescGZIP(), []int{0}
+}
+
+type SetupMFAResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Secret          string                 `protobuf:"bytes,1,opt,name=secret,proto3" json:"secret,omitempty"`
+	ProvisioningUri string                 `protobuf:"bytes,2,opt,name=provisioning_uri,json=provisioningUri,proto3" json:"provisioning_uri,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SetupMFAResponse) Reset() {
+	*x = SetupMFAResponse{}
+	mi := &file_rpc_setup_mfa_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetupMFAResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetupMFAResponse) ProtoMessage() {}
+
+func (x *SetupMFAResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_setup_mfa_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetupMFAResponse.ProtoReflect.Descriptor instead.
+func (*SetupMFAResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_setup_mfa_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SetupMFAResponse) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+func (x *SetupMFAResponse) GetProvisioningUri() string {
+	if x != nil {
+		return x.ProvisioningUri
+	}
+	return ""
+}
+
+var File_rpc_setup_mfa_proto protoreflect.FileDescriptor
+
+const file_rpc_setup_mfa_proto_rawDesc = "" +
+	"\n" +
+	"\x13rpc_setup_mfa.proto\x12\x02pb\"\x11\n" +
+	"\x0fSetupMFARequest\"U\n" +
+	"\x10SetupMFAResponse\x12\x16\n" +
+	"\x06secret\x18\x01 \x01(\tR\x06secret\x12)\n" +
+	"\x10provisioning_uri\x18\x02 \x01(\tR\x0fprovisioningUriB&Z$github.com/hykura1501/simple_bank/pbb\x06proto3"
+
+var (
+	file_rpc_setup_mfa_proto_rawDescOnce sync.Once
+	file_rpc_setup_mfa_proto_rawDescData []byte
+)
+
+func file_rpc_setup_mfa_proto_rawDescGZIP() []byte {
+	file_rpc_setup_mfa_proto_rawDescOnce.Do(func() {
+		file_rpc_setup_mfa_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rpc_setup_mfa_proto_rawDesc), len(file_rpc_setup_mfa_proto_rawDesc)))
+	})
+	return file_rpc_setup_mfa_proto_rawDescData
+}
+
+var file_rpc_setup_mfa_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_setup_mfa_proto_goTypes = []any{
+	(*SetupMFARequest)(nil),  // 0: pb.SetupMFARequest
+	(*SetupMFAResponse)(nil), // 1: pb.SetupMFAResponse
+}
+var file_rpc_setup_mfa_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_rpc_setup_mfa_proto_init() }
+func file_rpc_setup_mfa_proto_init() {
+	if File_rpc_setup_mfa_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rpc_setup_mfa_proto_rawDesc), len(file_rpc_setup_mfa_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_setup_mfa_proto_goTypes,
+		DependencyIndexes: file_rpc_setup_mfa_proto_depIdxs,
+		MessageInfos:      file_rpc_setup_mfa_proto_msgTypes,
+	}.Build()
+	File_rpc_setup_mfa_proto = out.File
+	file_rpc_setup_mfa_proto_goTypes = nil
+	file_rpc_setup_mfa_proto_depIdxs = nil
+}
@@ -26,39 +26,171 @@ var File_service_simple_bank_proto protoreflect.FileDescriptor
 
 const file_service_simple_bank_proto_rawDesc = "" +
 	"\n" +
-	"\x19service_simple_bank.proto\x12\x02pb\x1a\x1cgoogle/api/annotations.proto\x1a\x15rpc_create_user.proto\x1a\x14rpc_login_user.proto\x1a\x15rpc_update_user.proto\x1a.protoc-gen-openapiv2/options/annotations.proto2\xa6\x03\n" +
+	"\x19service_simple_bank.proto\x12\x02pb\x1a\x1cgoogle/api/annotations.proto\x1a\x15rpc_create_user.proto\x1a\x14rpc_login_user.proto\x1a\x1arpc_verify_login_mfa.proto\x1a\x1crpc_renew_access_token.proto\x1a\x10rpc_logout.proto\x1a\x14rpc_logout_all.proto\x1a\x17rpc_list_sessions.proto\x1a\x18rpc_revoke_session.proto\x1a\x13rpc_setup_mfa.proto\x1a\x14rpc_enable_mfa.proto\x1a\x15rpc_disable_mfa.proto\x1a\x12rpc_get_user.proto\x1a\x15rpc_update_user.proto\x1a\x16rpc_verify_email.proto\x1a rpc_request_password_reset.proto\x1a\x18rpc_reset_password.proto\x1a\x18rpc_create_account.proto\x1a\x15rpc_get_account.proto\x1a\x17rpc_list_accounts.proto\x1a\x18rpc_update_account.proto\x1a rpc_adjust_account_balance.proto\x1a\x1erpc_list_account_entries.proto\x1a\x19rpc_create_transfer.proto\x1a\x1arpc_reverse_transfer.proto\x1a#rpc_create_scheduled_transfer.proto\x1a rpc_get_scheduled_transfer.proto\x1a\"rpc_list_scheduled_transfers.proto\x1a#rpc_update_scheduled_transfer.proto\x1a#rpc_delete_scheduled_transfer.proto\x1a.protoc-gen-openapiv2/options/annotations.proto2\xe49\n" +
 	"\n" +
 	"SimpleBank\x12\x8e\x01\n" +
 	"\n" +
-	"CreateUser\x12\x15.pb.CreateUserRequest\x1a\x16.pb.CreateUserResponse\"Q\x92A4\x12\x0fCreate new user\x1a!Use this API to create a new user\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/create_user\x12~\n" +
-	"\tLoginUser\x12\x14.pb.LoginUserRequest\x1a\x15.pb.LoginUserResponse\"D\x92A(\x12\n" +
-	"Login user\x1a\x1aUse this API to login user\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/v1/login_user\x12\x86\x01\n" +
+	"CreateUser\x12\x15.pb.CreateUserRequest\x1a\x16.pb.CreateUserResponse\"Q\x92A4\x12\x0fCreate new user\x1a!Use this API to create a new user\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/create_user\x12\x91\x02\n" +
+	"\tLoginUser\x12\x14.pb.LoginUserRequest\x1a\x15.pb.LoginUserResponse\"\xd6\x01\x92A\xb9\x01\x12\n" +
+	"Login user\x1a\xaa\x01Use this API to login user. When the user has two-factor authentication enabled, no session is created: mfa_required is set and the mfa_token must be verified with a code\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/v1/login_user\x12\x87\x02\n" +
+	"\x0eVerifyLoginMFA\x12\x19.pb.VerifyLoginMFARequest\x1a\x15.pb.LoginUserResponse\"\xc2\x01\x92A\x9f\x01\x12\x11Verify login code\x1a\x89\x01Use this API to complete the login of a user with two-factor authentication, using the mfa_token of the login and a TOTP or recovery code\x82\xd3\xe4\x93\x02\x19:\x01*\"\x14/v1/verify_login_mfa\x12\x97\x02\n" +
+	"\x10RenewAccessToken\x12\x1b.pb.RenewAccessTokenRequest\x1a\x1c.pb.RenewAccessTokenResponse\"\xc7\x01\x92A\xa2\x01\x12\x12Renew access token\x1a\x8b\x01Use this API to exchange the refresh token of a session for a new access token and refresh token. The old refresh token can't be used again\x82\xd3\xe4\x93\x02\x1b:\x01*\"\x16/v1/renew_access_token\x12\x85\x01\n" +
+	"\x06Logout\x12\x11.pb.LogoutRequest\x1a\x12.pb.LogoutResponse\"T\x92A<\x12\x06Logout\x1a2Use this API to end the session of a refresh token\x82\xd3\xe4\x93\x02\x0f:\x01*\"\n" +
+	"/v1/logout\x12\xa4\x01\n" +
+	"\tLogoutAll\x12\x14.pb.LogoutAllRequest\x1a\x15.pb.LogoutAllResponse\"j\x92AN\x12\x13Logout all sessions\x1a7Use this API to end every session of the logged in user\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/v1/logout_all\x12\xa9\x01\n" +
+	"\fListSessions\x12\x17.pb.ListSessionsRequest\x1a\x18.pb.ListSessionsResponse\"f\x92AO\x12\rList sessions\x1a>Use this API to list the active sessions of the logged in user\x82\xd3\xe4\x93\x02\x0e\x12\f/v1/sessions\x12\xb7\x01\n" +
+	"\rRevokeSession\x12\x18.pb.RevokeSessionRequest\x1a\x19.pb.RevokeSessionResponse\"q\x92AM\x12\x0eRevoke session\x1a;Use this API to end an active session of the logged in user\x82\xd3\xe4\x93\x02\x1b*\x19/v1/sessions/{session_id}\x12\x88\x02\n" +
+	"\bSetupMFA\x12\x13.pb.SetupMFARequest\x1a\x14.pb.SetupMFAResponse\"\xd0\x01\x92A\xb4\x01\x12 Set up two-factor authentication\x1a\x8f\x01Use this API to start enrolling an authenticator app for two-factor authentication. It returns the TOTP secret and its otpauth provisioning URI\x82\xd3\xe4\x93\x02\x12:\x01*\"\r/v1/setup_mfa\x12\x89\x02\n" +
+	"\tEnableMFA\x12\x14.pb.EnableMFARequest\x1a\x15.pb.EnableMFAResponse\"\xce\x01\x92A\xb1\x01\x12 Enable two-factor authentication\x1a\x8c\x01Use this API to confirm the enrollment with a code of the authenticator app. It returns single-use recovery codes, which are only shown once\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/v1/enable_mfa\x12\xeb\x01\n" +
 	"\n" +
-	"UpdateUser\x12\x15.pb.UpdateUserRequest\x1a\x16.pb.UpdateUserResponse\"I\x92A,\x12\vUpdate user\x1a\x1dUse this API to update a user\x82\xd3\xe4\x93\x02\x14:\x01*2\x0f/v1/update_userB\x83\x01\x92AZ\x12X\n" +
+	"DisableMFA\x12\x15.pb.DisableMFARequest\x1a\x16.pb.DisableMFAResponse\"\xad\x01\x92A\x8f\x01\x12!Disable two-factor authentication\x1ajUse this API to turn off two-factor authentication with a code of the authenticator app or a recovery code\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/disable_mfa\x12\xaa\x01\n" +
+	"\aGetUser\x12\x12.pb.GetUserRequest\x1a\x13.pb.GetUserResponse\"v\x92AW\x12\bGet user\x1aKUse this API to get the logged in user. Bankers and admins can get any user\x82\xd3\xe4\x93\x02\x16\x12\x14/v1/users/{username}\x12\xe2\x01\n" +
+	"\n" +
+	"UpdateUser\x12\x15.pb.UpdateUserRequest\x1a\x16.pb.UpdateUserResponse\"\xa4\x01\x92A\x86\x01\x12\vUpdate user\x1awUse this API to update the logged in user. Bankers and admins can only change the full name of a user with a lower role\x82\xd3\xe4\x93\x02\x14:\x01*2\x0f/v1/update_user\x12\xb6\x01\n" +
+	"\vVerifyEmail\x12\x16.pb.VerifyEmailRequest\x1a\x17.pb.VerifyEmailResponse\"v\x92A[\x12\fVerify email\x1aKUse this API to verify the email address of a user with the link sent to it\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/verify_email\x12\xad\x02\n" +
+	"\x14RequestPasswordReset\x12\x1f.pb.RequestPasswordResetRequest\x1a .pb.RequestPasswordResetResponse\"\xd1\x01\x92A\xa8\x01\x12\x16Request password reset\x1a\x8d\x01Use this API to email a single-use password reset link to a user. The response is the same whether or not the email address belongs to a user\x82\xd3\xe4\x93\x02\x1f:\x01*\"\x1a/v1/request_password_reset\x12\xf0\x01\n" +
+	"\rResetPassword\x12\x18.pb.ResetPasswordRequest\x1a\x19.pb.ResetPasswordResponse\"\xa9\x01\x92A\x88\x01\x12\x0eReset password\x1avUse this API to choose a new password with the token of a password reset link. All sessions of the user are logged out\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/v1/reset_password\x12\xb7\x01\n" +
+	"\rCreateAccount\x12\x18.pb.CreateAccountRequest\x1a\x19.pb.CreateAccountResponse\"q\x92AQ\x12\x12Create new account\x1a;Use this API to create a new account for the logged in user\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/v1/create_account\x12\xc5\x01\n" +
+	"\n" +
+	"GetAccount\x12\x15.pb.GetAccountRequest\x1a\x16.pb.GetAccountResponse\"\x87\x01\x92Ak\x12\vGet account\x1a\\Use this API to get an account of the logged in user. Bankers and admins can get any account\x82\xd3\xe4\x93\x02\x13\x12\x11/v1/accounts/{id}\x12\xd6\x01\n" +
+	"\fListAccounts\x12\x17.pb.ListAccountsRequest\x1a\x18.pb.ListAccountsResponse\"\x92\x01\x92A{\x12\rList accounts\x1ajUse this API to list accounts of the logged in user. Bankers and admins can list the accounts of any owner\x82\xd3\xe4\x93\x02\x0e\x12\f/v1/accounts\x12\xc5\x01\n" +
+	"\rUpdateAccount\x12\x18.pb.UpdateAccountRequest\x1a\x19.pb.UpdateAccountResponse\"\x7f\x92A`\x12\x0eUpdate account\x1aNUse this API to update an account. Only bankers and admins can update accounts\x82\xd3\xe4\x93\x02\x16:\x01*2\x11/v1/accounts/{id}\x12\xec\x02\n" +
+	"\x14AdjustAccountBalance\x12\x1f.pb.AdjustAccountBalanceRequest\x1a .pb.AdjustAccountBalanceResponse\"\x90\x02\x92A\xe7\x01\x12\x16Adjust account balance\x1a\xcc\x01Use this API to correct the balance of an account with a compensating entry. The amount is added to the balance, negative for a debit, and a reason is required. Only bankers and admins can adjust balances\x82\xd3\xe4\x93\x02\x1f:\x01*\"\x1a/v1/adjust_account_balance\x12\xb3\x02\n" +
+	"\x12ListAccountEntries\x12\x1d.pb.ListAccountEntriesRequest\x1a\x1e.pb.ListAccountEntriesResponse\"\xdd\x01\x92A\xb0\x01\x12\x14List account entries\x1a\x97\x01Use this API to list the entries of an account of the logged in user with their running balance. Bankers and admins can list the entries of any account\x82\xd3\xe4\x93\x02#\x12!/v1/accounts/{account_id}/entries\x12\xfa\x02\n" +
+	"\x0eCreateTransfer\x12\x19.pb.CreateTransferRequest\x1a\x1a.pb.CreateTransferResponse\"\xb0\x02\x92A\x8e\x02\x12\x0fCreate transfer\x1a\xfa\x01Use this API to transfer money between two accounts. The amount is converted when the accounts hold different currencies. Transfers above the two-factor threshold of their currency require a totp_code if the user has enabled two-factor authentication\x82\xd3\xe4\x93\x02\x18:\x01*\"\x13/v1/create_transfer\x12\xf0\x02\n" +
+	"\x0fReverseTransfer\x12\x1a.pb.ReverseTransferRequest\x1a\x1b.pb.ReverseTransferResponse\"\xa3\x02\x92A\x80\x02\x12\x10Reverse transfer\x1a\xeb\x01Use this API to reverse all or part of a transfer with a compensating transfer back to its source account. The amount is in the currency of the source account and a reason_code is required. Only bankers and admins can reverse transfers\x82\xd3\xe4\x93\x02\x19:\x01*\"\x14/v1/reverse_transfer\x12\xc3\x03\n" +
+	"\x17CreateScheduledTransfer\x12\".pb.CreateScheduledTransferRequest\x1a#.pb.CreateScheduledTransferResponse\"\xde\x02\x92A\xb8\x02\x12\x19Create scheduled transfer\x1a\x9a\x02Use this API to create a standing order that transfers the amount from an account of the logged in user on a cron schedule, e.g. \"0 9 1 * *\" for 09:00 UTC on the 1st of every month. Both accounts must hold the currency. The schedule can end at end_time or after max_occurrences runs\x82\xd3\xe4\x93\x02\x1c:\x01*\"\x17/v1/scheduled_transfers\x12\xb4\x02\n" +
+	"\x14GetScheduledTransfer\x12\x1f.pb.GetScheduledTransferRequest\x1a .pb.GetScheduledTransferResponse\"\xd8\x01\x92A\xb0\x01\x12\x16Get scheduled transfer\x1a\x95\x01Use this API to get a scheduled transfer of the logged in user with the outcome of its recent runs. Bankers and admins can get any scheduled transfer\x82\xd3\xe4\x93\x02\x1e\x12\x1c/v1/scheduled_transfers/{id}\x12\xdd\x01\n" +
+	"\x16ListScheduledTransfers\x12!.pb.ListScheduledTransfersRequest\x1a\".pb.ListScheduledTransfersResponse\"|\x92AZ\x12\x18List scheduled transfers\x1a>Use this API to list scheduled transfers of the logged in user\x82\xd3\xe4\x93\x02\x19\x12\x17/v1/scheduled_transfers\x12\xea\x02\n" +
+	"\x17UpdateScheduledTransfer\x12\".pb.UpdateScheduledTransferRequest\x1a#.pb.UpdateScheduledTransferResponse\"\x85\x02\x92A\xda\x01\x12\x19Update scheduled transfer\x1a\xbc\x01Use this API to change the amount, schedule or limits of a scheduled transfer of the logged in user, or to pause and resume it. Completed and cancelled scheduled transfers can't be updated\x82\xd3\xe4\x93\x02!:\x01*2\x1c/v1/scheduled_transfers/{id}\x12\x82\x02\n" +
+	"\x17DeleteScheduledTransfer\x12\".pb.DeleteScheduledTransferRequest\x1a#.pb.DeleteScheduledTransferResponse\"\x9d\x01\x92Av\x12\x19Delete scheduled transfer\x1aYUse this API to cancel a scheduled transfer of the logged in user. Its past runs are kept\x82\xd3\xe4\x93\x02\x1e*\x1c/v1/scheduled_transfers/{id}B\x83\x01\x92AZ\x12X\n" +
 	"\x0fSimple Bank API\"@\n" +
 	"\n" +
 	"SimpleBank\x12\x1dhttps://github.com/hykura1501\x1a\x13voho39850@gmail.com2\x031.2Z$github.com/hykura1501/simple_bank/pbb\x06proto3"
 
 var file_service_simple_bank_proto_goTypes = []any{
-	(*CreateUserRequest)(nil),  // 0: pb.CreateUserRequest
-	(*LoginUserRequest)(nil),   // 1: pb.LoginUserRequest
-	(*UpdateUserRequest)(nil),  // 2: pb.UpdateUserRequest
-	(*CreateUserResponse)(nil), // 3: pb.CreateUserResponse
-	(*LoginUserResponse)(nil),  // 4: pb.LoginUserResponse
-	(*UpdateUserResponse)(nil), // 5: pb.UpdateUserResponse
+	(*CreateUserRequest)(nil),               // 0: pb.CreateUserRequest
+	(*LoginUserRequest)(nil),                // 1: pb.LoginUserRequest
+	(*VerifyLoginMFARequest)(nil),           // 2: pb.VerifyLoginMFARequest
+	(*RenewAccessTokenRequest)(nil),         // 3: pb.RenewAccessTokenRequest
+	(*LogoutRequest)(nil),                   // 4: pb.LogoutRequest
+	(*LogoutAllRequest)(nil),                // 5: pb.LogoutAllRequest
+	(*ListSessionsRequest)(nil),             // 6: pb.ListSessionsRequest
+	(*RevokeSessionRequest)(nil),            // 7: pb.RevokeSessionRequest
+	(*SetupMFARequest)(nil),                 // 8: pb.SetupMFARequest
+	(*EnableMFARequest)(nil),                // 9: pb.EnableMFARequest
+	(*DisableMFARequest)(nil),               // 10: pb.DisableMFARequest
+	(*GetUserRequest)(nil),                  // 11: pb.GetUserRequest
+	(*UpdateUserRequest)(nil),               // 12: pb.UpdateUserRequest
+	(*VerifyEmailRequest)(nil),              // 13: pb.VerifyEmailRequest
+	(*RequestPasswordResetRequest)(nil),     // 14: pb.RequestPasswordResetRequest
+	(*ResetPasswordRequest)(nil),            // 15: pb.ResetPasswordRequest
+	(*CreateAccountRequest)(nil),            // 16: pb.CreateAccountRequest
+	(*GetAccountRequest)(nil),               // 17: pb.GetAccountRequest
+	(*ListAccountsRequest)(nil),             // 18: pb.ListAccountsRequest
+	(*UpdateAccountRequest)(nil),            // 19: pb.UpdateAccountRequest
+	(*AdjustAccountBalanceRequest)(nil),     // 20: pb.AdjustAccountBalanceRequest
+	(*ListAccountEntriesRequest)(nil),       // 21: pb.ListAccountEntriesRequest
+	(*CreateTransferRequest)(nil),           // 22: pb.CreateTransferRequest
+	(*ReverseTransferRequest)(nil),          // 23: pb.ReverseTransferRequest
+	(*CreateScheduledTransferRequest)(nil),  // 24: pb.CreateScheduledTransferRequest
+	(*GetScheduledTransferRequest)(nil),     // 25: pb.GetScheduledTransferRequest
+	(*ListScheduledTransfersRequest)(nil),   // 26: pb.ListScheduledTransfersRequest
+	(*UpdateScheduledTransferRequest)(nil),  // 27: pb.UpdateScheduledTransferRequest
+	(*DeleteScheduledTransferRequest)(nil),  // 28: pb.DeleteScheduledTransferRequest
+	(*CreateUserResponse)(nil),              // 29: pb.CreateUserResponse
+	(*LoginUserResponse)(nil),               // 30: pb.LoginUserResponse
+	(*RenewAccessTokenResponse)(nil),        // 31: pb.RenewAccessTokenResponse
+	(*LogoutResponse)(nil),                  // 32: pb.LogoutResponse
+	(*LogoutAllResponse)(nil),               // 33: pb.LogoutAllResponse
+	(*ListSessionsResponse)(nil),            // 34: pb.ListSessionsResponse
+	(*RevokeSessionResponse)(nil),           // 35: pb.RevokeSessionResponse
+	(*SetupMFAResponse)(nil),                // 36: pb.SetupMFAResponse
+	(*EnableMFAResponse)(nil),               // 37: pb.EnableMFAResponse
+	(*DisableMFAResponse)(nil),              // 38: pb.DisableMFAResponse
+	(*GetUserResponse)(nil),                 // 39: pb.GetUserResponse
+	(*UpdateUserResponse)(nil),              // 40: pb.UpdateUserResponse
+	(*VerifyEmailResponse)(nil),             // 41: pb.VerifyEmailResponse
+	(*RequestPasswordResetResponse)(nil),    // 42: pb.RequestPasswordResetResponse
+	(*ResetPasswordResponse)(nil),           // 43: pb.ResetPasswordResponse
+	(*CreateAccountResponse)(nil),           // 44: pb.CreateAccountResponse
+	(*GetAccountResponse)(nil),              // 45: pb.GetAccountResponse
+	(*ListAccountsResponse)(nil),            // 46: pb.ListAccountsResponse
+	(*UpdateAccountResponse)(nil),           // 47: pb.UpdateAccountResponse
+	(*AdjustAccountBalanceResponse)(nil),    // 48: pb.AdjustAccountBalanceResponse
+	(*ListAccountEntriesResponse)(nil),      // 49: pb.ListAccountEntriesResponse
+	(*CreateTransferResponse)(nil),          // 50: pb.CreateTransferResponse
+	(*ReverseTransferResponse)(nil),         // 51: pb.ReverseTransferResponse
+	(*CreateScheduledTransferResponse)(nil), // 52: pb.CreateScheduledTransferResponse
+	(*GetScheduledTransferResponse)(nil),    // 53: pb.GetScheduledTransferResponse
+	(*ListScheduledTransfersResponse)(nil),  // 54: pb.ListScheduledTransfersResponse
+	(*UpdateScheduledTransferResponse)(nil), // 55: pb.UpdateScheduledTransferResponse
+	(*DeleteScheduledTransferResponse)(nil), // 56: pb.DeleteScheduledTransferResponse
 }
 var file_service_simple_bank_proto_depIdxs = []int32{
-	0, // 0: pb.SimpleBank.CreateUser:input_type -> pb.CreateUserRequest
-	1, // 1: pb.SimpleBank.LoginUser:input_type -> pb.LoginUserRequest
-	2, // 2: pb.SimpleBank.UpdateUser:input_type -> pb.UpdateUserRequest
-	3, // 3: pb.SimpleBank.CreateUser:output_type -> pb.CreateUserResponse
-	4, // 4: pb.SimpleBank.LoginUser:output_type -> pb.LoginUserResponse
-	5, // 5: pb.SimpleBank.UpdateUser:output_type -> pb.UpdateUserResponse
-	3, // [3:6] is the sub-list for method output_type
-	0, // [0:3] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: pb.SimpleBank.CreateUser:input_type -> pb.CreateUserRequest
+	1,  // 1: pb.SimpleBank.LoginUser:input_type -> pb.LoginUserRequest
+	2,  // 2: pb.SimpleBank.VerifyLoginMFA:input_type -> pb.VerifyLoginMFARequest
+	3,  // 3: pb.SimpleBank.RenewAccessToken:input_type -> pb.RenewAccessTokenRequest
+	4,  // 4: pb.SimpleBank.Logout:input_type -> pb.LogoutRequest
+	5,  // 5: pb.SimpleBank.LogoutAll:input_type -> pb.LogoutAllRequest
+	6,  // 6: pb.SimpleBank.ListSessions:input_type -> pb.ListSessionsRequest
+	7,  // 7: pb.SimpleBank.RevokeSession:input_type -> pb.RevokeSessionRequest
+	8,  // 8: pb.SimpleBank.SetupMFA:input_type -> pb.SetupMFARequest
+	9,  // 9: pb.SimpleBank.EnableMFA:input_type -> pb.EnableMFARequest
+	10, // 10: pb.SimpleBank.DisableMFA:input_type -> pb.DisableMFARequest
+	11, // 11: pb.SimpleBank.GetUser:input_type -> pb.GetUserRequest
+	12, // 12: pb.SimpleBank.UpdateUser:input_type -> pb.UpdateUserRequest
+	13, // 13: pb.SimpleBank.VerifyEmail:input_type -> pb.VerifyEmailRequest
+	14, // 14: pb.SimpleBank.RequestPasswordReset:input_type -> pb.RequestPasswordResetRequest
+	15, // 15: pb.SimpleBank.ResetPassword:input_type -> pb.ResetPasswordRequest
+	16, // 16: pb.SimpleBank.CreateAccount:input_type -> pb.CreateAccountRequest
+	17, // 17: pb.SimpleBank.GetAccount:input_type -> pb.GetAccountRequest
+	18, // 18: pb.SimpleBank.ListAccounts:input_type -> pb.ListAccountsRequest
+	19, // 19: pb.SimpleBank.UpdateAccount:input_type -> pb.UpdateAccountRequest
+	20, // 20: pb.SimpleBank.AdjustAccountBalance:input_type -> pb.AdjustAccountBalanceRequest
+	21, // 21: pb.SimpleBank.ListAccountEntries:input_type -> pb.ListAccountEntriesRequest
+	22, // 22: pb.SimpleBank.CreateTransfer:input_type -> pb.CreateTransferRequest
+	23, // 23: pb.SimpleBank.ReverseTransfer:input_type -> pb.ReverseTransferRequest
+	24, // 24: pb.SimpleBank.CreateScheduledTransfer:input_type -> pb.CreateScheduledTransferRequest
+	25, // 25: pb.SimpleBank.GetScheduledTransfer:input_type -> pb.GetScheduledTransferRequest
+	26, // 26: pb.SimpleBank.ListScheduledTransfers:input_type -> pb.ListScheduledTransfersRequest
+	27, // 27: pb.SimpleBank.UpdateScheduledTransfer:input_type -> pb.UpdateScheduledTransferRequest
+	28, // 28: pb.SimpleBank.DeleteScheduledTransfer:input_type -> pb.DeleteScheduledTransferRequest
+	29, // 29: pb.SimpleBank.CreateUser:output_type -> pb.CreateUserResponse
+	30, // 30: pb.SimpleBank.LoginUser:output_type -> pb.LoginUserResponse
+	30, // 31: pb.SimpleBank.VerifyLoginMFA:output_type -> pb.LoginUserResponse
+	31, // 32: pb.SimpleBank.RenewAccessToken:output_type -> pb.RenewAccessTokenResponse
+	32, // 33: pb.SimpleBank.Logout:output_type -> pb.LogoutResponse
+	33, // 34: pb.SimpleBank.LogoutAll:output_type -> pb.LogoutAllResponse
+	34, // 35: pb.SimpleBank.ListSessions:output_type -> pb.ListSessionsResponse
+	35, // 36: pb.SimpleBank.RevokeSession:output_type -> pb.RevokeSessionResponse
+	36, // 37: pb.SimpleBank.SetupMFA:output_type -> pb.SetupMFAResponse
+	37, // 38: pb.SimpleBank.EnableMFA:output_type -> pb.EnableMFAResponse
+	38, // 39: pb.SimpleBank.DisableMFA:output_type -> pb.DisableMFAResponse
+	39, // 40: pb.SimpleBank.GetUser:output_type -> pb.GetUserResponse
+	40, // 41: pb.SimpleBank.UpdateUser:output_type -> pb.UpdateUserResponse
+	41, // 42: pb.SimpleBank.VerifyEmail:output_type -> pb.VerifyEmailResponse
+	42, // 43: pb.SimpleBank.RequestPasswordReset:output_type -> pb.RequestPasswordResetResponse
+	43, // 44: pb.SimpleBank.ResetPassword:output_type -> pb.ResetPasswordResponse
+	44, // 45: pb.SimpleBank.CreateAccount:output_type -> pb.CreateAccountResponse
+	45, // 46: pb.SimpleBank.GetAccount:output_type -> pb.GetAccountResponse
+	46, // 47: pb.SimpleBank.ListAccounts:output_type -> pb.ListAccountsResponse
+	47, // 48: pb.SimpleBank.UpdateAccount:output_type -> pb.UpdateAccountResponse
+	48, // 49: pb.SimpleBank.AdjustAccountBalance:output_type -> pb.AdjustAccountBalanceResponse
+	49, // 50: pb.SimpleBank.ListAccountEntries:output_type -> pb.ListAccountEntriesResponse
+	50, // 51: pb.SimpleBank.CreateTransfer:output_type -> pb.CreateTransferResponse
+	51, // 52: pb.SimpleBank.ReverseTransfer:output_type -> pb.ReverseTransferResponse
+	52, // 53: pb.SimpleBank.CreateScheduledTransfer:output_type -> pb.CreateScheduledTransferResponse
+	53, // 54: pb.SimpleBank.GetScheduledTransfer:output_type -> pb.GetScheduledTransferResponse
+	54, // 55: pb.SimpleBank.ListScheduledTransfers:output_type -> pb.ListScheduledTransfersResponse
+	55, // 56: pb.SimpleBank.UpdateScheduledTransfer:output_type -> pb.UpdateScheduledTransferResponse
+	56, // 57: pb.SimpleBank.DeleteScheduledTransfer:output_type -> pb.DeleteScheduledTransferResponse
+	29, // [29:58] is the sub-list for method output_type
+	0,  // [0:29] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
 }
 
 func init() { file_service_simple_bank_proto_init() }
@@ -68,7 +200,33 @@ func file_service_simple_bank_proto_init() {
 	}
 	file_rpc_create_user_proto_init()
 	file_rpc_login_user_proto_init()
+	file_rpc_verify_login_mfa_proto_init()
+	file_rpc_renew_access_token_proto_init()
+	file_rpc_logout_proto_init()
+	file_rpc_logout_all_proto_init()
+	file_rpc_list_sessions_proto_init()
+	file_rpc_revoke_session_proto_init()
+	file_rpc_setup_mfa_proto_init()
+	file_rpc_enable_mfa_proto_init()
+	file_rpc_disable_mfa_proto_init()
+	file_rpc_get_user_proto_init()
 	file_rpc_update_user_proto_init()
+	file_rpc_verify_email_proto_init()
+	file_rpc_request_password_reset_proto_init()
+	file_rpc_reset_password_proto_init()
+	file_rpc_create_account_proto_init()
+	file_rpc_get_account_proto_init()
+	file_rpc_list_accounts_proto_init()
+	file_rpc_update_account_proto_init()
+	file_rpc_adjust_account_balance_proto_init()
+	file_rpc_list_account_entries_proto_init()
+	file_rpc_create_transfer_proto_init()
+	file_rpc_reverse_transfer_proto_init()
+	file_rpc_create_scheduled_transfer_proto_init()
+	file_rpc_get_scheduled_transfer_proto_init()
+	file_rpc_list_scheduled_transfers_proto_init()
+	file_rpc_update_scheduled_transfer_proto_init()
+	file_rpc_delete_scheduled_transfer_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
@@ -0,0 +1,181 @@
+// Package reconcile checks the accounts against the ledger and reports the mismatches.
+//
+// Accounts are scanned in batches from the store, so reconciling a large bank
+// doesn't hold a long transaction or load every account into memory.
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+)
+
+// DefaultBatchSize is the number of accounts checked at a time when Params.BatchSize is zero
+const DefaultBatchSize = 500
+
+// Params contains the input parameters of a reconciliation
+type Params struct {
+	// AfterAccountID starts the scan after this account, 0 scans every account
+	AfterAccountID int64
+	BatchSize      int32
+	// Freeze freezes the accounts of every mismatch found
+	Freeze bool
+}
+
+// AccountMismatch is an account whose balance isn't the sum of its entries
+type AccountMismatch struct {
+	AccountID      int64  `json:"account_id"`
+	Owner          string `json:"owner"`
+	Currency       string `json:"currency"`
+	Balance        int64  `json:"balance"`
+	EntriesBalance int64  `json:"entries_balance"`
+	// Difference is how much the balance exceeds the sum of the entries
+	Difference int64  `json:"difference"`
+	EntryCount int64  `json:"entry_count"`
+	Details    string `json:"details"`
+}
+
+// TransferMismatch is a transfer without exactly one matching entry on each side
+type TransferMismatch struct {
+	TransferID     int64  `json:"transfer_id"`
+	FromAccountID  int64  `json:"from_account_id"`
+	ToAccountID    int64  `json:"to_account_id"`
+	Amount         int64  `json:"amount"`
+	ToAmount       int64  `json:"to_amount"`
+	FromEntryCount int64  `json:"from_entry_count"`
+	ToEntryCount   int64  `json:"to_entry_count"`
+	Details        string `json:"details"`
+}
+
+// Report is the machine-readable result of a reconciliation
+type Report struct {
+	StartedAt          time.Time          `json:"started_at"`
+	FinishedAt         time.Time          `json:"finished_at"`
+	AccountsScanned    int64              `json:"accounts_scanned"`
+	AccountMismatches  []AccountMismatch  `json:"account_mismatches"`
+	TransferMismatches []TransferMismatch `json:"transfer_mismatches"`
+	FrozenAccountIDs   []int64            `json:"frozen_account_ids"`
+}
+
+// HasMismatches reports whether the reconciliation found anything wrong
+func (report Report) HasMismatches() bool {
+	return len(report.AccountMismatches) > 0 || len(report.TransferMismatches) > 0
+}
+
+// FileName returns the name of the report file, after the time the reconciliation started
+func (report Report) FileName() string {
+	return "reconciliation-" + report.StartedAt.UTC().Format("20060102T150405Z") + ".json"
+}
+
+// WriteJSON writes the report to w as indented JSON
+func (report Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// WriteFile writes the report to a new file in dir and returns its path
+func (report Report) WriteFile(dir string) (string, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, report.FileName())
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+
+	err = report.WriteJSON(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return path, err
+}
+
+// Run reconciles every account after params.AfterAccountID, one batch at a time.
+// If a batch fails, the report of the batches checked so far is returned with the error
+func Run(ctx context.Context, store db.Store, params Params) (Report, error) {
+	batchSize := params.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	report := Report{
+		StartedAt:          time.Now(),
+		AccountMismatches:  []AccountMismatch{},
+		TransferMismatches: []TransferMismatch{},
+		FrozenAccountIDs:   []int64{},
+	}
+
+	arg := db.ReconcileBatchTxParams{
+		AfterAccountID: params.AfterAccountID,
+		BatchSize:      batchSize,
+		Freeze:         params.Freeze,
+	}
+	for {
+		result, err := store.ReconcileBatchTx(ctx, arg)
+		if err != nil {
+			report.FinishedAt = time.Now()
+			return report, fmt.Errorf("failed to reconcile accounts after [%d]: %w", arg.AfterAccountID, err)
+		}
+
+		report.add(result)
+		if result.NextAfterID == 0 {
+			break
+		}
+		arg.AfterAccountID = result.NextAfterID
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+func (report *Report) add(result db.ReconcileBatchTxResult) {
+	report.AccountsScanned += int64(result.AccountsScanned)
+
+	for _, account := range result.Accounts {
+		difference := account.Balance - account.EntriesBalance
+		report.AccountMismatches = append(report.AccountMismatches, AccountMismatch{
+			AccountID:      account.ID,
+			Owner:          account.Owner,
+			Currency:       account.Currency,
+			Balance:        account.Balance,
+			EntriesBalance: account.EntriesBalance,
+			Difference:     difference,
+			EntryCount:     account.EntryCount,
+			Details: fmt.Sprintf("balance %d %s doesn't match the sum %d of %d entries",
+				account.Balance, account.Currency, account.EntriesBalance, account.EntryCount),
+		})
+	}
+
+	for _, transfer := range result.Transfers {
+		report.TransferMismatches = append(report.TransferMismatches, TransferMismatch{
+			TransferID:     transfer.ID,
+			FromAccountID:  transfer.FromAccountID,
+			ToAccountID:    transfer.ToAccountID,
+			Amount:         transfer.Amount,
+			ToAmount:       transfer.ToAmount,
+			FromEntryCount: transfer.FromEntryCount,
+			ToEntryCount:   transfer.ToEntryCount,
+			Details: fmt.Sprintf("expected 1 debit of %d from account [%d] and 1 credit of %d to account [%d], found %d and %d",
+				transfer.Amount, transfer.FromAccountID, transfer.ToAmount, transfer.ToAccountID,
+				transfer.FromEntryCount, transfer.ToEntryCount),
+		})
+	}
+
+	// an account can be frozen again by a later batch, e.g. as the destination of a broken transfer
+	for _, accountID := range result.FrozenAccountIDs {
+		if !slices.Contains(report.FrozenAccountIDs, accountID) {
+			report.FrozenAccountIDs = append(report.FrozenAccountIDs, accountID)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	drifted := db.ReconcileAccountBalancesRow{
+		ID:             3,
+		Owner:          util.RandomOwner(),
+		Currency:       util.USD,
+		Balance:        100,
+		EntriesBalance: 40,
+		EntryCount:     2,
+	}
+	broken := db.ReconcileTransferEntriesRow{
+		ID:             7,
+		FromAccountID:  4,
+		ToAccountID:    9,
+		Amount:         10,
+		ToAmount:       10,
+		FromEntryCount: 1,
+	}
+
+	gomock.InOrder(
+		store.EXPECT().
+			ReconcileBatchTx(gomock.Any(), gomock.Eq(db.ReconcileBatchTxParams{BatchSize: 5, Freeze: true})).
+			Times(1).
+			Return(db.ReconcileBatchTxResult{
+				AccountsScanned:  5,
+				Accounts:         []db.ReconcileAccountBalancesRow{drifted},
+				Transfers:        []db.ReconcileTransferEntriesRow{broken},
+				FrozenAccountIDs: []int64{3, 4, 9},
+				NextAfterID:      5,
+			}, nil),
+		store.EXPECT().
+			ReconcileBatchTx(gomock.Any(), gomock.Eq(db.ReconcileBatchTxParams{AfterAccountID: 5, BatchSize: 5, Freeze: true})).
+			Times(1).
+			Return(db.ReconcileBatchTxResult{
+				AccountsScanned: 5,
+				Accounts: []db.ReconcileAccountBalancesRow{
+					{ID: 9, Currency: util.USD, Balance: 10},
+				},
+				FrozenAccountIDs: []int64{9},
+				NextAfterID:      10,
+			}, nil),
+		store.EXPECT().
+			ReconcileBatchTx(gomock.Any(), gomock.Eq(db.ReconcileBatchTxParams{AfterAccountID: 10, BatchSize: 5, Freeze: true})).
+			Times(1).
+			Return(db.ReconcileBatchTxResult{AccountsScanned: 2}, nil),
+	)
+
+	report, err := Run(context.Background(), store, Params{BatchSize: 5, Freeze: true})
+	require.NoError(t, err)
+	require.True(t, report.HasMismatches())
+	require.Equal(t, int64(12), report.AccountsScanned)
+	require.False(t, report.FinishedAt.Before(report.StartedAt))
+
+	require.Len(t, report.AccountMismatches, 2)
+	require.Equal(t, AccountMismatch{
+		AccountID:      drifted.ID,
+		Owner:          drifted.Owner,
+		Currency:       util.USD,
+		Balance:        100,
+		EntriesBalance: 40,
+		Difference:     60,
+		EntryCount:     2,
+		Details:        "balance 100 USD doesn't match the sum 40 of 2 entries",
+	}, report.AccountMismatches[0])
+	require.Equal(t, int64(9), report.AccountMismatches[1].AccountID)
+
+	require.Equal(t, []TransferMismatch{{
+		TransferID:     7,
+		FromAccountID:  4,
+		ToAccountID:    9,
+		Amount:         10,
+		ToAmount:       10,
+		FromEntryCount: 1,
+		Details:        "expected 1 debit of 10 from account [4] and 1 credit of 10 to account [9], found 1 and 0",
+	}}, report.TransferMismatches)
+
+	require.Equal(t, []int64{3, 4, 9}, report.FrozenAccountIDs)
+}
+
+func TestRunNoMismatches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		ReconcileBatchTx(gomock.Any(), gomock.Eq(db.ReconcileBatchTxParams{AfterAccountID: 42, BatchSize: DefaultBatchSize})).
+		Times(1).
+		Return(db.ReconcileBatchTxResult{AccountsScanned: 3}, nil)
+
+	report, err := Run(context.Background(), store, Params{AfterAccountID: 42})
+	require.NoError(t, err)
+	require.False(t, report.HasMismatches())
+	require.Equal(t, int64(3), report.AccountsScanned)
+
+	// the report always lists its mismatches, even when there are none
+	var buffer bytes.Buffer
+	err = report.WriteJSON(&buffer)
+	require.NoError(t, err)
+	require.Contains(t, buffer.String(), `"account_mismatches": []`)
+	require.Contains(t, buffer.String(), `"transfer_mismatches": []`)
+}
+
+func TestRunBatchError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	gomock.InOrder(
+		store.EXPECT().
+			ReconcileBatchTx(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(db.ReconcileBatchTxResult{
+				AccountsScanned: 1,
+				Accounts:        []db.ReconcileAccountBalancesRow{{ID: 1, Balance: 5}},
+				NextAfterID:     1,
+			}, nil),
+		store.EXPECT().
+			ReconcileBatchTx(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(db.ReconcileBatchTxResult{}, errors.New("connection lost")),
+	)
+
+	report, err := Run(context.Background(), store, Params{BatchSize: 1})
+	require.EqualError(t, err, "failed to reconcile accounts after [1]: connection lost")
+	require.Len(t, report.AccountMismatches, 1)
+}
+
+func TestWriteFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		ReconcileBatchTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.ReconcileBatchTxResult{
+			AccountsScanned: 1,
+			Accounts:        []db.ReconcileAccountBalancesRow{{ID: 1, Currency: util.EUR, Balance: 5}},
+		}, nil)
+
+	report, err := Run(context.Background(), store, Params{})
+	require.NoError(t, err)
+
+	dir := filepath.Join(t.TempDir(), "reports")
+	path, err := report.WriteFile(dir)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, report.FileName()), path)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var written Report
+	err = json.Unmarshal(data, &written)
+	require.NoError(t, err)
+	require.Equal(t, report.AccountMismatches, written.AccountMismatches)
+	require.Equal(t, report.AccountsScanned, written.AccountsScanned)
+	require.True(t, report.StartedAt.Equal(written.StartedAt))
+}
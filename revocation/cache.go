@@ -0,0 +1,151 @@
+package revocation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hykura1501/simple_bank/token"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// Checker rejects tokens that were revoked after they were issued
+type Checker interface {
+	Check(ctx context.Context, payload *token.Payload) error
+}
+
+type cacheEntry[T any] struct {
+	value     T
+	expiredAt time.Time
+}
+
+// Cache is an in-process Checker that keeps what it loads from its Store for ttl,
+// so a revocation made by another process is enforced within ttl
+type Cache struct {
+	store Store
+	ttl   time.Duration
+	now   func() time.Time
+
+	mu        sync.Mutex
+	sessions  map[pgtype.UUID]cacheEntry[bool]
+	users     map[string]cacheEntry[time.Time]
+	nextSweep time.Time
+}
+
+func NewCache(store Store, ttl time.Duration) *Cache {
+	return &Cache{
+		store:    store,
+		ttl:      ttl,
+		now:      time.Now,
+		sessions: make(map[pgtype.UUID]cacheEntry[bool]),
+		users:    make(map[string]cacheEntry[time.Time]),
+	}
+}
+
+// Check returns ErrTokenRevoked if the token's session was blocked
+// or the token was issued before its user changed their password
+func (cache *Cache) Check(ctx context.Context, payload *token.Payload) error {
+	if payload.SessionID.Valid {
+		revoked, err := cache.isSessionRevoked(ctx, payload.SessionID)
+		if err != nil {
+			return fmt.Errorf("cannot check session: %w", err)
+		}
+		if revoked {
+			return ErrTokenRevoked
+		}
+	}
+
+	revokedBefore, err := cache.revokedBefore(ctx, payload.Username)
+	if err != nil {
+		return fmt.Errorf("cannot check user: %w", err)
+	}
+	if payload.IssuedAt.Before(revokedBefore) {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// RevokeSession rejects the tokens of the session in this process right away,
+// without waiting for the cached state to expire
+func (cache *Cache) RevokeSession(sessionID pgtype.UUID) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.sessions[sessionID] = cacheEntry[bool]{value: true, expiredAt: cache.now().Add(cache.ttl)}
+}
+
+// InvalidateUser drops the cached state of the user, so the next check reloads it
+func (cache *Cache) InvalidateUser(username string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	delete(cache.users, username)
+}
+
+func (cache *Cache) isSessionRevoked(ctx context.Context, sessionID pgtype.UUID) (bool, error) {
+	cache.mu.Lock()
+	entry, ok := cache.sessions[sessionID]
+	cache.mu.Unlock()
+	if ok && cache.now().Before(entry.expiredAt) {
+		return entry.value, nil
+	}
+
+	revoked, err := cache.store.IsSessionRevoked(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	// keep a revocation made while the store was being read
+	if current, ok := cache.sessions[sessionID]; ok && current.value {
+		return true, nil
+	}
+	cache.sessions[sessionID] = cacheEntry[bool]{value: revoked, expiredAt: cache.now().Add(cache.ttl)}
+	cache.sweep()
+	return revoked, nil
+}
+
+func (cache *Cache) revokedBefore(ctx context.Context, username string) (time.Time, error) {
+	cache.mu.Lock()
+	entry, ok := cache.users[username]
+	cache.mu.Unlock()
+	if ok && cache.now().Before(entry.expiredAt) {
+		return entry.value, nil
+	}
+
+	revokedBefore, err := cache.store.RevokedBefore(ctx, username)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.users[username] = cacheEntry[time.Time]{value: revokedBefore, expiredAt: cache.now().Add(cache.ttl)}
+	cache.sweep()
+	return revokedBefore, nil
+}
+
+// sweep removes the expired entries at most once per ttl. The caller must hold mu
+func (cache *Cache) sweep() {
+	now := cache.now()
+	if now.Before(cache.nextSweep) {
+		return
+	}
+	cache.nextSweep = now.Add(cache.ttl)
+
+	for id, entry := range cache.sessions {
+		if !now.Before(entry.expiredAt) {
+			delete(cache.sessions, id)
+		}
+	}
+	for username, entry := range cache.users {
+		if !now.Before(entry.expiredAt) {
+			delete(cache.users, username)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package revocation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hykura1501/simple_bank/token"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	revokedSessions map[pgtype.UUID]bool
+	revokedBefore   map[string]time.Time
+	err             error
+	calls           int
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		revokedSessions: make(map[pgtype.UUID]bool),
+		revokedBefore:   make(map[string]time.Time),
+	}
+}
+
+func (store *fakeStore) IsSessionRevoked(ctx context.Context, sessionID pgtype.UUID) (bool, error) {
+	store.calls++
+	return store.revokedSessions[sessionID], store.err
+}
+
+func (store *fakeStore) RevokedBefore(ctx context.Context, username string) (time.Time, error) {
+	store.calls++
+	return store.revokedBefore[username], store.err
+}
+
+func newTestCache(store Store, ttl time.Duration) (*Cache, *time.Time) {
+	now := time.Now()
+	cache := NewCache(store, ttl)
+	cache.now = func() time.Time { return now }
+	return cache, &now
+}
+
+func randomPayload(t *testing.T) *token.Payload {
+	sessionID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	payload, err := token.NewPayload(util.RandomOwner(), util.DepositorRole, sessionID, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	return payload
+}
+
+func TestCacheCheck(t *testing.T) {
+	store := newFakeStore()
+	cache, now := newTestCache(store, time.Second)
+	payload := randomPayload(t)
+
+	require.NoError(t, cache.Check(context.Background(), payload))
+
+	// the session of the first token is revoked, and every token of the second user issued before now
+	store.revokedSessions[payload.SessionID] = true
+	payload2 := randomPayload(t)
+	store.revokedBefore[payload2.Username] = payload2.IssuedAt.Add(time.Second)
+
+	require.ErrorIs(t, cache.Check(context.Background(), payload2), ErrTokenRevoked)
+
+	// the first token is accepted from the cache until the TTL, then rejected
+	require.NoError(t, cache.Check(context.Background(), payload))
+	*now = now.Add(time.Second)
+	require.ErrorIs(t, cache.Check(context.Background(), payload), ErrTokenRevoked)
+	require.ErrorIs(t, cache.Check(context.Background(), payload2), ErrTokenRevoked)
+}
+
+func TestCacheExpiry(t *testing.T) {
+	store := newFakeStore()
+	cache, now := newTestCache(store, 5*time.Second)
+	payload := randomPayload(t)
+
+	require.NoError(t, cache.Check(context.Background(), payload))
+	require.Equal(t, 2, store.calls)
+
+	// the revocation isn't seen until the cached state expires
+	store.revokedSessions[payload.SessionID] = true
+	require.NoError(t, cache.Check(context.Background(), payload))
+	require.Equal(t, 2, store.calls)
+
+	*now = now.Add(5 * time.Second)
+	require.ErrorIs(t, cache.Check(context.Background(), payload), ErrTokenRevoked)
+	require.Equal(t, 3, store.calls)
+}
+
+func TestCacheRevokeSession(t *testing.T) {
+	store := newFakeStore()
+	cache, _ := newTestCache(store, time.Minute)
+	payload := randomPayload(t)
+
+	require.NoError(t, cache.Check(context.Background(), payload))
+
+	cache.RevokeSession(payload.SessionID)
+	require.ErrorIs(t, cache.Check(context.Background(), payload), ErrTokenRevoked)
+}
+
+func TestCacheInvalidateUser(t *testing.T) {
+	store := newFakeStore()
+	cache, _ := newTestCache(store, time.Minute)
+	payload := randomPayload(t)
+
+	require.NoError(t, cache.Check(context.Background(), payload))
+
+	store.revokedBefore[payload.Username] = payload.IssuedAt.Add(time.Second)
+	require.NoError(t, cache.Check(context.Background(), payload))
+
+	cache.InvalidateUser(payload.Username)
+	require.ErrorIs(t, cache.Check(context.Background(), payload), ErrTokenRevoked)
+}
+
+func TestCacheWithoutSession(t *testing.T) {
+	store := newFakeStore()
+	cache, _ := newTestCache(store, time.Minute)
+
+	payload := randomPayload(t)
+	payload.SessionID = pgtype.UUID{}
+
+	require.NoError(t, cache.Check(context.Background(), payload))
+	require.Equal(t, 1, store.calls)
+}
+
+func TestCacheStoreError(t *testing.T) {
+	store := newFakeStore()
+	store.err = errors.New("connection refused")
+	cache, _ := newTestCache(store, time.Minute)
+
+	err := cache.Check(context.Background(), randomPayload(t))
+	require.ErrorIs(t, err, store.err)
+	require.NotErrorIs(t, err, ErrTokenRevoked)
+}
+
+func TestCacheSweep(t *testing.T) {
+	store := newFakeStore()
+	cache, now := newTestCache(store, time.Second)
+
+	require.NoError(t, cache.Check(context.Background(), randomPayload(t)))
+	require.Len(t, cache.sessions, 1)
+	require.Len(t, cache.users, 1)
+
+	*now = now.Add(time.Second)
+	require.NoError(t, cache.Check(context.Background(), randomPayload(t)))
+	require.Len(t, cache.sessions, 1)
+	require.Len(t, cache.users, 1)
+}
@@ -0,0 +1,41 @@
+package revocation
+
+import (
+	"context"
+	"time"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Store loads the revocation state that the cache keeps for a short time
+type Store interface {
+	// IsSessionRevoked reports whether the login session was blocked
+	IsSessionRevoked(ctx context.Context, sessionID pgtype.UUID) (bool, error)
+	// RevokedBefore returns the time before which every token of the user is revoked
+	RevokedBefore(ctx context.Context, username string) (time.Time, error)
+}
+
+// DBStore reads the revocation state from the sessions and users tables
+type DBStore struct {
+	querier db.Querier
+}
+
+func NewDBStore(querier db.Querier) *DBStore {
+	return &DBStore{
+		querier: querier,
+	}
+}
+
+func (store *DBStore) IsSessionRevoked(ctx context.Context, sessionID pgtype.UUID) (bool, error) {
+	return store.querier.IsSessionFamilyBlocked(ctx, sessionID)
+}
+
+// RevokedBefore returns when the user's password was last changed
+func (store *DBStore) RevokedBefore(ctx context.Context, username string) (time.Time, error) {
+	user, err := store.querier.GetUser(ctx, username)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return user.PasswordChangedAt.Time, nil
+}
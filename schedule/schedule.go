@@ -0,0 +1,99 @@
+// Package schedule works out when the occurrences of a recurring transfer are due.
+//
+// A schedule is a standard 5-field cron expression, e.g. "0 9 1 * *" for 09:00 on the 1st of every month,
+// or one of the descriptors @yearly, @monthly, @weekly, @daily and @hourly.
+// Occurrences are computed in UTC, unless the expression starts with a location,
+// e.g. "CRON_TZ=Europe/Paris 0 9 1 * *".
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	// the runtime image has no time zone database, CRON_TZ locations are loaded from the embedded copy
+	_ "time/tzdata"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ErrInvalidSchedule is returned by Parse when the expression isn't a supported cron expression
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+var parser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	cron cron.Schedule
+}
+
+// Parse parses a cron expression.
+// Fixed intervals such as "@every 24h" are rejected, an occurrence must fall on a calendar time
+func Parse(expression string) (Schedule, error) {
+	spec := strings.TrimSpace(expression)
+	if strings.Contains(spec, "@every") {
+		return Schedule{}, fmt.Errorf("%w: fixed intervals are not supported, use a cron expression", ErrInvalidSchedule)
+	}
+
+	schedule, err := parser.Parse(spec)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("%w: %s", ErrInvalidSchedule, err)
+	}
+	return Schedule{cron: schedule}, nil
+}
+
+// Next returns the first occurrence strictly after t, in UTC.
+// It returns the zero time if the schedule has no occurrence in the next five years, e.g. on February 30th
+func (schedule Schedule) Next(t time.Time) time.Time {
+	next := schedule.cron.Next(t.UTC())
+	if next.IsZero() {
+		return next
+	}
+	return next.UTC()
+}
+
+// atOrAfter returns the first occurrence at or after t
+func (schedule Schedule) atOrAfter(t time.Time) time.Time {
+	return schedule.Next(t.Add(-time.Nanosecond))
+}
+
+// Recurrence is a schedule with the limits of a recurring transfer
+type Recurrence struct {
+	Schedule Schedule
+	// EndAt is the last time an occurrence can be due, the zero time means the recurrence never ends
+	EndAt time.Time
+	// MaxOccurrences is the number of runs after which the recurrence ends, 0 means no limit
+	MaxOccurrences int32
+}
+
+// From returns the first occurrence at or after t, once occurrences runs have been made,
+// e.g. the first run of a new recurrence or the next run of a resumed one.
+// It returns false if the recurrence ends before that occurrence
+func (recurrence Recurrence) From(t time.Time, occurrences int32) (time.Time, bool) {
+	return recurrence.bounded(recurrence.Schedule.atOrAfter(t), occurrences)
+}
+
+// Next returns the occurrence that follows the run due at scheduledAt, once occurrences runs have been made.
+// Occurrences that fell before now, e.g. while the worker was down, are skipped
+// rather than run late one after another.
+// It returns false when the recurrence has ended
+func (recurrence Recurrence) Next(scheduledAt time.Time, now time.Time, occurrences int32) (time.Time, bool) {
+	next := recurrence.Schedule.Next(scheduledAt)
+	if !next.IsZero() && next.Before(now) {
+		next = recurrence.Schedule.atOrAfter(now)
+	}
+	return recurrence.bounded(next, occurrences)
+}
+
+func (recurrence Recurrence) bounded(next time.Time, occurrences int32) (time.Time, bool) {
+	if recurrence.MaxOccurrences > 0 && occurrences >= recurrence.MaxOccurrences {
+		return time.Time{}, false
+	}
+	if next.IsZero() {
+		return next, false
+	}
+	if !recurrence.EndAt.IsZero() && next.After(recurrence.EndAt) {
+		return time.Time{}, false
+	}
+	return next, true
+}
@@ -0,0 +1,96 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day, hour, min int) time.Time {
+	return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		expression string
+		after      time.Time
+		next       time.Time
+	}{
+		{"0 9 1 * *", date(2026, 1, 15, 12, 0), date(2026, 2, 1, 9, 0)},
+		{"0 9 1 * *", date(2026, 2, 1, 9, 0), date(2026, 3, 1, 9, 0)},
+		{"@monthly", date(2026, 1, 15, 12, 0), date(2026, 2, 1, 0, 0)},
+		{"30 8 * * 1", date(2026, 10, 18, 0, 0), date(2026, 10, 19, 8, 30)},
+		{"CRON_TZ=Asia/Ho_Chi_Minh 0 9 * * *", date(2026, 10, 18, 0, 0), date(2026, 10, 18, 2, 0)},
+	}
+
+	for _, tc := range testCases {
+		schedule, err := Parse(tc.expression)
+		require.NoError(t, err, tc.expression)
+
+		next := schedule.Next(tc.after)
+		require.Equal(t, tc.next, next, tc.expression)
+		require.Equal(t, time.UTC, next.Location())
+	}
+
+	for _, expression := range []string{"", "0 9 1 *", "61 * * * *", "@every 24h", "@fortnightly", "0 0 0 * * *"} {
+		_, err := Parse(expression)
+		require.ErrorIs(t, err, ErrInvalidSchedule, expression)
+	}
+}
+
+func TestRecurrenceFrom(t *testing.T) {
+	schedule, err := Parse("0 9 * * *")
+	require.NoError(t, err)
+
+	recurrence := Recurrence{Schedule: schedule}
+
+	// the start time itself can be the first occurrence
+	first, ok := recurrence.From(date(2026, 10, 18, 9, 0), 0)
+	require.True(t, ok)
+	require.Equal(t, date(2026, 10, 18, 9, 0), first)
+
+	first, ok = recurrence.From(date(2026, 10, 18, 9, 1), 0)
+	require.True(t, ok)
+	require.Equal(t, date(2026, 10, 19, 9, 0), first)
+
+	recurrence.MaxOccurrences = 2
+	_, ok = recurrence.From(date(2026, 10, 18, 9, 1), 2)
+	require.False(t, ok)
+
+	recurrence.EndAt = date(2026, 10, 19, 8, 59)
+	_, ok = recurrence.From(date(2026, 10, 18, 9, 1), 0)
+	require.False(t, ok)
+
+	never, err := Parse("0 0 30 2 *")
+	require.NoError(t, err)
+	_, ok = Recurrence{Schedule: never}.From(date(2026, 10, 18, 0, 0), 0)
+	require.False(t, ok)
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	schedule, err := Parse("0 9 1 * *")
+	require.NoError(t, err)
+
+	recurrence := Recurrence{
+		Schedule:       schedule,
+		EndAt:          date(2026, 12, 31, 0, 0),
+		MaxOccurrences: 3,
+	}
+
+	now := date(2026, 10, 1, 9, 0)
+	next, ok := recurrence.Next(now, now, 1)
+	require.True(t, ok)
+	require.Equal(t, date(2026, 11, 1, 9, 0), next)
+
+	// the occurrences missed while the worker was down are skipped
+	next, ok = recurrence.Next(date(2026, 8, 1, 9, 0), now.Add(time.Minute), 1)
+	require.True(t, ok)
+	require.Equal(t, date(2026, 11, 1, 9, 0), next)
+
+	_, ok = recurrence.Next(now, now, 3)
+	require.False(t, ok)
+
+	_, ok = recurrence.Next(date(2026, 12, 1, 9, 0), date(2026, 12, 1, 9, 0), 2)
+	require.False(t, ok)
+}
@@ -0,0 +1,76 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+)
+
+var csvHeader = []string{
+	"posted_at",
+	"entry_id",
+	"transfer_id",
+	"counterparty_account_id",
+	"description",
+	"amount",
+	"balance",
+	"currency",
+}
+
+// csvRenderer writes one row per entry, so the statement can be imported as a plain table
+type csvRenderer struct {
+	writer   *csv.Writer
+	currency string
+}
+
+func newCSVRenderer(w io.Writer) *csvRenderer {
+	return &csvRenderer{
+		writer: csv.NewWriter(w),
+	}
+}
+
+func (renderer *csvRenderer) begin(summary Summary) error {
+	renderer.currency = summary.Account.Currency
+	return renderer.writer.Write(csvHeader)
+}
+
+func (renderer *csvRenderer) writeEntry(entry db.ListAccountEntriesRow) error {
+	amount, err := formatAmount(entry.Amount, renderer.currency)
+	if err != nil {
+		return err
+	}
+
+	balance, err := formatAmount(entry.RunningBalance, renderer.currency)
+	if err != nil {
+		return err
+	}
+
+	transferID := ""
+	if entry.TransferID != nil {
+		transferID = strconv.FormatInt(*entry.TransferID, 10)
+	}
+
+	counterparty := ""
+	if id := entry.CounterpartyAccountID(); id != 0 {
+		counterparty = strconv.FormatInt(id, 10)
+	}
+
+	return renderer.writer.Write([]string{
+		entry.CreatedAt.Time.UTC().Format(time.RFC3339),
+		strconv.FormatInt(entry.ID, 10),
+		transferID,
+		counterparty,
+		describeEntry(entry),
+		amount,
+		balance,
+		renderer.currency,
+	})
+}
+
+func (renderer *csvRenderer) end() error {
+	renderer.writer.Flush()
+	return renderer.writer.Error()
+}
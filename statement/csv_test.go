@@ -0,0 +1,59 @@
+package statement
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportCSV(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	account := randomAccount()
+	account.ID = 7
+	otherAccountID := int64(8)
+	transferID := int64(42)
+
+	entries := []db.ListAccountEntriesRow{
+		{
+			ID:             1,
+			AccountID:      account.ID,
+			Amount:         -1250,
+			CreatedAt:      pgtype.Timestamptz{Time: account.CreatedAt.Time, Valid: true},
+			TransferID:     &transferID,
+			FromAccountID:  &account.ID,
+			ToAccountID:    &otherAccountID,
+			RunningBalance: 8750,
+		},
+		{
+			ID:             2,
+			AccountID:      account.ID,
+			Amount:         5,
+			CreatedAt:      pgtype.Timestamptz{Time: account.CreatedAt.Time, Valid: true},
+			RunningBalance: 8755,
+		},
+	}
+
+	store := mockdb.NewMockStore(ctrl)
+	expectPages(t, store, account, 10000, entries)
+
+	var buf bytes.Buffer
+	err := Export(context.Background(), store, Params{Account: account}, FormatCSV, &buf)
+	require.NoError(t, err)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		csvHeader,
+		{"2024-01-01T00:00:00Z", "1", "42", "8", "Transfer to account 8", "-12.50", "87.50", "USD"},
+		{"2024-01-01T00:00:00Z", "2", "", "", "Account entry", "0.05", "87.55", "USD"},
+	}, records)
+}
@@ -0,0 +1,117 @@
+package statement
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+)
+
+const (
+	ofxBankID      = "SIMPLEBANK"
+	ofxAccountType = "CHECKING"
+)
+
+const ofxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+`
+
+// ofxRenderer writes an OFX 2.2 bank statement response
+type ofxRenderer struct {
+	writer  *bufio.Writer
+	summary Summary
+}
+
+func newOFXRenderer(w io.Writer) *ofxRenderer {
+	return &ofxRenderer{
+		writer: bufio.NewWriter(w),
+	}
+}
+
+func (renderer *ofxRenderer) begin(summary Summary) error {
+	renderer.summary = summary
+
+	fromTime := summary.FromTime
+	if fromTime.IsZero() {
+		fromTime = summary.Account.CreatedAt.Time
+	}
+
+	renderer.writer.WriteString(ofxHeader)
+	renderer.writer.WriteString("<OFX>\n")
+	renderer.writer.WriteString("<SIGNONMSGSRSV1><SONRS>\n")
+	renderer.writer.WriteString("<STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>\n")
+	renderer.element("DTSERVER", ofxTime(summary.GeneratedAt))
+	renderer.element("LANGUAGE", "ENG")
+	renderer.writer.WriteString("</SONRS></SIGNONMSGSRSV1>\n")
+	renderer.writer.WriteString("<BANKMSGSRSV1><STMTTRNRS>\n")
+	renderer.element("TRNUID", "0")
+	renderer.writer.WriteString("<STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>\n")
+	renderer.writer.WriteString("<STMTRS>\n")
+	renderer.element("CURDEF", summary.Account.Currency)
+	renderer.writer.WriteString("<BANKACCTFROM>\n")
+	renderer.element("BANKID", ofxBankID)
+	renderer.element("ACCTID", strconv.FormatInt(summary.Account.ID, 10))
+	renderer.element("ACCTTYPE", ofxAccountType)
+	renderer.writer.WriteString("</BANKACCTFROM>\n")
+	renderer.writer.WriteString("<BANKTRANLIST>\n")
+	renderer.element("DTSTART", ofxTime(fromTime))
+	renderer.element("DTEND", ofxTime(summary.ToTime))
+	return nil
+}
+
+func (renderer *ofxRenderer) writeEntry(entry db.ListAccountEntriesRow) error {
+	amount, err := formatAmount(entry.Amount, renderer.summary.Account.Currency)
+	if err != nil {
+		return err
+	}
+
+	transactionType := "CREDIT"
+	if entry.Amount < 0 {
+		transactionType = "DEBIT"
+	}
+
+	renderer.writer.WriteString("<STMTTRN>\n")
+	renderer.element("TRNTYPE", transactionType)
+	renderer.element("DTPOSTED", ofxTime(entry.CreatedAt.Time))
+	renderer.element("TRNAMT", amount)
+	renderer.element("FITID", strconv.FormatInt(entry.ID, 10))
+	renderer.element("NAME", describeEntry(entry))
+	if entry.TransferID != nil {
+		renderer.element("MEMO", fmt.Sprintf("Transfer %d", *entry.TransferID))
+	}
+	_, err = renderer.writer.WriteString("</STMTTRN>\n")
+	return err
+}
+
+func (renderer *ofxRenderer) end() error {
+	closingBalance, err := formatAmount(renderer.summary.ClosingBalance, renderer.summary.Account.Currency)
+	if err != nil {
+		return err
+	}
+
+	renderer.writer.WriteString("</BANKTRANLIST>\n")
+	renderer.writer.WriteString("<LEDGERBAL>\n")
+	renderer.element("BALAMT", closingBalance)
+	renderer.element("DTASOF", ofxTime(renderer.summary.ToTime))
+	renderer.writer.WriteString("</LEDGERBAL>\n")
+	renderer.writer.WriteString("</STMTRS>\n")
+	renderer.writer.WriteString("</STMTTRNRS></BANKMSGSRSV1>\n")
+	renderer.writer.WriteString("</OFX>\n")
+	return renderer.writer.Flush()
+}
+
+// element writes a single line element. Write errors are kept by the bufio.Writer and reported by Flush
+func (renderer *ofxRenderer) element(name string, value string) {
+	renderer.writer.WriteString("<" + name + ">")
+	xml.EscapeText(renderer.writer, []byte(value))
+	renderer.writer.WriteString("</" + name + ">\n")
+}
+
+// ofxTime formats a time the way OFX expects it, in UTC
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:UTC]"
+}
@@ -0,0 +1,76 @@
+package statement
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"strconv"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type ofxTransaction struct {
+	Type   string `xml:"TRNTYPE"`
+	Posted string `xml:"DTPOSTED"`
+	Amount string `xml:"TRNAMT"`
+	ID     string `xml:"FITID"`
+	Name   string `xml:"NAME"`
+	Memo   string `xml:"MEMO"`
+}
+
+type ofxDocument struct {
+	XMLName      xml.Name         `xml:"OFX"`
+	Currency     string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>CURDEF"`
+	AccountID    string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKACCTFROM>ACCTID"`
+	Start        string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKTRANLIST>DTSTART"`
+	Transactions []ofxTransaction `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKTRANLIST>STMTTRN"`
+	Balance      string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>LEDGERBAL>BALAMT"`
+}
+
+func TestExportOFX(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	account := randomAccount()
+	entries := randomEntries(account, 1000, 3)
+
+	store := mockdb.NewMockStore(ctrl)
+	expectPages(t, store, account, 1000, entries)
+
+	var buf bytes.Buffer
+	err := Export(context.Background(), store, Params{Account: account}, FormatOFX, &buf)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(buf.Bytes(), []byte("<?xml")))
+	require.Contains(t, buf.String(), `<?OFX OFXHEADER="200" VERSION="220"`)
+
+	var document ofxDocument
+	err = xml.NewDecoder(&buf).Decode(&document)
+	require.NoError(t, err)
+
+	require.Equal(t, account.Currency, document.Currency)
+	require.Equal(t, strconv.FormatInt(account.ID, 10), document.AccountID)
+	require.Equal(t, "20240101000000.000[0:UTC]", document.Start)
+	require.Len(t, document.Transactions, len(entries))
+
+	for i, transaction := range document.Transactions {
+		entry := entries[i]
+		amount, err := formatAmount(entry.Amount, account.Currency)
+		require.NoError(t, err)
+
+		require.Equal(t, amount, transaction.Amount)
+		require.Equal(t, describeEntry(entry), transaction.Name)
+		require.NotEmpty(t, transaction.Memo)
+		if entry.Amount < 0 {
+			require.Equal(t, "DEBIT", transaction.Type)
+		} else {
+			require.Equal(t, "CREDIT", transaction.Type)
+		}
+	}
+
+	closingBalance, err := formatAmount(entries[len(entries)-1].RunningBalance, account.Currency)
+	require.NoError(t, err)
+	require.Equal(t, closingBalance, document.Balance)
+}
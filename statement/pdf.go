@@ -0,0 +1,223 @@
+package statement
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+)
+
+// A4 page layout, in points
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 40
+	pdfFontSize     = 9
+	pdfTitleSize    = 14
+	pdfLineHeight   = 12
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLineHeight
+)
+
+// objects with a fixed number, the others are numbered as they are written
+const (
+	pdfCatalogObject = 1
+	pdfPagesObject   = 2
+	pdfFontObject    = 3
+	pdfBoldObject    = 4
+)
+
+const pdfRowFormat = "%-20s %-34s %15s %15s"
+
+// pdfRenderer writes a simple text-only PDF 1.4 document with the built-in Courier fonts.
+// Pages are written as soon as they are full, and the page tree and cross-reference table
+// are written at the end, so only the current page is kept in memory
+type pdfRenderer struct {
+	writer  *countingWriter
+	summary Summary
+	// offsets[n] is the byte offset of object n
+	offsets []int64
+	pages   []int
+	page    bytes.Buffer
+	lines   int
+	err     error
+}
+
+func newPDFRenderer(w io.Writer) *pdfRenderer {
+	return &pdfRenderer{
+		writer:  &countingWriter{writer: bufio.NewWriter(w)},
+		offsets: make([]int64, pdfBoldObject+1),
+	}
+}
+
+func (renderer *pdfRenderer) begin(summary Summary) error {
+	renderer.summary = summary
+	renderer.writer.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	renderer.writeObject(pdfFontObject, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	renderer.writeObject(pdfBoldObject, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+
+	account := summary.Account
+	period := "since account opening"
+	if !summary.FromTime.IsZero() {
+		period = "from " + summary.FromTime.UTC().Format(time.RFC3339)
+	}
+	period += " to " + summary.ToTime.UTC().Format(time.RFC3339)
+
+	openingBalance, err := formatAmount(summary.OpeningBalance, account.Currency)
+	if err != nil {
+		return err
+	}
+
+	closingBalance, err := formatAmount(summary.ClosingBalance, account.Currency)
+	if err != nil {
+		return err
+	}
+
+	renderer.title("Account statement")
+	renderer.line(fmt.Sprintf("Account:         %d (%s)", account.ID, account.Currency))
+	renderer.line("Owner:           " + account.Owner)
+	renderer.line("Period:          " + period)
+	renderer.line("Opening balance: " + openingBalance)
+	renderer.line("Closing balance: " + closingBalance)
+	renderer.line("Generated at:    " + summary.GeneratedAt.UTC().Format(time.RFC3339))
+	renderer.line("")
+	renderer.tableHeader()
+	return renderer.err
+}
+
+func (renderer *pdfRenderer) writeEntry(entry db.ListAccountEntriesRow) error {
+	amount, err := formatAmount(entry.Amount, renderer.summary.Account.Currency)
+	if err != nil {
+		return err
+	}
+
+	balance, err := formatAmount(entry.RunningBalance, renderer.summary.Account.Currency)
+	if err != nil {
+		return err
+	}
+
+	if renderer.lines >= pdfLinesPerPage {
+		renderer.flushPage()
+		renderer.tableHeader()
+	}
+
+	renderer.line(fmt.Sprintf(pdfRowFormat,
+		entry.CreatedAt.Time.UTC().Format("2006-01-02 15:04:05"),
+		describeEntry(entry),
+		amount,
+		balance,
+	))
+	return renderer.err
+}
+
+func (renderer *pdfRenderer) end() error {
+	renderer.flushPage()
+
+	kids := make([]string, len(renderer.pages))
+	for i, page := range renderer.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+	}
+	renderer.writeObject(pdfPagesObject, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	renderer.writeObject(pdfCatalogObject, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pdfPagesObject))
+
+	xref := renderer.writer.written
+	renderer.writer.WriteString(fmt.Sprintf("xref\n0 %d\n", len(renderer.offsets)))
+	renderer.writer.WriteString("0000000000 65535 f \n")
+	for _, offset := range renderer.offsets[1:] {
+		renderer.writer.WriteString(fmt.Sprintf("%010d 00000 n \n", offset))
+	}
+	renderer.writer.WriteString(fmt.Sprintf("trailer\n<< /Size %d /Root %d 0 R >>\n", len(renderer.offsets), pdfCatalogObject))
+	renderer.writer.WriteString(fmt.Sprintf("startxref\n%d\n%%%%EOF\n", xref))
+
+	if renderer.err == nil {
+		renderer.err = renderer.writer.err
+	}
+	if renderer.err == nil {
+		renderer.err = renderer.writer.writer.Flush()
+	}
+	return renderer.err
+}
+
+func (renderer *pdfRenderer) title(text string) {
+	y := pdfPageHeight - pdfMargin - renderer.lines*pdfLineHeight
+	fmt.Fprintf(&renderer.page, "BT /F2 %d Tf %d %d Td (%s) Tj ET\n", pdfTitleSize, pdfMargin, y, pdfEscape(text))
+	renderer.lines += 2
+}
+
+func (renderer *pdfRenderer) tableHeader() {
+	y := pdfPageHeight - pdfMargin - renderer.lines*pdfLineHeight
+	header := fmt.Sprintf(pdfRowFormat, "Date (UTC)", "Description", "Amount", "Balance")
+	fmt.Fprintf(&renderer.page, "BT /F2 %d Tf %d %d Td (%s) Tj ET\n", pdfFontSize, pdfMargin, y, pdfEscape(header))
+	renderer.lines++
+}
+
+func (renderer *pdfRenderer) line(text string) {
+	y := pdfPageHeight - pdfMargin - renderer.lines*pdfLineHeight
+	fmt.Fprintf(&renderer.page, "BT /F1 %d Tf %d %d Td (%s) Tj ET\n", pdfFontSize, pdfMargin, y, pdfEscape(text))
+	renderer.lines++
+}
+
+// flushPage writes the current page and its content stream
+func (renderer *pdfRenderer) flushPage() {
+	content := renderer.nextObject()
+	renderer.writeObject(content, fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", renderer.page.Len(), renderer.page.String()))
+
+	page := renderer.nextObject()
+	renderer.writeObject(page, fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> /Contents %d 0 R >>",
+		pdfPagesObject, pdfPageWidth, pdfPageHeight, pdfFontObject, pdfBoldObject, content,
+	))
+	renderer.pages = append(renderer.pages, page)
+
+	renderer.page.Reset()
+	renderer.lines = 0
+}
+
+func (renderer *pdfRenderer) nextObject() int {
+	renderer.offsets = append(renderer.offsets, 0)
+	return len(renderer.offsets) - 1
+}
+
+func (renderer *pdfRenderer) writeObject(number int, body string) {
+	renderer.offsets[number] = renderer.writer.written
+	renderer.writer.WriteString(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", number, body))
+	if renderer.err == nil {
+		renderer.err = renderer.writer.err
+	}
+}
+
+// pdfEscape escapes a string literal. Characters the standard fonts can't show are replaced with '?'
+func pdfEscape(text string) string {
+	var escaped strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			escaped.WriteByte('\\')
+			escaped.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			escaped.WriteByte('?')
+		default:
+			escaped.WriteRune(r)
+		}
+	}
+	return escaped.String()
+}
+
+// countingWriter keeps track of the number of bytes written, which the cross-reference table needs
+type countingWriter struct {
+	writer  *bufio.Writer
+	written int64
+	err     error
+}
+
+func (w *countingWriter) WriteString(s string) {
+	if w.err != nil {
+		return
+	}
+	n, err := w.writer.WriteString(s)
+	w.written += int64(n)
+	w.err = err
+}
@@ -0,0 +1,59 @@
+package statement
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportPDF(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	account := randomAccount()
+	account.Owner = "owner (with parentheses)"
+	entries := randomEntries(account, 1000, 2*pdfLinesPerPage)
+
+	store := mockdb.NewMockStore(ctrl)
+	expectPages(t, store, account, 1000, entries)
+
+	var buf bytes.Buffer
+	err := Export(context.Background(), store, Params{Account: account}, FormatPDF, &buf)
+	require.NoError(t, err)
+
+	data := buf.Bytes()
+	require.True(t, bytes.HasPrefix(data, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(data, []byte("%%EOF\n")))
+	require.Contains(t, buf.String(), `owner \(with parentheses\)`)
+
+	// the statement doesn't fit on two pages with its header
+	pages := regexp.MustCompile(`/Type /Pages /Kids \[[^\]]*\] /Count (\d+)`).FindSubmatch(data)
+	require.NotNil(t, pages)
+	require.Equal(t, "3", string(pages[1]))
+
+	// every object listed in the cross-reference table starts at its offset
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	require.NotNil(t, startxref)
+	xref, err := strconv.Atoi(string(startxref[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(data[xref:], []byte("xref\n")))
+
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	require.NotEmpty(t, offsets)
+	for i, offset := range offsets {
+		n, err := strconv.Atoi(string(offset[1]))
+		require.NoError(t, err)
+		require.True(t, bytes.HasPrefix(data[n:], fmt.Appendf(nil, "%d 0 obj\n", i+1)))
+	}
+}
+
+func TestPDFEscape(t *testing.T) {
+	require.Equal(t, `a\\b \(c\) d?`, pdfEscape("a\\b (c) dé"))
+}
@@ -0,0 +1,184 @@
+// Package statement renders account statements in the formats accounting tools import.
+//
+// Statements are streamed page by page from the store, so exporting a large statement
+// doesn't load all of its entries into memory.
+package statement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/fx"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Format is the file format of a statement
+type Format string
+
+const (
+	FormatCSV Format = "csv"
+	FormatOFX Format = "ofx"
+	FormatPDF Format = "pdf"
+)
+
+// exportPageSize is the number of entries read from the store at a time
+const exportPageSize = 500
+
+var ErrUnsupportedFormat = errors.New("unsupported statement format")
+
+// ParseFormat parses a statement format. An empty string means FormatCSV
+func ParseFormat(format string) (Format, error) {
+	switch Format(format) {
+	case "":
+		return FormatCSV, nil
+	case FormatCSV, FormatOFX, FormatPDF:
+		return Format(format), nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+}
+
+// ContentType returns the MIME type of the format
+func (format Format) ContentType() string {
+	switch format {
+	case FormatOFX:
+		return "application/x-ofx"
+	case FormatPDF:
+		return "application/pdf"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// FileName returns the name of the statement file of an account
+func (format Format) FileName(accountID int64, fromTime time.Time, toTime time.Time) string {
+	name := fmt.Sprintf("statement-%d", accountID)
+	if !fromTime.IsZero() {
+		name += "-" + fromTime.UTC().Format("20060102")
+	}
+	if !toTime.IsZero() {
+		name += "-" + toTime.UTC().Format("20060102")
+	}
+	return name + "." + string(format)
+}
+
+// Params contains the input parameters of a statement export.
+// A zero FromTime starts the statement at the first entry of the account,
+// and a zero ToTime ends it at the time of the export
+type Params struct {
+	Account  db.Account
+	FromTime time.Time
+	ToTime   time.Time
+}
+
+// Summary is what a statement says about its account and period, before listing the entries
+type Summary struct {
+	Account        db.Account
+	FromTime       time.Time
+	ToTime         time.Time
+	OpeningBalance int64
+	ClosingBalance int64
+	GeneratedAt    time.Time
+}
+
+// renderer writes a statement in one format
+type renderer interface {
+	begin(summary Summary) error
+	writeEntry(entry db.ListAccountEntriesRow) error
+	end() error
+}
+
+func newRenderer(format Format, w io.Writer) (renderer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVRenderer(w), nil
+	case FormatOFX:
+		return newOFXRenderer(w), nil
+	case FormatPDF:
+		return newPDFRenderer(w), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+}
+
+// Export writes the statement of an account to w.
+// Nothing is written if the first page of entries can't be read
+func Export(ctx context.Context, store db.Store, params Params, format Format, w io.Writer) error {
+	renderer, err := newRenderer(format, w)
+	if err != nil {
+		return err
+	}
+
+	generatedAt := time.Now()
+	toTime := params.ToTime
+	if toTime.IsZero() {
+		toTime = generatedAt
+	}
+
+	arg := db.AccountStatementTxParams{
+		AccountID: params.Account.ID,
+		FromTime:  pgtype.Timestamptz{Time: params.FromTime, Valid: !params.FromTime.IsZero()},
+		ToTime:    pgtype.Timestamptz{Time: toTime, Valid: true},
+		PageSize:  exportPageSize,
+	}
+
+	page, err := store.AccountStatementTx(ctx, arg)
+	if err != nil {
+		return fmt.Errorf("cannot get statement entries: %w", err)
+	}
+
+	err = renderer.begin(Summary{
+		Account:        params.Account,
+		FromTime:       params.FromTime,
+		ToTime:         toTime,
+		OpeningBalance: page.OpeningBalance,
+		ClosingBalance: page.ClosingBalance,
+		GeneratedAt:    generatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		for _, entry := range page.Entries {
+			if err := renderer.writeEntry(entry); err != nil {
+				return err
+			}
+		}
+
+		if page.NextAfterID == 0 {
+			break
+		}
+
+		arg.AfterID = page.NextAfterID
+		page, err = store.AccountStatementTx(ctx, arg)
+		if err != nil {
+			return fmt.Errorf("cannot get statement entries: %w", err)
+		}
+	}
+
+	return renderer.end()
+}
+
+// describeEntry returns a human readable description of an entry
+func describeEntry(entry db.ListAccountEntriesRow) string {
+	counterparty := entry.CounterpartyAccountID()
+	switch {
+	case counterparty == 0:
+		return "Account entry"
+	case entry.Amount < 0:
+		return fmt.Sprintf("Transfer to account %d", counterparty)
+	default:
+		return fmt.Sprintf("Transfer from account %d", counterparty)
+	}
+}
+
+// formatAmount formats an amount in the currency of the statement account
+func formatAmount(amount int64, currency string) (string, error) {
+	formatted, err := fx.FormatAmount(amount, currency)
+	if err != nil {
+		return "", fmt.Errorf("cannot format amount: %w", err)
+	}
+	return formatted, nil
+}
@@ -0,0 +1,178 @@
+package statement
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func randomAccount() db.Account {
+	return db.Account{
+		ID:        util.RandomInt(1, 1000),
+		Owner:     util.RandomOwner(),
+		Balance:   util.RandomMoney(),
+		Currency:  util.USD,
+		CreatedAt: pgtype.Timestamptz{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+	}
+}
+
+// randomEntries creates n entries of the account, alternating transfers to and from another account
+func randomEntries(account db.Account, openingBalance int64, n int) []db.ListAccountEntriesRow {
+	otherAccountID := account.ID + 1
+	entries := make([]db.ListAccountEntriesRow, n)
+	balance := openingBalance
+	for i := range entries {
+		id := int64(i + 1)
+		transferID := 100 + id
+		entry := db.ListAccountEntriesRow{
+			ID:         id,
+			AccountID:  account.ID,
+			Amount:     util.RandomMoney(),
+			CreatedAt:  pgtype.Timestamptz{Time: time.Date(2024, 1, 2, 10, 0, i, 0, time.UTC), Valid: true},
+			TransferID: &transferID,
+		}
+		if i%2 == 0 {
+			entry.FromAccountID = &otherAccountID
+			entry.ToAccountID = &account.ID
+		} else {
+			entry.Amount = -entry.Amount
+			entry.FromAccountID = &account.ID
+			entry.ToAccountID = &otherAccountID
+		}
+		balance += entry.Amount
+		entry.RunningBalance = balance
+		entries[i] = entry
+	}
+	return entries
+}
+
+// expectPages makes the store return the entries in pages of exportPageSize
+func expectPages(t *testing.T, store *mockdb.MockStore, account db.Account, openingBalance int64, entries []db.ListAccountEntriesRow) {
+	closingBalance := openingBalance
+	if len(entries) > 0 {
+		closingBalance = entries[len(entries)-1].RunningBalance
+	}
+
+	start := 0
+	for {
+		end := min(start+exportPageSize, len(entries))
+		page := db.AccountStatementTxResult{
+			OpeningBalance: openingBalance,
+			ClosingBalance: closingBalance,
+			Entries:        entries[start:end],
+		}
+		if end-start == exportPageSize {
+			page.NextAfterID = entries[end-1].ID
+		}
+
+		afterID := int64(0)
+		if start > 0 {
+			afterID = entries[start-1].ID
+		}
+
+		store.EXPECT().
+			AccountStatementTx(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ any, arg db.AccountStatementTxParams) (db.AccountStatementTxResult, error) {
+				require.Equal(t, account.ID, arg.AccountID)
+				require.Equal(t, afterID, arg.AfterID)
+				require.Equal(t, int32(exportPageSize), arg.PageSize)
+				return page, nil
+			})
+
+		if page.NextAfterID == 0 {
+			return
+		}
+		start = end
+	}
+}
+
+func TestExportPaginates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	account := randomAccount()
+	entries := randomEntries(account, account.Balance, 2*exportPageSize+3)
+
+	store := mockdb.NewMockStore(ctrl)
+	expectPages(t, store, account, account.Balance, entries)
+
+	var buf bytes.Buffer
+	err := Export(context.Background(), store, Params{Account: account}, FormatCSV, &buf)
+	require.NoError(t, err)
+
+	// header row and one row per entry
+	require.Equal(t, len(entries)+1, bytes.Count(buf.Bytes(), []byte("\n")))
+}
+
+func TestExportStoreError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		AccountStatementTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.AccountStatementTxResult{}, sql.ErrConnDone)
+
+	var buf bytes.Buffer
+	err := Export(context.Background(), store, Params{Account: randomAccount()}, FormatPDF, &buf)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+	require.Zero(t, buf.Len())
+}
+
+func TestExportTimeRange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	params := Params{
+		Account:  randomAccount(),
+		FromTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		ToTime:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		AccountStatementTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ any, arg db.AccountStatementTxParams) (db.AccountStatementTxResult, error) {
+			require.Equal(t, pgtype.Timestamptz{Time: params.FromTime, Valid: true}, arg.FromTime)
+			require.Equal(t, pgtype.Timestamptz{Time: params.ToTime, Valid: true}, arg.ToTime)
+			return db.AccountStatementTxResult{}, nil
+		})
+
+	var buf bytes.Buffer
+	err := Export(context.Background(), store, params, FormatOFX, &buf)
+	require.NoError(t, err)
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("")
+	require.NoError(t, err)
+	require.Equal(t, FormatCSV, format)
+
+	format, err = ParseFormat("ofx")
+	require.NoError(t, err)
+	require.Equal(t, FormatOFX, format)
+	require.Equal(t, "application/x-ofx", format.ContentType())
+
+	_, err = ParseFormat("xlsx")
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestFileName(t *testing.T) {
+	fromTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	toTime := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	require.Equal(t, "statement-7.pdf", FormatPDF.FileName(7, time.Time{}, time.Time{}))
+	require.Equal(t, "statement-7-20240101-20240201.csv", FormatCSV.FileName(7, fromTime, toTime))
+}
@@ -0,0 +1,72 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// EdDSAJWTMaker signs JWTs with the signing key of a keyring, and names the key in the kid header
+// so the token can still be verified after the signing key is rotated
+type EdDSAJWTMaker struct {
+	keyring *Keyring
+}
+
+func NewEdDSAJWTMaker(keyring *Keyring) (Maker, error) {
+	if keyring == nil {
+		return nil, fmt.Errorf("missing keyring")
+	}
+	return &EdDSAJWTMaker{
+		keyring: keyring,
+	}, nil
+}
+
+func (maker *EdDSAJWTMaker) CreateToken(username string, role string, sessionID pgtype.UUID, tokenType TokenType, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, sessionID, tokenType, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	keyID, signingKey := maker.keyring.signingKey()
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodEdDSA, payload)
+	jwtToken.Header["kid"] = keyID
+	token, err := jwtToken.SignedString(signingKey)
+	return token, payload, err
+}
+
+func (maker *EdDSAJWTMaker) VerifyToken(token string) (*Payload, error) {
+	keyFunc := func(t *jwt.Token) (interface{}, error) {
+		_, ok := t.Method.(*jwt.SigningMethodEd25519)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+		keyID, ok := t.Header["kid"].(string)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+		key, ok := maker.keyring.verificationKey(keyID)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+		return key, nil
+	}
+	jwtToken, err := jwt.ParseWithClaims(token, &Payload{}, keyFunc)
+
+	if err != nil {
+		verr, ok := err.(*jwt.ValidationError)
+		if ok && errors.Is(verr.Inner, ErrExpiredToken) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	payload, ok := jwtToken.Claims.(*Payload)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,127 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEdDSAJWTMaker(t *testing.T) {
+	maker, err := NewEdDSAJWTMaker(randomKeyring(t))
+
+	require.NoError(t, err)
+	username := util.RandomOwner()
+	role := util.DepositorRole
+	sessionID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	duration := time.Second
+	issuedAt := time.Now()
+	expiredAt := issuedAt.Add(duration)
+
+	token, _, err := maker.CreateToken(username, role, sessionID, TokenTypeAccess, duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+
+	payload, err := maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+
+	require.NotZero(t, payload.ID)
+	require.Equal(t, username, payload.Username)
+	require.Equal(t, role, payload.Role)
+	require.Equal(t, TokenTypeAccess, payload.Type)
+	require.Equal(t, sessionID, payload.SessionID)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+}
+
+func TestExpiredEdDSAJWTToken(t *testing.T) {
+	maker, err := NewEdDSAJWTMaker(randomKeyring(t))
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, pgtype.UUID{}, TokenTypeAccess, -time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+
+	payload, err := maker.VerifyToken(token)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestEdDSAJWTKeyRotation(t *testing.T) {
+	oldKey := randomKey(t)
+	keyring, err := NewKeyring("old", oldKey, nil)
+	require.NoError(t, err)
+
+	maker, err := NewEdDSAJWTMaker(keyring)
+	require.NoError(t, err)
+
+	oldToken, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, pgtype.UUID{}, TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	// the old key still verifies the tokens it signed after a new signing key is introduced
+	err = keyring.Rotate("new", randomKey(t), map[string]ed25519.PublicKey{
+		"old": oldKey.Public().(ed25519.PublicKey),
+	})
+	require.NoError(t, err)
+
+	newToken, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, pgtype.UUID{}, TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(oldToken)
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(newToken)
+	require.NoError(t, err)
+
+	// tokens of a retired key are rejected
+	_, newSigningKey := keyring.signingKey()
+	err = keyring.Rotate("new", newSigningKey, nil)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(oldToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+	_, err = maker.VerifyToken(newToken)
+	require.NoError(t, err)
+}
+
+func TestEdDSAJWTUnknownKey(t *testing.T) {
+	maker, err := NewEdDSAJWTMaker(randomKeyring(t))
+	require.NoError(t, err)
+
+	otherMaker, err := NewEdDSAJWTMaker(randomKeyring(t))
+	require.NoError(t, err)
+
+	token, _, err := otherMaker.CreateToken(util.RandomOwner(), util.DepositorRole, pgtype.UUID{}, TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestInvalidEdDSAJWTTokenHMAC(t *testing.T) {
+	keyring := randomKeyring(t)
+	maker, err := NewEdDSAJWTMaker(keyring)
+	require.NoError(t, err)
+
+	payload, err := NewPayload(util.RandomOwner(), util.DepositorRole, pgtype.UUID{}, TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	// a token signed with HS256 using the public key as the secret must not be accepted
+	keyID, signingKey := keyring.signingKey()
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
+	jwtToken.Header["kid"] = keyID
+	token, err := jwtToken.SignedString([]byte(signingKey.Public().(ed25519.PublicKey)))
+	require.NoError(t, err)
+
+	payload, err = maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
@@ -0,0 +1,218 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync/atomic"
+)
+
+// keyringFile is the JSON format of a keyring file. Keys are base64 encoded: a private key
+// is its 32 byte seed, and a key that only verifies tokens may give its public key instead
+type keyringFile struct {
+	SigningKeyID string           `json:"signing_key_id"`
+	Keys         []keyringFileKey `json:"keys"`
+}
+
+type keyringFileKey struct {
+	ID         string `json:"id"`
+	PrivateKey string `json:"private_key,omitempty"`
+	PublicKey  string `json:"public_key,omitempty"`
+}
+
+type keySet struct {
+	signingKeyID string
+	signingKey   ed25519.PrivateKey
+	publicKeys   map[string]ed25519.PublicKey
+}
+
+// Keyring holds the Ed25519 keys of the asymmetric makers: one key that signs new tokens,
+// and the public keys of every key whose tokens are still accepted.
+// The keys can be replaced while the keyring is in use
+type Keyring struct {
+	keys atomic.Pointer[keySet]
+}
+
+// NewKeyring creates a keyring signing with signingKey. Tokens are verified with the signing key
+// and with verificationKeys, the keys of the previous signing keys that have not been retired yet
+func NewKeyring(signingKeyID string, signingKey ed25519.PrivateKey, verificationKeys map[string]ed25519.PublicKey) (*Keyring, error) {
+	keyring := &Keyring{}
+	if err := keyring.Rotate(signingKeyID, signingKey, verificationKeys); err != nil {
+		return nil, err
+	}
+	return keyring, nil
+}
+
+// LoadKeyringFile creates a keyring from a JSON keyring file such as
+// {"signing_key_id": "2026-10", "keys": [{"id": "2026-10", "private_key": "..."}, {"id": "2026-04", "public_key": "..."}]}
+func LoadKeyringFile(path string) (*Keyring, error) {
+	keyring := &Keyring{}
+	if err := keyring.ReloadFile(path); err != nil {
+		return nil, err
+	}
+	return keyring, nil
+}
+
+// GenerateKeyringFile writes a keyring file with a new random signing key with id keyID.
+// The file is only readable by its owner, and an existing file is never overwritten
+func GenerateKeyringFile(path string, keyID string) error {
+	if keyID == "" {
+		return fmt.Errorf("missing signing key id")
+	}
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("cannot generate signing key: %w", err)
+	}
+
+	data, err := json.MarshalIndent(keyringFile{
+		SigningKeyID: keyID,
+		Keys: []keyringFileKey{
+			{ID: keyID, PrivateKey: base64.StdEncoding.EncodeToString(privateKey.Seed())},
+		},
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot marshal keyring file: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("cannot create keyring file: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("cannot write keyring file: %w", err)
+	}
+	return file.Close()
+}
+
+// Rotate replaces every key of the keyring. Tokens signed by a key that is no longer in the keyring are rejected
+func (keyring *Keyring) Rotate(signingKeyID string, signingKey ed25519.PrivateKey, verificationKeys map[string]ed25519.PublicKey) error {
+	if signingKeyID == "" {
+		return fmt.Errorf("missing signing key id")
+	}
+	if len(signingKey) != ed25519.PrivateKeySize {
+		return fmt.Errorf("invalid signing key size: must be exactly %d bytes", ed25519.PrivateKeySize)
+	}
+
+	publicKeys := make(map[string]ed25519.PublicKey, len(verificationKeys)+1)
+	for id, key := range verificationKeys {
+		if len(key) != ed25519.PublicKeySize {
+			return fmt.Errorf("invalid size of key %s: must be exactly %d bytes", id, ed25519.PublicKeySize)
+		}
+		publicKeys[id] = key
+	}
+
+	signingPublicKey := signingKey.Public().(ed25519.PublicKey)
+	if key, ok := publicKeys[signingKeyID]; ok && !key.Equal(signingPublicKey) {
+		return fmt.Errorf("key %s doesn't match the signing key", signingKeyID)
+	}
+	publicKeys[signingKeyID] = signingPublicKey
+
+	keyring.keys.Store(&keySet{
+		signingKeyID: signingKeyID,
+		signingKey:   signingKey,
+		publicKeys:   publicKeys,
+	})
+	return nil
+}
+
+// ReloadFile replaces the keys of the keyring with the keys of a keyring file.
+// The keyring is left unchanged if the file is invalid
+func (keyring *Keyring) ReloadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read keyring file: %w", err)
+	}
+
+	var file keyringFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("cannot parse keyring file: %w", err)
+	}
+
+	var signingKey ed25519.PrivateKey
+	verificationKeys := make(map[string]ed25519.PublicKey, len(file.Keys))
+	for _, key := range file.Keys {
+		if key.ID == "" {
+			return fmt.Errorf("missing key id in keyring file")
+		}
+		if _, ok := verificationKeys[key.ID]; ok {
+			return fmt.Errorf("duplicate key %s in keyring file", key.ID)
+		}
+
+		if key.PrivateKey != "" {
+			seed, err := base64.StdEncoding.DecodeString(key.PrivateKey)
+			if err != nil || len(seed) != ed25519.SeedSize {
+				return fmt.Errorf("invalid private key %s: must be a base64 encoded %d byte seed", key.ID, ed25519.SeedSize)
+			}
+			privateKey := ed25519.NewKeyFromSeed(seed)
+			if key.ID == file.SigningKeyID {
+				signingKey = privateKey
+			}
+			verificationKeys[key.ID] = privateKey.Public().(ed25519.PublicKey)
+			continue
+		}
+
+		publicKey, err := base64.StdEncoding.DecodeString(key.PublicKey)
+		if err != nil || len(publicKey) != ed25519.PublicKeySize {
+			return fmt.Errorf("invalid public key %s: must be a base64 encoded %d byte key", key.ID, ed25519.PublicKeySize)
+		}
+		verificationKeys[key.ID] = publicKey
+	}
+
+	if signingKey == nil {
+		return fmt.Errorf("no private key for signing key %q in keyring file", file.SigningKeyID)
+	}
+	return keyring.Rotate(file.SigningKeyID, signingKey, verificationKeys)
+}
+
+// signingKey returns the key that signs new tokens and its id
+func (keyring *Keyring) signingKey() (string, ed25519.PrivateKey) {
+	keys := keyring.keys.Load()
+	return keys.signingKeyID, keys.signingKey
+}
+
+// verificationKey returns the public key of a key that is still accepted
+func (keyring *Keyring) verificationKey(id string) (ed25519.PublicKey, bool) {
+	key, ok := keyring.keys.Load().publicKeys[id]
+	return key, ok
+}
+
+// JSONWebKey is the JWK of an Ed25519 public key (RFC 8037)
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS returns the public keys that tokens are verified with, sorted by key id
+func (keyring *Keyring) JWKS() JSONWebKeySet {
+	keys := keyring.keys.Load()
+
+	set := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(keys.publicKeys))}
+	for id, key := range keys.publicKeys {
+		set.Keys = append(set.Keys, JSONWebKey{
+			KeyType:   "OKP",
+			Curve:     "Ed25519",
+			X:         base64.RawURLEncoding.EncodeToString(key),
+			KeyID:     id,
+			Algorithm: "EdDSA",
+			Use:       "sig",
+		})
+	}
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].KeyID < set.Keys[j].KeyID
+	})
+	return set
+}
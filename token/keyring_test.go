@@ -0,0 +1,145 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func randomKey(t *testing.T) ed25519.PrivateKey {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return privateKey
+}
+
+func randomKeyring(t *testing.T) *Keyring {
+	keyring, err := NewKeyring("current", randomKey(t), nil)
+	require.NoError(t, err)
+	return keyring
+}
+
+func writeKeyringFile(t *testing.T, path string, file keyringFile) {
+	data, err := json.Marshal(file)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func TestLoadKeyringFile(t *testing.T) {
+	currentKey := randomKey(t)
+	previousKey := randomKey(t)
+
+	path := filepath.Join(t.TempDir(), "keys.json")
+	writeKeyringFile(t, path, keyringFile{
+		SigningKeyID: "current",
+		Keys: []keyringFileKey{
+			{ID: "current", PrivateKey: base64.StdEncoding.EncodeToString(currentKey.Seed())},
+			{ID: "previous", PublicKey: base64.StdEncoding.EncodeToString(previousKey.Public().(ed25519.PublicKey))},
+		},
+	})
+
+	keyring, err := LoadKeyringFile(path)
+	require.NoError(t, err)
+
+	keyID, signingKey := keyring.signingKey()
+	require.Equal(t, "current", keyID)
+	require.True(t, currentKey.Equal(signingKey))
+
+	key, ok := keyring.verificationKey("previous")
+	require.True(t, ok)
+	require.True(t, previousKey.Public().(ed25519.PublicKey).Equal(key))
+
+	jwks := keyring.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	require.Equal(t, "current", jwks.Keys[0].KeyID)
+	require.Equal(t, "previous", jwks.Keys[1].KeyID)
+	for _, jwk := range jwks.Keys {
+		require.Equal(t, "OKP", jwk.KeyType)
+		require.Equal(t, "Ed25519", jwk.Curve)
+		require.Equal(t, "EdDSA", jwk.Algorithm)
+	}
+	require.Equal(t, base64.RawURLEncoding.EncodeToString(previousKey.Public().(ed25519.PublicKey)), jwks.Keys[1].X)
+}
+
+func TestReloadKeyringFile(t *testing.T) {
+	oldKey := randomKey(t)
+	newKey := randomKey(t)
+
+	path := filepath.Join(t.TempDir(), "keys.json")
+	writeKeyringFile(t, path, keyringFile{
+		SigningKeyID: "old",
+		Keys: []keyringFileKey{
+			{ID: "old", PrivateKey: base64.StdEncoding.EncodeToString(oldKey.Seed())},
+		},
+	})
+
+	keyring, err := LoadKeyringFile(path)
+	require.NoError(t, err)
+
+	writeKeyringFile(t, path, keyringFile{
+		SigningKeyID: "new",
+		Keys: []keyringFileKey{
+			{ID: "new", PrivateKey: base64.StdEncoding.EncodeToString(newKey.Seed())},
+			{ID: "old", PublicKey: base64.StdEncoding.EncodeToString(oldKey.Public().(ed25519.PublicKey))},
+		},
+	})
+	require.NoError(t, keyring.ReloadFile(path))
+
+	keyID, _ := keyring.signingKey()
+	require.Equal(t, "new", keyID)
+	_, ok := keyring.verificationKey("old")
+	require.True(t, ok)
+
+	// an invalid file leaves the keyring unchanged
+	writeKeyringFile(t, path, keyringFile{
+		SigningKeyID: "missing",
+		Keys: []keyringFileKey{
+			{ID: "new", PrivateKey: base64.StdEncoding.EncodeToString(newKey.Seed())},
+		},
+	})
+	require.Error(t, keyring.ReloadFile(path))
+
+	keyID, _ = keyring.signingKey()
+	require.Equal(t, "new", keyID)
+}
+
+func TestGenerateKeyringFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	require.NoError(t, GenerateKeyringFile(path, "2026-10"))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	keyring, err := LoadKeyringFile(path)
+	require.NoError(t, err)
+	keyID, _ := keyring.signingKey()
+	require.Equal(t, "2026-10", keyID)
+
+	// an existing keyring is never replaced
+	require.Error(t, GenerateKeyringFile(path, "2026-11"))
+
+	otherPath := filepath.Join(t.TempDir(), "keyring.json")
+	require.NoError(t, GenerateKeyringFile(otherPath, "2026-10"))
+	otherKeyring, err := LoadKeyringFile(otherPath)
+	require.NoError(t, err)
+	_, signingKey := keyring.signingKey()
+	_, otherSigningKey := otherKeyring.signingKey()
+	require.False(t, signingKey.Equal(otherSigningKey))
+}
+
+func TestNewKeyringMismatchedSigningKey(t *testing.T) {
+	signingKey := randomKey(t)
+	otherKey := randomKey(t)
+
+	keyring, err := NewKeyring("current", signingKey, map[string]ed25519.PublicKey{
+		"current": otherKey.Public().(ed25519.PublicKey),
+	})
+	require.Error(t, err)
+	require.Nil(t, keyring)
+}
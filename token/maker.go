@@ -1,8 +1,69 @@
 package token
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+)
 
 type Maker interface {
-	CreateToken(username string, duration time.Duration) (string, *Payload, error)
+	CreateToken(username string, role string, sessionID pgtype.UUID, tokenType TokenType, duration time.Duration) (string, *Payload, error)
 	VerifyToken(token string) (*Payload, error)
 }
+
+const (
+	TypeJWT          = "jwt"
+	TypePaseto       = "paseto"
+	TypeEdDSAJWT     = "eddsa_jwt"
+	TypePasetoPublic = "paseto_public"
+)
+
+// NewMaker builds the maker selected by TOKEN_TYPE, which defaults to jwt.
+// If TOKEN_ACCEPTED_TYPES is set, tokens of those types are still accepted, so a change
+// of TOKEN_TYPE doesn't invalidate the tokens already issued. The keyring is only
+// needed by the eddsa_jwt and paseto_public types
+func NewMaker(config util.Config, keyring *Keyring) (Maker, error) {
+	maker, err := newMakerOfType(config.TokenType, config, keyring)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.TokenAcceptedTypes) == 0 {
+		return maker, nil
+	}
+
+	verifiers := make([]Maker, 0, len(config.TokenAcceptedTypes))
+	for _, tokenType := range config.TokenAcceptedTypes {
+		verifier, err := newMakerOfType(tokenType, config, keyring)
+		if err != nil {
+			return nil, err
+		}
+		verifiers = append(verifiers, verifier)
+	}
+	return NewMultiMaker(maker, verifiers...), nil
+}
+
+// UsesKeyring reports whether TOKEN_TYPE or one of TOKEN_ACCEPTED_TYPES signs its tokens with the keyring
+func UsesKeyring(config util.Config) bool {
+	for _, tokenType := range append([]string{config.TokenType}, config.TokenAcceptedTypes...) {
+		if tokenType == TypeEdDSAJWT || tokenType == TypePasetoPublic {
+			return true
+		}
+	}
+	return false
+}
+
+func newMakerOfType(tokenType string, config util.Config, keyring *Keyring) (Maker, error) {
+	switch tokenType {
+	case "", TypeJWT:
+		return NewJWTMaker(config.TokenSymmetricKey)
+	case TypePaseto:
+		return NewPasetoMaker(config.TokenSymmetricKey)
+	case TypeEdDSAJWT:
+		return NewEdDSAJWTMaker(keyring)
+	case TypePasetoPublic:
+		return NewPasetoPublicMaker(keyring)
+	}
+	return nil, fmt.Errorf("unsupported token type: %s", tokenType)
+}
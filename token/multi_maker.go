@@ -0,0 +1,43 @@
+package token
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// MultiMaker creates tokens with one maker, and accepts the tokens of several makers.
+// It lets the token format change while the tokens issued in the old format are still valid
+type MultiMaker struct {
+	maker     Maker
+	verifiers []Maker
+}
+
+// NewMultiMaker creates tokens with maker, and verifies them with maker then with each of verifiers
+func NewMultiMaker(maker Maker, verifiers ...Maker) Maker {
+	return &MultiMaker{
+		maker:     maker,
+		verifiers: append([]Maker{maker}, verifiers...),
+	}
+}
+
+func (maker *MultiMaker) CreateToken(username string, role string, sessionID pgtype.UUID, tokenType TokenType, duration time.Duration) (string, *Payload, error) {
+	return maker.maker.CreateToken(username, role, sessionID, tokenType, duration)
+}
+
+// VerifyToken returns the payload of the first maker that accepts the token.
+// ErrExpiredToken is returned if a maker recognized the token but it has expired
+func (maker *MultiMaker) VerifyToken(token string) (*Payload, error) {
+	verifyErr := ErrInvalidToken
+	for _, verifier := range maker.verifiers {
+		payload, err := verifier.VerifyToken(token)
+		if err == nil {
+			return payload, nil
+		}
+		if errors.Is(err, ErrExpiredToken) {
+			verifyErr = err
+		}
+	}
+	return nil, verifyErr
+}
@@ -0,0 +1,108 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMaker(t *testing.T) {
+	keyring := randomKeyring(t)
+
+	testCases := []struct {
+		tokenType string
+		maker     Maker
+	}{
+		{tokenType: "", maker: &JWTMaker{}},
+		{tokenType: TypeJWT, maker: &JWTMaker{}},
+		{tokenType: TypePaseto, maker: &PasetoMaker{}},
+		{tokenType: TypeEdDSAJWT, maker: &EdDSAJWTMaker{}},
+		{tokenType: TypePasetoPublic, maker: &PasetoPublicMaker{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.tokenType, func(t *testing.T) {
+			config := util.Config{
+				TokenType:         tc.tokenType,
+				TokenSymmetricKey: util.RandomString(32),
+			}
+
+			maker, err := NewMaker(config, keyring)
+			require.NoError(t, err)
+			require.IsType(t, tc.maker, maker)
+		})
+	}
+}
+
+func TestNewMakerErrors(t *testing.T) {
+	_, err := NewMaker(util.Config{TokenType: "unknown"}, nil)
+	require.EqualError(t, err, "unsupported token type: unknown")
+
+	_, err = NewMaker(util.Config{TokenType: TypeEdDSAJWT}, nil)
+	require.Error(t, err)
+
+	_, err = NewMaker(util.Config{
+		TokenType:          TypeJWT,
+		TokenSymmetricKey:  util.RandomString(32),
+		TokenAcceptedTypes: []string{"unknown"},
+	}, nil)
+	require.Error(t, err)
+}
+
+func TestUsesKeyring(t *testing.T) {
+	require.False(t, UsesKeyring(util.Config{}))
+	require.False(t, UsesKeyring(util.Config{TokenType: TypePaseto, TokenAcceptedTypes: []string{TypeJWT}}))
+	require.True(t, UsesKeyring(util.Config{TokenType: TypeEdDSAJWT}))
+	require.True(t, UsesKeyring(util.Config{TokenType: TypeJWT, TokenAcceptedTypes: []string{TypePasetoPublic}}))
+}
+
+func TestMultiMaker(t *testing.T) {
+	keyring := randomKeyring(t)
+	config := util.Config{
+		TokenType:         TypeJWT,
+		TokenSymmetricKey: util.RandomString(32),
+	}
+
+	oldMaker, err := NewMaker(config, keyring)
+	require.NoError(t, err)
+	oldToken, _, err := oldMaker.CreateToken(util.RandomOwner(), util.DepositorRole, pgtype.UUID{}, TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	expiredToken, _, err := oldMaker.CreateToken(util.RandomOwner(), util.DepositorRole, pgtype.UUID{}, TokenTypeAccess, -time.Minute)
+	require.NoError(t, err)
+
+	// switch to PASETO while still accepting the JWTs already issued
+	config.TokenType = TypePasetoPublic
+	config.TokenAcceptedTypes = []string{TypeJWT}
+	maker, err := NewMaker(config, keyring)
+	require.NoError(t, err)
+	require.IsType(t, &MultiMaker{}, maker)
+
+	newToken, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, pgtype.UUID{}, TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	require.Regexp(t, `^v2\.public\.`, newToken)
+
+	_, err = maker.VerifyToken(newToken)
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(expiredToken)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+
+	payload, err = maker.VerifyToken("invalid")
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+
+	// once the old format is no longer accepted, its tokens are rejected
+	config.TokenAcceptedTypes = nil
+	maker, err = NewMaker(config, keyring)
+	require.NoError(t, err)
+
+	payload, err = maker.VerifyToken(oldToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
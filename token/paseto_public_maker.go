@@ -0,0 +1,65 @@
+package token
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/o1egl/paseto"
+)
+
+// keyFooter is the footer of a v2.public token, naming the key that signed it
+type keyFooter struct {
+	KeyID string `json:"kid"`
+}
+
+// PasetoPublicMaker signs v2.public PASETO tokens with the signing key of a keyring
+type PasetoPublicMaker struct {
+	paseto  *paseto.V2
+	keyring *Keyring
+}
+
+func NewPasetoPublicMaker(keyring *Keyring) (Maker, error) {
+	if keyring == nil {
+		return nil, fmt.Errorf("missing keyring")
+	}
+	return &PasetoPublicMaker{
+		paseto:  paseto.NewV2(),
+		keyring: keyring,
+	}, nil
+}
+
+func (maker *PasetoPublicMaker) CreateToken(username string, role string, sessionID pgtype.UUID, tokenType TokenType, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, sessionID, tokenType, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	keyID, signingKey := maker.keyring.signingKey()
+	token, err := maker.paseto.Sign(signingKey, payload, keyFooter{KeyID: keyID})
+	return token, payload, err
+}
+
+func (maker *PasetoPublicMaker) VerifyToken(token string) (*Payload, error) {
+	var footer keyFooter
+	if err := paseto.ParseFooter(token, &footer); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	key, ok := maker.keyring.verificationKey(footer.KeyID)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	payload := &Payload{}
+	err := maker.paseto.Verify(token, key, payload, nil)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	err = payload.Valid()
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
@@ -10,15 +10,31 @@ import (
 
 var ErrExpiredToken = errors.New("token has expired")
 var ErrInvalidToken = errors.New("token is invalid")
+var ErrWrongTokenType = errors.New("token is of the wrong type")
 
+// TokenType is what a token was issued for, so that a long-lived refresh token
+// cannot be used as an access token
+type TokenType string
+
+const (
+	TokenTypeAccess       TokenType = "access"
+	TokenTypeRefresh      TokenType = "refresh"
+	TokenTypeMFAChallenge TokenType = "mfa_challenge"
+)
+
+// Payload is the data carried by a token. SessionID is the login session the token
+// was issued for, and stays the same when the refresh token is rotated
 type Payload struct {
 	ID        pgtype.UUID `json:"id"`
+	SessionID pgtype.UUID `json:"session_id"`
+	Type      TokenType   `json:"token_type"`
 	Username  string      `json:"username"`
+	Role      string      `json:"role"`
 	IssuedAt  time.Time   `json:"issued_at"`
 	ExpiredAt time.Time   `json:"expired_at"`
 }
 
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+func NewPayload(username string, role string, sessionID pgtype.UUID, tokenType TokenType, duration time.Duration) (*Payload, error) {
 	u := uuid.New()
 	pgUUID := pgtype.UUID{
 		Bytes: u,
@@ -26,7 +42,10 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 	}
 	payload := &Payload{
 		ID:        pgUUID,
+		SessionID: sessionID,
+		Type:      tokenType,
 		Username:  username,
+		Role:      role,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}
@@ -40,3 +59,12 @@ func (p *Payload) Valid() error {
 	}
 	return nil
 }
+
+// CheckType returns ErrWrongTokenType unless the token was issued as tokenType.
+// Tokens issued before the type was added have none, and are rejected as well
+func (p *Payload) CheckType(tokenType TokenType) error {
+	if p.Type != tokenType {
+		return ErrWrongTokenType
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadCheckType(t *testing.T) {
+	payload, err := NewPayload(util.RandomOwner(), util.DepositorRole, pgtype.UUID{}, TokenTypeRefresh, time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, payload.CheckType(TokenTypeRefresh))
+	require.ErrorIs(t, payload.CheckType(TokenTypeAccess), ErrWrongTokenType)
+	require.ErrorIs(t, payload.CheckType(TokenTypeMFAChallenge), ErrWrongTokenType)
+
+	// tokens issued before the type claim was added have none
+	payload.Type = ""
+	require.ErrorIs(t, payload.CheckType(TokenTypeAccess), ErrWrongTokenType)
+}
@@ -7,14 +7,62 @@ import (
 )
 
 type Config struct {
-	Environment          string        `mapstructure:"ENVIRONMENT"`
-	DBSource             string        `mapstructure:"DB_SOURCE"`
-	HTTPServerAddress    string        `mapstructure:"HTTP_SERVER_ADDRESS"`
-	GRPCServerAddress    string        `mapstructure:"GRPC_SERVER_ADDRESS"`
-	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
-	MigrationURL         string        `mapstructure:"MIGRATION_URL"`
+	Environment                 string        `mapstructure:"ENVIRONMENT"`
+	DBSource                    string        `mapstructure:"DB_SOURCE"`
+	HTTPServerAddress           string        `mapstructure:"HTTP_SERVER_ADDRESS"`
+	GRPCServerAddress           string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	TokenType                   string        `mapstructure:"TOKEN_TYPE"`
+	TokenAcceptedTypes          []string      `mapstructure:"TOKEN_ACCEPTED_TYPES"`
+	TokenSymmetricKey           string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	TokenKeyringFile            string        `mapstructure:"TOKEN_KEYRING_FILE"`
+	KeyringReloadInterval       time.Duration `mapstructure:"TOKEN_KEYRING_RELOAD_INTERVAL"`
+	AccessTokenDuration         time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration        time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	MigrationURL                string        `mapstructure:"MIGRATION_URL"`
+	IdempotencyKeyDuration      time.Duration `mapstructure:"IDEMPOTENCY_KEY_DURATION"`
+	FXRateProvider              string        `mapstructure:"FX_RATE_PROVIDER"`
+	FXRatesFile                 string        `mapstructure:"FX_RATES_FILE"`
+	FXRateURL                   string        `mapstructure:"FX_RATE_URL"`
+	FXRoundingMode              string        `mapstructure:"FX_ROUNDING_MODE"`
+	RedisAddress                string        `mapstructure:"REDIS_ADDRESS"`
+	EmailSenderType             string        `mapstructure:"EMAIL_SENDER_TYPE"`
+	EmailSenderName             string        `mapstructure:"EMAIL_SENDER_NAME"`
+	EmailSenderAddress          string        `mapstructure:"EMAIL_SENDER_ADDRESS"`
+	EmailSenderPassword         string        `mapstructure:"EMAIL_SENDER_PASSWORD"`
+	SMTPServerAddress           string        `mapstructure:"SMTP_SERVER_ADDRESS"`
+	EmailOutboxDir              string        `mapstructure:"EMAIL_OUTBOX_DIR"`
+	VerifyEmailURL              string        `mapstructure:"VERIFY_EMAIL_URL"`
+	PasswordResetURL            string        `mapstructure:"PASSWORD_RESET_URL"`
+	PasswordResetDuration       time.Duration `mapstructure:"PASSWORD_RESET_DURATION"`
+	SessionCleanupSchedule      string        `mapstructure:"SESSION_CLEANUP_SCHEDULE"`
+	ReconciliationSchedule      string        `mapstructure:"RECONCILIATION_SCHEDULE"`
+	ReconciliationBatchSize     int32         `mapstructure:"RECONCILIATION_BATCH_SIZE"`
+	ReconciliationFreeze        bool          `mapstructure:"RECONCILIATION_FREEZE"`
+	ReconciliationReportDir     string        `mapstructure:"RECONCILIATION_REPORT_DIR"`
+	ScheduledTransferSchedule   string        `mapstructure:"SCHEDULED_TRANSFER_SCHEDULE"`
+	ScheduledTransferBatchSize  int32         `mapstructure:"SCHEDULED_TRANSFER_BATCH_SIZE"`
+	RevocationCacheTTL          time.Duration `mapstructure:"REVOCATION_CACHE_TTL"`
+	MFAIssuer                   string        `mapstructure:"MFA_ISSUER"`
+	MFAEncryptionKey            string        `mapstructure:"MFA_ENCRYPTION_KEY"`
+	MFAChallengeKey             string        `mapstructure:"MFA_CHALLENGE_KEY"`
+	MFAChallengeDuration        time.Duration `mapstructure:"MFA_CHALLENGE_DURATION"`
+	MFATransferThresholds       string        `mapstructure:"MFA_TRANSFER_THRESHOLDS"`
+	LoginAttemptStore           string        `mapstructure:"LOGIN_ATTEMPT_STORE"`
+	LoginMaxFailures            int64         `mapstructure:"LOGIN_MAX_FAILURES"`
+	LoginMaxFailuresPerIP       int64         `mapstructure:"LOGIN_MAX_FAILURES_PER_IP"`
+	LoginBackoffDelay           time.Duration `mapstructure:"LOGIN_BACKOFF_DELAY"`
+	LoginLockoutDuration        time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+	TrustedProxies              []string      `mapstructure:"TRUSTED_PROXIES"`
+	PasswordMinLength           int           `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordMinCharacterClasses int           `mapstructure:"PASSWORD_MIN_CHARACTER_CLASSES"`
+	PasswordMinEntropyBits      float64       `mapstructure:"PASSWORD_MIN_ENTROPY_BITS"`
+	PasswordHistorySize         int           `mapstructure:"PASSWORD_HISTORY_SIZE"`
+	PasswordBreachedFile        string        `mapstructure:"PASSWORD_BREACHED_FILE"`
+	PasswordHashAlgorithm       string        `mapstructure:"PASSWORD_HASH_ALGORITHM"`
+	PasswordArgon2idMemory      uint32        `mapstructure:"PASSWORD_ARGON2ID_MEMORY"`
+	PasswordArgon2idIterations  uint32        `mapstructure:"PASSWORD_ARGON2ID_ITERATIONS"`
+	PasswordArgon2idParallelism uint8         `mapstructure:"PASSWORD_ARGON2ID_PARALLELISM"`
+	PasswordBcryptCost          int           `mapstructure:"PASSWORD_BCRYPT_COST"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -1,21 +1,249 @@
 package util
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	HashAlgorithmArgon2id = "argon2id"
+	HashAlgorithmBcrypt   = "bcrypt"
+)
+
+const (
+	argon2idSaltLength = 16
+	argon2idKeyLength  = 32
+	// bcryptSaltLength is the number of characters of the salt in a bcrypt hash, which is followed by the hash itself
+	bcryptSaltLength = 22
+)
+
+var (
+	ErrMismatchedPassword = errors.New("password doesn't match the hash")
+	ErrUnsupportedHash    = errors.New("unsupported password hash format")
+)
+
+// Argon2idParams are the costs of an argon2id hash. Memory is in KiB
+type Argon2idParams struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+// DefaultArgon2idParams are the parameters used when none are configured
+var DefaultArgon2idParams = Argon2idParams{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+}
+
+// PasswordHasher hashes passwords with the configured algorithm into PHC strings, such as
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash> or $bcrypt$r=12$<salt>$<hash>.
+// It verifies hashes of every supported algorithm, including the bare bcrypt hashes stored before
+type PasswordHasher struct {
+	algorithm  string
+	argon2id   Argon2idParams
+	bcryptCost int
+	// dummyHash is a hash that no password is checked against successfully
+	dummyHash func() string
+}
+
+// NewPasswordHasher builds the hasher selected by PASSWORD_HASH_ALGORITHM.
+// The parameters that aren't configured take their default values
+func NewPasswordHasher(config Config) (*PasswordHasher, error) {
+	hasher := &PasswordHasher{
+		algorithm:  config.PasswordHashAlgorithm,
+		argon2id:   DefaultArgon2idParams,
+		bcryptCost: bcrypt.DefaultCost,
+	}
+
+	if config.PasswordArgon2idMemory > 0 {
+		hasher.argon2id.Memory = config.PasswordArgon2idMemory
+	}
+	if config.PasswordArgon2idIterations > 0 {
+		hasher.argon2id.Iterations = config.PasswordArgon2idIterations
+	}
+	if config.PasswordArgon2idParallelism > 0 {
+		hasher.argon2id.Parallelism = config.PasswordArgon2idParallelism
+	}
+	if config.PasswordBcryptCost > 0 {
+		hasher.bcryptCost = config.PasswordBcryptCost
+	}
+
+	switch hasher.algorithm {
+	case "":
+		hasher.algorithm = HashAlgorithmArgon2id
+	case HashAlgorithmArgon2id:
+	case HashAlgorithmBcrypt:
+		if hasher.bcryptCost < bcrypt.MinCost || hasher.bcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost must be from %d-%d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm: %s", hasher.algorithm)
+	}
+
+	hasher.dummyHash = sync.OnceValue(func() string {
+		hashedPassword, _ := hasher.Hash(RandomString(32))
+		return hashedPassword
+	})
+	return hasher, nil
+}
+
+// Hash hashes the password with the configured algorithm and parameters
+func (hasher *PasswordHasher) Hash(password string) (string, error) {
+	if hasher.algorithm == HashAlgorithmBcrypt {
+		return hashBcrypt(password, hasher.bcryptCost)
+	}
+	return hashArgon2id(password, hasher.argon2id)
+}
+
+// Verify checks the password against a hash of any supported format.
+// It returns ErrMismatchedPassword if the password is wrong. When the password is right, needsRehash tells
+// whether the hash was made with another algorithm or other parameters than configured, and should be replaced
+func (hasher *PasswordHasher) Verify(password string, hashedPassword string) (needsRehash bool, err error) {
+	switch {
+	case strings.HasPrefix(hashedPassword, "$"+HashAlgorithmArgon2id+"$"):
+		params, err := verifyArgon2id(password, hashedPassword)
+		if err != nil {
+			return false, err
+		}
+		return hasher.algorithm != HashAlgorithmArgon2id || params != hasher.argon2id, nil
+
+	case strings.HasPrefix(hashedPassword, "$"+HashAlgorithmBcrypt+"$"):
+		cost, err := verifyBcrypt(password, hashedPassword)
+		if err != nil {
+			return false, err
+		}
+		return hasher.algorithm != HashAlgorithmBcrypt || cost != hasher.bcryptCost, nil
+
+	case isLegacyBcryptHash(hashedPassword):
+		if err := compareBcrypt(password, hashedPassword); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, ErrUnsupportedHash
+}
+
+// VerifyUnknownUser takes as long as verifying the password of an existing user,
+// so that the time of a failed login doesn't reveal whether the username exists
+func (hasher *PasswordHasher) VerifyUnknownUser(password string) {
+	hasher.Verify(password, hasher.dummyHash())
+}
+
+// defaultPasswordHasher hashes with the default parameters
+var defaultPasswordHasher = sync.OnceValue(func() *PasswordHasher {
+	hasher, _ := NewPasswordHasher(Config{})
+	return hasher
+})
+
+// HashPassword hashes the password with argon2id and the default parameters
 func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return defaultPasswordHasher().Hash(password)
+}
 
+// CheckPassword checks the password against a hash of any supported format
+func CheckPassword(password, hashedPasword string) error {
+	_, err := defaultPasswordHasher().Verify(password, hashedPasword)
+	return err
+}
+
+func hashArgon2id(password string, params Argon2idParams) (string, error) {
+	salt := make([]byte, argon2idSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, argon2idKeyLength)
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		HashAlgorithmArgon2id, argon2.Version, params.Memory, params.Iterations, params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func verifyArgon2id(password string, hashedPassword string) (Argon2idParams, error) {
+	var params Argon2idParams
+
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 {
+		return params, ErrUnsupportedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, ErrUnsupportedHash
+	}
+
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism)
+	if err != nil {
+		return params, ErrUnsupportedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, ErrUnsupportedHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, ErrUnsupportedHash
+	}
+
+	otherKey := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, otherKey) != 1 {
+		return params, ErrMismatchedPassword
+	}
+	return params, nil
+}
+
+func hashBcrypt(password string, cost int) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), cost)
 	if err != nil {
 		return "", fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	return string(hashedPassword), nil
+	// a bcrypt hash is $2a$<cost>$<salt><hash>
+	saltAndHash := string(hashedPassword[strings.LastIndexByte(string(hashedPassword), '$')+1:])
+	return fmt.Sprintf("$%s$r=%d$%s$%s",
+		HashAlgorithmBcrypt, cost, saltAndHash[:bcryptSaltLength], saltAndHash[bcryptSaltLength:]), nil
 }
 
-func CheckPassword(password, hashedPasword string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPasword), []byte(password))
+func verifyBcrypt(password string, hashedPassword string) (int, error) {
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 5 {
+		return 0, ErrUnsupportedHash
+	}
+
+	var cost int
+	if _, err := fmt.Sscanf(parts[2], "r=%d", &cost); err != nil {
+		return 0, ErrUnsupportedHash
+	}
+
+	err := compareBcrypt(password, fmt.Sprintf("$2a$%02d$%s%s", cost, parts[3], parts[4]))
+	return cost, err
+}
+
+func isLegacyBcryptHash(hashedPassword string) bool {
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$"} {
+		if strings.HasPrefix(hashedPassword, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func compareBcrypt(password string, hashedPassword string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrMismatchedPassword
+	}
+	if err != nil {
+		return ErrUnsupportedHash
+	}
+	return nil
 }
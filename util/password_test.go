@@ -7,11 +7,31 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// testArgon2idParams keep the tests fast
+var testArgon2idParams = Argon2idParams{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+}
+
+func newTestPasswordHasher(t *testing.T, algorithm string, params Argon2idParams, bcryptCost int) *PasswordHasher {
+	hasher, err := NewPasswordHasher(Config{
+		PasswordHashAlgorithm:       algorithm,
+		PasswordArgon2idMemory:      params.Memory,
+		PasswordArgon2idIterations:  params.Iterations,
+		PasswordArgon2idParallelism: params.Parallelism,
+		PasswordBcryptCost:          bcryptCost,
+	})
+	require.NoError(t, err)
+	return hasher
+}
+
 func TestPassword(t *testing.T) {
 	password := RandomString(6)
 	hashedPassword, err := HashPassword(password)
 	require.NoError(t, err)
 	require.NotEmpty(t, hashedPassword)
+	require.Regexp(t, `^\$argon2id\$v=19\$m=65536,t=3,p=2\$`, hashedPassword)
 
 	err = CheckPassword(password, hashedPassword)
 
@@ -21,7 +41,7 @@ func TestPassword(t *testing.T) {
 
 	err = CheckPassword(wrongPassword, hashedPassword)
 
-	require.EqualError(t, err, bcrypt.ErrMismatchedHashAndPassword.Error())
+	require.EqualError(t, err, ErrMismatchedPassword.Error())
 
 	hashedPasswordAgain, err := HashPassword(password)
 
@@ -29,3 +49,98 @@ func TestPassword(t *testing.T) {
 	require.NotEmpty(t, hashedPassword)
 	require.NotEqual(t, hashedPassword, hashedPasswordAgain)
 }
+
+func TestPasswordHasher(t *testing.T) {
+	testCases := []struct {
+		name      string
+		algorithm string
+		pattern   string
+	}{
+		{
+			name:      "Argon2id",
+			algorithm: HashAlgorithmArgon2id,
+			pattern:   `^\$argon2id\$v=19\$m=1024,t=1,p=1\$[A-Za-z0-9+/]{22}\$[A-Za-z0-9+/]{43}$`,
+		},
+		{
+			name:      "Bcrypt",
+			algorithm: HashAlgorithmBcrypt,
+			pattern:   `^\$bcrypt\$r=4\$[A-Za-z0-9./]{22}\$[A-Za-z0-9./]{31}$`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hasher := newTestPasswordHasher(t, tc.algorithm, testArgon2idParams, bcrypt.MinCost)
+
+			password := RandomString(8)
+			hashedPassword, err := hasher.Hash(password)
+			require.NoError(t, err)
+			require.Regexp(t, tc.pattern, hashedPassword)
+
+			needsRehash, err := hasher.Verify(password, hashedPassword)
+			require.NoError(t, err)
+			require.False(t, needsRehash)
+
+			_, err = hasher.Verify(RandomString(8), hashedPassword)
+			require.ErrorIs(t, err, ErrMismatchedPassword)
+		})
+	}
+}
+
+func TestPasswordHasherNeedsRehash(t *testing.T) {
+	password := RandomString(8)
+
+	legacyHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	weakBcrypt := newTestPasswordHasher(t, HashAlgorithmBcrypt, testArgon2idParams, bcrypt.MinCost)
+	weakBcryptHash, err := weakBcrypt.Hash(password)
+	require.NoError(t, err)
+
+	weakArgon2id := newTestPasswordHasher(t, HashAlgorithmArgon2id, testArgon2idParams, bcrypt.MinCost)
+	weakArgon2idHash, err := weakArgon2id.Hash(password)
+	require.NoError(t, err)
+
+	strongerParams := testArgon2idParams
+	strongerParams.Iterations = 2
+	hasher := newTestPasswordHasher(t, HashAlgorithmArgon2id, strongerParams, bcrypt.MinCost)
+
+	for _, hashedPassword := range []string{string(legacyHash), weakBcryptHash, weakArgon2idHash} {
+		needsRehash, err := hasher.Verify(password, hashedPassword)
+		require.NoError(t, err)
+		require.True(t, needsRehash, hashedPassword)
+
+		_, err = hasher.Verify(RandomString(8), hashedPassword)
+		require.ErrorIs(t, err, ErrMismatchedPassword)
+	}
+
+	// a higher bcrypt cost asks for a rehash of the bcrypt hashes
+	strongerBcrypt := newTestPasswordHasher(t, HashAlgorithmBcrypt, testArgon2idParams, bcrypt.MinCost+1)
+	needsRehash, err := strongerBcrypt.Verify(password, weakBcryptHash)
+	require.NoError(t, err)
+	require.True(t, needsRehash)
+}
+
+func TestPasswordHasherUnsupportedHash(t *testing.T) {
+	hasher := newTestPasswordHasher(t, HashAlgorithmArgon2id, testArgon2idParams, 0)
+
+	for _, hashedPassword := range []string{
+		"",
+		"plaintext",
+		"$scrypt$ln=15,r=8,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=1024,t=1,p=1$not base64!$aGFzaA",
+		"$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$aGFzaA",
+		"$bcrypt$r=4$tooshort",
+	} {
+		_, err := hasher.Verify(RandomString(8), hashedPassword)
+		require.ErrorIs(t, err, ErrUnsupportedHash, hashedPassword)
+	}
+}
+
+func TestNewPasswordHasherErrors(t *testing.T) {
+	_, err := NewPasswordHasher(Config{PasswordHashAlgorithm: "md5"})
+	require.EqualError(t, err, "unsupported password hash algorithm: md5")
+
+	_, err = NewPasswordHasher(Config{PasswordHashAlgorithm: HashAlgorithmBcrypt, PasswordBcryptCost: 40})
+	require.Error(t, err)
+}
@@ -0,0 +1,24 @@
+package util
+
+import (
+	"slices"
+)
+
+// Reason codes of a transfer reversal, recorded for audit
+const (
+	ReversalReasonDuplicate       = "duplicate"
+	ReversalReasonFraud           = "fraud"
+	ReversalReasonCustomerRequest = "customer_request"
+	ReversalReasonProcessingError = "processing_error"
+)
+
+var REVERSAL_REASONS = []string{
+	ReversalReasonDuplicate,
+	ReversalReasonFraud,
+	ReversalReasonCustomerRequest,
+	ReversalReasonProcessingError,
+}
+
+func IsSupportedReversalReason(reason string) bool {
+	return slices.Contains(REVERSAL_REASONS, reason)
+}
@@ -0,0 +1,9 @@
+package util
+
+// Roles a user can have. Depositors can only access their own users and accounts,
+// while bankers and admins can access any of them
+const (
+	DepositorRole = "depositor"
+	BankerRole    = "banker"
+	AdminRole     = "admin"
+)
@@ -0,0 +1,28 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// secretCodeBytes is the number of random bytes of a secret code
+const secretCodeBytes = 32
+
+// RandomSecretCode generates a URL safe secret code from a cryptographically secure source
+func RandomSecretCode() (string, error) {
+	b := make([]byte, secretCodeBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("cannot read random bytes: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashSecretCode returns the hash a secret code is stored as, so that a leaked table doesn't leak
+// usable codes. The codes are random, so unlike passwords they don't need a slow hash
+func HashSecretCode(secretCode string) string {
+	sum := sha256.Sum256([]byte(secretCode))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,31 @@
+package util
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandomSecretCode(t *testing.T) {
+	code1, err := RandomSecretCode()
+	require.NoError(t, err)
+
+	b, err := base64.RawURLEncoding.DecodeString(code1)
+	require.NoError(t, err)
+	require.Len(t, b, secretCodeBytes)
+
+	code2, err := RandomSecretCode()
+	require.NoError(t, err)
+	require.NotEqual(t, code1, code2)
+}
+
+func TestHashSecretCode(t *testing.T) {
+	code, err := RandomSecretCode()
+	require.NoError(t, err)
+
+	hash := HashSecretCode(code)
+	require.Len(t, hash, 64)
+	require.Equal(t, hash, HashSecretCode(code))
+	require.NotEqual(t, hash, HashSecretCode(code+"x"))
+}
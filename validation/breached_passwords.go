@@ -0,0 +1,79 @@
+package validation
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// hashPrefixLength is the number of hex characters of a SHA-1 hash that select its range
+const hashPrefixLength = 5
+
+// BreachedPasswords is an offline list of the SHA-1 hashes of breached passwords.
+// Like the Pwned Passwords range API, it is looked up by the first characters of the hash,
+// and only the rest of the hash is compared within that range
+type BreachedPasswords struct {
+	ranges map[string][]string
+}
+
+// NewBreachedPasswords builds the list from the SHA-1 hex hashes of breached passwords
+func NewBreachedPasswords(hashes []string) (*BreachedPasswords, error) {
+	breached := &BreachedPasswords{
+		ranges: make(map[string][]string),
+	}
+
+	for _, hash := range hashes {
+		hash = strings.ToUpper(hash)
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != sha1.Size*2 {
+			return nil, fmt.Errorf("invalid SHA-1 hash: %s", hash)
+		}
+
+		prefix, suffix := hash[:hashPrefixLength], hash[hashPrefixLength:]
+		breached.ranges[prefix] = append(breached.ranges[prefix], suffix)
+	}
+
+	for _, suffixes := range breached.ranges {
+		slices.Sort(suffixes)
+	}
+	return breached, nil
+}
+
+// LoadBreachedPasswordsFile reads a file with one HASH[:COUNT] line per breached password,
+// the format of the Pwned Passwords downloads. Empty lines and lines starting with # are skipped
+func LoadBreachedPasswordsFile(path string) (*BreachedPasswords, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open breached passwords file: %w", err)
+	}
+	defer file.Close()
+
+	var hashes []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		hash, _, _ := strings.Cut(line, ":")
+		hashes = append(hashes, hash)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read breached passwords file: %w", err)
+	}
+
+	return NewBreachedPasswords(hashes)
+}
+
+// Contains tells whether the password is in the list
+func (breached *BreachedPasswords) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	_, found := slices.BinarySearch(breached.ranges[hash[:hashPrefixLength]], hash[hashPrefixLength:])
+	return found
+}
@@ -0,0 +1,122 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/hykura1501/simple_bank/util"
+)
+
+const (
+	minPasswordLength = 6
+	maxPasswordLength = 100
+	// minPersonalInfoLength is the length from which the username or email is looked for in a password
+	minPersonalInfoLength = 3
+)
+
+// characterClasses are the classes of characters counted by the policy, with the number of characters in each
+var characterClasses = []struct {
+	is   func(rune) bool
+	size float64
+}{
+	{is: unicode.IsLower, size: 26},
+	{is: unicode.IsUpper, size: 26},
+	{is: unicode.IsDigit, size: 10},
+	{is: func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }, size: 33},
+}
+
+// PasswordPolicy checks new passwords. Each rule is skipped when it isn't configured
+type PasswordPolicy struct {
+	MinLength           int
+	MinCharacterClasses int
+	MinEntropyBits      float64
+	// HistorySize is the number of the user's most recent passwords, the current one included, that cannot be reused
+	HistorySize int
+	Breached    *BreachedPasswords
+}
+
+// NewPasswordPolicy builds the policy configured with the PASSWORD_* settings
+func NewPasswordPolicy(config util.Config) (*PasswordPolicy, error) {
+	policy := &PasswordPolicy{
+		MinLength:           config.PasswordMinLength,
+		MinCharacterClasses: config.PasswordMinCharacterClasses,
+		MinEntropyBits:      config.PasswordMinEntropyBits,
+		HistorySize:         config.PasswordHistorySize,
+	}
+
+	if config.PasswordBreachedFile != "" {
+		breached, err := LoadBreachedPasswordsFile(config.PasswordBreachedFile)
+		if err != nil {
+			return nil, err
+		}
+		policy.Breached = breached
+	}
+	return policy, nil
+}
+
+// Validate checks a new password of the user with the given username and email.
+// It returns an error for every rule the password breaks
+func (policy *PasswordPolicy) Validate(password string, username string, email string) (errs []error) {
+	if err := ValidateString(password, max(policy.MinLength, minPasswordLength), maxPasswordLength); err != nil {
+		errs = append(errs, err)
+	}
+
+	classes, poolSize := passwordCharacterClasses(password)
+	if classes < policy.MinCharacterClasses {
+		errs = append(errs, fmt.Errorf("must contain %d of lowercase letters, uppercase letters, digits and symbols", policy.MinCharacterClasses))
+	}
+
+	if policy.MinEntropyBits > 0 && float64(len([]rune(password)))*math.Log2(poolSize) < policy.MinEntropyBits {
+		errs = append(errs, errors.New("is too easy to guess, use a longer password or more kinds of characters"))
+	}
+
+	lowerPassword := strings.ToLower(password)
+	if containsPersonalInfo(lowerPassword, username) {
+		errs = append(errs, errors.New("must not contain the username"))
+	}
+
+	localPart, _, _ := strings.Cut(email, "@")
+	if containsPersonalInfo(lowerPassword, email) || containsPersonalInfo(lowerPassword, localPart) {
+		errs = append(errs, errors.New("must not contain the email address"))
+	}
+
+	if policy.Breached != nil && policy.Breached.Contains(password) {
+		errs = append(errs, errors.New("has appeared in a data breach, choose a different password"))
+	}
+
+	return
+}
+
+// CheckHistory checks a new password against the hashes of the user's most recent passwords, newest first.
+// Only the first HistorySize hashes are compared
+func (policy *PasswordPolicy) CheckHistory(password string, hashedPasswords []string) error {
+	if len(hashedPasswords) > policy.HistorySize {
+		hashedPasswords = hashedPasswords[:policy.HistorySize]
+	}
+
+	for _, hashedPassword := range hashedPasswords {
+		if util.CheckPassword(password, hashedPassword) == nil {
+			return fmt.Errorf("must not be one of the last %d passwords", policy.HistorySize)
+		}
+	}
+	return nil
+}
+
+// passwordCharacterClasses returns the number of character classes used in the password
+// and the total number of characters in those classes
+func passwordCharacterClasses(password string) (classes int, poolSize float64) {
+	for _, class := range characterClasses {
+		if strings.IndexFunc(password, class.is) >= 0 {
+			classes++
+			poolSize += class.size
+		}
+	}
+	return
+}
+
+func containsPersonalInfo(lowerPassword string, info string) bool {
+	return len(info) >= minPersonalInfoLength && strings.Contains(lowerPassword, strings.ToLower(info))
+}
@@ -0,0 +1,145 @@
+package validation
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	breached, err := NewBreachedPasswords([]string{sha1Hex("Password123")})
+	require.NoError(t, err)
+
+	policy := &PasswordPolicy{
+		MinLength:           8,
+		MinCharacterClasses: 2,
+		Breached:            breached,
+	}
+
+	testCases := []struct {
+		name     string
+		password string
+		errs     []string
+	}{
+		{
+			name:     "OK",
+			password: "correct horse 42",
+		},
+		{
+			name:     "TooShort",
+			password: "abc123",
+			errs:     []string{"must contain from 8-100 characters"},
+		},
+		{
+			name:     "OneCharacterClass",
+			password: "abcdefghij",
+			errs:     []string{"must contain 2 of lowercase letters, uppercase letters, digits and symbols"},
+		},
+		{
+			name:     "ContainsUsername",
+			password: "xx-Alice_99",
+			errs:     []string{"must not contain the username"},
+		},
+		{
+			name:     "ContainsEmail",
+			password: "wonderland-7",
+			errs:     []string{"must not contain the email address"},
+		},
+		{
+			name:     "Breached",
+			password: "Password123",
+			errs:     []string{"has appeared in a data breach, choose a different password"},
+		},
+		{
+			name:     "SeveralRules",
+			password: "alice",
+			errs: []string{
+				"must contain from 8-100 characters",
+				"must contain 2 of lowercase letters, uppercase letters, digits and symbols",
+				"must not contain the username",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var messages []string
+			for _, err := range policy.Validate(tc.password, "alice", "wonderland@example.com") {
+				messages = append(messages, err.Error())
+			}
+			require.Equal(t, tc.errs, messages)
+		})
+	}
+}
+
+func TestPasswordPolicyEntropy(t *testing.T) {
+	policy := &PasswordPolicy{MinEntropyBits: 50}
+
+	// 8 lowercase letters and digits have about 41 bits
+	require.Len(t, policy.Validate("abcd1234", "", ""), 1)
+	// 10 characters of all four classes have about 65 bits
+	require.Empty(t, policy.Validate("aB3$eF6&hI", "", ""))
+}
+
+func TestPasswordPolicyDefaultLength(t *testing.T) {
+	policy := &PasswordPolicy{}
+
+	require.Empty(t, policy.Validate("abcdef", "", ""))
+	require.Len(t, policy.Validate("abcde", "", ""), 1)
+	require.Len(t, policy.Validate(util.RandomString(101), "", ""), 1)
+}
+
+func TestPasswordPolicyCheckHistory(t *testing.T) {
+	policy := &PasswordPolicy{HistorySize: 2}
+
+	passwords := []string{util.RandomString(8), util.RandomString(8), util.RandomString(8)}
+	hashedPasswords := make([]string, len(passwords))
+	for i, password := range passwords {
+		hashedPassword, err := util.HashPassword(password)
+		require.NoError(t, err)
+		hashedPasswords[i] = hashedPassword
+	}
+
+	require.EqualError(t, policy.CheckHistory(passwords[0], hashedPasswords), "must not be one of the last 2 passwords")
+	require.Error(t, policy.CheckHistory(passwords[1], hashedPasswords))
+	// only the most recent passwords are remembered
+	require.NoError(t, policy.CheckHistory(passwords[2], hashedPasswords))
+	require.NoError(t, policy.CheckHistory(util.RandomString(8), hashedPasswords))
+
+	policy.HistorySize = 0
+	require.NoError(t, policy.CheckHistory(passwords[0], hashedPasswords))
+}
+
+func TestLoadBreachedPasswordsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	content := "# breached passwords\n" + sha1Hex("letmein") + ":42\n\n" + sha1Hex("trustno1") + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	breached, err := LoadBreachedPasswordsFile(path)
+	require.NoError(t, err)
+	require.True(t, breached.Contains("letmein"))
+	require.True(t, breached.Contains("trustno1"))
+	require.False(t, breached.Contains("LetMeIn"))
+	require.False(t, breached.Contains(util.RandomString(12)))
+
+	require.NoError(t, os.WriteFile(path, []byte("not a hash\n"), 0o600))
+	_, err = LoadBreachedPasswordsFile(path)
+	require.Error(t, err)
+}
+
+func TestBundledBreachedPasswordsFile(t *testing.T) {
+	breached, err := LoadBreachedPasswordsFile("breached_passwords.txt")
+	require.NoError(t, err)
+	require.True(t, breached.Contains("password"))
+	require.True(t, breached.Contains("P@ssw0rd"))
+}
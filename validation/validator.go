@@ -5,11 +5,17 @@ import (
 	"fmt"
 	"net/mail"
 	"regexp"
+
+	"github.com/google/uuid"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/schedule"
+	"github.com/hykura1501/simple_bank/util"
 )
 
 var (
 	isValidUsername = regexp.MustCompile(`^[a-z0-9_]+$`).MatchString
 	isValidFullName = regexp.MustCompile(`^[a-zA-Z\s]+$`).MatchString
+	isValidTOTPCode = regexp.MustCompile(`^[0-9]{6}$`).MatchString
 )
 
 func ValidateString(value string, minLength, maxLength int) error {
@@ -40,8 +46,9 @@ func ValidateFullName(username string) error {
 	return nil
 }
 
+// ValidatePassword only checks the length of a password, use a PasswordPolicy to check a new password
 func ValidatePassword(password string) error {
-	return ValidateString(password, 6, 100)
+	return ValidateString(password, minPasswordLength, maxPasswordLength)
 }
 
 func ValidateEmail(email string) error {
@@ -53,3 +60,104 @@ func ValidateEmail(email string) error {
 	}
 	return nil
 }
+
+func ValidateSecretCode(secretCode string) error {
+	return ValidateString(secretCode, 32, 128)
+}
+
+func ValidateTOTPCode(code string) error {
+	if !isValidTOTPCode(code) {
+		return errors.New("must contain 6 digits")
+	}
+	return nil
+}
+
+func ValidateRecoveryCode(code string) error {
+	return ValidateString(code, 10, 11)
+}
+
+func ValidateCurrency(currency string) error {
+	if !util.IsSupportedCurrency(currency) {
+		return errors.New("is not a supported currency")
+	}
+	return nil
+}
+
+func ValidateReversalReason(reason string) error {
+	if !util.IsSupportedReversalReason(reason) {
+		return fmt.Errorf("must be one of %v", util.REVERSAL_REASONS)
+	}
+	return nil
+}
+
+func ValidateID(id int64) error {
+	if id < 1 {
+		return errors.New("must be a positive integer")
+	}
+	return nil
+}
+
+func ValidateUUID(value string) error {
+	if _, err := uuid.Parse(value); err != nil {
+		return errors.New("must be a valid UUID")
+	}
+	return nil
+}
+
+func ValidateAmount(amount int64) error {
+	if amount <= 0 {
+		return errors.New("must be greater than 0")
+	}
+	return nil
+}
+
+func ValidateAdjustmentAmount(amount int64) error {
+	if amount == 0 {
+		return errors.New("must not be 0")
+	}
+	return nil
+}
+
+func ValidateAdjustmentReason(reason string) error {
+	return ValidateString(reason, 1, 255)
+}
+
+func ValidateOverdraftLimit(limit int64) error {
+	if limit < 0 {
+		return errors.New("must not be negative")
+	}
+	return nil
+}
+
+func ValidatePageSize(pageSize int32, maxPageSize int32) error {
+	if pageSize < 1 || pageSize > maxPageSize {
+		return fmt.Errorf("must be from 1-%d", maxPageSize)
+	}
+	return nil
+}
+
+func ValidateEntryDirection(direction string) error {
+	if direction != db.EntryDirectionCredit && direction != db.EntryDirectionDebit {
+		return fmt.Errorf("must be %s or %s", db.EntryDirectionCredit, db.EntryDirectionDebit)
+	}
+	return nil
+}
+
+func ValidateSchedule(expression string) error {
+	if err := ValidateString(expression, 1, 100); err != nil {
+		return err
+	}
+	_, err := schedule.Parse(expression)
+	return err
+}
+
+func ValidateMaxOccurrences(maxOccurrences int32) error {
+	if maxOccurrences <= 0 {
+		return errors.New("must be greater than 0")
+	}
+	return nil
+}
+
+func ValidateIdempotencyKey(key string) error {
+	return ValidateString(key, 1, 255)
+}
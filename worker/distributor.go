@@ -12,6 +12,16 @@ type TaskDistributor interface {
 		payload *PayloadSendVerifyEmail,
 		otps ...asynq.Option,
 	) error
+	DistributeTaskSendPasswordReset(
+		ctx context.Context,
+		payload *PayloadSendPasswordReset,
+		otps ...asynq.Option,
+	) error
+	DistributeTaskSendScheduledTransferFailed(
+		ctx context.Context,
+		payload *PayloadSendScheduledTransferFailed,
+		otps ...asynq.Option,
+	) error
 }
 
 type RedisTaskDistributor struct {
@@ -2,37 +2,92 @@ package worker
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/hibiken/asynq"
 	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/mail"
+	"github.com/hykura1501/simple_bank/mfa"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	QueueCritical = "critical"
+	QueueDefault  = "default"
 )
 
 type TaskProcessor interface {
 	Start() error
 	ProcessTaskSendVerifyEmail(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendPasswordReset(ctx context.Context, task *asynq.Task) error
+	ProcessTaskDeleteExpiredSessions(ctx context.Context, task *asynq.Task) error
+	ProcessTaskReconcileLedger(ctx context.Context, task *asynq.Task) error
+	ProcessTaskExecuteScheduledTransfers(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendScheduledTransferFailed(ctx context.Context, task *asynq.Task) error
 }
 
 type RedisTaskProcessor struct {
-	server *asynq.Server
-	store  db.Store
+	server      *asynq.Server
+	store       db.Store
+	mailer      mail.EmailSender
+	distributor TaskDistributor
+	config      util.Config
+	// mfaThresholds are checked again when a scheduled transfer runs
+	mfaThresholds mfa.TransferThresholds
+	// now is the clock that decides which scheduled transfers are due
+	now func() time.Time
 }
 
-func NewRedisTaskProcessor(redisOpt asynq.RedisClientOpt, store db.Store) TaskProcessor {
+func NewRedisTaskProcessor(redisOpt asynq.RedisClientOpt, store db.Store, mailer mail.EmailSender, distributor TaskDistributor, config util.Config) (TaskProcessor, error) {
+	mfaThresholds, err := mfa.ParseTransferThresholds(config.MFATransferThresholds)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse MFA_TRANSFER_THRESHOLDS: %w", err)
+	}
+
 	server := asynq.NewServer(
 		redisOpt,
-		asynq.Config{},
+		asynq.Config{
+			Queues: map[string]int{
+				QueueCritical: 10,
+				QueueDefault:  5,
+			},
+			ErrorHandler: asynq.ErrorHandlerFunc(func(ctx context.Context, task *asynq.Task, err error) {
+				log.Error().Err(err).Str("type", task.Type()).
+					Bytes("payload", loggedPayload(task)).Msg("process task failed")
+			}),
+		},
 	)
 
 	return &RedisTaskProcessor{
-		server: server,
-		store:  store,
-	}
+		server:        server,
+		store:         store,
+		mailer:        mailer,
+		distributor:   distributor,
+		config:        config,
+		mfaThresholds: mfaThresholds,
+		now:           time.Now,
+	}, nil
 }
 
 func (processor *RedisTaskProcessor) Start() error {
 	mux := asynq.NewServeMux()
 
 	mux.HandleFunc(TaskSendVerifyEmail, processor.ProcessTaskSendVerifyEmail)
+	mux.HandleFunc(TaskSendPasswordReset, processor.ProcessTaskSendPasswordReset)
+	mux.HandleFunc(TaskDeleteExpiredSessions, processor.ProcessTaskDeleteExpiredSessions)
+	mux.HandleFunc(TaskReconcileLedger, processor.ProcessTaskReconcileLedger)
+	mux.HandleFunc(TaskExecuteScheduledTransfers, processor.ProcessTaskExecuteScheduledTransfers)
+	mux.HandleFunc(TaskSendScheduledTransferFailed, processor.ProcessTaskSendScheduledTransferFailed)
 
 	return processor.server.Start(mux)
 }
+
+// loggedPayload returns the payload of the task to log, leaving out the payloads that carry secrets
+func loggedPayload(task *asynq.Task) []byte {
+	if task.Type() == TaskSendPasswordReset {
+		return nil
+	}
+	return task.Payload()
+}
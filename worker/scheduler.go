@@ -0,0 +1,88 @@
+package worker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+// TaskScheduler enqueues the periodic tasks
+type TaskScheduler interface {
+	Start() error
+}
+
+type RedisTaskScheduler struct {
+	scheduler                         *asynq.Scheduler
+	deleteExpiredSessionsCronspec     string
+	reconcileLedgerCronspec           string
+	executeScheduledTransfersCronspec string
+}
+
+// NewRedisTaskScheduler creates a scheduler that enqueues the delete expired sessions task
+// on deleteExpiredSessionsCronspec, e.g. "@every 1h",
+// the reconcile ledger task on reconcileLedgerCronspec
+// and the execute scheduled transfers task on executeScheduledTransfersCronspec, e.g. "* * * * *".
+// An empty reconcileLedgerCronspec disables reconciliation,
+// and an empty executeScheduledTransfersCronspec disables scheduled transfers
+func NewRedisTaskScheduler(
+	redisOpt asynq.RedisClientOpt,
+	deleteExpiredSessionsCronspec string,
+	reconcileLedgerCronspec string,
+	executeScheduledTransfersCronspec string,
+) TaskScheduler {
+	scheduler := asynq.NewScheduler(redisOpt, &asynq.SchedulerOpts{
+		EnqueueErrorHandler: func(task *asynq.Task, opts []asynq.Option, err error) {
+			log.Error().Err(err).Str("type", task.Type()).Msg("enqueue periodic task failed")
+		},
+	})
+
+	return &RedisTaskScheduler{
+		scheduler:                         scheduler,
+		deleteExpiredSessionsCronspec:     deleteExpiredSessionsCronspec,
+		reconcileLedgerCronspec:           reconcileLedgerCronspec,
+		executeScheduledTransfersCronspec: executeScheduledTransfersCronspec,
+	}
+}
+
+func (scheduler *RedisTaskScheduler) Start() error {
+	_, err := scheduler.scheduler.Register(
+		scheduler.deleteExpiredSessionsCronspec,
+		NewDeleteExpiredSessionsTask(),
+		asynq.Queue(QueueDefault),
+		asynq.MaxRetry(3),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register task %s: %w", TaskDeleteExpiredSessions, err)
+	}
+
+	if scheduler.reconcileLedgerCronspec != "" {
+		// a reconciliation is a long scan, it isn't retried but waits for the next schedule
+		_, err = scheduler.scheduler.Register(
+			scheduler.reconcileLedgerCronspec,
+			NewReconcileLedgerTask(),
+			asynq.Queue(QueueDefault),
+			asynq.MaxRetry(0),
+			asynq.Unique(time.Hour),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to register task %s: %w", TaskReconcileLedger, err)
+		}
+	}
+
+	if scheduler.executeScheduledTransfersCronspec != "" {
+		// a retry can't run an occurrence twice, and a run that is late is picked up by the next one anyway
+		_, err = scheduler.scheduler.Register(
+			scheduler.executeScheduledTransfersCronspec,
+			NewExecuteScheduledTransfersTask(),
+			asynq.Queue(QueueCritical),
+			asynq.MaxRetry(3),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to register task %s: %w", TaskExecuteScheduledTransfers, err)
+		}
+	}
+
+	return scheduler.scheduler.Start()
+}
@@ -0,0 +1,27 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskDeleteExpiredSessions = "task:delete_expired_sessions"
+
+// NewDeleteExpiredSessionsTask creates the task that deletes every expired session
+func NewDeleteExpiredSessionsTask() *asynq.Task {
+	return asynq.NewTask(TaskDeleteExpiredSessions, nil)
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskDeleteExpiredSessions(ctx context.Context, task *asynq.Task) error {
+	deleted, err := processor.store.DeleteExpiredSessions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).
+		Int64("deleted", deleted).Msg("process task")
+	return nil
+}
@@ -0,0 +1,109 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskExecuteScheduledTransfers = "task:execute_scheduled_transfers"
+
+// DefaultScheduledTransferBatchSize is the number of due scheduled transfers listed at a time
+// when ScheduledTransferBatchSize isn't configured
+const DefaultScheduledTransferBatchSize = 100
+
+// NewExecuteScheduledTransfersTask creates the task that runs every scheduled transfer that is due
+func NewExecuteScheduledTransfersTask() *asynq.Task {
+	return asynq.NewTask(TaskExecuteScheduledTransfers, nil)
+}
+
+// ProcessTaskExecuteScheduledTransfers runs every scheduled transfer due at the processor's current time,
+// one database transaction each, and notifies the owners of the runs that failed.
+// If a run can't be recorded, the task fails once the batch is done and is retried:
+// the occurrences already run are no longer due, and a run is never made twice
+func (processor *RedisTaskProcessor) ProcessTaskExecuteScheduledTransfers(ctx context.Context, task *asynq.Task) error {
+	batchSize := processor.config.ScheduledTransferBatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultScheduledTransferBatchSize
+	}
+
+	now := processor.now()
+	var executed, failed int
+	for {
+		dueTransfers, err := processor.store.ListDueScheduledTransfers(ctx, db.ListDueScheduledTransfersParams{
+			Now:       pgtype.Timestamptz{Time: now, Valid: true},
+			BatchSize: batchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list due scheduled transfers: %w", err)
+		}
+
+		var errs []error
+		for _, scheduled := range dueTransfers {
+			result, err := processor.store.ExecuteScheduledTransferTx(ctx, db.ExecuteScheduledTransferTxParams{
+				ScheduledTransferID: scheduled.ID,
+				ScheduledAt:         scheduled.NextRunAt.Time,
+				Now:                 now,
+				MFAThresholds:       processor.mfaThresholds,
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to execute scheduled transfer [%d]: %w", scheduled.ID, err))
+				continue
+			}
+			if !result.Executed {
+				continue
+			}
+
+			executed++
+			if result.Run.Status == db.ScheduledTransferRunFailed {
+				failed++
+				processor.notifyScheduledTransferFailed(ctx, result)
+			}
+		}
+
+		// the transfers that couldn't be executed are still due, listing them again would loop
+		if len(errs) > 0 {
+			return errors.Join(errs...)
+		}
+		if len(dueTransfers) < int(batchSize) {
+			break
+		}
+	}
+
+	log.Info().Str("type", task.Type()).
+		Int("executed", executed).
+		Int("failed", failed).
+		Msg("process task")
+	return nil
+}
+
+// notifyScheduledTransferFailed enqueues the email that tells the owner a run failed.
+// The run is already recorded, so a notification that can't be enqueued is logged rather than retried
+func (processor *RedisTaskProcessor) notifyScheduledTransferFailed(ctx context.Context, result db.ExecuteScheduledTransferTxResult) {
+	run := result.Run
+
+	payload := &PayloadSendScheduledTransferFailed{
+		ScheduledTransferID: result.ScheduledTransfer.ID,
+		ScheduledAt:         run.ScheduledAt.Time,
+	}
+	if run.FailureReason != nil {
+		payload.FailureReason = *run.FailureReason
+	}
+
+	err := processor.distributor.DistributeTaskSendScheduledTransferFailed(ctx, payload,
+		asynq.MaxRetry(10),
+		asynq.Queue(QueueCritical),
+		asynq.TaskID(fmt.Sprintf("scheduled_transfer_run:%d", run.ID)),
+	)
+	if err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+		log.Error().Err(err).
+			Int64("scheduled_transfer_id", result.ScheduledTransfer.ID).
+			Int64("run_id", run.ID).
+			Msg("failed to notify the owner of a failed scheduled transfer")
+	}
+}
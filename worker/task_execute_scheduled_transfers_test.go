@@ -0,0 +1,172 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hibiken/asynq"
+	mockdb "github.com/hykura1501/simple_bank/db/mock"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/mfa"
+	"github.com/hykura1501/simple_bank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDistributor records the scheduled transfer failed tasks instead of enqueuing them
+type fakeDistributor struct {
+	TaskDistributor
+	failed []*PayloadSendScheduledTransferFailed
+}
+
+func (distributor *fakeDistributor) DistributeTaskSendScheduledTransferFailed(
+	ctx context.Context,
+	payload *PayloadSendScheduledTransferFailed,
+	otps ...asynq.Option,
+) error {
+	distributor.failed = append(distributor.failed, payload)
+	return nil
+}
+
+// testMFAThresholds are passed on to every run of a scheduled transfer
+var testMFAThresholds = mfa.TransferThresholds{util.USD: 100000}
+
+func newTestTaskProcessor(store db.Store, distributor TaskDistributor, now time.Time) *RedisTaskProcessor {
+	return &RedisTaskProcessor{
+		store:         store,
+		distributor:   distributor,
+		config:        util.Config{ScheduledTransferBatchSize: 2},
+		mfaThresholds: testMFAThresholds,
+		now:           func() time.Time { return now },
+	}
+}
+
+func randomDueScheduledTransfer(now time.Time) db.ScheduledTransfer {
+	return db.ScheduledTransfer{
+		ID:            util.RandomInt(1, 1000),
+		Owner:         util.RandomOwner(),
+		FromAccountID: util.RandomInt(1, 1000),
+		ToAccountID:   util.RandomInt(1, 1000),
+		Amount:        util.RandomMoney(),
+		Schedule:      "0 9 1 * *",
+		NextRunAt:     pgtype.Timestamptz{Time: now.Add(-time.Minute), Valid: true},
+		Status:        db.ScheduledTransferActive,
+	}
+}
+
+func TestProcessTaskExecuteScheduledTransfers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2026, 10, 1, 9, 0, 30, 0, time.UTC)
+	succeeded := randomDueScheduledTransfer(now)
+	failed := randomDueScheduledTransfer(now)
+	failed.ID = succeeded.ID + 1
+	failureReason := "insufficient funds"
+
+	store := mockdb.NewMockStore(ctrl)
+	listArg := db.ListDueScheduledTransfersParams{
+		Now:       pgtype.Timestamptz{Time: now, Valid: true},
+		BatchSize: 2,
+	}
+	gomock.InOrder(
+		store.EXPECT().
+			ListDueScheduledTransfers(gomock.Any(), gomock.Eq(listArg)).
+			Times(1).
+			Return([]db.ScheduledTransfer{succeeded, failed}, nil),
+		// a full batch means there may be more due transfers
+		store.EXPECT().
+			ListDueScheduledTransfers(gomock.Any(), gomock.Eq(listArg)).
+			Times(1).
+			Return([]db.ScheduledTransfer{}, nil),
+	)
+	store.EXPECT().
+		ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(db.ExecuteScheduledTransferTxParams{
+			ScheduledTransferID: succeeded.ID,
+			ScheduledAt:         succeeded.NextRunAt.Time,
+			Now:                 now,
+			MFAThresholds:       testMFAThresholds,
+		})).
+		Times(1).
+		Return(db.ExecuteScheduledTransferTxResult{
+			ScheduledTransfer: succeeded,
+			Executed:          true,
+			Run:               db.ScheduledTransferRun{ID: 1, Status: db.ScheduledTransferRunSucceeded},
+		}, nil)
+	store.EXPECT().
+		ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(db.ExecuteScheduledTransferTxParams{
+			ScheduledTransferID: failed.ID,
+			ScheduledAt:         failed.NextRunAt.Time,
+			Now:                 now,
+			MFAThresholds:       testMFAThresholds,
+		})).
+		Times(1).
+		Return(db.ExecuteScheduledTransferTxResult{
+			ScheduledTransfer: failed,
+			Executed:          true,
+			Run: db.ScheduledTransferRun{
+				ID:            2,
+				ScheduledAt:   failed.NextRunAt,
+				Status:        db.ScheduledTransferRunFailed,
+				FailureReason: &failureReason,
+			},
+		}, nil)
+
+	distributor := &fakeDistributor{}
+	processor := newTestTaskProcessor(store, distributor, now)
+
+	err := processor.ProcessTaskExecuteScheduledTransfers(context.Background(), NewExecuteScheduledTransfersTask())
+	require.NoError(t, err)
+
+	require.Equal(t, []*PayloadSendScheduledTransferFailed{{
+		ScheduledTransferID: failed.ID,
+		ScheduledAt:         failed.NextRunAt.Time,
+		FailureReason:       failureReason,
+	}}, distributor.failed)
+}
+
+func TestProcessTaskExecuteScheduledTransfersRetry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2026, 10, 1, 9, 0, 30, 0, time.UTC)
+	scheduled := randomDueScheduledTransfer(now)
+	alreadyRun := randomDueScheduledTransfer(now)
+	alreadyRun.ID = scheduled.ID + 1
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListDueScheduledTransfers(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.ScheduledTransfer{scheduled, alreadyRun}, nil)
+	store.EXPECT().
+		ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(db.ExecuteScheduledTransferTxParams{
+			ScheduledTransferID: scheduled.ID,
+			ScheduledAt:         scheduled.NextRunAt.Time,
+			Now:                 now,
+			MFAThresholds:       testMFAThresholds,
+		})).
+		Times(1).
+		Return(db.ExecuteScheduledTransferTxResult{}, errors.New("connection reset"))
+	// the occurrence was run by a previous attempt of the task
+	store.EXPECT().
+		ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(db.ExecuteScheduledTransferTxParams{
+			ScheduledTransferID: alreadyRun.ID,
+			ScheduledAt:         alreadyRun.NextRunAt.Time,
+			Now:                 now,
+			MFAThresholds:       testMFAThresholds,
+		})).
+		Times(1).
+		Return(db.ExecuteScheduledTransferTxResult{ScheduledTransfer: alreadyRun}, nil)
+
+	distributor := &fakeDistributor{}
+	processor := newTestTaskProcessor(store, distributor, now)
+
+	// the task fails so asynq retries it, rather than listing the same due transfers again
+	err := processor.ProcessTaskExecuteScheduledTransfers(context.Background(), NewExecuteScheduledTransfersTask())
+	require.ErrorContains(t, err, "connection reset")
+	require.Empty(t, distributor.failed)
+}
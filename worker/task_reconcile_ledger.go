@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/hykura1501/simple_bank/reconcile"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskReconcileLedger = "task:reconcile_ledger"
+
+// NewReconcileLedgerTask creates the task that reconciles every account against the ledger
+func NewReconcileLedgerTask() *asynq.Task {
+	return asynq.NewTask(TaskReconcileLedger, nil)
+}
+
+// ProcessTaskReconcileLedger reconciles every account and exports the report to ReconciliationReportDir.
+// Mismatches are logged but don't fail the task, retrying wouldn't fix them
+func (processor *RedisTaskProcessor) ProcessTaskReconcileLedger(ctx context.Context, task *asynq.Task) error {
+	report, err := reconcile.Run(ctx, processor.store, reconcile.Params{
+		BatchSize: processor.config.ReconciliationBatchSize,
+		Freeze:    processor.config.ReconciliationFreeze,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile ledger: %w", err)
+	}
+
+	logReconciliationReport(report)
+
+	if processor.config.ReconciliationReportDir != "" {
+		path, err := report.WriteFile(processor.config.ReconciliationReportDir)
+		if err != nil {
+			return fmt.Errorf("failed to export reconciliation report: %w", err)
+		}
+		log.Info().Str("type", task.Type()).Str("report", path).Msg("export reconciliation report")
+	}
+
+	log.Info().Str("type", task.Type()).
+		Int64("accounts", report.AccountsScanned).
+		Int("account_mismatches", len(report.AccountMismatches)).
+		Int("transfer_mismatches", len(report.TransferMismatches)).
+		Int("frozen_accounts", len(report.FrozenAccountIDs)).
+		Msg("process task")
+	return nil
+}
+
+// logReconciliationReport logs an error for each mismatch of the report
+func logReconciliationReport(report reconcile.Report) {
+	for _, mismatch := range report.AccountMismatches {
+		log.Error().Int64("account_id", mismatch.AccountID).
+			Int64("difference", mismatch.Difference).
+			Msgf("reconciliation mismatch: %s", mismatch.Details)
+	}
+	for _, mismatch := range report.TransferMismatches {
+		log.Error().Int64("transfer_id", mismatch.TransferID).
+			Msgf("reconciliation mismatch: %s", mismatch.Details)
+	}
+}
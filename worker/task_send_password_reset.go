@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskSendPasswordReset = "task:send_password_reset"
+
+// PayloadSendPasswordReset carries the reset token itself, since only its hash is stored.
+// Its payload is never logged
+type PayloadSendPasswordReset struct {
+	Username string `json:"username"`
+	Token    string `json:"token"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskSendPasswordReset(
+	ctx context.Context,
+	payload *PayloadSendPasswordReset,
+	otps ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+	task := asynq.NewTask(TaskSendPasswordReset, jsonPayload, otps...)
+	taskInfo, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).
+		Str("username", payload.Username).
+		Str("queue", taskInfo.Queue).
+		Int("max_retry", taskInfo.MaxRetry).
+		Msg("enqueue task")
+	return nil
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskSendPasswordReset(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendPasswordReset
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal task payload: %w", asynq.SkipRetry)
+	}
+
+	user, err := processor.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	resetURL, err := passwordResetLink(processor.config.PasswordResetURL, payload.Token)
+	if err != nil {
+		return fmt.Errorf("failed to build password reset link: %w", err)
+	}
+
+	subject := "Reset your Simple Bank password"
+	content := fmt.Sprintf(`Hello %s,<br/>
+We received a request to reset the password of your account.<br/>
+Please <a href="%s">click here</a> to choose a new password. The link expires in %s and can only be used once.<br/>
+If you didn't request a password reset, you can ignore this email.<br/>
+`, html.EscapeString(user.FullName), html.EscapeString(resetURL), processor.config.PasswordResetDuration)
+	to := []string{user.Email}
+
+	err = processor.mailer.SendEmail(subject, content, to, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to send password reset email: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Str("username", user.Username).
+		Str("email", user.Email).Msg("process task")
+	return nil
+}
+
+// passwordResetLink adds the reset token to the query of baseURL
+func passwordResetLink(baseURL string, token string) (string, error) {
+	link, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String(), nil
+}
@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/hykura1501/simple_bank/fx"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskSendScheduledTransferFailed = "task:send_scheduled_transfer_failed"
+
+// PayloadSendScheduledTransferFailed describes the failed run of a scheduled transfer
+type PayloadSendScheduledTransferFailed struct {
+	ScheduledTransferID int64     `json:"scheduled_transfer_id"`
+	ScheduledAt         time.Time `json:"scheduled_at"`
+	FailureReason       string    `json:"failure_reason"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskSendScheduledTransferFailed(
+	ctx context.Context,
+	payload *PayloadSendScheduledTransferFailed,
+	otps ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+	task := asynq.NewTask(TaskSendScheduledTransferFailed, jsonPayload, otps...)
+	taskInfo, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).
+		Bytes("payload", task.Payload()).
+		Str("queue", taskInfo.Queue).
+		Int("max_retry", taskInfo.MaxRetry).
+		Msg("enqueue task")
+	return nil
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskSendScheduledTransferFailed(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendScheduledTransferFailed
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal task payload: %w", asynq.SkipRetry)
+	}
+
+	scheduled, err := processor.store.GetScheduledTransfer(ctx, payload.ScheduledTransferID)
+	if err != nil {
+		return fmt.Errorf("failed to get scheduled transfer: %w", err)
+	}
+
+	user, err := processor.store.GetUser(ctx, scheduled.Owner)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	account, err := processor.store.GetAccount(ctx, scheduled.FromAccountID)
+	if err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+
+	amount, err := fx.FormatAmount(scheduled.Amount, account.Currency)
+	if err != nil {
+		return fmt.Errorf("failed to format amount: %w", asynq.SkipRetry)
+	}
+
+	subject := "Your scheduled transfer failed"
+	content := fmt.Sprintf(`Hello %s,<br/>
+Your scheduled transfer of %s %s from account %d to account %d, due on %s, could not be made:<br/>
+%s<br/>
+This transfer won't be retried, the next one will be made as scheduled. You can review your scheduled transfers at any time.<br/>
+`, html.EscapeString(user.FullName), amount, account.Currency, scheduled.FromAccountID, scheduled.ToAccountID,
+		payload.ScheduledAt.UTC().Format(time.RFC1123), html.EscapeString(payload.FailureReason))
+	to := []string{user.Email}
+
+	err = processor.mailer.SendEmail(subject, content, to, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to send scheduled transfer failed email: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("email", user.Email).Msg("process task")
+	return nil
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"html"
+	"net/url"
 
 	"github.com/hibiken/asynq"
-	"github.com/jackc/pgx/v5"
+	db "github.com/hykura1501/simple_bank/db/sqlc"
+	"github.com/hykura1501/simple_bank/util"
 	"github.com/rs/zerolog/log"
 )
 
@@ -45,18 +48,60 @@ func (processor *RedisTaskProcessor) ProcessTaskSendVerifyEmail(ctx context.Cont
 		return fmt.Errorf("failed to unmarshal task payload: %w", asynq.SkipRetry)
 	}
 
+	// the task is enqueued before the transaction creating the user commits,
+	// so a missing user is retried rather than skipped
 	user, err := processor.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
 
+	secretCode, err := util.RandomSecretCode()
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return fmt.Errorf("user doesn't exist: %w", asynq.SkipRetry)
+		return fmt.Errorf("failed to generate secret code: %w", err)
+	}
 
-		}
-		return fmt.Errorf("failed to get user: %w", err)
+	verifyEmail, err := processor.store.CreateVerifyEmail(ctx, db.CreateVerifyEmailParams{
+		Username:   user.Username,
+		Email:      user.Email,
+		SecretCode: secretCode,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create verify email: %w", err)
+	}
+
+	verifyURL, err := verifyEmailLink(processor.config.VerifyEmailURL, verifyEmail)
+	if err != nil {
+		return fmt.Errorf("failed to build verify email link: %w", err)
+	}
+
+	subject := "Welcome to Simple Bank"
+	content := fmt.Sprintf(`Hello %s,<br/>
+Thank you for registering with us!<br/>
+Please <a href="%s">click here</a> to verify your email address.<br/>
+`, html.EscapeString(user.FullName), html.EscapeString(verifyURL))
+	to := []string{user.Email}
+
+	err = processor.mailer.SendEmail(subject, content, to, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to send verify email: %w", err)
 	}
 
-	// TODO: send email to user
 	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
 		Str("email", user.Email).Msg("process task")
 	return nil
 }
+
+// verifyEmailLink adds the email id and secret code of the verify email to the query of baseURL
+func verifyEmailLink(baseURL string, verifyEmail db.VerifyEmail) (string, error) {
+	link, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := link.Query()
+	query.Set("email_id", fmt.Sprint(verifyEmail.ID))
+	query.Set("secret_code", verifyEmail.SecretCode)
+	link.RawQuery = query.Encode()
+
+	return link.String(), nil
+}